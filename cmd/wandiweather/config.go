@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+// A --config file is JSON. Each key other than "stations" sets the flag of the
+// same name with underscores for dashes, e.g. "pws_api_key" or
+// "raw_retention_days"; flags and environment variables both override it.
+// "stations" replaces the built-in station list.
+
+// configStation is one entry in a config file's station list.
+type configStation struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Elevation float64 `json:"elevation"`
+	Tier      string  `json:"tier"` // "valley_floor", "local", "mid_slope" or "upper"
+	Primary   bool    `json:"primary"`
+	Active    *bool   `json:"active"` // defaults to true
+}
+
+// loadConfig is the kong configuration loader for --config. Kong applies a
+// flag's environment variable before the file, so flags whose variable is set
+// are skipped here to keep the environment in charge.
+func loadConfig(r io.Reader) (kong.Resolver, error) {
+	file, err := kong.JSON(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	var resolver kong.ResolverFunc = func(ctx *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+		for _, env := range flag.Envs {
+			if _, ok := os.LookupEnv(env); ok {
+				return nil, nil
+			}
+		}
+		return file.Resolve(ctx, parent, flag)
+	}
+	return resolver, nil
+}
+
+// loadStations returns the station list from the config file at path, or nil
+// if the file doesn't have one.
+func loadStations(path string) ([]models.Station, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Stations []configStation `json:"stations"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	var stations []models.Station
+	for _, cs := range file.Stations {
+		stations = append(stations, models.Station{
+			StationID:     cs.ID,
+			Name:          cs.Name,
+			Latitude:      cs.Latitude,
+			Longitude:     cs.Longitude,
+			Elevation:     cs.Elevation,
+			ElevationTier: cs.Tier,
+			IsPrimary:     cs.Primary,
+			Active:        cs.Active == nil || *cs.Active,
+		})
+	}
+	return stations, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wandiweather.json")
+	if err := os.WriteFile(path, []byte(`{
+		"pws_api_key": "from-file",
+		"timezone": "Australia/Sydney",
+		"lapse_rate": 7.5,
+		"qc_temp_max": 45,
+		"raw_retention_days": 30,
+		"stations": [
+			{"id": "ITEST1", "name": "Test Valley", "latitude": -36.8, "longitude": 147.0, "elevation": 400, "tier": "valley_floor", "primary": true},
+			{"id": "ITEST2", "name": "Test Ridge", "elevation": 900, "tier": "upper", "active": false}
+		]
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the settings under test come from the environment
+	for _, env := range []string{"PWS_API_KEY", "TIMEZONE", "LAPSE_RATE", "QC_TEMP_MAX", "RAW_RETENTION_DAYS"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+	t.Setenv("RAW_RETENTION_DAYS", "60")
+
+	parser, err := kong.New(&cli, kong.Configuration(loadConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.Parse([]string{"--config", path, "--qc-temp-max", "48"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if cli.PWSApiKey != "from-file" || cli.Timezone != "Australia/Sydney" || cli.LapseRate != 7.5 {
+		t.Errorf("file values: key %q, timezone %q, lapse rate %v", cli.PWSApiKey, cli.Timezone, cli.LapseRate)
+	}
+	if cli.QCTempMax != 48 {
+		t.Errorf("qc temp max = %v, want 48 from the flag over the file", cli.QCTempMax)
+	}
+	if cli.RawRetentionDays != 60 {
+		t.Errorf("raw retention days = %d, want 60 from the environment over the file", cli.RawRetentionDays)
+	}
+	if cli.ForecastDays != 5 {
+		t.Errorf("forecast days = %d, want the default 5", cli.ForecastDays)
+	}
+
+	stations, err := loadStations(path)
+	if err != nil {
+		t.Fatalf("loadStations: %v", err)
+	}
+	if len(stations) != 2 {
+		t.Fatalf("got %d stations, want 2", len(stations))
+	}
+	if st := stations[0]; st.StationID != "ITEST1" || !st.IsPrimary || !st.Active || st.ElevationTier != "valley_floor" || st.Elevation != 400 {
+		t.Errorf("first station = %+v", st)
+	}
+	if stations[1].Active {
+		t.Errorf("second station is active, want inactive")
+	}
+}
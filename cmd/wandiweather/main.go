@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"log"
+	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	_ "modernc.org/sqlite"
 
 	"github.com/lox/wandiweather/internal/api"
+	"github.com/lox/wandiweather/internal/emergency"
 	"github.com/lox/wandiweather/internal/firedanger"
 	"github.com/lox/wandiweather/internal/ingest"
 	"github.com/lox/wandiweather/internal/models"
@@ -20,14 +24,44 @@ import (
 )
 
 var cli struct {
+	Config       kong.ConfigFlag `name:"config" env:"CONFIG" help:"JSON file of settings (API key, time zone, stations, thresholds, retention); flags and environment variables override it."`
 	DB           string `name:"db" default:"data/wandiweather.db" help:"Path to SQLite database."`
 	Port         string `name:"port" default:"8080" env:"PORT" help:"HTTP server port."`
 	NoPoll       bool   `name:"no-poll" help:"Disable polling (server only, for local dev)."`
+	ReadOnly     bool   `name:"readonly" env:"READONLY" help:"Serve a read-only copy of the database: no migrations, polling or admin changes."`
 	Once         bool   `name:"once" help:"Ingest once and exit (for testing)."`
 	Backfill     bool   `name:"backfill" help:"Backfill 7-day observation history."`
 	Daily        bool   `name:"daily" help:"Run daily jobs (summaries + verification) and exit."`
+	DailyDryRun  bool   `name:"daily-dryrun" help:"Run daily jobs without writing to the database, logging what would change, and exit."`
 	BackfillDaily bool  `name:"backfill-daily" help:"Backfill all daily summaries and verification."`
+	JSON         bool   `name:"json" help:"Print a JSON summary of --daily or --backfill-daily to stdout."`
 	PWSApiKey    string `name:"pws-api-key" env:"PWS_API_KEY" required:"" help:"Weather Underground API key."`
+	Timezone     string `name:"timezone" default:"Australia/Melbourne" env:"TIMEZONE" help:"Time zone for local days and times."`
+
+	AdminToken   string `name:"admin-token" env:"ADMIN_TOKEN" help:"Shared secret for /admin endpoints (disabled when empty)."`
+	ForecastDays int    `name:"forecast-days" default:"5" env:"FORECAST_DAYS" help:"Days shown on the forecast page (1-7)."`
+	LapseRate    float64 `name:"lapse-rate" default:"6.5" env:"LAPSE_RATE" help:"Lapse rate (°C/km) inversion detection expects between valley and upper stations."`
+	ForecastBlend bool   `name:"forecast-blend" env:"FORECAST_BLEND" help:"Blend BOM and WU by recent skill for today's temperatures instead of picking one."`
+	RawRetentionDays int `name:"raw-retention-days" default:"90" env:"RAW_RETENTION_DAYS" help:"Days of raw API payloads the nightly jobs keep (0 keeps them forever)."`
+	ObservationRounding time.Duration `name:"observation-rounding" default:"0s" env:"OBSERVATION_ROUNDING" help:"Round PWS observation timestamps to this interval (e.g. 1m) so jittered re-reports collapse (0 keeps them as reported)."`
+
+	QCTempMin      float64 `name:"qc-temp-min" default:"-10" env:"QC_TEMP_MIN" help:"Lowest plausible temperature (°C) before an observation is flagged."`
+	QCTempMax      float64 `name:"qc-temp-max" default:"50" env:"QC_TEMP_MAX" help:"Highest plausible temperature (°C) before an observation is flagged."`
+	QCWindSpeedMax float64 `name:"qc-wind-max" default:"200" env:"QC_WIND_MAX" help:"Highest plausible sustained wind (km/h)."`
+	QCPressureMin  float64 `name:"qc-pressure-min" default:"900" env:"QC_PRESSURE_MIN" help:"Lowest plausible pressure (hPa)."`
+	QCPressureMax  float64 `name:"qc-pressure-max" default:"1100" env:"QC_PRESSURE_MAX" help:"Highest plausible pressure (hPa)."`
+
+	InversionAlertSpread float64 `name:"inversion-alert-spread" default:"5" env:"INVERSION_ALERT_SPREAD" help:"How much warmer (°C) the upper stations must be than the valley floor to raise a frost alert."`
+	InversionAlertFrost  float64 `name:"inversion-alert-frost" default:"3" env:"INVERSION_ALERT_FROST" help:"Valley floor temperature (°C) at or below which a strong inversion raises a frost alert."`
+	AlertCategories      []string `name:"alert-categories" env:"ALERT_CATEGORIES" help:"VicEmergency categories to show, comma separated (e.g. Fire,Met,Flood; all when empty)."`
+	AlertWebhook         string   `name:"alert-webhook" env:"ALERT_WEBHOOK" help:"URL to POST new and escalated emergency alerts to as JSON (disabled when empty)."`
+
+	HTTPReadHeaderTimeout time.Duration `name:"http-read-header-timeout" default:"10s" env:"HTTP_READ_HEADER_TIMEOUT" help:"How long a client may take to send request headers."`
+	HTTPReadTimeout       time.Duration `name:"http-read-timeout" default:"30s" env:"HTTP_READ_TIMEOUT" help:"How long a client may take to send a whole request."`
+	HTTPWriteTimeout      time.Duration `name:"http-write-timeout" default:"150s" env:"HTTP_WRITE_TIMEOUT" help:"How long a response may take, including on-demand image generation."`
+	HTTPIdleTimeout       time.Duration `name:"http-idle-timeout" default:"2m" env:"HTTP_IDLE_TIMEOUT" help:"How long an idle keep-alive connection is kept open."`
+	HTTPMaxHeaderBytes    int           `name:"http-max-header-bytes" default:"65536" env:"HTTP_MAX_HEADER_BYTES" help:"Largest request header accepted, in bytes."`
+	HTTPMaxBodyBytes      int64         `name:"http-max-body-bytes" default:"1048576" env:"HTTP_MAX_BODY_BYTES" help:"Largest request body accepted, in bytes."`
 }
 
 var defaultStations = []models.Station{
@@ -38,13 +72,6 @@ var defaultStations = []models.Station{
 	{StationID: "IHARRI19", Name: "Harrietville", Latitude: -36.9, Longitude: 147.053, Elevation: 543, ElevationTier: "upper", IsPrimary: false, Active: true},
 }
 
-var stationIDs = []string{
-	"IWANDI23",  // Primary station (valley floor)
-	"IWANDI25",  // Shade reference (valley floor)
-	"IBRIGH180", // Bright (valley floor)
-	"IHARRI19",  // Harrietville (upper, for inversion detection)
-}
-
 const (
 	wandiligongLat = -36.794
 	wandiligongLon = 146.977
@@ -58,79 +85,151 @@ func main() {
 	kong.Parse(&cli,
 		kong.Name("wandiweather"),
 		kong.Description("Weather station data ingestion and display server."),
+		kong.Configuration(loadConfig),
 	)
 
-	db, err := sql.Open("sqlite", cli.DB)
+	stations := defaultStations
+	if cli.Config != "" {
+		configured, err := loadStations(string(cli.Config))
+		if err != nil {
+			log.Fatalf("load stations from %s: %v", cli.Config, err)
+		}
+		if configured != nil {
+			stations = configured
+		}
+	}
+
+	if cli.ReadOnly && (cli.Once || cli.Backfill || cli.Daily || cli.DailyDryRun || cli.BackfillDaily) {
+		log.Fatalf("--readonly only serves; it can't be combined with ingest or daily job modes")
+	}
+
+	// A dry run reads the same way the read-only server does, so it can't
+	// write even by accident
+	readOnlyDB := cli.ReadOnly || cli.DailyDryRun
+	dsn := cli.DB
+	if readOnlyDB {
+		dsn = "file:" + cli.DB + "?mode=ro"
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		log.Fatalf("open database: %v", err)
 	}
 	defer db.Close()
 
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		log.Printf("warning: failed to set journal_mode=WAL: %v", err)
+	if !readOnlyDB {
+		if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+			log.Printf("warning: failed to set journal_mode=WAL: %v", err)
+		}
 	}
 	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
 		log.Printf("warning: failed to set busy_timeout: %v", err)
 	}
 
 	// Load timezone once at startup
-	loc, err := time.LoadLocation("Australia/Melbourne")
+	loc, err := time.LoadLocation(cli.Timezone)
 	if err != nil {
-		log.Printf("Warning: could not load Australia/Melbourne timezone, using UTC: %v", err)
+		log.Printf("Warning: could not load %s timezone, using UTC: %v", cli.Timezone, err)
 		loc = time.UTC
 	}
 
 	st := store.New(db, loc)
+	if cli.ReadOnly {
+		serveReadOnly(st, loc)
+		return
+	}
+	if cli.DailyDryRun {
+		runDailyDryRun(st, loc)
+		return
+	}
 	if err := st.Migrate(); err != nil {
 		log.Fatalf("migrate: %v", err)
 	}
 	log.Println("database migrated")
 
-	for _, station := range defaultStations {
-		if err := st.UpsertStation(station); err != nil {
-			log.Fatalf("upsert station %s: %v", station.StationID, err)
-		}
+	if err := models.ValidateStations(stations); err != nil {
+		log.Fatalf("invalid station config: %v", err)
+	}
+	if err := st.SeedStations(stations); err != nil {
+		log.Fatalf("seed stations: %v", err)
 	}
 	log.Println("stations seeded")
 
 	pws := ingest.NewPWS(cli.PWSApiKey)
+	pws.SetStationLocations(stations)
 	forecast := ingest.NewForecastClient(cli.PWSApiKey, wandiligongLat, wandiligongLon)
-	scheduler := ingest.NewScheduler(st, pws, forecast, stationIDs, loc)
-	server := api.NewServer(st, cli.Port, loc)
+	scheduler := ingest.NewScheduler(st, pws, forecast, loc)
+
+	qc := ingest.DefaultQCThresholds()
+	qc.TempMin, qc.TempMax = cli.QCTempMin, cli.QCTempMax
+	qc.WindSpeedMax = cli.QCWindSpeedMax
+	qc.PressureMin, qc.PressureMax = cli.QCPressureMin, cli.QCPressureMax
+	scheduler.SetQCThresholds(qc)
+	scheduler.SetInversionAlertThresholds(ingest.InversionAlertThresholds{
+		Spread:     cli.InversionAlertSpread,
+		ValleyTemp: cli.InversionAlertFrost,
+	})
+	scheduler.SetRawRetentionDays(cli.RawRetentionDays)
+	scheduler.SetObservationRounding(cli.ObservationRounding)
+	server := newServer(st, loc)
 
 	// Configure image generation for weather banners, sharing mutex with server
 	if gen := server.ImageGenerator(); gen != nil {
 		scheduler.SetImageGenerator(gen, server.ImageCache(), server.ImageGenMutex())
 	}
 
-	// Share emergency client between server and scheduler
+	// Share emergency client between server and scheduler, caching the feed
+	// alongside the database so it survives restarts
+	server.EmergencyClient().SetCacheDir(filepath.Join(filepath.Dir(cli.DB), "emergency"))
 	scheduler.SetEmergencyClient(server.EmergencyClient())
+	if cli.AlertWebhook != "" {
+		scheduler.SetAlertWebhook(emergency.NewWebhook(cli.AlertWebhook))
+	}
 
 	// Set up fire danger client for North East district
 	scheduler.SetFireDangerClient(firedanger.NewNorthEastClient())
 
+	// Allow manual ingest via POST /admin/ingest
+	server.SetIngester(scheduler, cli.AdminToken)
+
+	// Refresh cached current conditions as soon as new observations land
+	scheduler.SetObservationHook(server.InvalidateCurrent)
+
 	if cli.Backfill {
 		log.Println("backfilling 7-day observation history")
-		if err := scheduler.BackfillHistory7Day(); err != nil {
-			log.Fatalf("backfill: %v", err)
+		result, err := scheduler.BackfillHistory7Day()
+		if err != nil {
+			if result.Succeeded == 0 {
+				log.Fatalf("backfill: %v", err)
+			}
+			log.Printf("backfill: %d of %d stations failed: %v", result.Failed, result.Succeeded+result.Failed, err)
 		}
 	}
 
 	if cli.BackfillDaily {
 		log.Println("backfilling daily summaries and verification")
-		if err := scheduler.BackfillDailySummaries(); err != nil {
+		result, err := scheduler.BackfillDailySummaries()
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			printDailyResult(result)
 			log.Fatalf("backfill summaries: %v", err)
 		}
-		if err := scheduler.BackfillVerification(); err != nil {
+		verification, err := scheduler.BackfillVerification()
+		result.Merge(verification)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			printDailyResult(result)
 			log.Fatalf("backfill verification: %v", err)
 		}
+		printDailyResult(result)
 		log.Println("done")
 		return
 	}
 
 	if cli.Daily {
 		log.Println("running daily jobs")
-		if err := scheduler.RunDailyJobs(); err != nil {
+		result, err := scheduler.RunDailyJobs(false)
+		printDailyResult(result)
+		if err != nil {
 			log.Fatalf("daily jobs: %v", err)
 		}
 		log.Println("done")
@@ -139,7 +238,7 @@ func main() {
 
 	if cli.Once {
 		log.Println("running single ingestion")
-		if err := scheduler.IngestOnce(); err != nil {
+		if _, err := scheduler.IngestOnce(); err != nil {
 			log.Fatalf("ingest: %v", err)
 		}
 		log.Println("done")
@@ -159,4 +258,80 @@ func main() {
 	if err := server.Run(ctx); err != nil {
 		log.Fatalf("server: %v", err)
 	}
+
+	// Let an in-flight ingest finish or abort before the database is closed
+	if !cli.NoPoll {
+		scheduler.Wait()
+		log.Println("scheduler stopped")
+	}
+}
+
+// newServer builds the HTTP server with the settings from the command line.
+func newServer(st *store.Store, loc *time.Location) *api.Server {
+	server := api.NewServer(st, cli.Port, loc)
+	server.SetForecastDays(cli.ForecastDays)
+	server.SetLapseRate(cli.LapseRate)
+	server.SetForecastBlend(cli.ForecastBlend)
+	server.SetRawRetentionDays(cli.RawRetentionDays)
+	server.EmergencyClient().SetCategories(cli.AlertCategories)
+	server.SetHTTPLimits(api.HTTPLimits{
+		ReadHeaderTimeout: cli.HTTPReadHeaderTimeout,
+		ReadTimeout:       cli.HTTPReadTimeout,
+		WriteTimeout:      cli.HTTPWriteTimeout,
+		IdleTimeout:       cli.HTTPIdleTimeout,
+		MaxHeaderBytes:    cli.HTTPMaxHeaderBytes,
+		MaxBodyBytes:      cli.HTTPMaxBodyBytes,
+	})
+	return server
+}
+
+// serveReadOnly serves the dashboard from a snapshot without migrating,
+// seeding stations or polling, for a public mirror.
+func serveReadOnly(st *store.Store, loc *time.Location) {
+	if pending, err := st.PendingMigrations(); err != nil {
+		log.Fatalf("check migrations: %v", err)
+	} else if len(pending) > 0 {
+		log.Printf("warning: snapshot is missing migrations %v; some pages may fail", pending)
+	}
+
+	server := newServer(st, loc)
+	server.SetReadOnly(true)
+	server.SetAdminToken(cli.AdminToken)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("starting read-only server on :%s", cli.Port)
+	if err := server.Run(ctx); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+}
+
+// runDailyDryRun logs what the daily jobs would change without migrating,
+// seeding stations or otherwise writing to the database.
+func runDailyDryRun(st *store.Store, loc *time.Location) {
+	if pending, err := st.PendingMigrations(); err != nil {
+		log.Fatalf("check migrations: %v", err)
+	} else if len(pending) > 0 {
+		log.Fatalf("database is missing migrations %v; run without --daily-dryrun to apply them first", pending)
+	}
+
+	log.Println("running daily jobs (dry run)")
+	result, err := ingest.NewScheduler(st, nil, nil, loc).RunDailyJobs(true)
+	printDailyResult(result)
+	if err != nil {
+		log.Fatalf("daily jobs: %v", err)
+	}
+	log.Println("done")
+}
+
+// printDailyResult writes a daily or backfill result to stdout as JSON when
+// --json is set. Logs go to stderr, so stdout stays machine-readable.
+func printDailyResult(result ingest.DailyResult) {
+	if !cli.JSON {
+		return
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+		log.Printf("write json result: %v", err)
+	}
 }
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// currentCacheTTL is how long the derived current data and condition are
+// reused. HTMX polling and image requests otherwise rebuild them from SQLite
+// on every hit.
+const currentCacheTTL = 60 * time.Second
+
+// climatologyCacheTTL is how long the same-day climatology behind the
+// temperature anomaly is reused. It's also reloaded when the date changes.
+const climatologyCacheTTL = time.Hour
+
+// regimeCacheTTL is how long today's regime inputs, the primary station's
+// partial summary and recent summaries, are reused. The partial summary scans
+// all of today's observations, and the regime rarely changes within the hour.
+const regimeCacheTTL = 10 * time.Minute
+
+// ttlCache holds a single value for a fixed time. Loads happen under the lock
+// so concurrent requests for an expired value share one load.
+type ttlCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	value   T
+	key     string // what value was loaded for, see getFor
+	expires time.Time
+	now     func() time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{ttl: ttl, now: time.Now}
+}
+
+// get returns the cached value, calling load if it's missing or expired.
+// Errors are returned without being cached.
+func (c *ttlCache[T]) get(load func() (T, error)) (T, error) {
+	return c.getFor("", load)
+}
+
+// getFor is get for a value that depends on key, such as a station and date.
+// A value loaded for a different key is reloaded even if it hasn't expired.
+func (c *ttlCache[T]) getFor(key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if key == c.key && now.Before(c.expires) {
+		return c.value, nil
+	}
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.value, c.key = v, key
+	c.expires = now.Add(c.ttl)
+	return v, nil
+}
+
+// invalidate drops the cached value so the next get reloads it.
+func (c *ttlCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	c.value = zero
+	c.expires = time.Time{}
+}
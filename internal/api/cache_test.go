@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTTLCache(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	c := newTTLCache[int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	for i := 0; i < 5; i++ {
+		if v, _ := c.get(load); v != 1 {
+			t.Fatalf("get #%d = %d, want cached 1", i, v)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	if calls != 1 {
+		t.Errorf("load called %d times within TTL, want 1", calls)
+	}
+
+	now = now.Add(time.Minute)
+	if v, _ := c.get(load); v != 2 || calls != 2 {
+		t.Errorf("after expiry got %d with %d loads, want 2 and 2", v, calls)
+	}
+
+	c.invalidate()
+	if v, _ := c.get(load); v != 3 || calls != 3 {
+		t.Errorf("after invalidate got %d with %d loads, want 3 and 3", v, calls)
+	}
+}
+
+func TestTTLCache_ErrorsNotCached(t *testing.T) {
+	c := newTTLCache[string](time.Minute)
+
+	calls := 0
+	if _, err := c.get(func() (string, error) {
+		calls++
+		return "", errors.New("db locked")
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	v, err := c.get(func() (string, error) {
+		calls++
+		return "ok", nil
+	})
+	if err != nil || v != "ok" {
+		t.Errorf("get = %q, %v; want ok", v, err)
+	}
+	if calls != 2 {
+		t.Errorf("load called %d times, want 2", calls)
+	}
+}
+
+func TestTTLCache_GetForReloadsOnNewKey(t *testing.T) {
+	c := newTTLCache[string](time.Hour)
+
+	calls := 0
+	load := func(v string) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return v, nil
+		}
+	}
+
+	if v, _ := c.getFor("2026-01-15", load("thu")); v != "thu" {
+		t.Fatalf("getFor = %q, want thu", v)
+	}
+	if v, _ := c.getFor("2026-01-15", load("unused")); v != "thu" {
+		t.Errorf("getFor same key = %q, want cached thu", v)
+	}
+	if v, _ := c.getFor("2026-01-16", load("fri")); v != "fri" {
+		t.Errorf("getFor new key = %q, want fri", v)
+	}
+	if calls != 2 {
+		t.Errorf("load called %d times, want 2", calls)
+	}
+}
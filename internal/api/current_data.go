@@ -2,16 +2,25 @@ package api
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"math"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/lox/wandiweather/internal/forecast"
 	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
 )
 
-// getCurrentData aggregates all current weather data for display.
+// getCurrentData returns the current weather data, cached for currentCacheTTL.
 func (s *Server) getCurrentData() (*CurrentData, error) {
+	return s.currentCache.get(s.buildCurrentData)
+}
+
+// buildCurrentData aggregates all current weather data for display.
+func (s *Server) buildCurrentData() (*CurrentData, error) {
 	stations, err := s.store.GetActiveStations()
 	if err != nil {
 		return nil, err
@@ -22,11 +31,13 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		StationMeta: make(map[string]models.Station),
 	}
 
-	var valleyTemps, midTemps, upperTemps []float64
-
+	var primary *models.Station
 	for _, st := range stations {
 		data.StationMeta[st.StationID] = st
-		obs, err := s.store.GetLatestObservation(st.StationID)
+		if st.IsPrimary && primary == nil {
+			primary = &st
+		}
+		obs, err := s.store.GetLatestObservation(st.StationID, true)
 		if err != nil {
 			log.Printf("get latest %s: %v", st.StationID, err)
 			continue
@@ -37,52 +48,56 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		data.Stations[st.StationID] = obs
 
 		if st.IsPrimary {
-			data.Primary = obs
+			// LastUpdated tracks the raw latest reading so staleness still
+			// shows, but the display skips over QC-flagged spikes.
 			data.LastUpdated = obs.ObservedAt.In(s.loc)
+			clean, err := s.store.GetLatestCleanObservation(st.StationID)
+			if err != nil {
+				log.Printf("get latest clean %s: %v", st.StationID, err)
+			}
+			data.Primary = displayObservation(obs, clean)
+			data.WindCardinal = windCardinal(data.Primary)
 		}
 
-		reading := StationReading{Station: st, Obs: obs}
+		reading := StationReading{Station: st, Obs: obs, WindCardinal: windCardinal(obs)}
 		data.AllStations = append(data.AllStations, reading)
 		switch st.ElevationTier {
-		case "valley_floor":
+		case "valley_floor", "local":
 			data.ValleyFloor = append(data.ValleyFloor, reading)
-			if obs.Temp.Valid {
-				valleyTemps = append(valleyTemps, obs.Temp.Float64)
-			}
 		case "mid_slope":
 			data.MidSlope = append(data.MidSlope, reading)
-			if obs.Temp.Valid {
-				midTemps = append(midTemps, obs.Temp.Float64)
-			}
 		case "upper":
 			data.Upper = append(data.Upper, reading)
-			if obs.Temp.Valid {
-				upperTemps = append(upperTemps, obs.Temp.Float64)
-			}
-		case "local":
-			data.ValleyFloor = append(data.ValleyFloor, reading)
-			if obs.Temp.Valid {
-				valleyTemps = append(valleyTemps, obs.Temp.Float64)
-			}
 		}
 	}
 
-	if len(valleyTemps) > 0 {
-		data.ValleyTemp = median(valleyTemps)
+	tiers := gatherTierTemps(data.ValleyFloor, data.MidSlope, data.Upper)
+	valleyTemps, midTemps, upperTemps := tiers.valley, tiers.mid, tiers.upper
+
+	for _, tier := range []struct {
+		name  string
+		temps []float64
+	}{
+		{"valley_floor", valleyTemps},
+		{"mid_slope", midTemps},
+		{"upper", upperTemps},
+	} {
+		if ts := tierSummary(tier.name, tier.temps); ts != nil {
+			data.Tiers = append(data.Tiers, *ts)
+		}
+	}
 
-		if len(upperTemps) > 0 {
-			valleyAvg := avg(valleyTemps)
-			midAvg := avg(midTemps)
-			upperAvg := avg(upperTemps)
-			expectedDiff := (400.0 - 117.0) / 1000.0 * 6.5
-			actualDiff := upperAvg - valleyAvg
+	if len(valleyTemps) > 0 {
+		valleyTemp := median(valleyTemps)
+		data.ValleyTemp = &valleyTemp
 
+		if inv := forecast.InversionStatus(valleyTemps, upperTemps, tiers.valleyElev, tiers.upperElev, s.lapseRate); inv != nil {
 			data.Inversion = &InversionStatus{
-				Active:    actualDiff > expectedDiff+2,
-				Strength:  actualDiff - expectedDiff,
-				ValleyAvg: valleyAvg,
-				MidAvg:    midAvg,
-				UpperAvg:  upperAvg,
+				Active:    inv.Active,
+				Strength:  inv.Strength,
+				ValleyAvg: inv.ValleyAvg,
+				MidAvg:    avg(midTemps),
+				UpperAvg:  inv.UpperAvg,
 			}
 		}
 	}
@@ -100,37 +115,91 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		Emoji:        moonEmoji(phase),
 	}
 
-	todayStats, err := s.store.GetTodayStatsExtended("IWANDI23", now)
-	if err == nil {
-		ts := &TodayStats{}
-		if todayStats.MinTemp.Valid {
-			ts.MinTemp = todayStats.MinTemp.Float64
-			ts.MinTempValid = true
-		}
-		if todayStats.MaxTemp.Valid {
-			ts.MaxTemp = todayStats.MaxTemp.Float64
-			ts.MaxTempValid = true
-		}
-		if todayStats.MinTempTime.Valid {
-			ts.MinTempTime = todayStats.MinTempTime.Time.In(loc).Format("3:04 PM")
+	if primary != nil {
+		todayStats, err := s.store.GetTodayStatsExtended(primary.StationID, now)
+		if err == nil {
+			ts := &TodayStats{}
+			if todayStats.MinTemp.Valid {
+				ts.MinTemp = todayStats.MinTemp.Float64
+				ts.MinTempValid = true
+			}
+			if todayStats.MaxTemp.Valid {
+				ts.MaxTemp = todayStats.MaxTemp.Float64
+				ts.MaxTempValid = true
+			}
+			if todayStats.MinTempTime.Valid {
+				ts.MinTempTime = todayStats.MinTempTime.Time.In(loc).Format("3:04 PM")
+			}
+			if todayStats.MaxTempTime.Valid {
+				ts.MaxTempTime = todayStats.MaxTempTime.Time.In(loc).Format("3:04 PM")
+			}
+			if todayStats.RainTotal.Valid && todayStats.RainTotal.Float64 > 0 {
+				ts.RainTotal = todayStats.RainTotal.Float64
+				ts.HasRain = true
+			}
+			if todayStats.MaxWind.Valid || todayStats.MaxGust.Valid {
+				ts.MaxWind = todayStats.MaxWind.Float64
+				ts.MaxGust = todayStats.MaxGust.Float64
+				ts.HasWind = true
+			}
+			if todayStats.MinHumidity.Valid && todayStats.MaxHumidity.Valid {
+				ts.MinHumidity = todayStats.MinHumidity.Int64
+				ts.MaxHumidity = todayStats.MaxHumidity.Int64
+				ts.HasHumidity = true
+			}
+			if todayStats.MinDewpoint.Valid {
+				ts.MinDewpoint = todayStats.MinDewpoint.Float64
+				ts.HasDewpoint = true
+			}
+			if observations, err := s.store.GetDayObservations(primary.StationID, now, now); err != nil {
+				log.Printf("api: sunshine hours: %v", err)
+			} else if hours, ok := forecast.SunshineHours(observations, *primary); ok {
+				ts.Sunshine = hours
+				ts.HasSunshine = true
+			}
+			data.TodayStats = ts
 		}
-		if todayStats.MaxTempTime.Valid {
-			ts.MaxTempTime = todayStats.MaxTempTime.Time.In(loc).Format("3:04 PM")
+
+		if records, err := s.store.CheckRecordsBroken(primary.StationID, now); err != nil {
+			log.Printf("api: check records: %v", err)
+		} else {
+			data.Records = records
 		}
-		if todayStats.RainTotal.Valid && todayStats.RainTotal.Float64 > 0 {
-			ts.RainTotal = todayStats.RainTotal.Float64
-			ts.HasRain = true
+
+		if rate, err := s.store.GetTempChangeRate(primary.StationID); err == nil && rate.Valid {
+			data.TempChangeRate = &rate.Float64
+			data.Trend = tempTrend(rate.Float64, now.In(s.loc))
 		}
-		if todayStats.MaxWind.Valid || todayStats.MaxGust.Valid {
-			ts.MaxWind = todayStats.MaxWind.Float64
-			ts.MaxGust = todayStats.MaxGust.Float64
-			ts.HasWind = true
+	}
+
+	data.UV = uvGuidance(data.Primary, now.In(s.loc))
+	if data.Primary != nil && data.Primary.PrecipRate.Valid {
+		data.RainIntensity = forecast.RainIntensity(data.Primary.PrecipRate.Float64)
+	}
+	data.WindAdvisory = windAdvisory(data.Primary)
+
+	if tod := forecast.GetTimeOfDay(now); tod == forecast.TimeDusk || tod == forecast.TimeNight {
+		data.InversionTimes = s.inversionOutlook(stations, now)
+	}
+
+	if data.Primary != nil && data.Primary.Pressure.Valid {
+		if trend, err := s.store.GetPressureTrend(data.Primary.StationID); err == nil && trend.Valid {
+			windDir := -1
+			if data.Primary.WindDir.Valid && data.Primary.WindSpeed.Valid && data.Primary.WindSpeed.Float64 > 0 {
+				windDir = int(data.Primary.WindDir.Int64)
+			}
+			data.Zambretti = forecast.Zambretti(data.Primary.Pressure.Float64, trend.Float64, now.In(s.loc).Month(), windDir)
 		}
-		data.TodayStats = ts
 	}
 
-	if rate, err := s.store.GetTempChangeRate("IWANDI23"); err == nil && rate.Valid {
-		data.TempChangeRate = &rate.Float64
+	if data.Primary != nil && data.ValleyTemp != nil {
+		if clim, err := s.sameDayClimatology(data.Primary.StationID, now); err != nil {
+			log.Printf("api: climatology: %v", err)
+		} else if clim != nil {
+			anomaly := *data.ValleyTemp - clim.MedianTemp
+			data.TempAnomaly = &anomaly
+			data.AnomalyLabel = anomalyLabel(anomaly, UnitsMetric)
+		}
 	}
 
 	if data.Primary != nil {
@@ -151,11 +220,8 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		biasCorrector := forecast.NewBiasCorrector(s.store)
 
 		var primaryStationID string
-		for _, st := range stations {
-			if st.IsPrimary {
-				primaryStationID = st.StationID
-				break
-			}
+		if primary != nil {
+			primaryStationID = primary.StationID
 		}
 
 		todayStr := todayDate.Format("2006-01-02")
@@ -164,14 +230,14 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		// Prefer forecasts that have valid temp data (skip day-0 entries with NULL temps)
 		var wuForecast, bomForecast *models.Forecast
 		for _, fc := range forecasts["wu"] {
-			if fc.ValidDate.Format("2006-01-02") == todayStr && (fc.TempMax.Valid || fc.TempMin.Valid) {
+			if fc.ValidDate.Format("2006-01-02") == todayStr && !fc.NarrativeOnly() {
 				f := fc
 				wuForecast = &f
 				break
 			}
 		}
 		for _, fc := range forecasts["bom"] {
-			if fc.ValidDate.Format("2006-01-02") == todayStr && (fc.TempMax.Valid || fc.TempMin.Valid) {
+			if fc.ValidDate.Format("2006-01-02") == todayStr && !fc.NarrativeOnly() {
 				f := fc
 				bomForecast = &f
 				break
@@ -200,6 +266,7 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 				WUForecast:       wuForecast,
 				BOMForecast:      bomForecast,
 				CorrectionStats:  correctionStats,
+				Regime:           s.todayRegime(primaryStationID, wuForecast, bomForecast),
 				BiasCorrector:    biasCorrector,
 				Nowcaster:        nowcaster,
 				PrimaryStationID: primaryStationID,
@@ -210,8 +277,10 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 				ObservedMin:      observedMin,
 				ObservedMinValid: observedMinValid,
 				Hour:             now.Hour(),
-				TempFalling:      data.TempChangeRate != nil && *data.TempChangeRate < -0.5,
-				LogNowcast:       true, // Log nowcast for the main display
+				TempFalling:      data.TempChangeRate != nil && *data.TempChangeRate < forecast.FallingRate,
+				LogNowcast:       !s.readOnly, // Log nowcast for the main display
+				Blend:            s.forecastBlend,
+				Skill:            forecast.SkillFromCorrectionStats(correctionStats, 0),
 			}
 
 			tempResult := forecast.ComputeTodayTemps(tempInput)
@@ -223,7 +292,9 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 				NowcastApplied:    tempResult.NowcastApplied,
 				NowcastAdjustment: tempResult.NowcastAdjustment,
 				Explanation:       tempResult.Explanation,
+				FetchedAt:         latestFetch(wuForecast, bomForecast),
 			}
+			tf.UpdatedAgo = formatAge(now.Sub(tf.FetchedAt))
 
 			// Precip from WU (has more detail)
 			if wuForecast != nil {
@@ -249,7 +320,7 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 			data.TodayForecast = tf
 
 			// Log displayed forecast for accuracy tracking
-			if wuForecast != nil && bomForecast != nil {
+			if wuForecast != nil && bomForecast != nil && !s.readOnly {
 				dayOfForecast := bomForecast.DayOfForecast
 				df := models.DisplayedForecast{
 					DisplayedAt:   time.Now().UTC(),
@@ -261,7 +332,7 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 				df.BOMForecastID = sql.NullInt64{Int64: bomForecast.ID, Valid: bomForecast != nil}
 				df.RawTempMax = sql.NullFloat64{Float64: exp.MaxRaw, Valid: exp.MaxSource != ""}
 				df.RawTempMin = sql.NullFloat64{Float64: exp.MinRaw, Valid: exp.MinSource != ""}
-				df.CorrectedTempMax = sql.NullFloat64{Float64: exp.MaxFinal, Valid: exp.MaxSource != ""}
+				df.CorrectedTempMax = sql.NullFloat64{Float64: exp.MaxForecast, Valid: exp.MaxSource != ""}
 				df.CorrectedTempMin = sql.NullFloat64{Float64: exp.MinFinal, Valid: exp.MinSource != ""}
 				df.BiasAppliedMax = sql.NullFloat64{Float64: exp.MaxBiasApplied, Valid: exp.MaxBiasDayUsed >= 0}
 				df.BiasAppliedMin = sql.NullFloat64{Float64: exp.MinBiasApplied, Valid: exp.MinBiasDayUsed >= 0}
@@ -271,6 +342,7 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 				df.BiasSamplesMin = sql.NullInt64{Int64: int64(exp.MinBiasSamples), Valid: exp.MinBiasDayUsed >= 0}
 				df.BiasFallbackMax = sql.NullBool{Bool: exp.MaxBiasFallback, Valid: exp.MaxBiasDayUsed >= 0}
 				df.BiasFallbackMin = sql.NullBool{Bool: exp.MinBiasFallback, Valid: exp.MinBiasDayUsed >= 0}
+				df.NowcastMax = sql.NullFloat64{Float64: exp.MaxNowcast, Valid: tf.NowcastApplied}
 				df.SourceMax = sql.NullString{String: exp.MaxSource, Valid: exp.MaxSource != ""}
 				df.SourceMin = sql.NullString{String: exp.MinSource, Valid: exp.MinSource != ""}
 
@@ -281,6 +353,8 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		}
 	}
 
+	data.ForecastUnavailable = data.TodayForecast == nil
+
 	// Get emergency alerts from database (populated by scheduler)
 	if alerts, err := s.store.GetActiveAlerts(30 * time.Minute); err != nil {
 		log.Printf("get active alerts: %v", err)
@@ -293,11 +367,26 @@ func (s *Server) getCurrentData() (*CurrentData, error) {
 		}
 	}
 
+	// Alerts that have cleared in the last day, so an evacuation that's
+	// dropped off the banner doesn't just vanish without a trace
+	if history, err := s.store.GetAlertHistory(now.Add(-24 * time.Hour)); err != nil {
+		log.Printf("get alert history: %v", err)
+	} else {
+		for _, e := range history {
+			if e.ClearedAt.Valid {
+				e.ClearedAt.Time = e.ClearedAt.Time.In(s.loc)
+				data.ClearedAlerts = append(data.ClearedAlerts, e)
+			}
+		}
+	}
+
 	// Get today's fire danger rating
 	if fdr, err := s.store.GetTodayFireDanger(s.loc); err == nil {
 		data.FireDanger = fdr
 	}
 
+	data.Narrative = buildCurrentNarrative(data, UnitsMetric)
+
 	return data, nil
 }
 
@@ -337,6 +426,251 @@ func avg(vals []float64) float64 {
 	return sum / float64(len(vals))
 }
 
+// sameDayClimatology returns the station's same-day climatology for now's local
+// date. It only changes once a day, so it's cached rather than queried on
+// every rebuild of the current data.
+func (s *Server) sameDayClimatology(stationID string, now time.Time) (*store.SameDayClimatology, error) {
+	key := stationID + " " + now.In(s.loc).Format("2006-01-02")
+	return s.climCache.getFor(key, func() (*store.SameDayClimatology, error) {
+		return s.store.GetSameDayClimatology(stationID, now)
+	})
+}
+
+// inversionOutlook estimates when tonight's inversion forms and breaks, using
+// the last complete day's summary as a guide to how clear and calm it will be.
+// After midnight "tonight" is the night that began the previous evening.
+func (s *Server) inversionOutlook(stations []models.Station, now time.Time) *InversionOutlook {
+	var primary *models.Station
+	for i := range stations {
+		if stations[i].IsPrimary {
+			primary = &stations[i]
+			break
+		}
+	}
+	if primary == nil {
+		return nil
+	}
+
+	evening := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Hour() < 12 {
+		evening = evening.AddDate(0, 0, -1)
+	}
+	lastDay := evening.AddDate(0, 0, -1)
+	summary, err := s.store.GetDailySummary(primary.StationID, time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Printf("api: inversion outlook summary: %v", err)
+		return nil
+	}
+	if summary == nil {
+		return nil
+	}
+
+	_, sunset := forecast.SunTimes(evening, primary.Latitude, primary.Longitude)
+	sunrise, _ := forecast.SunTimes(evening.AddDate(0, 0, 1), primary.Latitude, primary.Longitude)
+	onset, breakup := forecast.InversionTiming(*summary, sunrise, sunset)
+	if onset.IsZero() {
+		return nil
+	}
+	return &InversionOutlook{Onset: onset, Breakup: breakup}
+}
+
+// uvGuidance returns sun-protection advice for the observation's UV index, or
+// nil if there's no UV reading or it's night.
+func uvGuidance(obs *models.Observation, t time.Time) *UVGuidance {
+	if obs == nil || !obs.UV.Valid || forecast.GetTimeOfDay(t) == forecast.TimeNight {
+		return nil
+	}
+	category, advice := forecast.UVAdvice(obs.UV.Float64)
+	if category == "" {
+		return nil
+	}
+	return &UVGuidance{Index: obs.UV.Float64, Category: category, Advice: advice}
+}
+
+// tempTrend describes the temperature's direction at rate °C/hr, and whether
+// at time t the day's max has likely passed.
+func tempTrend(rate float64, t time.Time) *Trend {
+	falling := rate < forecast.FallingRate
+	trend := &Trend{Rate: rate, Direction: TrendSteady, PastPeak: forecast.LikelyPastPeak(t.Hour(), falling)}
+	switch {
+	case falling:
+		trend.Direction = TrendFalling
+	case rate > -forecast.FallingRate:
+		trend.Direction = TrendRising
+	}
+	return trend
+}
+
+// buildCurrentNarrative sums up the primary station's conditions in a line,
+// e.g. "18°C and falling, humid, light NW wind, valley inversion forming",
+// with the temperature in units. data is always metric. It returns "" without
+// a current temperature.
+func buildCurrentNarrative(data *CurrentData, units string) string {
+	obs := data.Primary
+	if obs == nil || !obs.Temp.Valid {
+		return ""
+	}
+
+	temp := formatTemp(obs.Temp.Float64, units)
+	if data.Trend != nil && data.Trend.Direction != TrendSteady {
+		temp += " and " + data.Trend.Direction
+	}
+	parts := []string{temp}
+
+	if data.RainIntensity != "" {
+		parts = append(parts, data.RainIntensity+" rain")
+	}
+	if obs.Dewpoint.Valid {
+		if comfort := forecast.DewpointComfort(obs.Dewpoint.Float64); comfort != forecast.ComfortComfortable {
+			parts = append(parts, comfort)
+		}
+	}
+	if obs.WindSpeed.Valid {
+		parts = append(parts, windPhrase(obs.WindSpeed.Float64, obs.WindDir))
+	}
+	if data.Inversion != nil && data.Inversion.Active {
+		if data.Trend != nil && data.Trend.Direction == TrendFalling {
+			parts = append(parts, "valley inversion forming")
+		} else {
+			parts = append(parts, "valley inversion")
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// windPhrase describes a sustained wind speed (km/h) using the Bureau's
+// terms, with its compass direction when known, e.g. "light NW wind".
+func windPhrase(speed float64, dir sql.NullInt64) string {
+	var strength string
+	switch {
+	case speed < 2:
+		return "calm"
+	case speed < 20:
+		strength = "light"
+	case speed < 31:
+		strength = "moderate"
+	case speed < 41:
+		strength = "fresh"
+	case speed < 63:
+		strength = "strong"
+	default:
+		strength = "gale force"
+	}
+	if !dir.Valid {
+		return strength + " winds"
+	}
+	return fmt.Sprintf("%s %s wind", strength, forecast.DegreesToCompassPoint(int(dir.Int64)))
+}
+
+// maxCleanFallback is how far a clean reading may trail the latest one and
+// still be displayed in its place.
+const maxCleanFallback = 15 * time.Minute
+
+// displayObservation picks the reading to display from a station's latest
+// observation and its latest clean one: the clean reading skips a QC-flagged
+// spike, but only while it's recent enough to pass as current. Otherwise, or
+// without a clean reading, it's the latest.
+func displayObservation(latest, clean *models.Observation) *models.Observation {
+	if clean == nil || latest.ObservedAt.Sub(clean.ObservedAt) > maxCleanFallback {
+		return latest
+	}
+	return clean
+}
+
+// windAdvisory grades the observation's wind for outdoor activities, or
+// returns "" if there's no wind reading.
+func windAdvisory(obs *models.Observation) string {
+	if obs == nil || !obs.WindSpeed.Valid {
+		return ""
+	}
+	gust := math.NaN()
+	if obs.WindGust.Valid {
+		gust = obs.WindGust.Float64
+	}
+	return forecast.WindAdvisory(obs.WindSpeed.Float64, gust)
+}
+
+// windCardinal returns the 16-point direction an observation's wind is coming
+// from, or "" when the direction is unknown or the air is calm.
+func windCardinal(obs *models.Observation) string {
+	if !obs.WindDir.Valid || (obs.WindSpeed.Valid && obs.WindSpeed.Float64 == 0) {
+		return ""
+	}
+	return forecast.DegreesToCardinal(int(obs.WindDir.Int64))
+}
+
+// anomalyLabel describes a temperature anomaly in °C in units, e.g. "3°C
+// above average" or "5°F above average".
+func anomalyLabel(anomaly float64, units string) string {
+	unit := "°C"
+	if units == UnitsImperial {
+		anomaly, unit = toFahrenheitDelta(anomaly), "°F"
+	}
+	rounded := int(math.Round(anomaly))
+	switch {
+	case rounded > 0:
+		return fmt.Sprintf("%d%s above average", rounded, unit)
+	case rounded < 0:
+		return fmt.Sprintf("%d%s below average", -rounded, unit)
+	default:
+		return "About average"
+	}
+}
+
+// tierTemps are the temperatures reported in each elevation tier, with the
+// reference elevations for the inversion check: the lowest valley station and
+// highest upper station that are actually reporting.
+type tierTemps struct {
+	valley, mid, upper    []float64
+	valleyElev, upperElev float64
+}
+
+// gatherTierTemps collects the valid temperatures from each tier's readings.
+func gatherTierTemps(valley, mid, upper []StationReading) tierTemps {
+	var t tierTemps
+	haveValleyElev := false
+	for _, r := range valley {
+		if r.Obs == nil || !r.Obs.Temp.Valid {
+			continue
+		}
+		t.valley = append(t.valley, r.Obs.Temp.Float64)
+		if !haveValleyElev || r.Station.Elevation < t.valleyElev {
+			t.valleyElev = r.Station.Elevation
+			haveValleyElev = true
+		}
+	}
+	for _, r := range mid {
+		if r.Obs != nil && r.Obs.Temp.Valid {
+			t.mid = append(t.mid, r.Obs.Temp.Float64)
+		}
+	}
+	for _, r := range upper {
+		if r.Obs == nil || !r.Obs.Temp.Valid {
+			continue
+		}
+		t.upper = append(t.upper, r.Obs.Temp.Float64)
+		if r.Station.Elevation > t.upperElev {
+			t.upperElev = r.Station.Elevation
+		}
+	}
+	return t
+}
+
+// tierSummary summarises the current temperatures in one elevation tier, or
+// returns nil if no station in it is reporting a temperature.
+func tierSummary(tier string, temps []float64) *TierSummary {
+	if len(temps) == 0 {
+		return nil
+	}
+	ts := &TierSummary{Tier: tier, Count: len(temps), MedianTemp: median(temps), MinTemp: temps[0], MaxTemp: temps[0]}
+	for _, t := range temps[1:] {
+		ts.MinTemp = math.Min(ts.MinTemp, t)
+		ts.MaxTemp = math.Max(ts.MaxTemp, t)
+	}
+	return ts
+}
+
 // median calculates the median of a slice of floats.
 func median(vals []float64) float64 {
 	if len(vals) == 0 {
@@ -351,3 +685,56 @@ func median(vals []float64) float64 {
 	}
 	return sorted[n/2]
 }
+
+// regimeInputs is what todayRegime reads besides the forecast: the primary
+// station's partial summary so far today and its recent summaries.
+type regimeInputs struct {
+	summary  *models.DailySummary
+	prevDays []models.DailySummary
+}
+
+// todayRegime classifies today's regime from the forecast, the primary
+// station's partial summary so far today and its recent summaries, so
+// regime-specific bias corrections can be used. The summaries are cached for
+// regimeCacheTTL, as both the current and forecast data classify the regime.
+func (s *Server) todayRegime(primaryStationID string, wu, bom *models.Forecast) string {
+	fc := wu
+	if fc == nil {
+		fc = bom
+	}
+	var in regimeInputs
+	if primaryStationID != "" {
+		today := time.Now().In(s.loc)
+		var err error
+		in, err = s.regimeCache.getFor(primaryStationID+" "+today.Format("2006-01-02"), func() (regimeInputs, error) {
+			return s.loadRegimeInputs(primaryStationID, today)
+		})
+		if err != nil {
+			log.Printf("api: today's partial summary: %v", err)
+		}
+	}
+	return forecast.RegimeToString(forecast.ClassifyRegime(fc, in.summary, in.prevDays))
+}
+
+// loadRegimeInputs computes today's partial summary for the station, with
+// last night's inversion as the daily job will record it, and fetches its
+// recent summaries. If the summary fails the recent summaries are still
+// returned, with the error, so the failure isn't cached.
+func (s *Server) loadRegimeInputs(stationID string, today time.Time) (regimeInputs, error) {
+	var in regimeInputs
+	in.prevDays, _ = s.store.GetRecentDailySummaries(stationID, 2)
+	summary, err := s.store.ComputeDailySummary(stationID, today)
+	if err != nil {
+		return in, err
+	}
+	if summary != nil {
+		mins, _ := s.store.GetOvernightMinByTier(today)
+		valleyMin, okValley := mins["valley_floor"]
+		upperMin, okUpper := mins["upper"]
+		if okValley && okUpper {
+			summary.InversionDetected = sql.NullBool{Bool: upperMin-valleyMin > 1.0, Valid: true}
+		}
+		in.summary = summary
+	}
+	return in, nil
+}
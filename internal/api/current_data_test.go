@@ -0,0 +1,429 @@
+package api
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/lox/wandiweather/internal/forecast"
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
+)
+
+func TestMoonEmoji(t *testing.T) {
+	if got := moonEmoji(forecast.MoonFull); got != "🌕" {
+		t.Errorf("moonEmoji(MoonFull) = %q, want %q", got, "🌕")
+	}
+
+	phases := []forecast.MoonPhase{
+		forecast.MoonNew,
+		forecast.MoonWaxingCrescent,
+		forecast.MoonFirstQuarter,
+		forecast.MoonWaxingGibbous,
+		forecast.MoonFull,
+		forecast.MoonWaningGibbous,
+		forecast.MoonLastQuarter,
+		forecast.MoonWaningCrescent,
+		"unknown",
+	}
+	seen := make(map[string]forecast.MoonPhase)
+	for _, phase := range phases {
+		got := moonEmoji(phase)
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) != 1 {
+			t.Errorf("moonEmoji(%q) = %q, want a single valid rune", phase, got)
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(got); r < 0x1F311 || r > 0x1F319 {
+			t.Errorf("moonEmoji(%q) = %U, want a moon emoji", phase, r)
+		}
+		if prev, ok := seen[got]; ok {
+			t.Errorf("moonEmoji(%q) = %q, same as %q", phase, got, prev)
+		}
+		seen[got] = phase
+	}
+}
+
+func setupTestStore(t *testing.T) (*store.Store, *time.Location) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	loc := time.UTC
+	st := store.New(db, loc)
+	if err := st.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return st, loc
+}
+
+func TestBuildCurrentData_TempAnomaly(t *testing.T) {
+	st, loc := setupTestStore(t)
+	if err := st.UpsertStation(models.Station{StationID: "IWANDI23", Name: "Primary", ElevationTier: "valley_floor", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two years of summaries for today's date with a median of 20°C, plus
+	// warmer neighbouring days that aren't the same day
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for years, avg := range map[int]float64{1: 19, 2: 21} {
+		for offset, v := range map[int]float64{-1: 30, 0: avg, 1: 30} {
+			if err := st.UpsertDailySummary(models.DailySummary{
+				Date:      today.AddDate(-years, 0, offset),
+				StationID: "IWANDI23",
+				TempAvg:   sql.NullFloat64{Float64: v, Valid: true},
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := st.InsertObservation(models.Observation{
+		StationID:  "IWANDI23",
+		ObservedAt: now,
+		Temp:       sql.NullFloat64{Float64: 23, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(st, "8080", loc)
+	data, err := srv.buildCurrentData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TempAnomaly == nil {
+		t.Fatal("expected TempAnomaly to be set")
+	}
+	if math.Abs(*data.TempAnomaly-3) > 0.01 {
+		t.Errorf("TempAnomaly = %.2f, want 3", *data.TempAnomaly)
+	}
+	if data.AnomalyLabel != "3°C above average" {
+		t.Errorf("AnomalyLabel = %q, want %q", data.AnomalyLabel, "3°C above average")
+	}
+}
+
+func TestBuildCurrentData_TierSummaries(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	readings := []struct {
+		id, tier string
+		temp     float64
+	}{
+		{"VALLEY1", "valley_floor", 10},
+		{"VALLEY2", "local", 14},
+		{"VALLEY3", "valley_floor", 11},
+		{"MID1", "mid_slope", 13},
+		{"MID2", "mid_slope", 15},
+		{"UPPER1", "upper", 16},
+	}
+	now := time.Now().UTC()
+	for _, r := range readings {
+		if err := st.UpsertStation(models.Station{StationID: r.id, ElevationTier: r.tier, Active: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.InsertObservation(models.Observation{
+			StationID:  r.id,
+			ObservedAt: now,
+			Temp:       sql.NullFloat64{Float64: r.temp, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := NewServer(st, "8080", loc)
+	data, err := srv.buildCurrentData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []TierSummary{
+		{Tier: "valley_floor", Count: 3, MedianTemp: 11, MinTemp: 10, MaxTemp: 14},
+		{Tier: "mid_slope", Count: 2, MedianTemp: 14, MinTemp: 13, MaxTemp: 15},
+		{Tier: "upper", Count: 1, MedianTemp: 16, MinTemp: 16, MaxTemp: 16},
+	}
+	if len(data.Tiers) != len(want) {
+		t.Fatalf("Tiers = %+v, want %d tiers", data.Tiers, len(want))
+	}
+	for i, w := range want {
+		if data.Tiers[i] != w {
+			t.Errorf("Tiers[%d] = %+v, want %+v", i, data.Tiers[i], w)
+		}
+	}
+	if data.ValleyTemp == nil || *data.ValleyTemp != data.Tiers[0].MedianTemp {
+		t.Errorf("ValleyTemp = %v, want the valley floor median %v", data.ValleyTemp, data.Tiers[0].MedianTemp)
+	}
+}
+
+func TestAnomalyLabel(t *testing.T) {
+	tests := []struct {
+		anomaly float64
+		units   string
+		want    string
+	}{
+		{3.2, UnitsMetric, "3°C above average"},
+		{-1.6, UnitsMetric, "2°C below average"},
+		{0.4, UnitsMetric, "About average"},
+		{3.2, UnitsImperial, "6°F above average"},
+		{-1.6, UnitsImperial, "3°F below average"},
+		{0.2, UnitsImperial, "About average"},
+	}
+	for _, tt := range tests {
+		if got := anomalyLabel(tt.anomaly, tt.units); got != tt.want {
+			t.Errorf("anomalyLabel(%v, %s) = %q, want %q", tt.anomaly, tt.units, got, tt.want)
+		}
+	}
+}
+
+func TestUVGuidance(t *testing.T) {
+	noon := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	midnight := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	obs := &models.Observation{UV: sql.NullFloat64{Float64: 9, Valid: true}}
+	got := uvGuidance(obs, noon)
+	if got == nil {
+		t.Fatal("expected guidance for a daytime UV reading")
+	}
+	if got.Category != forecast.UVVeryHigh || got.Advice == "" {
+		t.Errorf("uvGuidance() = %+v, want very high with advice", got)
+	}
+
+	if got := uvGuidance(obs, midnight); got != nil {
+		t.Errorf("expected no guidance at night, got %+v", got)
+	}
+	if got := uvGuidance(&models.Observation{}, noon); got != nil {
+		t.Errorf("expected no guidance without a UV reading, got %+v", got)
+	}
+	if got := uvGuidance(nil, noon); got != nil {
+		t.Errorf("expected no guidance without an observation, got %+v", got)
+	}
+}
+
+func TestWindAdvisoryFromObservation(t *testing.T) {
+	gusty := &models.Observation{
+		WindSpeed: sql.NullFloat64{Float64: 15, Valid: true},
+		WindGust:  sql.NullFloat64{Float64: 35, Valid: true},
+	}
+	if got := windAdvisory(gusty); got != forecast.WindStrongGusts {
+		t.Errorf("windAdvisory(gusty) = %q, want %q", got, forecast.WindStrongGusts)
+	}
+
+	// Without a gust reading the sustained speed decides
+	steady := &models.Observation{WindSpeed: sql.NullFloat64{Float64: 3, Valid: true}}
+	if got := windAdvisory(steady); got != forecast.WindCalm {
+		t.Errorf("windAdvisory(no gust) = %q, want %q", got, forecast.WindCalm)
+	}
+
+	if got := windAdvisory(&models.Observation{WindGust: gusty.WindGust}); got != "" {
+		t.Errorf("expected no advisory without a wind speed, got %q", got)
+	}
+	if got := windAdvisory(nil); got != "" {
+		t.Errorf("expected no advisory without an observation, got %q", got)
+	}
+}
+
+func TestTempTrend(t *testing.T) {
+	day := func(hour int) time.Time { return time.Date(2026, 1, 15, hour, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name          string
+		rate          float64
+		at            time.Time
+		wantDirection string
+		wantPastPeak  bool
+	}{
+		{"falling afternoon", -1.2, day(16), TrendFalling, true},
+		{"falling morning", -1.2, day(9), TrendFalling, false},
+		{"rising afternoon", 0.8, day(16), TrendRising, false},
+		{"steady afternoon", -0.3, day(16), TrendSteady, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tempTrend(tt.rate, tt.at)
+			if got.Rate != tt.rate || got.Direction != tt.wantDirection || got.PastPeak != tt.wantPastPeak {
+				t.Errorf("tempTrend(%v, %v) = %+v, want direction %q, past peak %v", tt.rate, tt.at, got, tt.wantDirection, tt.wantPastPeak)
+			}
+		})
+	}
+}
+
+func TestBuildCurrentNarrative(t *testing.T) {
+	temp := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	dir := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+
+	tests := []struct {
+		name  string
+		data  *CurrentData
+		units string
+		want  string
+	}{
+		{
+			name: "humid evening with an inversion forming",
+			data: &CurrentData{
+				Primary: &models.Observation{
+					Temp: temp(18.2), Dewpoint: temp(17), WindSpeed: temp(8), WindDir: dir(310),
+				},
+				Trend:     &Trend{Rate: -1.5, Direction: TrendFalling},
+				Inversion: &InversionStatus{Active: true},
+			},
+			want: "18°C and falling, humid, light NW wind, valley inversion forming",
+		},
+		{
+			name: "imperial",
+			data: &CurrentData{
+				Primary: &models.Observation{Temp: temp(18.2), WindSpeed: temp(8), WindDir: dir(310)},
+				Trend:   &Trend{Rate: -1.5, Direction: TrendFalling},
+			},
+			units: UnitsImperial,
+			want:  "65°F and falling, light NW wind",
+		},
+		{
+			name: "comfortable, calm and steady",
+			data: &CurrentData{
+				Primary: &models.Observation{Temp: temp(22), Dewpoint: temp(12), WindSpeed: temp(0.5)},
+				Trend:   &Trend{Direction: TrendSteady},
+			},
+			want: "22°C, calm",
+		},
+		{
+			name: "wet and windy without a direction",
+			data: &CurrentData{
+				Primary:       &models.Observation{Temp: temp(9.6), Dewpoint: temp(5), WindSpeed: temp(45)},
+				Trend:         &Trend{Direction: TrendRising},
+				RainIntensity: "heavy",
+			},
+			want: "10°C and rising, heavy rain, dry, strong winds",
+		},
+		{
+			name: "no temperature",
+			data: &CurrentData{Primary: &models.Observation{WindSpeed: temp(10)}},
+			want: "",
+		},
+		{
+			name: "no primary station",
+			data: &CurrentData{},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units := tt.units
+			if units == "" {
+				units = UnitsMetric
+			}
+			if got := buildCurrentNarrative(tt.data, units); got != tt.want {
+				t.Errorf("buildCurrentNarrative() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCurrentCondition_NoForecastUsesObservations(t *testing.T) {
+	st, loc := setupTestStore(t)
+	if err := st.UpsertStation(models.Station{StationID: "IWANDI23", Name: "Primary", ElevationTier: "valley_floor", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(st, "8080", loc)
+	if got := srv.buildCurrentCondition(); got != noForecastCondition {
+		t.Errorf("with no forecast or observation, condition = %q, want %q", got, noForecastCondition)
+	}
+
+	// Raining at the station with no forecast stored at all.
+	if err := st.InsertObservation(models.Observation{
+		StationID:  "IWANDI23",
+		ObservedAt: time.Now().UTC().Add(-5 * time.Minute),
+		Temp:       sql.NullFloat64{Float64: 12, Valid: true},
+		PrecipRate: sql.NullFloat64{Float64: 3.5, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.buildCurrentCondition(); got != forecast.ConditionLightRain {
+		t.Errorf("raining with no forecast, condition = %q, want %q", got, forecast.ConditionLightRain)
+	}
+
+	if err := st.InsertObservation(models.Observation{
+		StationID:  "IWANDI23",
+		ObservedAt: time.Now().UTC(),
+		Temp:       sql.NullFloat64{Float64: 11, Valid: true},
+		PrecipRate: sql.NullFloat64{Float64: 22, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.buildCurrentCondition(); got != forecast.ConditionHeavyRain {
+		t.Errorf("pouring with no forecast, condition = %q, want %q", got, forecast.ConditionHeavyRain)
+	}
+}
+
+func TestDisplayObservation(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	latest := &models.Observation{ID: 1, ObservedAt: now}
+	recent := &models.Observation{ID: 2, ObservedAt: now.Add(-10 * time.Minute)}
+	stale := &models.Observation{ID: 3, ObservedAt: now.Add(-3 * time.Hour)}
+
+	tests := []struct {
+		name  string
+		clean *models.Observation
+		want  *models.Observation
+	}{
+		{"latest is clean", latest, latest},
+		{"recent clean reading", recent, recent},
+		{"stale clean reading", stale, latest},
+		{"no clean reading", nil, latest},
+	}
+	for _, tt := range tests {
+		if got := displayObservation(latest, tt.clean); got != tt.want {
+			t.Errorf("%s: displayObservation = observation %d, want %d", tt.name, got.ID, tt.want.ID)
+		}
+	}
+}
+
+func TestImperialTempExplanation_LeavesUnsetFieldsZero(t *testing.T) {
+	got := imperialTempExplanation(forecast.TempExplanation{MaxSource: "bom", MaxRaw: 20, MaxForecast: 20, MaxFinal: 20})
+	if got.MaxFinal != 68 || got.MaxForecast != 68 {
+		t.Errorf("MaxFinal, MaxForecast = %v, %v, want 68", got.MaxFinal, got.MaxForecast)
+	}
+	if got.MaxBustedFrom != 0 {
+		t.Errorf("MaxBustedFrom = %v without a bust, want 0", got.MaxBustedFrom)
+	}
+	if got.MinRaw != 0 || got.MinFinal != 0 {
+		t.Errorf("MinRaw, MinFinal = %v, %v without a min source, want 0", got.MinRaw, got.MinFinal)
+	}
+
+	busted := imperialTempExplanation(forecast.TempExplanation{MaxSource: "bom", MaxBusted: true, MaxBustedFrom: 30})
+	if busted.MaxBustedFrom != 86 {
+		t.Errorf("MaxBustedFrom = %v, want 86", busted.MaxBustedFrom)
+	}
+}
+
+func TestHourlyTrack_HalfHourZone(t *testing.T) {
+	adelaide := time.FixedZone("ACDT", 10*3600+1800)
+	temp := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+
+	// 09:10, 09:50 and 10:05 local are two local hours but straddle a UTC hour
+	// boundary at 09:30
+	base := time.Date(2026, 1, 15, 9, 10, 0, 0, adelaide)
+	obs := []models.Observation{
+		{ObservedAt: base.UTC(), Temp: temp(20)},
+		{ObservedAt: base.Add(40 * time.Minute).UTC(), Temp: temp(22)},
+		{ObservedAt: base.Add(55 * time.Minute).UTC(), Temp: temp(25)},
+	}
+
+	got := hourlyTrack(obs, adelaide)
+	if len(got) != 2 {
+		t.Fatalf("hourlyTrack = %+v, want 2 hours", got)
+	}
+	for i, want := range []TrackPoint{
+		{Time: time.Date(2026, 1, 15, 9, 0, 0, 0, adelaide), Temp: 21, Samples: 2},
+		{Time: time.Date(2026, 1, 15, 10, 0, 0, 0, adelaide), Temp: 25, Samples: 1},
+	} {
+		if !got[i].Time.Equal(want.Time) || got[i].Temp != want.Temp || got[i].Samples != want.Samples {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"math"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/lox/wandiweather/internal/forecast"
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
 )
 
 // getForecastData assembles the multi-day forecast data.
@@ -46,15 +49,15 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 		f := fc
 		dayMap[key].WU = &f
 
-		if fc.TempMax.Valid {
-			if bias := forecast.LookupBias(correctionStats, "wu", "tmax", fc.DayOfForecast); bias != 0 {
-				corrected := fc.TempMax.Float64 - bias
-				dayMap[key].WUCorrectedMax = &corrected
+		for _, c := range forecastCorrections(correctionStats, "wu", fc) {
+			dayMap[key].Corrections = append(dayMap[key].Corrections, c)
+			if c.Bias == 0 {
+				continue
 			}
-		}
-		if fc.TempMin.Valid {
-			if bias := forecast.LookupBias(correctionStats, "wu", "tmin", fc.DayOfForecast); bias != 0 {
-				corrected := fc.TempMin.Float64 - bias
+			corrected := c.Corrected
+			if c.Target == "max" {
+				dayMap[key].WUCorrectedMax = &corrected
+			} else {
 				dayMap[key].WUCorrectedMin = &corrected
 			}
 		}
@@ -73,15 +76,15 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 		f := fc
 		dayMap[key].BOM = &f
 
-		if fc.TempMax.Valid {
-			if bias := forecast.LookupBias(correctionStats, "bom", "tmax", fc.DayOfForecast); bias != 0 {
-				corrected := fc.TempMax.Float64 - bias
-				dayMap[key].BOMCorrectedMax = &corrected
+		for _, c := range forecastCorrections(correctionStats, "bom", fc) {
+			dayMap[key].Corrections = append(dayMap[key].Corrections, c)
+			if c.Bias == 0 {
+				continue
 			}
-		}
-		if fc.TempMin.Valid {
-			if bias := forecast.LookupBias(correctionStats, "bom", "tmin", fc.DayOfForecast); bias != 0 {
-				corrected := fc.TempMin.Float64 - bias
+			corrected := c.Corrected
+			if c.Target == "max" {
+				dayMap[key].BOMCorrectedMax = &corrected
+			} else {
 				dayMap[key].BOMCorrectedMin = &corrected
 			}
 		}
@@ -96,6 +99,8 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 		}
 	}
 
+	tiers := tierElevations(stations)
+
 	nowcaster := forecast.NewNowcaster(s.store, s.loc)
 	biasCorrector := forecast.NewBiasCorrector(s.store)
 
@@ -116,14 +121,14 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 			}
 		}
 		// Get current temp from latest observation
-		if obs, err := s.store.GetLatestObservation(primaryStationID); err == nil && obs != nil && obs.Temp.Valid {
+		if obs, err := s.store.GetLatestObservation(primaryStationID, true); err == nil && obs != nil && obs.Temp.Valid {
 			currentTemp = obs.Temp.Float64
 			hasCurrentTemp = true
 		}
 	}
 
 	var days []ForecastDay
-	for i := 0; i < 5; i++ {
+	for i := 0; i < s.forecastDays; i++ {
 		date := todayDate.AddDate(0, 0, i)
 		key := date.Format("2006-01-02")
 		if day, ok := dayMap[key]; ok {
@@ -133,6 +138,7 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 					WUForecast:       day.WU,
 					BOMForecast:      day.BOM,
 					CorrectionStats:  correctionStats,
+					Regime:           s.todayRegime(primaryStationID, day.WU, day.BOM),
 					BiasCorrector:    biasCorrector,
 					Nowcaster:        nowcaster,
 					PrimaryStationID: primaryStationID,
@@ -145,6 +151,8 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 					Hour:             today.Hour(),
 					TempFalling:      false, // We don't have temp change rate here, safer to not assume
 					LogNowcast:       false, // Don't log again, main display already logged
+					Blend:            s.forecastBlend,
+					Skill:            forecast.SkillFromCorrectionStats(correctionStats, 0),
 				}
 
 				tempResult := forecast.ComputeTodayTemps(tempInput)
@@ -157,11 +165,20 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 				}
 			}
 			day.GeneratedNarrative = buildGeneratedNarrative(day)
+			day.PrecipTypes = tierPrecipTypes(day, tiers)
 			days = append(days, *day)
 		}
 	}
 
 	data := &ForecastData{Days: days}
+	for i := range days {
+		if fetched := latestFetch(days[i].WU, days[i].BOM); fetched.After(data.FetchedAt) {
+			data.FetchedAt = fetched
+		}
+	}
+	if !data.FetchedAt.IsZero() {
+		data.UpdatedAgo = formatAge(today.Sub(data.FetchedAt))
+	}
 	if wuStats, ok := stats["wu"]; ok {
 		data.WUStats = &wuStats
 		data.HasStats = true
@@ -174,6 +191,159 @@ func (s *Server) getForecastData() (*ForecastData, error) {
 	return data, nil
 }
 
+// forecastCorrections returns the working behind a source's bias-corrected
+// max and min for one forecast day, for each temperature it has. Like the
+// rest of the multi-day forecast it uses the all-regime bias, since regimes
+// aren't known ahead of time.
+func forecastCorrections(stats store.CorrectionStatsMap, source string, fc models.Forecast) []ForecastCorrection {
+	var corrections []ForecastCorrection
+	for _, t := range []struct {
+		target, statsTarget string
+		temp                sql.NullFloat64
+	}{
+		{"max", "tmax", fc.TempMax},
+		{"min", "tmin", fc.TempMin},
+	} {
+		if !t.temp.Valid {
+			continue
+		}
+		bias := forecast.LookupBiasWithFallback(stats, source, t.statsTarget, fc.DayOfForecast, "all")
+		corrections = append(corrections, ForecastCorrection{
+			Source:        source,
+			Target:        t.target,
+			DayOfForecast: fc.DayOfForecast,
+			Raw:           t.temp.Float64,
+			Bias:          bias.Bias,
+			BiasDayUsed:   bias.DayUsed,
+			BiasSamples:   bias.Samples,
+			BiasFallback:  bias.IsFallback,
+			Corrected:     t.temp.Float64 - bias.Bias,
+		})
+	}
+	return corrections
+}
+
+// latestFetch returns the most recent FetchedAt among the given forecasts,
+// skipping nils. It returns the zero time if there are none.
+func latestFetch(forecasts ...*models.Forecast) time.Time {
+	var latest time.Time
+	for _, fc := range forecasts {
+		if fc != nil && fc.FetchedAt.After(latest) {
+			latest = fc.FetchedAt
+		}
+	}
+	return latest
+}
+
+// formatAge renders a forecast's age as "just now", "40m ago", "6h ago" or
+// "2d ago".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// tierElevation is the reference elevation of a tier, relative to the valley floor.
+type tierElevation struct {
+	tier      string
+	label     string
+	elevation float64
+}
+
+// tierElevations returns the valley, mid-slope and upper tiers present among
+// stations, each with its highest station's height above the lowest valley
+// station. It returns nil if there's no valley station to measure from.
+func tierElevations(stations []models.Station) []tierElevation {
+	var valleyElev float64
+	haveValley := false
+	highest := make(map[string]float64)
+	for _, st := range stations {
+		tier := st.ElevationTier
+		if tier == "local" {
+			tier = "valley_floor"
+		}
+		if tier == "valley_floor" && (!haveValley || st.Elevation < valleyElev) {
+			valleyElev = st.Elevation
+			haveValley = true
+		}
+		if e, ok := highest[tier]; !ok || st.Elevation > e {
+			highest[tier] = st.Elevation
+		}
+	}
+	if !haveValley {
+		return nil
+	}
+
+	var tiers []tierElevation
+	for _, t := range []struct{ tier, label string }{
+		{"valley_floor", "Valley"},
+		{"mid_slope", "Mid"},
+		{"upper", "Upper"},
+	} {
+		if e, ok := highest[t.tier]; ok {
+			tiers = append(tiers, tierElevation{tier: t.tier, label: t.label, elevation: e - valleyElev})
+		}
+	}
+	return tiers
+}
+
+// tierPrecipTypes infers rain/sleet/snow for each tier on a day with likely
+// precipitation. It returns nil when precipitation is unlikely or it's rain
+// everywhere, so only days worth flagging show anything.
+func tierPrecipTypes(day *ForecastDay, tiers []tierElevation) []TierPrecipType {
+	if len(tiers) == 0 || day.WU == nil || !day.WU.PrecipChance.Valid || day.WU.PrecipChance.Int64 <= 10 {
+		return nil
+	}
+
+	hi, lo, haveHi, haveLo := chooseTemps(day)
+	if day.DisplayMax != nil {
+		hi, haveHi = *day.DisplayMax, true
+	}
+	if day.DisplayMin != nil {
+		lo, haveLo = *day.DisplayMin, true
+	}
+	var surface float64
+	switch {
+	case haveHi && haveLo:
+		surface = (hi + lo) / 2
+	case haveHi:
+		surface = hi
+	case haveLo:
+		surface = lo
+	default:
+		return nil
+	}
+
+	var narrative string
+	if day.WU.Narrative.Valid {
+		narrative = day.WU.Narrative.String
+	}
+	if day.BOM != nil && day.BOM.Narrative.Valid {
+		narrative += " " + day.BOM.Narrative.String
+	}
+
+	var types []TierPrecipType
+	allRain := true
+	for _, t := range tiers {
+		pt := forecast.PrecipTypeForStation(surface, t.elevation, narrative)
+		if pt != forecast.PrecipRain {
+			allRain = false
+		}
+		types = append(types, TierPrecipType{Tier: t.tier, Label: t.label, Elevation: t.elevation, Type: pt})
+	}
+	if allRain {
+		return nil
+	}
+	return types
+}
+
 // extractCondition extracts the weather condition from a WU narrative,
 // stripping out temperature information.
 func extractCondition(narrative string) string {
@@ -284,5 +454,53 @@ func buildGeneratedNarrative(day *ForecastDay) string {
 		return ""
 	}
 
+	if precip := precipPhrase(day); precip != "" {
+		parts = append(parts, precip+".")
+	}
+
 	return strings.Join(parts, " ")
 }
+
+// precipPhrase describes the day's rain chance and amount, e.g. "60% chance
+// of 2–5mm". BOM's range is preferred over WU's single amount. It returns ""
+// when the chance is 10% or less, matching the forecast card's rain badge.
+func precipPhrase(day *ForecastDay) string {
+	var chance int64
+	switch {
+	case day.WU != nil && day.WU.PrecipChance.Valid:
+		chance = day.WU.PrecipChance.Int64
+	case day.BOM != nil && day.BOM.PrecipChance.Valid:
+		chance = day.BOM.PrecipChance.Int64
+	default:
+		return ""
+	}
+	if chance <= 10 {
+		return ""
+	}
+
+	amount := ""
+	if day.BOM != nil && day.BOM.PrecipRange.Valid {
+		amount = formatPrecipRange(day.BOM.PrecipRange.String)
+	}
+	if amount == "" && day.WU != nil && day.WU.PrecipAmount.Valid && day.WU.PrecipAmount.Float64 > 0 {
+		if mm := math.Round(day.WU.PrecipAmount.Float64); mm >= 1 {
+			amount = fmt.Sprintf("%dmm", int(mm))
+		} else {
+			amount = "<1mm"
+		}
+	}
+	if amount == "" {
+		return fmt.Sprintf("%d%% chance of rain", chance)
+	}
+	return fmt.Sprintf("%d%% chance of %s", chance, amount)
+}
+
+// formatPrecipRange turns BOM's "1 to 5 mm" into "1–5mm". A zero range
+// returns "" so the caller can fall back to another amount.
+func formatPrecipRange(r string) string {
+	r = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(r), "mm"))
+	if r == "" || r == "0" {
+		return ""
+	}
+	return strings.Replace(r, " to ", "–", 1) + "mm"
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// adminTokenHeader carries the shared secret for /admin endpoints.
+const adminTokenHeader = "X-Admin-Token"
+
+// readOnlyMessage is the response to admin changes while serving read-only.
+const readOnlyMessage = "server is read-only"
+
+// authorizeAdmin reports whether the request carries the configured admin
+// token. With no token configured, nothing is authorized.
+func (s *Server) authorizeAdmin(r *http.Request) bool {
+	if s.adminToken == "" {
+		return false
+	}
+	got := r.Header.Get(adminTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(s.adminToken)) == 1
+}
+
+func (s *Server) handleAdminIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.readOnly {
+		http.Error(w, readOnlyMessage, http.StatusForbidden)
+		return
+	}
+	if s.ingester == nil {
+		http.Error(w, "ingest not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Println("api: manual ingest triggered")
+	results, err := s.ingester.IngestOnce()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// stationUpdate is the body of POST /admin/stations/{id}. Omitted fields are
+// left unchanged.
+type stationUpdate struct {
+	Active    *bool `json:"active"`
+	IsPrimary *bool `json:"is_primary"`
+}
+
+// handleAdminStation changes a station's active or primary flag at runtime.
+// Making a station primary clears the flag on the previous primary, so the
+// primary is moved by promoting its replacement; demoting or deactivating the
+// primary directly is rejected. The scheduler polls the new set of active
+// stations from its next cycle; changes last until the next restart re-seeds
+// the configured stations.
+func (s *Server) handleAdminStation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if s.readOnly {
+		http.Error(w, readOnlyMessage, http.StatusForbidden)
+		return
+	}
+
+	var update stationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stationID := r.PathValue("id")
+	st, err := s.store.GetStation(stationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if st == nil {
+		http.Error(w, "station not found", http.StatusNotFound)
+		return
+	}
+
+	active, primary := st.Active, st.IsPrimary
+	if update.Active != nil {
+		active = *update.Active
+	}
+	if update.IsPrimary != nil {
+		primary = *update.IsPrimary
+	}
+	if st.IsPrimary && !primary {
+		http.Error(w, "can't demote the primary station; promote another station instead", http.StatusConflict)
+		return
+	}
+	if primary && !active {
+		http.Error(w, "the primary station must be active", http.StatusConflict)
+		return
+	}
+	if err := s.store.UpdateStationFlags(stationID, active, primary); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.currentCache.invalidate()
+
+	log.Printf("api: station %s updated", stationID)
+	st, err = s.store.GetStation(stationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+const (
+	defaultPayloadLimit = 50
+	maxPayloadLimit     = 500
+)
+
+// handleAdminPayloads lists stored raw API responses, newest first.
+// source=wu|bom narrows the list and limit caps its length.
+func (s *Server) handleAdminPayloads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	limit := defaultPayloadLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPayloadLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPayloadLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	payloads, err := s.store.ListRawPayloads(q.Get("source"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]PayloadInfo, 0, len(payloads))
+	for _, p := range payloads {
+		info := PayloadInfo{
+			ID:         p.ID,
+			FetchedAt:  p.FetchedAt,
+			Source:     p.Source,
+			Endpoint:   p.Endpoint,
+			StationID:  p.StationID.String,
+			LocationID: p.LocationID.String,
+			SizeBytes:  p.SizeBytes,
+			Hash:       p.PayloadHash,
+		}
+		if p.IngestRunID.Valid {
+			info.IngestRunID = &p.IngestRunID.Int64
+		}
+		infos = append(infos, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+// handleAdminPayload returns a stored raw API response exactly as it was
+// received, for checking what an upstream sent when a parse looks wrong.
+func (s *Server) handleAdminPayload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid payload id", http.StatusBadRequest)
+		return
+	}
+	p, err := s.store.GetRawPayloadByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if p == nil {
+		http.Error(w, "payload not found", http.StatusNotFound)
+		return
+	}
+	body, err := p.Decompress()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", payloadContentType(p.Source, body))
+	w.Write(body)
+}
+
+// payloadContentType returns the media type a source's responses are served
+// with: JSON from Weather Underground and XML from the BOM.
+func payloadContentType(source string, body []byte) string {
+	switch source {
+	case "wu":
+		return "application/json"
+	case "bom":
+		return "application/xml"
+	default:
+		return http.DetectContentType(body)
+	}
+}
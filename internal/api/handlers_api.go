@@ -1,9 +1,18 @@
 package api
 
 import (
-	"encoding/json"
+	"database/sql"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"time"
+
+	"github.com/lox/wandiweather/internal/forecast"
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
 )
 
 func (s *Server) handleAPICurrent(w http.ResponseWriter, r *http.Request) {
@@ -12,28 +21,346 @@ func (s *Server) handleAPICurrent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	s.writeJSON(w, r, data)
+}
+
+const (
+	defaultHistoryLimit = 1000
+	maxHistoryLimit     = 5000
+	maxHistorySpan      = 7 * 24 * time.Hour
+)
+
+// historyBuckets are the widths accepted by the history API's bucket parameter.
+var historyBuckets = map[string]time.Duration{
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
 }
 
+// handleAPIHistory returns observations for a station. With no parameters it
+// returns the last 24 hours. from/to (RFC3339 or YYYY-MM-DD) select a range of
+// at most 7 days, and limit/offset page through it; when more rows remain a
+// Link header with rel="next" points at the following page. bucket=15m|1h
+// returns aggregates per time bucket instead of raw observations, unpaged.
+// smooth (e.g. 5m) replaces temperatures with a centred moving average over the
+// whole range, so a page's values don't depend on where the page breaks fall.
+// Without a limit the page is sized to hold the range at one-minute cadence, up
+// to maxHistoryLimit, so the default 24 hours still comes back in one response.
+// An empty range returns [] rather than null.
 func (s *Server) handleAPIHistory(w http.ResponseWriter, r *http.Request) {
-	stationID := r.URL.Query().Get("station")
-	if stationID == "" {
-		stationID = "IWANDI23"
-	}
+	q := r.URL.Query()
 
-	hours := 24
 	end := time.Now()
-	start := end.Add(-time.Duration(hours) * time.Hour)
+	if v := q.Get("to"); v != "" {
+		t, err := s.parseHistoryTime(v)
+		if err != nil {
+			http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		end = t
+	}
+	start := end.Add(-24 * time.Hour)
+	if v := q.Get("from"); v != "" {
+		t, err := s.parseHistoryTime(v)
+		if err != nil {
+			http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+	if end.Sub(start) > maxHistorySpan {
+		http.Error(w, "range exceeds 7 days; request it in chunks", http.StatusBadRequest)
+		return
+	}
+	smooth, err := parseSmooth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stationID, ok := s.queryStationID(w, r)
+	if !ok {
+		return
+	}
+
+	if v := q.Get("bucket"); v != "" {
+		width, ok := historyBuckets[v]
+		if !ok {
+			http.Error(w, "bucket must be 15m or 1h", http.StatusBadRequest)
+			return
+		}
+		s.writeHistoryBuckets(w, r, stationID, start, end, width, smooth)
+		return
+	}
+
+	limit := min(int(end.Sub(start)/time.Minute)+1, maxHistoryLimit)
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+
+	// Fetch one extra row to know whether there's a next page
+	observations, err := s.store.GetObservationsPage(stationID, start.UTC(), end.UTC(), limit+1, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(observations) > limit {
+		observations = observations[:limit]
+		next := url.Values{}
+		next.Set("station", stationID)
+		next.Set("from", start.UTC().Format(time.RFC3339))
+		next.Set("to", end.UTC().Format(time.RFC3339))
+		next.Set("limit", strconv.Itoa(limit))
+		next.Set("offset", strconv.Itoa(offset+limit))
+		if smooth > 0 {
+			next.Set("smooth", q.Get("smooth"))
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+	if observations == nil {
+		observations = []models.Observation{}
+	}
+	if smooth > 0 && len(observations) > 0 {
+		if err := s.smoothHistoryPage(observations, stationID, start, end, smooth); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	s.writeJSON(w, r, observationList(observations))
+}
+
+// queryStationID returns the request's station parameter, defaulting to the
+// primary station. It writes an error and returns false if there's neither.
+func (s *Server) queryStationID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if stationID := r.URL.Query().Get("station"); stationID != "" {
+		return stationID, true
+	}
+	primary, err := s.store.GetPrimaryStation()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+	if primary == nil {
+		http.Error(w, "no primary station; pass station", http.StatusNotFound)
+		return "", false
+	}
+	return primary.StationID, true
+}
+
+func (s *Server) writeHistoryBuckets(w http.ResponseWriter, r *http.Request, stationID string, start, end time.Time, width, smooth time.Duration) {
+	buckets, err := s.store.GetObservationBuckets(stationID, start.UTC(), end.UTC(), width)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]HistoryBucket, 0, len(buckets))
+	for _, b := range buckets {
+		hb := HistoryBucket{Start: b.Start, Samples: b.Samples}
+		if b.TempAvg.Valid {
+			hb.TempAvg = &b.TempAvg.Float64
+		}
+		if b.WindGustMax.Valid {
+			hb.WindGustMax = &b.WindGustMax.Float64
+		}
+		if b.Precip.Valid {
+			hb.Precip = &b.Precip.Float64
+		}
+		result = append(result, hb)
+	}
+	if smooth > 0 {
+		times := make([]time.Time, len(result))
+		temps := make([]float64, len(result))
+		for i, hb := range result {
+			times[i] = hb.Start
+			temps[i] = math.NaN()
+			if hb.TempAvg != nil {
+				temps[i] = *hb.TempAvg
+			}
+		}
+		for i, v := range smoothSeries(times, temps, smooth) {
+			if result[i].TempAvg != nil {
+				result[i].TempAvg = &v
+			}
+		}
+	}
+
+	s.writeJSON(w, r, imperialList[HistoryBucket](result))
+}
+
+// handleAPIObservationsSince returns a station's observations newer than a
+// cursor, so polling clients can append rather than re-fetch a whole window.
+// after is either an observation id or a time (RFC3339 or YYYY-MM-DD); the
+// response's cursor is the id to pass as after on the next poll.
+func (s *Server) handleAPIObservationsSince(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	stationID, ok := s.queryStationID(w, r)
+	if !ok {
+		return
+	}
+
+	after := q.Get("after")
+	if after == "" {
+		http.Error(w, "after is required", http.StatusBadRequest)
+		return
+	}
+
+	var observations []models.Observation
+	var cursor int64
+	if id, err := strconv.ParseInt(after, 10, 64); err == nil {
+		cursor = id
+		observations, err = s.store.GetObservationsAfterID(stationID, id, defaultHistoryLimit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		t, err := s.parseHistoryTime(after)
+		if err != nil {
+			http.Error(w, "invalid after: must be an observation id or time", http.StatusBadRequest)
+			return
+		}
+		observations, err = s.store.GetObservationsAfterTime(stationID, t.UTC(), defaultHistoryLimit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if len(observations) > 0 {
+		cursor = observations[len(observations)-1].ID
+	}
+	if observations == nil {
+		observations = []models.Observation{}
+	}
+
+	s.writeJSON(w, r, ObservationsSince{Observations: observations, Cursor: cursor})
+}
 
-	observations, err := s.store.GetObservations(stationID, start, end)
+const (
+	// sparklinePoints is how many values /api/sparkline returns.
+	sparklinePoints       = 24
+	defaultSparklineHours = 24
+	maxSparklineHours     = 7 * 24
+)
+
+// sparklineMetrics picks each supported metric out of a bucket.
+var sparklineMetrics = map[string]func(store.ObservationBucket) sql.NullFloat64{
+	"temp":      func(b store.ObservationBucket) sql.NullFloat64 { return b.TempAvg },
+	"humidity":  func(b store.ObservationBucket) sql.NullFloat64 { return b.HumidityAvg },
+	"pressure":  func(b store.ObservationBucket) sql.NullFloat64 { return b.PressureAvg },
+	"wind_gust": func(b store.ObservationBucket) sql.NullFloat64 { return b.WindGustMax },
+	"precip":    func(b store.ObservationBucket) sql.NullFloat64 { return b.Precip },
+}
+
+// sparklineImperial converts each metric for units=imperial. A bare array of
+// numbers doesn't say what it measures, so the handler converts it itself.
+var sparklineImperial = map[string]func(float64) float64{
+	"temp":      models.CelsiusToFahrenheit,
+	"pressure":  models.HPaToInHg,
+	"wind_gust": models.KmhToMph,
+	"precip":    models.MmToInches,
+}
+
+// handleAPISparkline returns a bare array of sparklinePoints values for one
+// metric over the last hours (default 24, at most 168), oldest first. Buckets
+// without data repeat the previous value; there are no values at all if the
+// station reported nothing in the window.
+func (s *Server) handleAPISparkline(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	stationID, ok := s.queryStationID(w, r)
+	if !ok {
+		return
+	}
+
+	metric, ok := sparklineMetrics[q.Get("metric")]
+	if !ok {
+		http.Error(w, "metric must be one of temp, humidity, pressure, wind_gust, precip", http.StatusBadRequest)
+		return
+	}
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hours := defaultSparklineHours
+	if v := q.Get("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSparklineHours {
+			http.Error(w, fmt.Sprintf("hours must be between 1 and %d", maxSparklineHours), http.StatusBadRequest)
+			return
+		}
+		hours = n
+	}
+
+	// Align the window to whole buckets so each bucket maps onto one point
+	width := int64(hours) * 3600 / sparklinePoints
+	endUnix := (time.Now().Unix()/width + 1) * width
+	start := time.Unix(endUnix-width*sparklinePoints, 0).UTC()
+	end := time.Unix(endUnix, 0).UTC()
+
+	buckets, err := s.store.GetObservationBuckets(stationID, start, end, time.Duration(width)*time.Second)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(observations)
+	var points [sparklinePoints]sql.NullFloat64
+	for _, b := range buckets {
+		i := (b.Start.Unix() - start.Unix()) / width
+		if i >= 0 && i < sparklinePoints {
+			points[i] = metric(b)
+		}
+	}
+
+	values := []float64{}
+	var last sql.NullFloat64
+	for _, p := range points {
+		if p.Valid {
+			last = p
+		}
+		if last.Valid {
+			values = append(values, last.Float64)
+		}
+	}
+	// Backfill any leading gap with the first value
+	for len(values) > 0 && len(values) < sparklinePoints {
+		values = append([]float64{values[0]}, values...)
+	}
+	if conv, ok := sparklineImperial[q.Get("metric")]; ok && units == UnitsImperial {
+		for i, v := range values {
+			values[i] = conv(v)
+		}
+	}
+
+	s.writeJSON(w, r, values)
+}
+
+// parseHistoryTime accepts RFC3339 timestamps or YYYY-MM-DD dates (local midnight).
+func (s *Server) parseHistoryTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, s.loc)
 }
 
 func (s *Server) handleAPIStations(w http.ResponseWriter, r *http.Request) {
@@ -42,8 +369,126 @@ func (s *Server) handleAPIStations(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stations)
+	s.writeJSON(w, r, stations)
+}
+
+// completenessWindow is how far back /api/stations/detail measures completeness.
+const completenessWindow = 24 * time.Hour
+
+// handleAPIStationsDetail returns every station, including inactive ones, with
+// its latest reading, last-seen age and how complete its last day of data is,
+// overall and per sensor field.
+func (s *Server) handleAPIStationsDetail(w http.ResponseWriter, r *http.Request) {
+	stations, err := s.store.GetAllStations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	details := make([]StationDetail, 0, len(stations))
+	for _, st := range stations {
+		d := StationDetail{
+			StationID:  st.StationID,
+			Name:       st.Name,
+			Tier:       st.ElevationTier,
+			Elevation:  st.Elevation,
+			Active:     st.Active,
+			IsPrimary:  st.IsPrimary,
+			AgeMinutes: -1,
+			Stale:      true,
+		}
+
+		obs, err := s.store.GetLatestObservation(st.StationID, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if obs != nil {
+			lastSeen := obs.ObservedAt
+			d.LastSeen = &lastSeen
+			d.AgeMinutes = int(now.Sub(lastSeen).Minutes())
+			d.Stale = now.Sub(lastSeen) > time.Duration(staleThresholdMinutes(st))*time.Minute
+			if obs.Temp.Valid {
+				temp := obs.Temp.Float64
+				d.Temp = &temp
+			}
+		}
+
+		hours, err := s.store.CountObservedHours(st.StationID, now.Add(-completenessWindow))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		d.Completeness = min(1, float64(hours)/completenessWindow.Hours())
+
+		fields, err := s.store.GetFieldCompleteness(st.StationID, completenessWindow)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		d.FieldCompleteness = fields
+		if hours > 0 {
+			for field, frac := range fields {
+				if frac == 0 {
+					d.MissingSensors = append(d.MissingSensors, field)
+				}
+			}
+			sort.Strings(d.MissingSensors)
+		}
+
+		details = append(details, d)
+	}
+
+	s.writeJSON(w, r, imperialList[StationDetail](details))
+}
+
+// handleAPIComfort returns apparent temperature, dewpoint comfort and a heat
+// stress flag for each active station with a current temperature and humidity.
+func (s *Server) handleAPIComfort(w http.ResponseWriter, r *http.Request) {
+	stations, err := s.store.GetActiveStations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results := make([]StationComfort, 0, len(stations))
+	for _, st := range stations {
+		obs, err := s.store.GetLatestObservation(st.StationID, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if obs == nil || !obs.Temp.Valid || !obs.Humidity.Valid {
+			continue
+		}
+
+		temp := obs.Temp.Float64
+		humidity := float64(obs.Humidity.Int64)
+		var wind float64
+		if obs.WindSpeed.Valid {
+			wind = obs.WindSpeed.Float64
+		}
+		dewpoint := forecast.Dewpoint(temp, humidity)
+		if obs.Dewpoint.Valid {
+			dewpoint = obs.Dewpoint.Float64
+		}
+		apparent := forecast.ApparentTemperature(temp, humidity, wind)
+
+		results = append(results, StationComfort{
+			StationID:       st.StationID,
+			Name:            st.Name,
+			ObservedAt:      obs.ObservedAt,
+			Temp:            temp,
+			Humidity:        humidity,
+			ApparentTemp:    math.Round(apparent*10) / 10,
+			Dewpoint:        math.Round(dewpoint*10) / 10,
+			DewpointComfort: forecast.DewpointComfort(dewpoint),
+			HeatStress:      apparent >= forecast.HeatStressApparentTemp,
+		})
+	}
+
+	s.writeJSON(w, r, imperialList[StationComfort](results))
 }
 
 func (s *Server) handleAPIForecast(w http.ResponseWriter, r *http.Request) {
@@ -52,6 +497,428 @@ func (s *Server) handleAPIForecast(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	s.writeJSON(w, r, data)
+}
+
+// handleAPIForecastCorrections returns the raw forecast, bias and corrected
+// value behind each source's max and min for the days the forecast page shows.
+func (s *Server) handleAPIForecastCorrections(w http.ResponseWriter, r *http.Request) {
+	data, err := s.getForecastData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]ForecastCorrectionDay, 0, len(data.Days))
+	for _, day := range data.Days {
+		corrections := day.Corrections
+		if corrections == nil {
+			corrections = []ForecastCorrection{}
+		}
+		resp = append(resp, ForecastCorrectionDay{
+			Date:        day.Date.Format("2006-01-02"),
+			Corrections: corrections,
+		})
+	}
+	s.writeJSON(w, r, imperialList[ForecastCorrectionDay](resp))
+}
+
+func (s *Server) handleAPIForecastExplain(w http.ResponseWriter, r *http.Request) {
+	date := time.Now().In(s.loc)
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, s.loc)
+		if err != nil {
+			http.Error(w, "invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	df, err := s.store.GetLatestDisplayedForecast(date)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if df == nil {
+		http.Error(w, "no displayed forecast for "+date.Format("2006-01-02"), http.StatusNotFound)
+		return
+	}
+
+	explanation := ForecastExplanation{
+		ValidDate:     df.ValidDate.Format("2006-01-02"),
+		DisplayedAt:   df.DisplayedAt,
+		DayOfForecast: df.DayOfForecast,
+		Max: explainTemp(df.SourceMax, df.RawTempMax, df.BiasAppliedMax, df.NowcastMax, df.CorrectedTempMax,
+			df.BiasDayUsedMax, df.BiasSamplesMax, df.BiasFallbackMax),
+		Min: explainTemp(df.SourceMin, df.RawTempMin, df.BiasAppliedMin, sql.NullFloat64{}, df.CorrectedTempMin,
+			df.BiasDayUsedMin, df.BiasSamplesMin, df.BiasFallbackMin),
+	}
+
+	s.writeJSON(w, r, explanation)
+}
+
+// explainTemp builds the breakdown for one displayed temperature.
+func explainTemp(source sql.NullString, raw, bias, nowcast, final sql.NullFloat64, dayUsed, samples sql.NullInt64, fallback sql.NullBool) TempExplanationRow {
+	row := TempExplanationRow{
+		Source:       source.String,
+		BiasFallback: fallback.Valid && fallback.Bool,
+	}
+	if raw.Valid {
+		row.Raw = &raw.Float64
+	}
+	if bias.Valid {
+		row.Bias = &bias.Float64
+	}
+	if final.Valid {
+		row.Final = &final.Float64
+	}
+	if dayUsed.Valid {
+		d := int(dayUsed.Int64)
+		row.BiasDayUsed = &d
+	}
+	if samples.Valid {
+		n := int(samples.Int64)
+		row.BiasSamples = &n
+	}
+	if nowcast.Valid {
+		row.Nowcast = &nowcast.Float64
+	}
+	return row
+}
+
+func (s *Server) handleAPIRegime(w http.ResponseWriter, r *http.Request) {
+	date := time.Now().In(s.loc)
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, s.loc)
+		if err != nil {
+			http.Error(w, "invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	primary, err := s.store.GetPrimaryStation()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if primary == nil {
+		http.Error(w, "no primary station", http.StatusNotFound)
+		return
+	}
+
+	summary, err := s.store.GetDailySummary(primary.StationID, date)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Previous days, most recent first, as the daily job passes them
+	var prevDays []models.DailySummary
+	for i := 1; i <= 2; i++ {
+		prev, err := s.store.GetDailySummary(primary.StationID, date.AddDate(0, 0, -i))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if prev == nil {
+			break
+		}
+		prevDays = append(prevDays, *prev)
+	}
+
+	forecasts, err := s.store.GetForecastsForDate(date)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var fc *models.Forecast
+	for _, f := range forecasts {
+		if f.Source == "wu" {
+			fc = &f
+			break
+		}
+	}
+
+	flags := forecast.ClassifyRegime(fc, summary, prevDays)
+	resp := RegimeResponse{
+		Date:      date.Format("2006-01-02"),
+		StationID: primary.StationID,
+		Regime:    forecast.RegimeToString(flags),
+		Flags: RegimeFlags{
+			Heatwave:       flags.Heatwave,
+			ColdSnap:       flags.ColdSnap,
+			InversionNight: flags.InversionNight,
+			ClearCalm:      flags.ClearCalm,
+		},
+		Inputs: RegimeInputs{PrevDayMaxes: []float64{}},
+	}
+	if fc != nil && fc.TempMax.Valid {
+		resp.Inputs.ForecastMax = &fc.TempMax.Float64
+	}
+	if summary != nil {
+		if summary.InversionDetected.Valid {
+			resp.Inputs.InversionDetected = &summary.InversionDetected.Bool
+		}
+		if summary.PrecipTotal.Valid {
+			resp.Inputs.PrecipTotal = &summary.PrecipTotal.Float64
+		}
+		if summary.SolarIntegral.Valid {
+			resp.Inputs.SolarIntegral = &summary.SolarIntegral.Float64
+		}
+		if summary.CalmFractionNight.Valid {
+			resp.Inputs.CalmFractionNight = &summary.CalmFractionNight.Float64
+		}
+	}
+	for _, pd := range prevDays {
+		if pd.TempMax.Valid {
+			resp.Inputs.PrevDayMaxes = append(resp.Inputs.PrevDayMaxes, pd.TempMax.Float64)
+		}
+	}
+
+	s.writeJSON(w, r, resp)
+}
+
+func (s *Server) handleAPITodayTrack(w http.ResponseWriter, r *http.Request) {
+	primary, err := s.store.GetPrimaryStation()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if primary == nil {
+		http.Error(w, "no primary station", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now().In(s.loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+
+	obs, err := s.store.GetObservations(primary.StationID, midnight.UTC(), now.UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	track := TodayTrack{
+		Date:      midnight.Format("2006-01-02"),
+		StationID: primary.StationID,
+		Observed:  hourlyTrack(obs, s.loc),
+	}
+
+	df, err := s.store.GetLatestDisplayedForecast(midnight)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if df != nil {
+		track.ForecastFrom = &df.DisplayedAt
+		if df.CorrectedTempMin.Valid {
+			track.ForecastMin = &df.CorrectedTempMin.Float64
+		}
+		if df.CorrectedTempMax.Valid {
+			track.ForecastMax = &df.CorrectedTempMax.Float64
+			// The stored max already includes the nowcast; split it back out
+			// so both the forecast and the adjusted line can be drawn.
+			if df.NowcastMax.Valid {
+				preNowcast := math.Round(df.CorrectedTempMax.Float64 - df.NowcastMax.Float64)
+				track.ForecastMax = &preNowcast
+				track.NowcastMax = &df.CorrectedTempMax.Float64
+			}
+		}
+	}
+
+	s.writeJSON(w, r, track)
+}
+
+// hourlyTrack averages observed temperatures into local-hour buckets. Hours
+// are built from the local clock rather than truncated, which would bucket on
+// UTC hours and be off by half an hour in zones like Adelaide.
+func hourlyTrack(obs []models.Observation, loc *time.Location) []TrackPoint {
+	points := []TrackPoint{}
+	var sum float64
+	for _, o := range obs {
+		if !o.Temp.Valid {
+			continue
+		}
+		t := o.ObservedAt.In(loc)
+		hour := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		if n := len(points); n > 0 && points[n-1].Time.Equal(hour) {
+			sum += o.Temp.Float64
+			points[n-1].Samples++
+			points[n-1].Temp = sum / float64(points[n-1].Samples)
+			continue
+		}
+		sum = o.Temp.Float64
+		points = append(points, TrackPoint{Time: hour, Temp: o.Temp.Float64, Samples: 1})
+	}
+	for i := range points {
+		points[i].Temp = math.Round(points[i].Temp*10) / 10
+	}
+	return points
+}
+
+// handleAPIInversion explains the current inversion verdict: the tier averages
+// and the readings behind them, and how the observed valley-to-upper
+// difference compares with what the lapse rate predicts.
+func (s *Server) handleAPIInversion(w http.ResponseWriter, r *http.Request) {
+	data, err := s.getCurrentData()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := InversionExplanation{LapseRate: s.lapseRate, Stations: []InversionReading{}}
+	for _, tier := range [][]StationReading{data.ValleyFloor, data.MidSlope, data.Upper} {
+		for _, reading := range tier {
+			if reading.Obs == nil || !reading.Obs.Temp.Valid {
+				continue
+			}
+			resp.Stations = append(resp.Stations, InversionReading{
+				StationID:  reading.Station.StationID,
+				Name:       reading.Station.Name,
+				Tier:       reading.Station.ElevationTier,
+				Elevation:  reading.Station.Elevation,
+				Temp:       reading.Obs.Temp.Float64,
+				ObservedAt: reading.Obs.ObservedAt,
+			})
+		}
+	}
+
+	tiers := gatherTierTemps(data.ValleyFloor, data.MidSlope, data.Upper)
+	if len(tiers.mid) > 0 {
+		midAvg := avg(tiers.mid)
+		resp.MidAvg = &midAvg
+	}
+	if inv := forecast.InversionStatus(tiers.valley, tiers.upper, tiers.valleyElev, tiers.upperElev, s.lapseRate); inv != nil {
+		resp.Available = true
+		resp.Active = inv.Active
+		resp.ValleyAvg = &inv.ValleyAvg
+		resp.UpperAvg = &inv.UpperAvg
+		resp.ValleyElevation = tiers.valleyElev
+		resp.UpperElevation = tiers.upperElev
+		resp.ExpectedDiff = inv.ExpectedDiff
+		resp.ActualDiff = inv.UpperAvg - inv.ValleyAvg
+		resp.Strength = inv.Strength
+	}
+
+	s.writeJSON(w, r, resp)
+}
+
+// handleAPISchema reports which migrations have been applied and whether this
+// build knows of any that haven't, for checking a deploy.
+func (s *Server) handleAPISchema(w http.ResponseWriter, r *http.Request) {
+	version, err := s.store.MigrationVersion()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	applied, err := s.store.GetAppliedMigrationDetails()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pending, err := s.store.PendingMigrations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := SchemaStatus{
+		Version:         version,
+		LatestKnown:     store.LatestMigrationVersion(),
+		Pending:         len(pending) > 0,
+		PendingVersions: []int{},
+		Applied:         []SchemaMigration{},
+	}
+	resp.PendingVersions = append(resp.PendingVersions, pending...)
+	for _, m := range applied {
+		resp.Applied = append(resp.Applied, SchemaMigration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   m.AppliedAt,
+		})
+	}
+
+	s.writeJSON(w, r, resp)
+}
+
+// handleAPIPalette returns the theme palette for any condition and time of
+// day, for front-end theming work: ?condition=storm&tod=night, or
+// ?condition=storm_night. With ?time=HH:MM it also returns the palette blended
+// for that time, as the index page shows it, and tod defaults to the one at
+// that time. Without a condition it uses today's.
+func (s *Server) handleAPIPalette(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	condition := forecast.WeatherCondition(q.Get("condition"))
+	tod := forecast.TimeOfDay(q.Get("tod"))
+	if tod == "" {
+		if c, t, ok := parseWeatherOverride(string(condition)); ok {
+			condition, tod = c, t
+		}
+	}
+	if condition == "" {
+		condition = s.getCurrentCondition()
+	}
+
+	now := time.Now().In(s.loc)
+	view := PaletteView{}
+	if raw := q.Get("time"); raw != "" {
+		clock, err := time.Parse("15:04", raw)
+		if err != nil {
+			http.Error(w, "invalid time: want HH:MM", http.StatusBadRequest)
+			return
+		}
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, s.loc)
+		if tod == "" {
+			tod = forecast.GetTimeOfDay(at)
+		}
+		interpolated := forecast.PaletteAt(condition, at)
+		view.Time = raw
+		view.Interpolated = &interpolated
+	}
+	if tod == "" {
+		tod = forecast.GetTimeOfDay(now)
+	}
+
+	palette, found := forecast.LookupPalette(condition, tod)
+	view.Key = string(forecast.ConditionWithTime(condition, tod))
+	view.Condition = string(condition)
+	view.TimeOfDay = string(tod)
+	view.Default = !found
+	view.Palette = palette
+	s.writeJSON(w, r, view)
+}
+
+// handleAPIVerificationScatter returns (forecast, actual) pairs across all
+// verified forecasts for plotting against the 1:1 line:
+// ?target=tmax|tmin&source=wu|bom. Without a source it includes every source.
+func (s *Server) handleAPIVerificationScatter(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	source, target := q.Get("source"), q.Get("target")
+	if target != "tmax" && target != "tmin" {
+		http.Error(w, "target must be tmax or tmin", http.StatusBadRequest)
+		return
+	}
+
+	pairs, err := s.store.GetVerificationPairs(source, target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := VerificationScatter{
+		Source: source,
+		Target: target,
+		Pairs:  make([]VerificationScatterPair, 0, len(pairs)),
+	}
+	for _, p := range pairs {
+		resp.Pairs = append(resp.Pairs, VerificationScatterPair{
+			Source:        p.Source,
+			DayOfForecast: p.DayOfForecast,
+			Date:          p.ValidDate,
+			Forecast:      p.Forecast,
+			Actual:        p.Actual,
+		})
+	}
+	s.writeJSON(w, r, resp)
 }
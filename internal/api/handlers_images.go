@@ -117,8 +117,11 @@ func (s *Server) handleOGImage(w http.ResponseWriter, r *http.Request) {
 		ogData.Temperature = currentData.Primary.Temp.Float64
 	}
 
-	// Get condition description from today's forecast narrative or derive from condition
-	if currentData.TodayForecast != nil && currentData.TodayForecast.Narrative != "" {
+	// Describe conditions now, falling back to today's forecast narrative or
+	// the weather condition
+	if currentData.Narrative != "" {
+		ogData.Condition = currentData.Narrative
+	} else if currentData.TodayForecast != nil && currentData.TodayForecast.Narrative != "" {
 		// Extract first sentence or use a simplified version
 		narrative := currentData.TodayForecast.Narrative
 		if len(narrative) > 30 {
@@ -249,15 +252,25 @@ func parseWeatherOverride(override string) (condition forecast.WeatherCondition,
 	return forecast.WeatherCondition(override), "", false
 }
 
-// getCurrentCondition extracts the weather condition from today's forecast.
+// getCurrentCondition returns today's weather condition, cached for currentCacheTTL.
 func (s *Server) getCurrentCondition() forecast.WeatherCondition {
+	condition, _ := s.conditionCache.get(func() (forecast.WeatherCondition, error) {
+		return s.buildCurrentCondition(), nil
+	})
+	return condition
+}
+
+// buildCurrentCondition extracts the weather condition from today's forecast,
+// falling back to the primary station's observations if there isn't one.
+func (s *Server) buildCurrentCondition() forecast.WeatherCondition {
 	loc := s.loc
 	today := time.Now().In(loc)
 	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
 
 	forecasts, err := s.store.GetLatestForecasts()
 	if err != nil {
-		return forecast.ConditionClearCool // Default fallback
+		log.Printf("api: current condition: %v", err)
+		return s.conditionFromObservations()
 	}
 
 	// Check WU forecasts first
@@ -275,9 +288,49 @@ func (s *Server) getCurrentCondition() forecast.WeatherCondition {
 			if fc.TempMin.Valid {
 				tempMin = fc.TempMin.Float64
 			}
-			return forecast.ExtractCondition(narrative, tempMax, tempMin)
+			condition := forecast.ExtractCondition(narrative, tempMax, tempMin)
+			return s.refineConditionFromSolar(condition, tempMax)
 		}
 	}
 
-	return forecast.ConditionClearCool
+	return s.conditionFromObservations()
+}
+
+// noForecastCondition is used when there's neither a forecast for today nor a
+// recent observation to derive a condition from. It makes no claim about
+// clear skies.
+const noForecastCondition = forecast.ConditionPartlyCloudy
+
+// conditionFromObservations derives the current condition from the primary
+// station's latest observation, for when there's no forecast to go on.
+func (s *Server) conditionFromObservations() forecast.WeatherCondition {
+	st, err := s.store.GetPrimaryStation()
+	if err != nil || st == nil {
+		return noForecastCondition
+	}
+	obs, err := s.store.GetLatestObservation(st.StationID, true)
+	if err != nil || obs == nil || time.Since(obs.ObservedAt) > solarObsMaxAge {
+		return noForecastCondition
+	}
+	return forecast.ConditionFromObservation(*obs, forecast.ObservedCloudiness(*obs, *st))
+}
+
+// solarObsMaxAge is how old the primary station's observation can be before
+// it's no longer used to refine or stand in for the forecast condition.
+const solarObsMaxAge = 30 * time.Minute
+
+// refineConditionFromSolar checks the forecast condition against the primary
+// station's measured solar radiation, so a forecast "sunny" day that is
+// actually overcast (or vice versa) shows the right condition during daylight.
+func (s *Server) refineConditionFromSolar(condition forecast.WeatherCondition, tempMax float64) forecast.WeatherCondition {
+	st, err := s.store.GetPrimaryStation()
+	if err != nil || st == nil {
+		return condition
+	}
+	obs, err := s.store.GetLatestObservation(st.StationID, true)
+	if err != nil || obs == nil || time.Since(obs.ObservedAt) > solarObsMaxAge {
+		return condition
+	}
+	cloudiness := forecast.ObservedCloudiness(*obs, *st)
+	return forecast.RefineConditionWithCloudiness(condition, cloudiness, tempMax)
 }
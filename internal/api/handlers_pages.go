@@ -4,12 +4,16 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/lox/wandiweather/internal/forecast"
 	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
 )
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -23,10 +27,10 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current weather condition and time of day for palette
+	// Get current weather condition for palette
 	now := time.Now().In(s.loc)
-	tod := forecast.GetTimeOfDay(now)
 	condition := s.getCurrentCondition()
+	var tod forecast.TimeOfDay
 
 	// Check for override query param: ?weather=storm_night
 	if override := r.URL.Query().Get("weather"); override != "" {
@@ -39,7 +43,12 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	palette := forecast.GetPalette(condition, tod)
+	// Near a time of day change the palette blends towards the next one, as
+	// /api/palette?time= previews, unless the override pinned a time of day
+	palette := forecast.PaletteAt(condition, now)
+	if tod != "" {
+		palette = forecast.GetPalette(condition, tod)
+	}
 
 	indexData := IndexData{
 		CurrentData:     data,
@@ -47,7 +56,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		WeatherOverride: r.URL.Query().Get("weather"),
 	}
 
-	s.tmpl.ExecuteTemplate(w, "index.html", indexData)
+	s.renderTemplate(w, "index.html", indexData)
 }
 
 func (s *Server) handleCurrentPartial(w http.ResponseWriter, r *http.Request) {
@@ -62,37 +71,71 @@ func (s *Server) handleCurrentPartial(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleChartPartial(w http.ResponseWriter, r *http.Request) {
+	smooth, err := parseSmooth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	end := time.Now()
 	start := end.Add(-24 * time.Hour)
 
 	stations, _ := s.store.GetActiveStations()
-	colors := []string{"#4fc3f7", "#81c784", "#ffb74d", "#f48fb1"}
 
+	stationIDs := make([]string, len(stations))
+	for i, st := range stations {
+		stationIDs[i] = st.StationID
+	}
+	observations, err := s.store.GetCleanObservationsMulti(stationIDs, start, end)
+	if err != nil {
+		log.Printf("chart observations: %v", err)
+	}
+
+	s.tmpl.ExecuteTemplate(w, "chart.html", buildChartData(stations, observations, s.loc, smooth))
+}
+
+// buildChartData builds one temperature series per station, smoothed with a
+// moving average over smooth when it's non-zero. Labels come from the first
+// station's observation times.
+func buildChartData(stations []models.Station, observations map[string][]models.Observation, loc *time.Location, smooth time.Duration) ChartData {
 	chartData := ChartData{
 		Labels: make([]string, 0),
 		Series: make([]ChartSeries, 0),
 	}
 
 	for i, st := range stations {
-		obs, _ := s.store.GetObservations(st.StationID, start, end)
 		series := ChartSeries{
 			Name:  fmt.Sprintf("%s (%.0fm)", st.Name, st.Elevation),
 			Data:  make([]float64, 0),
-			Color: colors[i%len(colors)],
+			Color: stationColor(st.StationID),
 		}
 
-		for _, o := range obs {
+		var times []time.Time
+		for _, o := range observations[st.StationID] {
 			if o.Temp.Valid {
 				if i == 0 {
-					chartData.Labels = append(chartData.Labels, o.ObservedAt.In(s.loc).Format("3:04 PM"))
+					chartData.Labels = append(chartData.Labels, o.ObservedAt.In(loc).Format("3:04 PM"))
 				}
 				series.Data = append(series.Data, o.Temp.Float64)
+				times = append(times, o.ObservedAt)
 			}
 		}
+		if smooth > 0 {
+			series.Data = smoothSeries(times, series.Data, smooth)
+		}
 		chartData.Series = append(chartData.Series, series)
 	}
+	return chartData
+}
+
+// stationColors is the palette for per-station chart series.
+var stationColors = []string{"#4fc3f7", "#81c784", "#ffb74d", "#f48fb1", "#ba68c8", "#fff176", "#4db6ac", "#e57373"}
 
-	s.tmpl.ExecuteTemplate(w, "chart.html", chartData)
+// stationColor picks a chart color from a hash of the station ID, so a station
+// keeps its color when others are added, removed or reordered.
+func stationColor(stationID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(stationID))
+	return stationColors[h.Sum32()%uint32(len(stationColors))]
 }
 
 func (s *Server) handleForecastPartial(w http.ResponseWriter, r *http.Request) {
@@ -101,7 +144,7 @@ func (s *Server) handleForecastPartial(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	s.tmpl.ExecuteTemplate(w, "forecast.html", data)
+	s.renderTemplate(w, "forecast.html", data)
 }
 
 func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
@@ -141,6 +184,20 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 		} else if corrStats.Count > 0 {
 			data.CorrectedStats = corrStats
 		}
+		if bySource, err := s.store.GetCorrectedAccuracyBySource(primaryStation.StationID, 30); err != nil {
+			log.Printf("get corrected accuracy by source: %v", err)
+		} else {
+			data.CorrectedBySource = bySource
+		}
+		series, err := s.store.GetCorrectedVsRawSeries(primaryStation.StationID, 30)
+		if err != nil {
+			log.Printf("get corrected vs raw series: %v", err)
+		}
+		for _, d := range series {
+			data.CorrectionLabels = append(data.CorrectionLabels, d.Date.Format("Jan 2"))
+			data.CorrectionRawMAE = append(data.CorrectionRawMAE, d.RawMAE)
+			data.CorrectionCorrectedMAE = append(data.CorrectionCorrectedMAE, d.CorrectedMAE)
+		}
 	}
 
 	// Get best-lead history with regime data for chart and table (WU D+1, BOM D+2)
@@ -167,29 +224,7 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Build table row
-		row := VerificationRow{
-			Date:        dateStr,
-			Source:      h.Source,
-			Regime:      h.Regime,
-			RegimeBadge: regimeBadge(h.Regime),
-		}
-		if h.ForecastTempMax.Valid {
-			row.ForecastMax = h.ForecastTempMax.Float64
-		}
-		if h.ForecastTempMin.Valid {
-			row.ForecastMin = h.ForecastTempMin.Float64
-		}
-		if h.ActualTempMax.Valid {
-			row.ActualMax = h.ActualTempMax.Float64
-		}
-		if h.ActualTempMin.Valid {
-			row.ActualMin = h.ActualTempMin.Float64
-		}
-		if h.BiasTempMax.Valid {
-			row.BiasMax = h.BiasTempMax.Float64
-			row.MaxBiasClass = biasClass(h.BiasTempMax.Float64)
-		}
-		data.History = append(data.History, row)
+		data.History = append(data.History, verificationRow(h))
 
 		// Build chart data
 		pt := chartData[dateStr]
@@ -224,6 +259,15 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 	}
 	data.UniqueDays = len(dates)
 
+	// Biggest max temp misses across both sources, at any lead time
+	worst, err := s.store.GetWorstForecasts("", 5)
+	if err != nil {
+		log.Printf("get worst forecasts: %v", err)
+	}
+	for _, w := range worst {
+		data.WorstForecasts = append(data.WorstForecasts, verificationRow(w))
+	}
+
 	// Get lead time breakdown (reuse biasStats from earlier)
 	leadMap := make(map[int]*LeadTimeRow)
 	for _, b := range biasStats {
@@ -241,7 +285,7 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 			lt.BOMDays = b.CountMax
 		}
 	}
-	for i := 1; i <= 5; i++ {
+	for i := 1; i <= s.forecastDays; i++ {
 		if lt, ok := leadMap[i]; ok {
 			data.LeadTimeData = append(data.LeadTimeData, *lt)
 		}
@@ -252,6 +296,7 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("get regime verification stats: %v", err)
 	}
+	bestSources := store.BestSourceByRegime(regimeStats)
 	for _, rs := range regimeStats {
 		data.RegimeStats = append(data.RegimeStats, RegimeRow{
 			Regime:    rs.Regime,
@@ -264,13 +309,73 @@ func (s *Server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
 			BOMMAEMin: rs.BOMMAEMin,
 			WUDays:    rs.WUDays,
 			BOMDays:   rs.BOMDays,
+
+			WUMinDays:      rs.WUMinDays,
+			BOMMinDays:     rs.BOMMinDays,
+			BestSourceNote: bestSourceNote(rs.Regime, bestSources[rs.Regime]),
 		})
 	}
 
-	s.tmpl.ExecuteTemplate(w, "accuracy.html", data)
+	data.RainChanceRows = s.rainCalibrationRows()
+
+	s.renderTemplate(w, "accuracy.html", data)
 }
 
+// rainCalibrationDays is how far back the accuracy page checks rain chances;
+// longer than the temperature windows as each decile needs enough days.
+const rainCalibrationDays = 90
 
+// rainCalibrationRows builds the rain chance reliability table, one row per
+// decile either source has forecast.
+func (s *Server) rainCalibrationRows() []RainCalibrationRow {
+	var rows [10]RainCalibrationRow
+	for _, source := range []string{"wu", "bom"} {
+		buckets, err := s.store.GetPrecipCalibration(source, rainCalibrationDays)
+		if err != nil {
+			log.Printf("get %s precip calibration: %v", source, err)
+			continue
+		}
+		for _, b := range buckets {
+			if b.Bucket < 0 || b.Bucket >= len(rows) {
+				continue
+			}
+			row := &rows[b.Bucket]
+			if source == "wu" {
+				row.WUObserved, row.WUDays = b.Observed*100, b.N
+			} else {
+				row.BOMObserved, row.BOMDays = b.Observed*100, b.N
+			}
+		}
+	}
+
+	var result []RainCalibrationRow
+	for i, row := range rows {
+		if row.WUDays == 0 && row.BOMDays == 0 {
+			continue
+		}
+		row.Label = fmt.Sprintf("%d-%d%%", i*10, i*10+9)
+		if i == 9 {
+			row.Label = "90-100%"
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+
+
+// rainGaugeCheckWindow is how far back the data page compares rain gauges
+// against their same-tier peers.
+const rainGaugeCheckWindow = 48 * time.Hour
+
+// diurnalRangeDays is how far back the data page charts the diurnal range.
+const diurnalRangeDays = 90
+
+// precipSpellDays is how far back the data page looks for dry and wet spells.
+const precipSpellDays = 365
+
+// ingestLatencyDays is how far back the data page measures ingest durations.
+const ingestLatencyDays = 7
 
 func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
 	data := DataPageData{
@@ -292,12 +397,41 @@ func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
 		data.ParseErrors24h = stats.ParseErrors24h
 	}
 
+	// Counts above are after the nightly prune, so show what it keeps
+	data.RawRetentionDays = max(s.rawRetention, 0)
+	if payloads, err := s.store.GetRawPayloadStats(); err != nil {
+		log.Printf("get raw payload stats: %v", err)
+	} else if payloads.TotalCount > 0 {
+		data.RawPayloadOldest = payloads.OldestFetchedAt.In(s.loc).Format("Jan 2, 2006")
+	}
+
 	if health, err := s.store.GetIngestHealth(1); err != nil {
 		log.Printf("get ingest health: %v", err)
 	} else {
 		data.IngestHealth = health
 	}
 
+	if latency, err := s.store.GetIngestLatencyStats(ingestLatencyDays); err != nil {
+		log.Printf("get ingest latency: %v", err)
+	} else {
+		for _, st := range latency {
+			data.IngestLatency = append(data.IngestLatency, st)
+		}
+		sort.Slice(data.IngestLatency, func(i, j int) bool {
+			a, b := data.IngestLatency[i], data.IngestLatency[j]
+			if a.Source != b.Source {
+				return a.Source < b.Source
+			}
+			return a.Endpoint < b.Endpoint
+		})
+	}
+
+	if counts, err := s.store.GetCleanCountsByStation(); err != nil {
+		log.Printf("get clean counts by station: %v", err)
+	} else {
+		data.StationQuality = counts
+	}
+
 	if obsTypes, err := s.store.GetObsTypeCounts(); err != nil {
 		log.Printf("get obs types: %v", err)
 	} else {
@@ -316,9 +450,56 @@ func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
 		data.RecentErrors = errors
 	}
 
+	if primary, err := s.store.GetPrimaryStation(); err != nil {
+		log.Printf("get primary station: %v", err)
+	} else if primary != nil {
+		if rainfall, err := s.store.GetRollingRainfall(primary.StationID, time.Now()); err != nil {
+			log.Printf("get rolling rainfall: %v", err)
+		} else {
+			data.Rainfall = rainfall
+		}
+
+		now := time.Now().In(s.loc)
+		if dry, wet, err := s.store.GetPrecipSpells(primary.StationID, now.AddDate(0, 0, -precipSpellDays), now); err != nil {
+			log.Printf("get precip spells: %v", err)
+		} else {
+			data.LongestDrySpell, data.LongestWetSpell = dry, wet
+		}
+
+		if ranges, err := s.store.GetDiurnalRange(primary.StationID, now.AddDate(0, 0, -diurnalRangeDays), now); err != nil {
+			log.Printf("get diurnal range: %v", err)
+		} else {
+			for _, r := range ranges {
+				data.DiurnalLabels = append(data.DiurnalLabels, r.Date.Format("2 Jan"))
+				data.DiurnalRanges = append(data.DiurnalRanges, r.Range)
+			}
+		}
+	}
+
+	if suspects, err := s.store.DetectBrokenRainGauges(rainGaugeCheckWindow); err != nil {
+		log.Printf("detect broken rain gauges: %v", err)
+	} else {
+		data.SuspectRainGauges = suspects
+	}
+
 	s.tmpl.ExecuteTemplate(w, "data.html", data)
 }
 
+// healthSources are the ingest sources reported by /health, with how long
+// each can go without a successful run. Forecasts are fetched four times a
+// day; observations and alerts every five minutes.
+var healthSources = []struct {
+	name      string
+	source    string
+	endpoint  string
+	threshold time.Duration
+}{
+	{"WU current", "wu", "pws/observations/current", 30 * time.Minute},
+	{"WU forecast", "wu", "forecast/daily/5day", 12 * time.Hour},
+	{"BOM forecast", "bom", "forecast/fwo", 12 * time.Hour},
+	{"VicEmergency", "vicemergency", "events", 30 * time.Minute},
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	stations, err := s.store.GetActiveStations()
 	if err != nil {
@@ -332,17 +513,19 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		Stations: make([]StationHealth, 0, len(stations)),
 	}
 
-	staleThreshold := 60 * time.Minute
 	now := time.Now()
 
 	for _, st := range stations {
-		obs, err := s.store.GetLatestObservation(st.StationID)
+		obs, err := s.store.GetLatestObservation(st.StationID, true)
 		if err != nil {
 			health.Errors = append(health.Errors, st.StationID+": "+err.Error())
 			continue
 		}
 
-		sh := StationHealth{StationID: st.StationID}
+		thresholdMinutes := staleThresholdMinutes(st)
+		staleThreshold := time.Duration(thresholdMinutes) * time.Minute
+
+		sh := StationHealth{StationID: st.StationID, StaleThresholdMinutes: thresholdMinutes}
 		if obs != nil {
 			sh.LastSeen = obs.ObservedAt
 			sh.AgeMinutes = int(now.Sub(obs.ObservedAt).Minutes())
@@ -358,6 +541,17 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		health.Stations = append(health.Stations, sh)
 	}
 
+	health.Sources = s.sourceHealth(now, &health)
+
+	outages, err := s.store.GetOpenOutages()
+	if err != nil {
+		health.Errors = append(health.Errors, "outages: "+err.Error())
+	}
+	health.Outages = make([]OutageHealth, 0, len(outages))
+	for _, o := range outages {
+		health.Outages = append(health.Outages, OutageHealth{StationID: o.StationID, StartedAt: o.StartedAt, DetectedAt: o.DetectedAt})
+	}
+
 	if len(health.Errors) > 0 {
 		health.Status = "error"
 	}
@@ -371,6 +565,42 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sourceHealth reports the freshness of each ingest source, marking the overall
+// status degraded if any is stale. Sources that have never succeeded aren't
+// counted as stale, so a fresh install or an unconfigured source stays ok.
+func (s *Server) sourceHealth(now time.Time, health *HealthStatus) []SourceHealth {
+	lastSuccess, err := s.store.GetLastSuccessBySource()
+	if err != nil {
+		health.Errors = append(health.Errors, "ingest runs: "+err.Error())
+		return nil
+	}
+
+	sources := make([]SourceHealth, 0, len(healthSources))
+	for _, hs := range healthSources {
+		sh := SourceHealth{
+			Name:                  hs.name,
+			Source:                hs.source,
+			Endpoint:              hs.endpoint,
+			AgeMinutes:            -1,
+			StaleThresholdMinutes: int(hs.threshold.Minutes()),
+		}
+		for _, ls := range lastSuccess {
+			if ls.Source == hs.source && ls.Endpoint == hs.endpoint {
+				t := ls.LastSuccess
+				sh.LastSuccess = &t
+				sh.AgeMinutes = int(now.Sub(t).Minutes())
+				sh.Stale = now.Sub(t) > hs.threshold
+				break
+			}
+		}
+		if sh.Stale {
+			health.Status = "degraded"
+		}
+		sources = append(sources, sh)
+	}
+	return sources
+}
+
 // Helper functions for accuracy page
 
 func biasClass(bias float64) string {
@@ -391,6 +621,8 @@ func regimeBadge(regime string) string {
 	switch regime {
 	case "heatwave":
 		return "🔥"
+	case "cold_snap":
+		return "❄️"
 	case "inversion":
 		return "🏔️"
 	case "clear_calm":
@@ -404,6 +636,8 @@ func regimeLabel(regime string) string {
 	switch regime {
 	case "heatwave":
 		return "Heatwave"
+	case "cold_snap":
+		return "Cold Snap"
 	case "inversion":
 		return "Inversion"
 	case "clear_calm":
@@ -415,10 +649,63 @@ func regimeLabel(regime string) string {
 	}
 }
 
+// staleThresholdMinutes returns how long a station can go quiet before it's
+// stale, falling back to the default when it has no threshold of its own.
+func staleThresholdMinutes(st models.Station) int {
+	if st.StaleThresholdMinutes <= 0 {
+		return models.DefaultStaleThresholdMinutes
+	}
+	return st.StaleThresholdMinutes
+}
+
+// verificationRow converts a verification into an accuracy table row.
+func verificationRow(h store.VerificationWithRegime) VerificationRow {
+	row := VerificationRow{
+		Date:        h.ValidDate.Format("Jan 2"),
+		Source:      h.Source,
+		LeadTime:    h.DayOfForecast,
+		Regime:      h.Regime,
+		RegimeBadge: regimeBadge(h.Regime),
+	}
+	if h.ForecastTempMax.Valid {
+		row.ForecastMax = h.ForecastTempMax.Float64
+	}
+	if h.ForecastTempMin.Valid {
+		row.ForecastMin = h.ForecastTempMin.Float64
+	}
+	if h.ActualTempMax.Valid {
+		row.ActualMax = h.ActualTempMax.Float64
+	}
+	if h.ActualTempMin.Valid {
+		row.ActualMin = h.ActualTempMin.Float64
+	}
+	if h.BiasTempMax.Valid {
+		row.BiasMax = h.BiasTempMax.Float64
+		row.MaxBiasClass = biasClass(h.BiasTempMax.Float64)
+	}
+	return row
+}
+
+// bestSourceNote phrases a GetBestSourceByRegime verdict for the accuracy page.
+func bestSourceNote(regime, best string) string {
+	var source string
+	switch best {
+	case "wu":
+		source = "WU"
+	case "bom":
+		source = "BOM"
+	default:
+		return ""
+	}
+	return "Use " + source + " on " + strings.ToLower(regimeLabel(regime)) + " days"
+}
+
 func regimeColor(regime string) string {
 	switch regime {
 	case "heatwave":
 		return "#ff7043"
+	case "cold_snap":
+		return "#90caf9"
 	case "inversion":
 		return "#4fc3f7"
 	case "clear_calm":
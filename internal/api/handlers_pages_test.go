@@ -0,0 +1,46 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestBuildChartData_StableStationColors(t *testing.T) {
+	obs := func(id string) []models.Observation {
+		return []models.Observation{{StationID: id, ObservedAt: time.Now(), Temp: sql.NullFloat64{Float64: 20, Valid: true}}}
+	}
+	observations := map[string][]models.Observation{
+		"IWANDI23":  obs("IWANDI23"),
+		"IBRIGH180": obs("IBRIGH180"),
+		"IHARRI19":  obs("IHARRI19"),
+	}
+
+	colorsByStation := func(stations []models.Station) map[string]string {
+		data := buildChartData(stations, observations, time.UTC, 0)
+		colors := make(map[string]string)
+		for i, st := range stations {
+			colors[st.StationID] = data.Series[i].Color
+		}
+		return colors
+	}
+
+	before := colorsByStation([]models.Station{
+		{StationID: "IWANDI23"}, {StationID: "IBRIGH180"}, {StationID: "IHARRI19"},
+	})
+	// Bright drops out and the remaining stations swap order
+	after := colorsByStation([]models.Station{
+		{StationID: "IHARRI19"}, {StationID: "IWANDI23"},
+	})
+
+	for _, id := range []string{"IWANDI23", "IHARRI19"} {
+		if before[id] != after[id] {
+			t.Errorf("%s color changed from %s to %s", id, before[id], after[id])
+		}
+	}
+	if before["IWANDI23"] == before["IHARRI19"] {
+		t.Errorf("expected distinct colors, both %s", before["IWANDI23"])
+	}
+}
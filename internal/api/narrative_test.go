@@ -243,6 +243,49 @@ func TestBuildGeneratedNarrative(t *testing.T) {
 			},
 			want: "Sunny. High 35°C.",
 		},
+		{
+			name: "rainy day uses BOM range over WU amount",
+			day: &ForecastDay{
+				WU: &models.Forecast{
+					Narrative:    sql.NullString{String: "Showers.", Valid: true},
+					TempMax:      sql.NullFloat64{Float64: 18, Valid: true},
+					TempMin:      sql.NullFloat64{Float64: 9, Valid: true},
+					PrecipChance: sql.NullInt64{Int64: 60, Valid: true},
+					PrecipAmount: sql.NullFloat64{Float64: 3.2, Valid: true},
+				},
+				BOM: &models.Forecast{
+					PrecipRange: sql.NullString{String: "2 to 5 mm", Valid: true},
+				},
+			},
+			want: "Showers. High 18°C, low 9°C. 60% chance of 2–5mm.",
+		},
+		{
+			name: "rainy day falls back to WU amount",
+			day: &ForecastDay{
+				WU: &models.Forecast{
+					Narrative:    sql.NullString{String: "Showers.", Valid: true},
+					TempMax:      sql.NullFloat64{Float64: 18, Valid: true},
+					PrecipChance: sql.NullInt64{Int64: 60, Valid: true},
+					PrecipAmount: sql.NullFloat64{Float64: 3.2, Valid: true},
+				},
+			},
+			want: "Showers. High 18°C. 60% chance of 3mm.",
+		},
+		{
+			name: "dry day omits precip",
+			day: &ForecastDay{
+				WU: &models.Forecast{
+					Narrative:    sql.NullString{String: "Sunny.", Valid: true},
+					TempMax:      sql.NullFloat64{Float64: 30, Valid: true},
+					PrecipChance: sql.NullInt64{Int64: 5, Valid: true},
+					PrecipAmount: sql.NullFloat64{Float64: 0, Valid: true},
+				},
+				BOM: &models.Forecast{
+					PrecipRange: sql.NullString{String: "0 to 1 mm", Valid: true},
+				},
+			},
+			want: "Sunny. High 30°C.",
+		},
 		{
 			name: "no data",
 			day:  &ForecastDay{},
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/lox/wandiweather/internal/emergency"
+	"github.com/lox/wandiweather/internal/forecast"
 	"github.com/lox/wandiweather/internal/imagegen"
+	"github.com/lox/wandiweather/internal/ingest"
 	"github.com/lox/wandiweather/internal/store"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -28,10 +30,58 @@ type Server struct {
 	genMu           sync.Mutex // Prevents concurrent generation of same image
 	emergencyClient *emergency.Client
 	ogImageCache    *imagegen.OGImageCache
+	ingester        Ingester
+	adminToken      string
+	currentCache    *ttlCache[*CurrentData]
+	conditionCache  *ttlCache[forecast.WeatherCondition]
+	climCache       *ttlCache[*store.SameDayClimatology]
+	regimeCache     *ttlCache[regimeInputs]
+	forecastDays    int
+	lapseRate       float64 // °C per metre, for inversion detection
+	forecastBlend   bool
+	httpLimits      HTTPLimits
+	rawRetention    int // days of raw payloads kept, shown on the data page
+	readOnly        bool
+}
+
+const (
+	// defaultForecastDays is how many days the forecast page shows by default.
+	defaultForecastDays = 5
+	// maxForecastDays is the longest horizon any source provides (BOM's 7 days).
+	maxForecastDays = 7
+)
+
+// HTTPLimits bounds how long the HTTP server waits on clients and how much of
+// a request it will read. Zero values disable a limit.
+type HTTPLimits struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration // whole request, including the body
+	WriteTimeout      time.Duration // must outlast on-demand image generation
+	IdleTimeout       time.Duration // keep-alive connections between requests
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+}
+
+// DefaultHTTPLimits returns limits suited to a public deployment. The write
+// timeout leaves room for the two minutes a weather image may take to generate.
+func DefaultHTTPLimits() HTTPLimits {
+	return HTTPLimits{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      150 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    64 << 10,
+		MaxBodyBytes:      1 << 20,
+	}
+}
+
+// Ingester runs an on-demand ingest, as triggered by POST /admin/ingest.
+type Ingester interface {
+	IngestOnce() ([]ingest.StationIngestResult, error)
 }
 
 // NewServer creates a new Server instance.
-func NewServer(store *store.Store, port string, loc *time.Location) *Server {
+func NewServer(st *store.Store, port string, loc *time.Location) *Server {
 	tmpl := newTemplates()
 
 	// Initialize image generator (optional - may not have API key)
@@ -46,7 +96,7 @@ func NewServer(store *store.Store, port string, loc *time.Location) *Server {
 	emergencyClient := emergency.NewClient(-36.794, 146.977, emergency.DefaultRadiusKM)
 
 	return &Server{
-		store:           store,
+		store:           st,
 		port:            port,
 		loc:             loc,
 		tmpl:            tmpl,
@@ -54,9 +104,35 @@ func NewServer(store *store.Store, port string, loc *time.Location) *Server {
 		imageGen:        imageGen,
 		emergencyClient: emergencyClient,
 		ogImageCache:    imagegen.NewOGImageCache(5 * time.Minute),
+		currentCache:    newTTLCache[*CurrentData](currentCacheTTL),
+		conditionCache:  newTTLCache[forecast.WeatherCondition](currentCacheTTL),
+		climCache:       newTTLCache[*store.SameDayClimatology](climatologyCacheTTL),
+		regimeCache:     newTTLCache[regimeInputs](regimeCacheTTL),
+		forecastDays:    defaultForecastDays,
+		lapseRate:       forecast.StandardLapseRate,
+		httpLimits:      DefaultHTTPLimits(),
+		rawRetention:    ingest.DefaultRawRetentionDays,
 	}
 }
 
+// SetHTTPLimits overrides the HTTP server's timeouts and size limits.
+func (s *Server) SetHTTPLimits(limits HTTPLimits) {
+	s.httpLimits = limits
+}
+
+// SetForecastDays sets how many days the forecast page and accuracy lead-time
+// breakdown cover, clamped to between 1 and maxForecastDays.
+func (s *Server) SetForecastDays(days int) {
+	s.forecastDays = max(1, min(days, maxForecastDays))
+}
+
+// InvalidateCurrent drops the cached current data and condition. The
+// scheduler calls it after ingesting new observations.
+func (s *Server) InvalidateCurrent() {
+	s.currentCache.invalidate()
+	s.conditionCache.invalidate()
+}
+
 // ImageGenerator returns the image generator for use by the scheduler.
 func (s *Server) ImageGenerator() *imagegen.Generator {
 	return s.imageGen
@@ -73,6 +149,48 @@ func (s *Server) ImageGenMutex() *sync.Mutex {
 	return &s.genMu
 }
 
+// SetIngester enables POST /admin/ingest, guarded by the given shared secret.
+// The endpoint rejects every request while token is empty.
+func (s *Server) SetIngester(ingester Ingester, token string) {
+	s.ingester = ingester
+	s.adminToken = token
+}
+
+// SetAdminToken sets the shared secret that guards the /admin endpoints.
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// SetLapseRate overrides the lapse rate (°C per km) inversion detection
+// expects between the valley and upper stations. Non-positive values restore
+// forecast.StandardLapseRate.
+func (s *Server) SetLapseRate(perKm float64) {
+	if perKm <= 0 {
+		s.lapseRate = forecast.StandardLapseRate
+		return
+	}
+	s.lapseRate = perKm / 1000
+}
+
+// SetForecastBlend switches today's forecast between picking BOM or WU by
+// fixed rules and blending the two by their recent skill.
+func (s *Server) SetForecastBlend(enabled bool) {
+	s.forecastBlend = enabled
+}
+
+// SetRawRetentionDays records how many days of raw payloads the scheduler
+// keeps, for display on the data page. Zero or less means forever.
+func (s *Server) SetRawRetentionDays(days int) {
+	s.rawRetention = days
+}
+
+// SetReadOnly serves from a database the server may not write to, such as a
+// public mirror's snapshot. Displayed forecast and nowcast logging are skipped
+// and the admin endpoints that change data are refused.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
 // EmergencyClient returns the VicEmergency client for use by the scheduler.
 func (s *Server) EmergencyClient() *emergency.Client {
 	return s.emergencyClient
@@ -96,8 +214,26 @@ func (s *Server) Handler() http.Handler {
 	// API endpoints
 	mux.HandleFunc("/api/current", s.handleAPICurrent)
 	mux.HandleFunc("/api/history", s.handleAPIHistory)
+	mux.HandleFunc("/api/observations/since", s.handleAPIObservationsSince)
+	mux.HandleFunc("/api/sparkline", s.handleAPISparkline)
 	mux.HandleFunc("/api/stations", s.handleAPIStations)
+	mux.HandleFunc("/api/stations/detail", s.handleAPIStationsDetail)
+	mux.HandleFunc("/api/comfort", s.handleAPIComfort)
 	mux.HandleFunc("/api/forecast", s.handleAPIForecast)
+	mux.HandleFunc("/api/forecast/explain", s.handleAPIForecastExplain)
+	mux.HandleFunc("/api/forecast/corrections", s.handleAPIForecastCorrections)
+	mux.HandleFunc("/api/regime", s.handleAPIRegime)
+	mux.HandleFunc("/api/inversion", s.handleAPIInversion)
+	mux.HandleFunc("/api/today/track", s.handleAPITodayTrack)
+	mux.HandleFunc("/api/schema", s.handleAPISchema)
+	mux.HandleFunc("/api/palette", s.handleAPIPalette)
+	mux.HandleFunc("/api/verification/scatter", s.handleAPIVerificationScatter)
+
+	// Admin endpoints
+	mux.HandleFunc("/admin/ingest", s.handleAdminIngest)
+	mux.HandleFunc("/admin/stations/{id}", s.handleAdminStation)
+	mux.HandleFunc("/admin/payloads", s.handleAdminPayloads)
+	mux.HandleFunc("/admin/payloads/{id}", s.handleAdminPayload)
 
 	// Image endpoints
 	mux.HandleFunc("/weather-image", s.handleWeatherImage)
@@ -114,12 +250,26 @@ func (s *Server) Handler() http.Handler {
 	return mux
 }
 
+// httpServer builds the http.Server for addr with the configured limits.
+func (s *Server) httpServer(addr string) *http.Server {
+	handler := s.Handler()
+	if s.httpLimits.MaxBodyBytes > 0 {
+		handler = http.MaxBytesHandler(handler, s.httpLimits.MaxBodyBytes)
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: s.httpLimits.ReadHeaderTimeout,
+		ReadTimeout:       s.httpLimits.ReadTimeout,
+		WriteTimeout:      s.httpLimits.WriteTimeout,
+		IdleTimeout:       s.httpLimits.IdleTimeout,
+		MaxHeaderBytes:    s.httpLimits.MaxHeaderBytes,
+	}
+}
+
 // Run starts the HTTP server and blocks until the context is cancelled.
 func (s *Server) Run(ctx context.Context) error {
-	server := &http.Server{
-		Addr:    ":" + s.port,
-		Handler: s.Handler(),
-	}
+	server := s.httpServer(":" + s.port)
 
 	go func() {
 		<-ctx.Done()
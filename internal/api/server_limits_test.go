@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHTTPServer_DisconnectsSlowClient(t *testing.T) {
+	s := NewServer(nil, "0", time.UTC)
+	s.SetHTTPLimits(HTTPLimits{
+		ReadHeaderTimeout: 100 * time.Millisecond,
+		ReadTimeout:       100 * time.Millisecond,
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := s.httpServer(ln.Addr().String())
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Start a request but never finish its headers.
+	if _, err := io.WriteString(conn, "GET /health HTTP/1.1\r\nHost: localhost\r\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	_, err = conn.Read(make([]byte, 1))
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatal("connection still open after 5s, want it closed by the read timeout")
+	}
+	if err == nil {
+		t.Fatal("read succeeded, want the connection closed")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("connection closed after %v, before the read timeout", elapsed)
+	}
+}
@@ -2,12 +2,20 @@ package api_test
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/lox/wandiweather/internal/api"
+	"github.com/lox/wandiweather/internal/emergency"
+	"github.com/lox/wandiweather/internal/forecast"
+	"github.com/lox/wandiweather/internal/ingest"
 	"github.com/lox/wandiweather/internal/models"
 	"github.com/lox/wandiweather/internal/store"
 
@@ -49,6 +57,96 @@ func TestHealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestHealthEndpoint_StaleSource(t *testing.T) {
+	t.Parallel()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := store.New(db, time.UTC)
+	if err := s.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Last good BOM fetch was a day ago; WU current is fresh
+	for _, r := range []struct {
+		source, endpoint string
+		age              time.Duration
+	}{
+		{"bom", "forecast/fwo", 24 * time.Hour},
+		{"wu", "pws/observations/current", 5 * time.Minute},
+	} {
+		if _, err := db.Exec(`INSERT INTO ingest_runs (started_at, source, endpoint, success) VALUES (?, ?, ?, TRUE)`,
+			time.Now().UTC().Add(-r.age), r.source, r.endpoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", time.UTC)
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 503 {
+		t.Fatalf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var health api.HealthStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "degraded" {
+		t.Errorf("expected degraded status, got %q", health.Status)
+	}
+
+	stale := map[string]bool{}
+	for _, src := range health.Sources {
+		stale[src.Source+"/"+src.Endpoint] = src.Stale
+	}
+	if !stale["bom/forecast/fwo"] {
+		t.Errorf("expected BOM source stale, got %+v", health.Sources)
+	}
+	if stale["wu/pws/observations/current"] {
+		t.Errorf("expected WU current fresh, got %+v", health.Sources)
+	}
+}
+
+func TestHealthEndpoint_PerStationThreshold(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	s.UpsertStation(models.Station{
+		StationID:             "SLOW1",
+		Name:                  "Slow Station",
+		Active:                true,
+		StaleThresholdMinutes: 180,
+	})
+	s.InsertObservation(models.Observation{
+		StationID:  "SLOW1",
+		ObservedAt: time.Now().UTC().Add(-90 * time.Minute),
+		Temp:       sql.NullFloat64{Float64: 20, Valid: true},
+	})
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"status":"ok"`) {
+		t.Errorf("expected ok status, got %s", body)
+	}
+	if !strings.Contains(body, `"stale":false`) {
+		t.Errorf("expected station not stale, got %s", body)
+	}
+}
+
 func TestAccuracyPage_NoData(t *testing.T) {
 	t.Parallel()
 	s, loc := setupTestStore(t)
@@ -138,7 +236,7 @@ func TestAccuracyPage_ChartPresent(t *testing.T) {
 			TempMax:       sql.NullFloat64{Float64: float64(25 + i), Valid: true},
 			TempMin:       sql.NullFloat64{Float64: float64(10 + i), Valid: true},
 		})
-		s.InsertForecastVerification(models.ForecastVerification{
+		s.UpsertForecastVerification(models.ForecastVerification{
 			ForecastID:  int64(i),
 			ValidDate:   validDate,
 			BiasTempMax: sql.NullFloat64{Float64: float64(i), Valid: true},
@@ -166,3 +264,1604 @@ func TestAccuracyPage_ChartPresent(t *testing.T) {
 		t.Error("expected Bias Over Time section header")
 	}
 }
+
+func TestForecastEndpoint_Horizon(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	// BOM provides eight days here, one more than any horizon allows
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 8; i++ {
+		if err := s.InsertForecast(models.Forecast{
+			Source:        "bom",
+			FetchedAt:     now,
+			ValidDate:     today.AddDate(0, 0, i),
+			DayOfForecast: i,
+			TempMax:       sql.NullFloat64{Float64: 25, Valid: true},
+			TempMin:       sql.NullFloat64{Float64: 10, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		days int // 0 leaves the default
+		want int
+	}{
+		{"default", 0, 5},
+		{"seven days", 7, 7},
+		{"clamped to what sources provide", 10, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := api.NewServer(s, "8080", loc)
+			if tt.days != 0 {
+				srv.SetForecastDays(tt.days)
+			}
+			req := httptest.NewRequest("GET", "/api/forecast", nil)
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, req)
+
+			if w.Code != 200 {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var got api.ForecastData
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(got.Days) != tt.want {
+				t.Errorf("len(Days) = %d, want %d", len(got.Days), tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastEndpoint_Age(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	// WU was refreshed 2h ago, BOM 6h ago; the page is only as stale as WU
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	wuFetched := now.Add(-2 * time.Hour).Truncate(time.Second)
+	for source, fetched := range map[string]time.Time{"wu": wuFetched, "bom": now.Add(-6 * time.Hour)} {
+		if err := s.InsertForecast(models.Forecast{
+			Source:        source,
+			FetchedAt:     fetched,
+			ValidDate:     today,
+			DayOfForecast: 0,
+			TempMax:       sql.NullFloat64{Float64: 25, Valid: true},
+			TempMin:       sql.NullFloat64{Float64: 10, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/forecast", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got api.ForecastData
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !got.FetchedAt.Equal(wuFetched) {
+		t.Errorf("FetchedAt = %v, want %v", got.FetchedAt, wuFetched)
+	}
+	if got.UpdatedAgo != "2h ago" {
+		t.Errorf("UpdatedAgo = %q, want %q", got.UpdatedAgo, "2h ago")
+	}
+}
+
+func TestCurrentPartial_ForecastUnavailable(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	if err := s.UpsertStation(models.Station{StationID: "PRIMARY", Name: "Primary", ElevationTier: "valley_floor", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertObservation(models.Observation{
+		StationID:  "PRIMARY",
+		ObservedAt: time.Now().UTC(),
+		Temp:       sql.NullFloat64{Float64: 18, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/partials/current", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "Forecast unavailable") {
+		t.Errorf("expected forecast unavailable state, got %s", w.Body.String())
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	df, err := s.GetLatestDisplayedForecast(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df != nil {
+		t.Errorf("expected no displayed forecast logged, got %+v", df)
+	}
+}
+
+func TestCurrentPartial_RecentlyClearedAlerts(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	seen := time.Now().UTC().Add(-time.Hour)
+	fire := emergency.Alert{ID: "fire-1", Category: "Fire", Location: "Wandiligong", Severity: emergency.SeverityWatchAct}
+	flood := emergency.Alert{ID: "flood-1", Category: "Flood", Location: "Bright", Severity: emergency.SeverityAdvice}
+	for _, a := range []emergency.Alert{fire, flood} {
+		if _, _, err := s.UpsertAlert(a, seen); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.ClearMissingAlerts([]string{fire.ID}, seen.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	srv := api.NewServer(s, "8080", loc)
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/partials/current", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Recently cleared") || !strings.Contains(body, "Flood — Bright") {
+		t.Errorf("expected the cleared flood alert, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/current", nil))
+	var data api.CurrentData
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(data.ClearedAlerts) != 1 || data.ClearedAlerts[0].ID != flood.ID {
+		t.Errorf("ClearedAlerts = %+v, want just %s", data.ClearedAlerts, flood.ID)
+	}
+}
+
+func TestComfortEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	now := time.Now().UTC()
+	for _, st := range []struct {
+		id             string
+		temp, humidity float64
+	}{
+		{"HOT", 36, 60},
+		{"COOL", 15, 50},
+	} {
+		if err := s.UpsertStation(models.Station{StationID: st.id, Name: st.id, Active: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.InsertObservation(models.Observation{
+			StationID:  st.id,
+			ObservedAt: now,
+			Temp:       sql.NullFloat64{Float64: st.temp, Valid: true},
+			Humidity:   sql.NullInt64{Int64: int64(st.humidity), Valid: true},
+			WindSpeed:  sql.NullFloat64{Float64: 5, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A station without humidity is left out
+	if err := s.UpsertStation(models.Station{StationID: "NOHUM", Name: "NOHUM", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertObservation(models.Observation{
+		StationID:  "NOHUM",
+		ObservedAt: now,
+		Temp:       sql.NullFloat64{Float64: 20, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/comfort", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []api.StationComfort
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	byID := make(map[string]api.StationComfort)
+	for _, c := range got {
+		byID[c.StationID] = c
+	}
+	if len(byID) != 2 {
+		t.Fatalf("got %d stations, want 2: %+v", len(byID), got)
+	}
+	if hot := byID["HOT"]; !hot.HeatStress || hot.ApparentTemp < 40 {
+		t.Errorf("HOT = %+v, want heat stress with apparent temp above 40", hot)
+	}
+	if cool := byID["COOL"]; cool.HeatStress || cool.DewpointComfort != "dry" {
+		t.Errorf("COOL = %+v, want no heat stress and dry", cool)
+	}
+}
+
+func TestForecastExplainEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	validDate := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	if err := s.UpsertDisplayedForecast(models.DisplayedForecast{
+		DisplayedAt:      validDate.Add(8 * time.Hour),
+		ValidDate:        validDate,
+		DayOfForecast:    0,
+		WUForecastID:     sql.NullInt64{Int64: 1, Valid: true},
+		BOMForecastID:    sql.NullInt64{Int64: 2, Valid: true},
+		RawTempMax:       sql.NullFloat64{Float64: 30, Valid: true},
+		RawTempMin:       sql.NullFloat64{Float64: 12, Valid: true},
+		CorrectedTempMax: sql.NullFloat64{Float64: 32, Valid: true},
+		CorrectedTempMin: sql.NullFloat64{Float64: 11, Valid: true},
+		BiasAppliedMax:   sql.NullFloat64{Float64: -1.5, Valid: true},
+		BiasAppliedMin:   sql.NullFloat64{Float64: 1, Valid: true},
+		BiasDayUsedMax:   sql.NullInt64{Int64: 1, Valid: true},
+		BiasDayUsedMin:   sql.NullInt64{Int64: 0, Valid: true},
+		BiasSamplesMax:   sql.NullInt64{Int64: 14, Valid: true},
+		BiasSamplesMin:   sql.NullInt64{Int64: 20, Valid: true},
+		BiasFallbackMax:  sql.NullBool{Bool: true, Valid: true},
+		BiasFallbackMin:  sql.NullBool{Bool: false, Valid: true},
+		NowcastMax:       sql.NullFloat64{Float64: 0.5, Valid: true},
+		SourceMax:        sql.NullString{String: "bom", Valid: true},
+		SourceMin:        sql.NullString{String: "wu", Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertDisplayedForecast: %v", err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/forecast/explain?date=2026-01-15", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got api.ForecastExplanation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.ValidDate != "2026-01-15" {
+		t.Errorf("ValidDate = %q, want 2026-01-15", got.ValidDate)
+	}
+	if got.Max.Source != "bom" || got.Min.Source != "wu" {
+		t.Errorf("sources = %q/%q, want bom/wu", got.Max.Source, got.Min.Source)
+	}
+	if got.Max.Raw == nil || *got.Max.Raw != 30 {
+		t.Errorf("Max.Raw = %v, want 30", got.Max.Raw)
+	}
+	if got.Max.Bias == nil || *got.Max.Bias != -1.5 {
+		t.Errorf("Max.Bias = %v, want -1.5", got.Max.Bias)
+	}
+	if got.Max.BiasDayUsed == nil || *got.Max.BiasDayUsed != 1 {
+		t.Errorf("Max.BiasDayUsed = %v, want 1", got.Max.BiasDayUsed)
+	}
+	if got.Max.BiasSamples == nil || *got.Max.BiasSamples != 14 {
+		t.Errorf("Max.BiasSamples = %v, want 14", got.Max.BiasSamples)
+	}
+	if !got.Max.BiasFallback {
+		t.Error("expected Max.BiasFallback to be true")
+	}
+	if got.Max.Nowcast == nil || *got.Max.Nowcast != 0.5 {
+		t.Errorf("Max.Nowcast = %v, want 0.5", got.Max.Nowcast)
+	}
+	if got.Max.Final == nil || *got.Max.Final != 32 {
+		t.Errorf("Max.Final = %v, want 32", got.Max.Final)
+	}
+	if got.Min.Nowcast != nil {
+		t.Errorf("Min.Nowcast = %v, want nil", *got.Min.Nowcast)
+	}
+}
+
+func TestForecastExplainEndpoint_NotFound(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+	srv := api.NewServer(s, "8080", loc)
+
+	req := httptest.NewRequest("GET", "/api/forecast/explain?date=2026-01-15", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestRegimeEndpoint_Inversion(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	s.UpsertStation(models.Station{StationID: "PRIMARY", Name: "Primary", IsPrimary: true, Active: true})
+	date := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	err := s.UpsertDailySummary(models.DailySummary{
+		Date:              date,
+		StationID:         "PRIMARY",
+		TempMax:           sql.NullFloat64{Float64: 24, Valid: true},
+		TempMin:           sql.NullFloat64{Float64: 6, Valid: true},
+		InversionDetected: sql.NullBool{Bool: true, Valid: true},
+		InversionStrength: sql.NullFloat64{Float64: 3.5, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("upsert summary: %v", err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/regime?date=2026-01-15", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got api.RegimeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Regime != "inversion" {
+		t.Errorf("regime = %q, want inversion", got.Regime)
+	}
+	want := api.RegimeFlags{InversionNight: true}
+	if got.Flags != want {
+		t.Errorf("flags = %+v, want %+v", got.Flags, want)
+	}
+	if got.Inputs.InversionDetected == nil || !*got.Inputs.InversionDetected {
+		t.Errorf("inputs.inversion_detected = %v, want true", got.Inputs.InversionDetected)
+	}
+	if got.StationID != "PRIMARY" {
+		t.Errorf("station_id = %q, want PRIMARY", got.StationID)
+	}
+}
+
+func TestTodayTrackEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	s.UpsertStation(models.Station{StationID: "PRIMARY", Name: "Primary", IsPrimary: true, Active: true})
+
+	now := time.Now().In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	for i, ts := range []time.Time{midnight, midnight.Add(time.Minute), now} {
+		s.InsertObservation(models.Observation{
+			StationID:  "PRIMARY",
+			ObservedAt: ts,
+			Temp:       sql.NullFloat64{Float64: 10 + float64(i), Valid: true},
+		})
+	}
+
+	// A blended max records no single bias, so the forecast before the
+	// nowcast can't be rebuilt from the raw value
+	if err := s.UpsertDisplayedForecast(models.DisplayedForecast{
+		DisplayedAt:      midnight.Add(time.Second).UTC(),
+		ValidDate:        midnight,
+		SourceMax:        sql.NullString{String: "blend", Valid: true},
+		RawTempMax:       sql.NullFloat64{Float64: 24.6, Valid: true},
+		CorrectedTempMax: sql.NullFloat64{Float64: 27, Valid: true},
+		CorrectedTempMin: sql.NullFloat64{Float64: 8, Valid: true},
+		NowcastMax:       sql.NullFloat64{Float64: 1, Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertDisplayedForecast: %v", err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/today/track", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got api.TodayTrack
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Observed) == 0 {
+		t.Fatal("expected observed series")
+	}
+	if first := got.Observed[0]; !first.Time.Equal(midnight) || first.Samples < 2 {
+		t.Errorf("first point = %+v, want midnight bucket with >=2 samples", first)
+	}
+	if got.ForecastMax == nil || *got.ForecastMax != 26 {
+		t.Errorf("forecast_max = %v, want 26", got.ForecastMax)
+	}
+	if got.ForecastMin == nil || *got.ForecastMin != 8 {
+		t.Errorf("forecast_min = %v, want 8", got.ForecastMin)
+	}
+	if got.NowcastMax == nil || *got.NowcastMax != 27 {
+		t.Errorf("nowcast_max = %v, want 27", got.NowcastMax)
+	}
+}
+
+func TestHistoryAPI_Paged(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	base := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: base.Add(time.Duration(i) * 10 * time.Minute),
+			Temp:       sql.NullFloat64{Float64: float64(i), Valid: true},
+		})
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/history?station=TEST1&from=2026-01-15T00:00:00Z&to=2026-01-15T12:00:00Z&limit=3&offset=3", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []models.Observation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("len(observations) = %d, want 3", len(got))
+	}
+	for i, obs := range got {
+		if want := float64(i + 3); obs.Temp.Float64 != want {
+			t.Errorf("observation %d temp = %v, want %v", i, obs.Temp.Float64, want)
+		}
+	}
+
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, `rel="next"`) || !strings.Contains(link, "offset=6") {
+		t.Errorf("Link = %q, want next page at offset=6", link)
+	}
+}
+
+func TestHistoryAPI_DefaultReturnsFullDay(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	// A full day at one-minute cadence is more than defaultHistoryLimit
+	start := time.Now().UTC().Add(-24*time.Hour + time.Minute)
+	for i := 0; i < 1440; i++ {
+		if err := s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: start.Add(time.Duration(i) * time.Minute),
+			Temp:       sql.NullFloat64{Float64: 10, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/history?station=TEST1", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []models.Observation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 1440 {
+		t.Errorf("len(observations) = %d, want the full day of 1440", len(got))
+	}
+	if link := w.Header().Get("Link"); link != "" {
+		t.Errorf("Link = %q, want no next page", link)
+	}
+}
+
+func TestHistoryAPI_DefaultsToPrimaryStation(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "IWANDI23", Name: "Old primary", Active: true},
+		{StationID: "OTHER1", Name: "Primary", IsPrimary: true, Active: true},
+	} {
+		if err := s.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+	at := time.Date(2026, 1, 15, 1, 0, 0, 0, time.UTC)
+	for id, temp := range map[string]float64{"IWANDI23": 10, "OTHER1": 20} {
+		if err := s.InsertObservation(models.Observation{
+			StationID:  id,
+			ObservedAt: at,
+			Temp:       sql.NullFloat64{Float64: temp, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/history?from=2026-01-15T00:00:00Z&to=2026-01-15T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []models.Observation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].StationID != "OTHER1" || got[0].Temp.Float64 != 20 {
+		t.Errorf("observations = %+v, want the single OTHER1 reading", got)
+	}
+}
+
+func TestHistoryAPI_PagedSmoothing(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	base := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: base.Add(time.Duration(i) * 10 * time.Minute),
+			Temp:       sql.NullFloat64{Float64: float64(i * i), Valid: true},
+		})
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/history?station=TEST1&from=2026-01-15T00:00:00Z&to=2026-01-15T12:00:00Z&limit=3&offset=3&smooth=30m", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []models.Observation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("len(observations) = %d, want 3", len(got))
+	}
+	// Each reading averages with its neighbours either side, including the
+	// ones on the adjacent pages: ((i-1)² + i² + (i+1)²) / 3
+	for i, obs := range got {
+		n := float64(i + 3)
+		if want := (3*n*n + 2) / 3; math.Abs(obs.Temp.Float64-want) > 1e-9 {
+			t.Errorf("observation %d temp = %v, want %v", i, obs.Temp.Float64, want)
+		}
+	}
+
+	if link := w.Header().Get("Link"); !strings.Contains(link, "smooth=30m") {
+		t.Errorf("Link = %q, want smooth carried to the next page", link)
+	}
+}
+
+func TestHistoryAPI_LastPageHasNoNextLink(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	base := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: base.Add(time.Duration(i) * 10 * time.Minute),
+			Temp:       sql.NullFloat64{Float64: float64(i), Valid: true},
+		})
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/history?station=TEST1&from=2026-01-15T00:00:00Z&to=2026-01-15T12:00:00Z&limit=3&offset=3", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if link := w.Header().Get("Link"); link != "" {
+		t.Errorf("Link = %q, want none on last page", link)
+	}
+}
+
+func TestHistoryAPI_RejectsOversizedRange(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+	srv := api.NewServer(s, "8080", loc)
+
+	req := httptest.NewRequest("GET", "/api/history?from=2026-01-01&to=2026-01-09", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHistoryAPI_Bucketed(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	base := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: base.Add(time.Duration(i) * 10 * time.Minute),
+			Temp:       sql.NullFloat64{Float64: float64(i), Valid: true},
+		})
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/history?station=TEST1&from=2026-01-15T00:00:00Z&to=2026-01-15T12:00:00Z&bucket=1h", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []api.HistoryBucket
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(buckets) = %d, want 2", len(got))
+	}
+	if got[1].Samples != 6 || got[1].TempAvg == nil || *got[1].TempAvg != 8.5 {
+		t.Errorf("second bucket = %d samples, avg %v, want 6 samples, avg 8.5", got[1].Samples, got[1].TempAvg)
+	}
+
+	req = httptest.NewRequest("GET", "/api/history?station=TEST1&bucket=5m", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != 400 {
+		t.Errorf("bucket=5m: expected 400, got %d", w.Code)
+	}
+}
+
+func TestObservationsSinceAPI(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+	srv := api.NewServer(s, "8080", loc)
+
+	base := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	insert := func(from, to int) {
+		for i := from; i < to; i++ {
+			if err := s.InsertObservation(models.Observation{
+				StationID:  "TEST1",
+				ObservedAt: base.Add(time.Duration(i) * 5 * time.Minute),
+				Temp:       sql.NullFloat64{Float64: float64(i), Valid: true},
+			}); err != nil {
+				t.Fatalf("InsertObservation: %v", err)
+			}
+		}
+	}
+	fetch := func(after string) api.ObservationsSince {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/observations/since?station=TEST1&after="+after, nil)
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("after=%s: expected 200, got %d: %s", after, w.Code, w.Body.String())
+		}
+		var got api.ObservationsSince
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return got
+	}
+
+	insert(0, 3)
+	first := fetch("2026-01-15T00:00:00Z")
+	if len(first.Observations) != 2 {
+		t.Fatalf("first poll: %d observations, want 2 after the start time", len(first.Observations))
+	}
+	if first.Cursor != first.Observations[1].ID {
+		t.Errorf("cursor = %d, want last id %d", first.Cursor, first.Observations[1].ID)
+	}
+
+	cursor := strconv.FormatInt(first.Cursor, 10)
+	if got := fetch(cursor); len(got.Observations) != 0 || got.Cursor != first.Cursor {
+		t.Errorf("idle poll = %d observations, cursor %d, want none and cursor %d", len(got.Observations), got.Cursor, first.Cursor)
+	}
+
+	insert(3, 5)
+	second := fetch(cursor)
+	if len(second.Observations) != 2 {
+		t.Fatalf("second poll: %d observations, want 2", len(second.Observations))
+	}
+	for i, obs := range second.Observations {
+		if want := float64(i + 3); obs.Temp.Float64 != want {
+			t.Errorf("observation %d temp = %v, want %v", i, obs.Temp.Float64, want)
+		}
+	}
+}
+
+type stubIngester struct {
+	calls   int
+	results []ingest.StationIngestResult
+}
+
+func (s *stubIngester) IngestOnce() ([]ingest.StationIngestResult, error) {
+	s.calls++
+	return s.results, nil
+}
+
+func TestAdminIngest_Authorized(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	temp := 18.5
+	stub := &stubIngester{results: []ingest.StationIngestResult{
+		{StationID: "TEST1", Success: true, Temp: &temp},
+		{StationID: "TEST2", Error: "http 500"},
+	}}
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetIngester(stub, "s3cret")
+
+	req := httptest.NewRequest("POST", "/admin/ingest", nil)
+	req.Header.Set("X-Admin-Token", "s3cret")
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if stub.calls != 1 {
+		t.Errorf("IngestOnce called %d times, want 1", stub.calls)
+	}
+
+	var got []ingest.StationIngestResult
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 2 || got[0].StationID != "TEST1" || !got[0].Success || got[1].Error != "http 500" {
+		t.Errorf("unexpected results: %+v", got)
+	}
+}
+
+func TestAdminIngest_Unauthorized(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	stub := &stubIngester{}
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetIngester(stub, "s3cret")
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest("POST", "/admin/ingest", nil)
+		if token != "" {
+			req.Header.Set("X-Admin-Token", token)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+
+		if w.Code != 401 {
+			t.Errorf("token %q: expected 401, got %d", token, w.Code)
+		}
+	}
+	if stub.calls != 0 {
+		t.Errorf("IngestOnce called %d times, want 0", stub.calls)
+	}
+}
+
+func TestStationsDetailAPI(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "FRESH", Name: "Fresh", ElevationTier: "valley_floor", Elevation: 320, IsPrimary: true, Active: true},
+		{StationID: "SILENT", Name: "Silent", ElevationTier: "upper", Elevation: 550, Active: false},
+	} {
+		if err := s.UpsertStation(st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Readings in six distinct hours, the latest five minutes ago
+	now := time.Now().UTC()
+	for h := 5; h >= 0; h-- {
+		if err := s.InsertObservation(models.Observation{
+			StationID:  "FRESH",
+			ObservedAt: now.Add(-time.Duration(h)*time.Hour - 5*time.Minute),
+			Temp:       sql.NullFloat64{Float64: 12.5, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/stations/detail", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []api.StationDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d stations, want 2", len(got))
+	}
+
+	fresh, silent := got[0], got[1]
+	if fresh.StationID != "FRESH" || !fresh.Active || !fresh.IsPrimary || fresh.Tier != "valley_floor" {
+		t.Errorf("fresh station = %+v", fresh)
+	}
+	if fresh.LastSeen == nil || fresh.Stale || fresh.AgeMinutes < 4 || fresh.AgeMinutes > 6 {
+		t.Errorf("fresh last seen %v, age %d, stale %v; want about 5 minutes ago and not stale", fresh.LastSeen, fresh.AgeMinutes, fresh.Stale)
+	}
+	if fresh.Temp == nil || *fresh.Temp != 12.5 {
+		t.Errorf("fresh temp = %v, want 12.5", fresh.Temp)
+	}
+	if fresh.Completeness != 0.25 {
+		t.Errorf("fresh completeness = %v, want 0.25", fresh.Completeness)
+	}
+	// Only temperature was reported, so every other sensor looks absent
+	if fresh.FieldCompleteness["temp"] != 1 || fresh.FieldCompleteness["pressure"] != 0 {
+		t.Errorf("fresh field completeness = %v, want temp 1 and pressure 0", fresh.FieldCompleteness)
+	}
+	if want := []string{"humidity", "precip", "pressure", "solar", "wind"}; strings.Join(fresh.MissingSensors, ",") != strings.Join(want, ",") {
+		t.Errorf("fresh missing sensors = %v, want %v", fresh.MissingSensors, want)
+	}
+
+	if silent.StationID != "SILENT" || silent.Active || silent.IsPrimary || silent.Tier != "upper" {
+		t.Errorf("silent station = %+v", silent)
+	}
+	if silent.LastSeen != nil || silent.Temp != nil || !silent.Stale || silent.AgeMinutes != -1 || silent.Completeness != 0 || len(silent.MissingSensors) != 0 {
+		t.Errorf("silent station = %+v, want no reading, stale, age -1 and no completeness", silent)
+	}
+
+	// Imperial converts the temperature but not the completeness fractions
+	req = httptest.NewRequest("GET", "/api/stations/detail?units=imperial", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	var imperial []api.StationDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &imperial); err != nil {
+		t.Fatalf("decode imperial response: %v", err)
+	}
+	if len(imperial) != 2 || imperial[0].Temp == nil || *imperial[0].Temp != models.CelsiusToFahrenheit(12.5) {
+		t.Fatalf("imperial stations = %+v, want FRESH at %v°F", imperial, models.CelsiusToFahrenheit(12.5))
+	}
+	if imperial[0].Completeness != 0.25 || imperial[0].FieldCompleteness["temp"] != 1 {
+		t.Errorf("imperial completeness = %v, fields %v, want them unchanged", imperial[0].Completeness, imperial[0].FieldCompleteness)
+	}
+}
+
+func TestAdminStation_SwapPrimary(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "OLD", IsPrimary: true, Active: true},
+		{StationID: "NEW", Active: false},
+	} {
+		if err := s.UpsertStation(st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetAdminToken("s3cret")
+
+	req := httptest.NewRequest("POST", "/admin/stations/NEW", strings.NewReader(`{"active": true, "is_primary": true}`))
+	req.Header.Set("X-Admin-Token", "s3cret")
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	primary, err := s.GetPrimaryStation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primary == nil || primary.StationID != "NEW" || !primary.Active {
+		t.Errorf("primary = %+v, want active NEW", primary)
+	}
+	if old, _ := s.GetStation("OLD"); old == nil || old.IsPrimary {
+		t.Errorf("OLD = %+v, want no longer primary", old)
+	}
+}
+
+func TestAdminStation_RejectsLeavingNoPrimary(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "PRIMARY", IsPrimary: true, Active: true},
+		{StationID: "OTHER", Active: false},
+	} {
+		if err := s.UpsertStation(st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetAdminToken("s3cret")
+
+	for _, tt := range []struct {
+		station, body string
+	}{
+		{"PRIMARY", `{"is_primary": false}`},
+		{"PRIMARY", `{"active": false}`},
+		{"OTHER", `{"is_primary": true}`}, // inactive stations can't be primary
+	} {
+		req := httptest.NewRequest("POST", "/admin/stations/"+tt.station, strings.NewReader(tt.body))
+		req.Header.Set("X-Admin-Token", "s3cret")
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+
+		if w.Code != 409 {
+			t.Errorf("%s %s: expected 409, got %d", tt.station, tt.body, w.Code)
+		}
+	}
+
+	primary, err := s.GetPrimaryStation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primary == nil || primary.StationID != "PRIMARY" || !primary.Active {
+		t.Errorf("primary = %+v, want active PRIMARY unchanged", primary)
+	}
+}
+
+func TestAdminStation_Unauthorized(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	if err := s.UpsertStation(models.Station{StationID: "TEST1", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetAdminToken("s3cret")
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest("POST", "/admin/stations/TEST1", strings.NewReader(`{"active": false}`))
+		if token != "" {
+			req.Header.Set("X-Admin-Token", token)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+
+		if w.Code != 401 {
+			t.Errorf("token %q: expected 401, got %d", token, w.Code)
+		}
+	}
+
+	st, err := s.GetStation("TEST1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Active {
+		t.Error("station was deactivated without a valid token")
+	}
+}
+
+func TestAdminPayloads(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	station := "IWANDI23"
+	wuBody := `{"observations":[{"stationID":"IWANDI23","metric":{"temp":"21.5"}}]}`
+	wuID, err := s.StoreRawPayload(nil, "wu", "pws/observations/current", &station, nil, []byte(wuBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := "VIC_PT075"
+	bomBody := `<?xml version="1.0"?><product><forecast/></product>`
+	bomID, err := s.StoreRawPayload(nil, "bom", "forecast/fwo", nil, &area, []byte(bomBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	srv.SetAdminToken("s3cret")
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Admin-Token", "s3cret")
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/admin/payloads?source=wu")
+	if w.Code != 200 {
+		t.Fatalf("list: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var infos []api.PayloadInfo
+	if err := json.NewDecoder(w.Body).Decode(&infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].ID != wuID || infos[0].StationID != station || infos[0].SizeBytes == 0 {
+		t.Errorf("wu payloads = %+v, want the one WU payload", infos)
+	}
+
+	w = get("/admin/payloads?limit=10")
+	infos = nil
+	if err := json.NewDecoder(w.Body).Decode(&infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Errorf("got %d payloads, want 2", len(infos))
+	}
+
+	for _, tc := range []struct {
+		id          int64
+		body        string
+		contentType string
+	}{
+		{wuID, wuBody, "application/json"},
+		{bomID, bomBody, "application/xml"},
+	} {
+		w := get(fmt.Sprintf("/admin/payloads/%d", tc.id))
+		if w.Code != 200 {
+			t.Fatalf("payload %d: expected 200, got %d", tc.id, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != tc.contentType {
+			t.Errorf("payload %d: Content-Type = %q, want %q", tc.id, got, tc.contentType)
+		}
+		if w.Body.String() != tc.body {
+			t.Errorf("payload %d: body = %q, want %q", tc.id, w.Body.String(), tc.body)
+		}
+	}
+
+	if w := get("/admin/payloads/9999"); w.Code != 404 {
+		t.Errorf("missing payload: expected 404, got %d", w.Code)
+	}
+	if w := get("/admin/payloads?limit=0"); w.Code != 400 {
+		t.Errorf("limit=0: expected 400, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/admin/payloads/%d", wuID), nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != 401 {
+		t.Errorf("no token: expected 401, got %d", w.Code)
+	}
+}
+
+func TestSparklineAPI(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	// One reading an hour for the last day, so each hourly bucket gets one
+	now := time.Now().UTC()
+	for i := 0; i < 24; i++ {
+		if err := s.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: now.Add(-time.Duration(23-i) * time.Hour),
+			Temp:       sql.NullFloat64{Float64: float64(i), Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/sparkline?station=TEST1&metric=temp&hours=24", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got []float64
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 24 {
+		t.Fatalf("len = %d, want 24", len(got))
+	}
+	for i, v := range got {
+		if v != float64(i) {
+			t.Errorf("point %d = %v, want %v", i, v, float64(i))
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/api/sparkline?station=TEST1&metric=temp&hours=24&units=imperial", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode imperial response: %v", err)
+	}
+	if len(got) != 24 || got[0] != 32 || got[10] != 50 {
+		t.Errorf("imperial sparkline = %v, want 32°F rising to 50°F at point 10", got)
+	}
+
+	for _, query := range []string{"metric=bogus", "metric=temp&hours=0", "metric=temp&hours=169", "metric=temp&units=kelvin"} {
+		req := httptest.NewRequest("GET", "/api/sparkline?station=TEST1&"+query, nil)
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestCurrentAPI_ImperialUnits(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	if err := s.UpsertStation(models.Station{StationID: "PRIMARY", Name: "Primary", Active: true, IsPrimary: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertObservation(models.Observation{
+		StationID:  "PRIMARY",
+		ObservedAt: time.Now().UTC(),
+		Temp:       sql.NullFloat64{Float64: 20, Valid: true},
+		Humidity:   sql.NullInt64{Int64: 55, Valid: true},
+		Pressure:   sql.NullFloat64{Float64: 1013.2, Valid: true},
+		WindSpeed:  sql.NullFloat64{Float64: 16, Valid: true},
+		WindGust:   sql.NullFloat64{Float64: 30, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	srv := api.NewServer(s, "8080", loc)
+
+	get := func(query string) api.CurrentData {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/current"+query, nil)
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		var got api.CurrentData
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if got.Primary == nil {
+			t.Fatalf("%s: no primary observation", query)
+		}
+		return got
+	}
+
+	metric := get("").Primary
+	imperial := get("?units=imperial").Primary
+	// Asking for metric again proves the imperial request didn't touch the cache
+	if again := get("?units=metric").Primary; again.Temp != metric.Temp {
+		t.Errorf("metric temp after imperial request = %v, want %v", again.Temp, metric.Temp)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		metric, got sql.NullFloat64
+		convert     func(float64) float64
+	}{
+		{"Temp", metric.Temp, imperial.Temp, models.CelsiusToFahrenheit},
+		{"WindSpeed", metric.WindSpeed, imperial.WindSpeed, models.KmhToMph},
+		{"WindGust", metric.WindGust, imperial.WindGust, models.KmhToMph},
+		{"Pressure", metric.Pressure, imperial.Pressure, models.HPaToInHg},
+	} {
+		if !tt.metric.Valid || !tt.got.Valid {
+			t.Errorf("%s: metric %v, imperial %v, want both valid", tt.name, tt.metric, tt.got)
+			continue
+		}
+		if want := tt.convert(tt.metric.Float64); math.Abs(tt.got.Float64-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got.Float64, want)
+		}
+	}
+	if imperial.Temp.Float64 != 68 {
+		t.Errorf("Temp = %v°F, want 68", imperial.Temp.Float64)
+	}
+	if imperial.Humidity != metric.Humidity {
+		t.Errorf("Humidity = %v, want unchanged %v", imperial.Humidity, metric.Humidity)
+	}
+	// No valley floor stations, so there's no valley temperature to convert
+	if got := get("?units=imperial"); !strings.HasPrefix(got.Narrative, "68°F") || got.ValleyTemp != nil {
+		t.Errorf("Narrative = %q, ValleyTemp = %v, want a °F narrative and no valley temp", got.Narrative, got.ValleyTemp)
+	}
+
+	req := httptest.NewRequest("GET", "/api/current?units=kelvin", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != 400 {
+		t.Errorf("units=kelvin: expected 400, got %d", w.Code)
+	}
+}
+
+func TestInversionEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	now := time.Now().UTC()
+	for _, st := range []struct {
+		station models.Station
+		temp    float64
+	}{
+		{models.Station{StationID: "VALLEY1", ElevationTier: "valley_floor", Elevation: 300, Active: true, IsPrimary: true}, 2},
+		{models.Station{StationID: "VALLEY2", ElevationTier: "valley_floor", Elevation: 320, Active: true}, 4},
+		{models.Station{StationID: "MID1", ElevationTier: "mid_slope", Elevation: 450, Active: true}, 6},
+		{models.Station{StationID: "UPPER1", ElevationTier: "upper", Elevation: 700, Active: true}, 10},
+		{models.Station{StationID: "UPPER2", ElevationTier: "upper", Elevation: 800, Active: true}, 12},
+	} {
+		if err := s.UpsertStation(st.station); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.InsertObservation(models.Observation{
+			StationID:  st.station.StationID,
+			ObservedAt: now.Add(-5 * time.Minute),
+			Temp:       sql.NullFloat64{Float64: st.temp, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/inversion", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got api.InversionExplanation
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if !got.Available || len(got.Stations) != 5 {
+		t.Fatalf("got %+v, want an explanation over 5 stations", got)
+	}
+	if got.ValleyAvg == nil || *got.ValleyAvg != 3 || got.MidAvg == nil || *got.MidAvg != 6 || got.UpperAvg == nil || *got.UpperAvg != 11 {
+		t.Errorf("averages = %v/%v/%v, want 3/6/11", got.ValleyAvg, got.MidAvg, got.UpperAvg)
+	}
+	// 500 m between the lowest valley and highest upper station
+	if got.ValleyElevation != 300 || got.UpperElevation != 800 {
+		t.Errorf("elevations = %v..%v, want 300..800", got.ValleyElevation, got.UpperElevation)
+	}
+	wantExpected := 500 * got.LapseRate
+	if math.Abs(got.ExpectedDiff-wantExpected) > 1e-9 || got.ActualDiff != 8 {
+		t.Errorf("expected/actual diff = %v/%v, want %v/8", got.ExpectedDiff, got.ActualDiff, wantExpected)
+	}
+	if math.Abs(got.Strength-(8-wantExpected)) > 1e-9 || !got.Active {
+		t.Errorf("strength %v active %v, want %v and active", got.Strength, got.Active, 8-wantExpected)
+	}
+	if st := got.Stations[0]; st.StationID != "VALLEY1" || st.Tier != "valley_floor" || st.Temp != 2 {
+		t.Errorf("first station = %+v, want VALLEY1 at 2°C", st)
+	}
+}
+
+func TestSchemaEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/schema", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp api.SchemaStatus
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Version != store.LatestMigrationVersion() || resp.LatestKnown != resp.Version {
+		t.Errorf("version = %d, latest_known = %d, want both %d", resp.Version, resp.LatestKnown, store.LatestMigrationVersion())
+	}
+	if resp.Pending || len(resp.PendingVersions) != 0 {
+		t.Errorf("pending = %v %v, want none after Migrate", resp.Pending, resp.PendingVersions)
+	}
+	if len(resp.Applied) != resp.Version {
+		t.Fatalf("got %d applied migrations, want %d", len(resp.Applied), resp.Version)
+	}
+	first := resp.Applied[0]
+	if first.Version != 1 || first.Description != "Initial schema" || first.AppliedAt.IsZero() {
+		t.Errorf("first applied = %+v, want version 1 Initial schema with a timestamp", first)
+	}
+}
+
+func TestReadOnly_SkipsDisplayedForecastLog(t *testing.T) {
+	t.Parallel()
+
+	// Seed a database file, then serve it through a read-only connection
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	loc := time.UTC
+	s := store.New(db, loc)
+	if err := s.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if err := s.UpsertStation(models.Station{StationID: "PRIMARY", Name: "Primary", ElevationTier: "valley_floor", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertObservation(models.Observation{
+		StationID:  "PRIMARY",
+		ObservedAt: now.Add(-5 * time.Minute),
+		Temp:       sql.NullFloat64{Float64: 18, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, source := range []string{"wu", "bom"} {
+		if err := s.InsertForecast(models.Forecast{
+			Source:        source,
+			FetchedAt:     now.Add(-time.Hour),
+			ValidDate:     today,
+			DayOfForecast: 0,
+			TempMax:       sql.NullFloat64{Float64: 25, Valid: true},
+			TempMin:       sql.NullFloat64{Float64: 10, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The guard, not the connection, is what skips the write
+	w := httptest.NewRecorder()
+	readOnly := api.NewServer(s, "8080", loc)
+	readOnly.SetReadOnly(true)
+	readOnly.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/partials/current", nil))
+	if w.Code != 200 {
+		t.Fatalf("read-only GET = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	df, err := s.GetLatestDisplayedForecast(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if df != nil {
+		t.Errorf("read-only server logged a displayed forecast: %+v", df)
+	}
+
+	// Every read path works against a read-only connection
+	roDB, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { roDB.Close() })
+	roStore := store.New(roDB, loc)
+
+	srv := api.NewServer(roStore, "8080", loc)
+	srv.SetReadOnly(true)
+	srv.SetAdminToken("secret")
+	handler := srv.Handler()
+	for _, path := range []string{"/partials/current", "/api/current", "/api/forecast"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 200 {
+			t.Errorf("GET %s = %d, want 200: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/admin/stations/PRIMARY", strings.NewReader(`{"active": false}`))
+	req.Header.Set("X-Admin-Token", "secret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != 403 {
+		t.Errorf("admin station update = %d, want 403", w.Code)
+	}
+
+	// The same page served read-write does log what it displayed
+	w = httptest.NewRecorder()
+	api.NewServer(s, "8080", loc).Handler().ServeHTTP(w, httptest.NewRequest("GET", "/partials/current", nil))
+	if w.Code != 200 {
+		t.Fatalf("read-write GET = %d, want 200", w.Code)
+	}
+	if df, err := s.GetLatestDisplayedForecast(today); err != nil || df == nil {
+		t.Errorf("read-write server logged %+v (err %v), want a displayed forecast", df, err)
+	}
+}
+
+func TestForecastCorrectionsEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	for _, fc := range []models.Forecast{
+		{Source: "wu", TempMax: sql.NullFloat64{Float64: 30, Valid: true}, TempMin: sql.NullFloat64{Float64: 10, Valid: true}},
+		{Source: "bom", TempMax: sql.NullFloat64{Float64: 28, Valid: true}, TempMin: sql.NullFloat64{Float64: 9, Valid: true}},
+	} {
+		fc.FetchedAt = now.Add(-time.Hour)
+		fc.ValidDate = tomorrow
+		fc.DayOfForecast = 1
+		if err := s.InsertForecast(fc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// WU has day-1 stats for its max; BOM only has day-2 stats to fall back on
+	for _, cs := range []store.CorrectionStats{
+		{Source: "wu", Target: "tmax", DayOfForecast: 1, Regime: "all", WindowDays: 30, SampleSize: 10, MeanBias: 2},
+		{Source: "bom", Target: "tmax", DayOfForecast: 2, Regime: "all", WindowDays: 30, SampleSize: 8, MeanBias: -1},
+	} {
+		if err := s.UpsertCorrectionStats(cs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	req := httptest.NewRequest("GET", "/api/forecast/corrections", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var days []api.ForecastCorrectionDay
+	if err := json.Unmarshal(w.Body.Bytes(), &days); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	var got []api.ForecastCorrection
+	for _, d := range days {
+		if d.Date == tomorrow.Format("2006-01-02") {
+			got = d.Corrections
+		}
+	}
+
+	want := map[string]api.ForecastCorrection{
+		"wu/max":  {Source: "wu", Target: "max", DayOfForecast: 1, Raw: 30, Bias: 2, BiasDayUsed: 1, BiasSamples: 10, Corrected: 28},
+		"wu/min":  {Source: "wu", Target: "min", DayOfForecast: 1, Raw: 10, BiasDayUsed: -1, Corrected: 10},
+		"bom/max": {Source: "bom", Target: "max", DayOfForecast: 1, Raw: 28, Bias: -1, BiasDayUsed: 2, BiasSamples: 8, BiasFallback: true, Corrected: 29},
+		"bom/min": {Source: "bom", Target: "min", DayOfForecast: 1, Raw: 9, BiasDayUsed: -1, Corrected: 9},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d corrections for tomorrow, want %d: %+v", len(got), len(want), got)
+	}
+	for _, c := range got {
+		key := c.Source + "/" + c.Target
+		if c != want[key] {
+			t.Errorf("%s = %+v, want %+v", key, c, want[key])
+		}
+	}
+}
+
+func TestPaletteEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+	srv := api.NewServer(s, "8080", loc)
+
+	get := func(query string) api.PaletteView {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/palette?"+query, nil)
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		var view api.PaletteView
+		if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+			t.Fatalf("%s: decode response: %v", query, err)
+		}
+		return view
+	}
+
+	want := forecast.GetPalette(forecast.ConditionStorm, forecast.TimeNight)
+	for _, query := range []string{"condition=storm&tod=night", "condition=storm_night"} {
+		view := get(query)
+		if view.Key != "storm_night" || view.Default || view.Palette != want {
+			t.Errorf("%s = %+v, want the storm_night palette %+v", query, view, want)
+		}
+		if view.Interpolated != nil {
+			t.Errorf("%s: interpolated without a time", query)
+		}
+	}
+
+	if view := get("condition=volcanic&tod=night"); !view.Default || view.Palette != forecast.DefaultPalette {
+		t.Errorf("unknown key = %+v, want the default palette", view)
+	}
+
+	// Half an hour before dusk the blend is part way to the dusk palette
+	view := get("condition=storm&time=16:30")
+	if view.TimeOfDay != "day" || view.Interpolated == nil {
+		t.Fatalf("time=16:30 = %+v, want day with an interpolated palette", view)
+	}
+	if *view.Interpolated == view.Palette {
+		t.Errorf("interpolated palette at 16:30 matches the day palette, want it blended towards dusk")
+	}
+}
+
+func TestVerificationScatterEndpoint(t *testing.T) {
+	t.Parallel()
+	s, loc := setupTestStore(t)
+
+	day1 := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	temp := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	for i, seed := range []struct {
+		fc models.Forecast
+		v  models.ForecastVerification
+	}{
+		{
+			models.Forecast{Source: "wu", ValidDate: day1, DayOfForecast: 1},
+			models.ForecastVerification{ForecastTempMax: temp(30), ActualTempMax: temp(28), ForecastTempMin: temp(10), ActualTempMin: temp(12)},
+		},
+		{
+			models.Forecast{Source: "bom", ValidDate: day1, DayOfForecast: 1},
+			models.ForecastVerification{ForecastTempMax: temp(27), ActualTempMax: temp(28), ForecastTempMin: temp(11), ActualTempMin: temp(12)},
+		},
+		{
+			// No min was forecast, so it only shows up for tmax
+			models.Forecast{Source: "wu", ValidDate: day2, DayOfForecast: 2},
+			models.ForecastVerification{ForecastTempMax: temp(25), ActualTempMax: temp(26), ActualTempMin: temp(9)},
+		},
+	} {
+		seed.fc.FetchedAt = seed.fc.ValidDate.Add(-24 * time.Hour)
+		if err := s.InsertForecast(seed.fc); err != nil {
+			t.Fatal(err)
+		}
+		seed.v.ForecastID = int64(i + 1)
+		seed.v.ValidDate = seed.fc.ValidDate
+		if err := s.UpsertForecastVerification(seed.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := api.NewServer(s, "8080", loc)
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/verification/scatter?"+query, nil)
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		query string
+		want  []api.VerificationScatterPair
+	}{
+		{"source=wu&target=tmax", []api.VerificationScatterPair{
+			{Source: "wu", DayOfForecast: 1, Date: "2026-01-10", Forecast: 30, Actual: 28},
+			{Source: "wu", DayOfForecast: 2, Date: "2026-01-11", Forecast: 25, Actual: 26},
+		}},
+		{"source=wu&target=tmin", []api.VerificationScatterPair{
+			{Source: "wu", DayOfForecast: 1, Date: "2026-01-10", Forecast: 10, Actual: 12},
+		}},
+		{"source=bom&target=tmax", []api.VerificationScatterPair{
+			{Source: "bom", DayOfForecast: 1, Date: "2026-01-10", Forecast: 27, Actual: 28},
+		}},
+		{"target=tmax", []api.VerificationScatterPair{
+			{Source: "bom", DayOfForecast: 1, Date: "2026-01-10", Forecast: 27, Actual: 28},
+			{Source: "wu", DayOfForecast: 1, Date: "2026-01-10", Forecast: 30, Actual: 28},
+			{Source: "wu", DayOfForecast: 2, Date: "2026-01-11", Forecast: 25, Actual: 26},
+		}},
+	}
+	for _, tt := range tests {
+		w := get(tt.query)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		var got api.VerificationScatter
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: decode response: %v", tt.query, err)
+		}
+		if len(got.Pairs) != len(tt.want) {
+			t.Fatalf("%s: got %d pairs, want %d: %+v", tt.query, len(got.Pairs), len(tt.want), got.Pairs)
+		}
+		for i := range tt.want {
+			if got.Pairs[i] != tt.want[i] {
+				t.Errorf("%s: pairs[%d] = %+v, want %+v", tt.query, i, got.Pairs[i], tt.want[i])
+			}
+		}
+	}
+
+	if w := get("source=wu&target=rain"); w.Code != 400 {
+		t.Errorf("unknown target: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+// maxSmoothWindow is the widest moving average the smooth parameter accepts.
+const maxSmoothWindow = 3 * time.Hour
+
+// parseSmooth reads the smooth query parameter, a moving-average window such
+// as "5m". It returns zero, meaning no smoothing, when the parameter is absent.
+func parseSmooth(r *http.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("smooth")
+	if v == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(v)
+	if err != nil || window < time.Minute || window > maxSmoothWindow {
+		return 0, fmt.Errorf("smooth must be a duration between 1m and %s, e.g. 5m", maxSmoothWindow)
+	}
+	return window, nil
+}
+
+// smoothSeries returns a centred moving average of values, each replaced by
+// the mean of the values observed within window/2 either side of it. times
+// must be ascending and the same length as values. NaN values mark gaps: they
+// are left as NaN and excluded from their neighbours' averages, and a gap in
+// time longer than the window leaves the readings either side of it alone.
+func smoothSeries(times []time.Time, values []float64, window time.Duration) []float64 {
+	smoothed := make([]float64, len(values))
+	half := window / 2
+	lo, hi := 0, 0
+	var sum float64
+	var count int
+	for i, v := range values {
+		if math.IsNaN(v) {
+			smoothed[i] = v
+			continue
+		}
+		// Slide the window [lo, hi) to cover times[i]-half to times[i]+half
+		for hi < len(values) && times[hi].Sub(times[i]) <= half {
+			if !math.IsNaN(values[hi]) {
+				sum += values[hi]
+				count++
+			}
+			hi++
+		}
+		for times[i].Sub(times[lo]) > half {
+			if !math.IsNaN(values[lo]) {
+				sum -= values[lo]
+				count--
+			}
+			lo++
+		}
+		smoothed[i] = sum / float64(count)
+	}
+	return smoothed
+}
+
+// smoothObservationTemps smooths the temperatures of observations, which must
+// be in time order, in place. Observations without a temperature are gaps.
+func smoothObservationTemps(observations []models.Observation, window time.Duration) {
+	times := make([]time.Time, len(observations))
+	temps := make([]float64, len(observations))
+	for i, o := range observations {
+		times[i] = o.ObservedAt
+		temps[i] = math.NaN()
+		if o.Temp.Valid {
+			temps[i] = o.Temp.Float64
+		}
+	}
+	for i, v := range smoothSeries(times, temps, window) {
+		if observations[i].Temp.Valid {
+			observations[i].Temp.Float64 = v
+		}
+	}
+}
+
+// smoothHistoryPage smooths a page of a station's observations between start
+// and end as if the whole range had been smoothed at once, padding the page
+// with the readings within half a window of its edges that fall on the pages
+// either side.
+func (s *Server) smoothHistoryPage(page []models.Observation, stationID string, start, end time.Time, window time.Duration) error {
+	first, last := page[0].ObservedAt, page[len(page)-1].ObservedAt
+	half := window / 2
+
+	padStart, padEnd := first.Add(-half), last.Add(half)
+	if padStart.Before(start) {
+		padStart = start
+	}
+	if padEnd.After(end) {
+		padEnd = end
+	}
+	before, err := s.store.GetObservations(stationID, padStart.UTC(), first.UTC())
+	if err != nil {
+		return err
+	}
+	after, err := s.store.GetObservations(stationID, last.UTC(), padEnd.UTC())
+	if err != nil {
+		return err
+	}
+
+	padded := make([]models.Observation, 0, len(before)+len(page)+len(after))
+	for _, o := range before {
+		if o.ObservedAt.Before(first) {
+			padded = append(padded, o)
+		}
+	}
+	offset := len(padded)
+	padded = append(padded, page...)
+	for _, o := range after {
+		if o.ObservedAt.After(last) {
+			padded = append(padded, o)
+		}
+	}
+
+	smoothObservationTemps(padded, window)
+	copy(page, padded[offset:offset+len(page)])
+	return nil
+}
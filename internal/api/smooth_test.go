@@ -0,0 +1,97 @@
+package api
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSmoothSeries(t *testing.T) {
+	start := time.Date(2026, 1, 15, 6, 0, 0, 0, time.UTC)
+	minutes := func(n ...int) []time.Time {
+		times := make([]time.Time, len(n))
+		for i, m := range n {
+			times[i] = start.Add(time.Duration(m) * time.Minute)
+		}
+		return times
+	}
+
+	t.Run("spike is flattened", func(t *testing.T) {
+		// A steady 10° with a one-minute 16° spike
+		times := minutes(0, 1, 2, 3, 4, 5, 6, 7, 8)
+		values := []float64{10, 10, 10, 10, 16, 10, 10, 10, 10}
+		got := smoothSeries(times, values, 5*time.Minute)
+
+		if len(got) != len(values) {
+			t.Fatalf("len = %d, want %d", len(got), len(values))
+		}
+		// Each point averages the two readings either side of it
+		want := []float64{10, 10, 11.2, 11.2, 11.2, 11.2, 11.2, 10, 10}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 0.001 {
+				t.Errorf("got[%d] = %.2f, want %.2f", i, got[i], want[i])
+			}
+		}
+		if got[0] != values[0] || got[len(got)-1] != values[len(values)-1] {
+			t.Errorf("endpoints = %v, %v, want unchanged %v, %v", got[0], got[len(got)-1], values[0], values[len(values)-1])
+		}
+	})
+
+	t.Run("trend survives", func(t *testing.T) {
+		times := minutes(0, 1, 2, 3, 4)
+		values := []float64{10, 11, 12, 13, 14}
+		got := smoothSeries(times, values, 3*time.Minute)
+		// The ends only see one neighbour, pulling them half a degree inwards
+		want := []float64{10.5, 11, 12, 13, 13.5}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 0.001 {
+				t.Errorf("got[%d] = %.2f, want %.2f", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("gaps are ignored", func(t *testing.T) {
+		times := minutes(0, 1, 2, 3, 30, 31)
+		values := []float64{10, math.NaN(), 14, 12, 20, 22}
+		got := smoothSeries(times, values, 5*time.Minute)
+
+		if !math.IsNaN(got[1]) {
+			t.Errorf("got[1] = %v, want NaN left in place", got[1])
+		}
+		if want := 12.0; math.Abs(got[0]-want) > 0.001 {
+			t.Errorf("got[0] = %.2f, want %.2f (missing reading skipped)", got[0], want)
+		}
+		// Readings after the half-hour gap only average with each other
+		if want := 21.0; math.Abs(got[4]-want) > 0.001 || math.Abs(got[5]-want) > 0.001 {
+			t.Errorf("after gap = %.2f, %.2f, want %.2f", got[4], got[5], want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if got := smoothSeries(nil, nil, 5*time.Minute); len(got) != 0 {
+			t.Errorf("got %v, want empty", got)
+		}
+	})
+}
+
+func TestParseSmooth(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"smooth=5m", 5 * time.Minute, false},
+		{"smooth=1h", time.Hour, false},
+		{"smooth=30s", 0, true},
+		{"smooth=12h", 0, true},
+		{"smooth=five", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSmooth(httptest.NewRequest("GET", "/api/history?"+tt.query, nil))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSmooth(%q) = %v, %v; want %v, error %v", tt.query, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
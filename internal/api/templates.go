@@ -1,8 +1,11 @@
 package api
 
 import (
+	"bytes"
 	"embed"
 	"html/template"
+	"log"
+	"net/http"
 	"strings"
 )
 
@@ -31,3 +34,17 @@ func newTemplates() *template.Template {
 	}
 	return template.Must(template.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html"))
 }
+
+// renderTemplate executes the named template into a buffer and only writes it
+// to the response on success, so a failing template yields a 500 rather than a
+// truncated page with a 200 status.
+func (s *Server) renderTemplate(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Printf("api: render %s: %v", name, err)
+		http.Error(w, "template error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate_ErrorReturns500WithoutPartialBody(t *testing.T) {
+	srv := &Server{tmpl: newTemplates()}
+
+	// A string has no .Days field, so forecast.html fails after writing its header.
+	w := httptest.NewRecorder()
+	srv.renderTemplate(w, "forecast.html", "broken")
+
+	if w.Code != 500 {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "section-header") {
+		t.Errorf("expected no partial template output, got %q", w.Body.String())
+	}
+}
+
+func TestRenderTemplate_Success(t *testing.T) {
+	srv := &Server{tmpl: newTemplates()}
+
+	w := httptest.NewRecorder()
+	srv.renderTemplate(w, "forecast.html", &ForecastData{})
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "This Week") {
+		t.Error("expected rendered forecast section")
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("expected text/html content type, got %q", ct)
+	}
+}
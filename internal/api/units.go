@@ -0,0 +1,411 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lox/wandiweather/internal/forecast"
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
+)
+
+// Unit systems accepted by the JSON API's units parameter.
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// parseUnits reads the units query parameter, defaulting to metric.
+func parseUnits(r *http.Request) (string, error) {
+	switch units := r.URL.Query().Get("units"); units {
+	case "", UnitsMetric:
+		return UnitsMetric, nil
+	case UnitsImperial:
+		return UnitsImperial, nil
+	default:
+		return "", errUnits
+	}
+}
+
+var errUnits = errors.New("units must be metric or imperial")
+
+// imperialConverter is implemented by API responses that carry measurements.
+// toImperial returns a copy converted from metric, leaving the receiver, which
+// may be cached, unchanged. Responses that don't implement it are written as
+// they are.
+type imperialConverter interface {
+	toImperial() any
+}
+
+// writeJSON encodes v as the response body in the units requested by the
+// units query parameter. Storage and the view models are always metric, so
+// imperial responses are converted on the way out by v's toImperial.
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c, ok := v.(imperialConverter); ok && units == UnitsImperial {
+		v = c.toImperial()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+var (
+	toFahrenheit      = models.CelsiusToFahrenheit
+	toFahrenheitDelta = models.CelsiusDeltaToFahrenheit
+	toMph             = models.KmhToMph
+	toInHg            = models.HPaToInHg
+	toInches          = models.MmToInches
+)
+
+// formatTemp formats a °C temperature as a whole number in units, e.g. "18°C"
+// or "64°F".
+func formatTemp(celsius float64, units string) string {
+	if units == UnitsImperial {
+		return fmt.Sprintf("%.0f°F", toFahrenheit(celsius))
+	}
+	return fmt.Sprintf("%.0f°C", celsius)
+}
+
+func convertNull(conv func(float64) float64, v sql.NullFloat64) sql.NullFloat64 {
+	if v.Valid {
+		v.Float64 = conv(v.Float64)
+	}
+	return v
+}
+
+func convertPtr(conv func(float64) float64, v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := conv(*v)
+	return &c
+}
+
+// imperialEach converts every element of s, keeping a nil slice nil so it
+// still encodes as null.
+func imperialEach[T interface{ toImperial() T }](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	out := make([]T, len(s))
+	for i, v := range s {
+		out[i] = v.toImperial()
+	}
+	return out
+}
+
+// imperialList is a response that's a bare array of convertible elements.
+type imperialList[T interface{ toImperial() T }] []T
+
+func (l imperialList[T]) toImperial() any {
+	return imperialEach([]T(l))
+}
+
+// observationList is a response that's a bare array of observations.
+type observationList []models.Observation
+
+func (l observationList) toImperial() any {
+	return imperialObservations(l)
+}
+
+func imperialObservations(obs []models.Observation) []models.Observation {
+	if obs == nil {
+		return nil
+	}
+	out := make([]models.Observation, len(obs))
+	for i, o := range obs {
+		out[i] = imperialObservation(o)
+	}
+	return out
+}
+
+func imperialObservation(o models.Observation) models.Observation {
+	o.Temp = convertNull(toFahrenheit, o.Temp)
+	o.Dewpoint = convertNull(toFahrenheit, o.Dewpoint)
+	o.HeatIndex = convertNull(toFahrenheit, o.HeatIndex)
+	o.WindChill = convertNull(toFahrenheit, o.WindChill)
+	o.WindSpeed = convertNull(toMph, o.WindSpeed)
+	o.WindGust = convertNull(toMph, o.WindGust)
+	o.Pressure = convertNull(toInHg, o.Pressure)
+	o.PrecipRate = convertNull(toInches, o.PrecipRate)
+	o.PrecipTotal = convertNull(toInches, o.PrecipTotal)
+	return o
+}
+
+func imperialObservationPtr(o *models.Observation) *models.Observation {
+	if o == nil {
+		return nil
+	}
+	c := imperialObservation(*o)
+	return &c
+}
+
+func imperialForecast(f *models.Forecast) *models.Forecast {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	c.TempMax = convertNull(toFahrenheit, f.TempMax)
+	c.TempMin = convertNull(toFahrenheit, f.TempMin)
+	c.PrecipAmount = convertNull(toInches, f.PrecipAmount)
+	c.WindSpeed = convertNull(toMph, f.WindSpeed)
+	return &c
+}
+
+func imperialVerificationStats(v *models.VerificationStats) *models.VerificationStats {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	c.AvgMaxBias = convertNull(toFahrenheitDelta, v.AvgMaxBias)
+	c.AvgMinBias = convertNull(toFahrenheitDelta, v.AvgMinBias)
+	c.MAEMax = convertNull(toFahrenheitDelta, v.MAEMax)
+	c.MAEMin = convertNull(toFahrenheitDelta, v.MAEMin)
+	c.AvgWindBias = convertNull(toMph, v.AvgWindBias)
+	c.MAEWind = convertNull(toMph, v.MAEWind)
+	c.AvgPrecipBias = convertNull(toInches, v.AvgPrecipBias)
+	c.MAEPrecip = convertNull(toInches, v.MAEPrecip)
+	return &c
+}
+
+// imperialTempExplanation converts only the values the explanation has: a
+// max or min without a source, or a bust that didn't happen, stays zero
+// rather than becoming 32°F.
+func imperialTempExplanation(e forecast.TempExplanation) forecast.TempExplanation {
+	if e.MaxSource != "" {
+		e.MaxRaw = toFahrenheit(e.MaxRaw)
+		e.MaxBiasApplied = toFahrenheitDelta(e.MaxBiasApplied)
+		e.MaxNowcast = toFahrenheitDelta(e.MaxNowcast)
+		e.MaxForecast = toFahrenheit(e.MaxForecast)
+		e.MaxFinal = toFahrenheit(e.MaxFinal)
+	}
+	if e.MaxBusted {
+		e.MaxBustedFrom = toFahrenheit(e.MaxBustedFrom)
+	}
+	if e.MinSource != "" {
+		e.MinRaw = toFahrenheit(e.MinRaw)
+		e.MinBiasApplied = toFahrenheitDelta(e.MinBiasApplied)
+		e.MinFinal = toFahrenheit(e.MinFinal)
+	}
+	return e
+}
+
+func (d *CurrentData) toImperial() any {
+	c := *d
+	c.Primary = imperialObservationPtr(d.Primary)
+	c.ValleyTemp = convertPtr(toFahrenheit, d.ValleyTemp)
+	c.TempChangeRate = convertPtr(toFahrenheitDelta, d.TempChangeRate)
+	if d.Trend != nil {
+		trend := *d.Trend
+		trend.Rate = toFahrenheitDelta(trend.Rate)
+		c.Trend = &trend
+	}
+	c.FeelsLike = convertPtr(toFahrenheit, d.FeelsLike)
+	c.TempAnomaly = convertPtr(toFahrenheitDelta, d.TempAnomaly)
+	if d.TempAnomaly != nil {
+		c.AnomalyLabel = anomalyLabel(*d.TempAnomaly, UnitsImperial)
+	}
+	c.Narrative = buildCurrentNarrative(d, UnitsImperial)
+	if d.Stations != nil {
+		c.Stations = make(map[string]*models.Observation, len(d.Stations))
+		for id, obs := range d.Stations {
+			c.Stations[id] = imperialObservationPtr(obs)
+		}
+	}
+	c.AllStations = imperialEach(d.AllStations)
+	c.ValleyFloor = imperialEach(d.ValleyFloor)
+	c.MidSlope = imperialEach(d.MidSlope)
+	c.Upper = imperialEach(d.Upper)
+	c.Tiers = imperialEach(d.Tiers)
+	if d.Inversion != nil {
+		inv := d.Inversion.toImperial()
+		c.Inversion = &inv
+	}
+	if d.TodayForecast != nil {
+		today := d.TodayForecast.toImperial()
+		c.TodayForecast = &today
+	}
+	if d.TodayStats != nil {
+		stats := d.TodayStats.toImperial()
+		c.TodayStats = &stats
+	}
+	if d.Records != nil {
+		c.Records = make([]store.RecordBroken, len(d.Records))
+		for i, rec := range d.Records {
+			rec.Value = toFahrenheit(rec.Value)
+			rec.Previous = toFahrenheit(rec.Previous)
+			c.Records[i] = rec
+		}
+	}
+	return &c
+}
+
+func (r StationReading) toImperial() StationReading {
+	r.Obs = imperialObservationPtr(r.Obs)
+	return r
+}
+
+func (t TierSummary) toImperial() TierSummary {
+	t.MedianTemp = toFahrenheit(t.MedianTemp)
+	t.MinTemp = toFahrenheit(t.MinTemp)
+	t.MaxTemp = toFahrenheit(t.MaxTemp)
+	return t
+}
+
+func (i InversionStatus) toImperial() InversionStatus {
+	i.Strength = toFahrenheitDelta(i.Strength)
+	i.ValleyAvg = toFahrenheit(i.ValleyAvg)
+	i.MidAvg = toFahrenheit(i.MidAvg)
+	i.UpperAvg = toFahrenheit(i.UpperAvg)
+	return i
+}
+
+func (f TodayForecast) toImperial() TodayForecast {
+	f.TempMax = toFahrenheit(f.TempMax)
+	f.TempMin = toFahrenheit(f.TempMin)
+	f.TempMaxPreNowcast = toFahrenheit(f.TempMaxPreNowcast)
+	f.NowcastAdjustment = toFahrenheitDelta(f.NowcastAdjustment)
+	f.PrecipAmount = toInches(f.PrecipAmount)
+	f.Explanation = imperialTempExplanation(f.Explanation)
+	return f
+}
+
+func (t TodayStats) toImperial() TodayStats {
+	t.MinTemp = toFahrenheit(t.MinTemp)
+	t.MaxTemp = toFahrenheit(t.MaxTemp)
+	t.RainTotal = toInches(t.RainTotal)
+	t.MaxWind = toMph(t.MaxWind)
+	t.MaxGust = toMph(t.MaxGust)
+	t.MinDewpoint = toFahrenheit(t.MinDewpoint)
+	return t
+}
+
+func (d *ForecastData) toImperial() any {
+	c := *d
+	c.Days = imperialEach(d.Days)
+	c.WUStats = imperialVerificationStats(d.WUStats)
+	c.BOMStats = imperialVerificationStats(d.BOMStats)
+	return &c
+}
+
+func (d ForecastDay) toImperial() ForecastDay {
+	d.WU = imperialForecast(d.WU)
+	d.BOM = imperialForecast(d.BOM)
+	d.WUCorrectedMax = convertPtr(toFahrenheit, d.WUCorrectedMax)
+	d.WUCorrectedMin = convertPtr(toFahrenheit, d.WUCorrectedMin)
+	d.BOMCorrectedMax = convertPtr(toFahrenheit, d.BOMCorrectedMax)
+	d.BOMCorrectedMin = convertPtr(toFahrenheit, d.BOMCorrectedMin)
+	d.DisplayMax = convertPtr(toFahrenheit, d.DisplayMax)
+	d.DisplayMin = convertPtr(toFahrenheit, d.DisplayMin)
+	d.Corrections = imperialEach(d.Corrections)
+	return d
+}
+
+func (c ForecastCorrection) toImperial() ForecastCorrection {
+	c.Raw = toFahrenheit(c.Raw)
+	c.Bias = toFahrenheitDelta(c.Bias)
+	c.Corrected = toFahrenheit(c.Corrected)
+	return c
+}
+
+func (d ForecastCorrectionDay) toImperial() ForecastCorrectionDay {
+	d.Corrections = imperialEach(d.Corrections)
+	return d
+}
+
+func (e ForecastExplanation) toImperial() any {
+	e.Max = e.Max.toImperial()
+	e.Min = e.Min.toImperial()
+	return e
+}
+
+func (r TempExplanationRow) toImperial() TempExplanationRow {
+	r.Raw = convertPtr(toFahrenheit, r.Raw)
+	r.Bias = convertPtr(toFahrenheitDelta, r.Bias)
+	r.Nowcast = convertPtr(toFahrenheitDelta, r.Nowcast)
+	r.Final = convertPtr(toFahrenheit, r.Final)
+	return r
+}
+
+func (r RegimeResponse) toImperial() any {
+	r.Inputs.ForecastMax = convertPtr(toFahrenheit, r.Inputs.ForecastMax)
+	r.Inputs.PrecipTotal = convertPtr(toInches, r.Inputs.PrecipTotal)
+	maxes := make([]float64, len(r.Inputs.PrevDayMaxes))
+	for i, v := range r.Inputs.PrevDayMaxes {
+		maxes[i] = toFahrenheit(v)
+	}
+	r.Inputs.PrevDayMaxes = maxes
+	return r
+}
+
+func (t TodayTrack) toImperial() any {
+	t.Observed = imperialEach(t.Observed)
+	t.ForecastMax = convertPtr(toFahrenheit, t.ForecastMax)
+	t.ForecastMin = convertPtr(toFahrenheit, t.ForecastMin)
+	t.NowcastMax = convertPtr(toFahrenheit, t.NowcastMax)
+	return t
+}
+
+func (p TrackPoint) toImperial() TrackPoint {
+	p.Temp = toFahrenheit(p.Temp)
+	return p
+}
+
+func (b HistoryBucket) toImperial() HistoryBucket {
+	b.TempAvg = convertPtr(toFahrenheit, b.TempAvg)
+	b.WindGustMax = convertPtr(toMph, b.WindGustMax)
+	b.Precip = convertPtr(toInches, b.Precip)
+	return b
+}
+
+func (o ObservationsSince) toImperial() any {
+	o.Observations = imperialObservations(o.Observations)
+	return o
+}
+
+func (c StationComfort) toImperial() StationComfort {
+	c.Temp = toFahrenheit(c.Temp)
+	c.ApparentTemp = toFahrenheit(c.ApparentTemp)
+	c.Dewpoint = toFahrenheit(c.Dewpoint)
+	return c
+}
+
+func (d StationDetail) toImperial() StationDetail {
+	d.Temp = convertPtr(toFahrenheit, d.Temp)
+	return d
+}
+
+func (e InversionExplanation) toImperial() any {
+	e.ValleyAvg = convertPtr(toFahrenheit, e.ValleyAvg)
+	e.MidAvg = convertPtr(toFahrenheit, e.MidAvg)
+	e.UpperAvg = convertPtr(toFahrenheit, e.UpperAvg)
+	e.LapseRate = toFahrenheitDelta(e.LapseRate)
+	e.ExpectedDiff = toFahrenheitDelta(e.ExpectedDiff)
+	e.ActualDiff = toFahrenheitDelta(e.ActualDiff)
+	e.Strength = toFahrenheitDelta(e.Strength)
+	e.Stations = imperialEach(e.Stations)
+	return e
+}
+
+func (r InversionReading) toImperial() InversionReading {
+	r.Temp = toFahrenheit(r.Temp)
+	return r
+}
+
+func (v VerificationScatter) toImperial() any {
+	v.Pairs = imperialEach(v.Pairs)
+	return v
+}
+
+func (p VerificationScatterPair) toImperial() VerificationScatterPair {
+	p.Forecast = toFahrenheit(p.Forecast)
+	p.Actual = toFahrenheit(p.Actual)
+	return p
+}
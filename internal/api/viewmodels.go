@@ -12,23 +12,62 @@ import (
 // CurrentData contains all the data needed to render the current conditions view.
 type CurrentData struct {
 	Primary        *models.Observation
-	ValleyTemp     float64
+	ValleyTemp     *float64 // median valley floor temperature; nil when none are reporting
 	TempChangeRate *float64
+	Trend          *Trend // nil without enough recent readings for a rate
+	Narrative      string // one-line summary, e.g. "18°C and falling, humid, light NW wind"
 	FeelsLike      *float64
+	TempAnomaly    *float64 // valley temp minus the median daily mean for this date in past years
+	AnomalyLabel   string   // e.g. "3°C above average"
+	Zambretti      string   // barometer-based outlook, e.g. "Fairly fine, showery later"
+	UV             *UVGuidance
+	RainIntensity  string // WMO class of the current rain rate, e.g. "moderate"; empty when dry
+	WindCardinal   string // primary station's wind direction, e.g. "NW"; empty when calm or unknown
+	WindAdvisory   string // forecast.WindAdvisory for outdoor activities; empty without a wind reading
 	Stations       map[string]*models.Observation
 	StationMeta    map[string]models.Station
 	AllStations    []StationReading
 	ValleyFloor    []StationReading
 	MidSlope       []StationReading
 	Upper          []StationReading
+	Tiers          []TierSummary // compact per-tier temperatures, valley floor first
 	Inversion      *InversionStatus
+	InversionTimes *InversionOutlook
 	TodayForecast  *TodayForecast
-	TodayStats     *TodayStats
-	LastUpdated    time.Time
-	Moon           *MoonData
-	Alerts         []emergency.Alert
-	UrgentAlerts   []emergency.Alert
-	FireDanger     *firedanger.DayForecast
+	// ForecastUnavailable is set when there's no WU or BOM forecast for today,
+	// e.g. on first boot before any forecast fetch.
+	ForecastUnavailable bool
+	TodayStats          *TodayStats
+	Records             []store.RecordBroken
+	LastUpdated         time.Time
+	Moon                *MoonData
+	Alerts              []emergency.Alert
+	UrgentAlerts        []emergency.Alert
+	FireDanger          *firedanger.DayForecast
+	// ClearedAlerts are alerts cleared in the last 24 hours, most recently
+	// seen first.
+	ClearedAlerts []store.AlertHistoryEntry
+}
+
+// Trend directions.
+const (
+	TrendRising  = "rising"
+	TrendFalling = "falling"
+	TrendSteady  = "steady"
+)
+
+// Trend is the primary station's temperature trend over the last hour.
+type Trend struct {
+	Rate      float64 `json:"rate"`      // °C per hour
+	Direction string  `json:"direction"` // TrendRising, TrendFalling or TrendSteady
+	PastPeak  bool    `json:"past_peak"` // falling in the afternoon, so today's max has likely passed
+}
+
+// UVGuidance contextualises the current UV index with sun-protection advice.
+type UVGuidance struct {
+	Index    float64
+	Category string // WHO band, e.g. "Very high"
+	Advice   string
 }
 
 // MoonData contains moon phase information for display.
@@ -57,6 +96,8 @@ type TodayForecast struct {
 	Narrative         string
 	HasPrecip         bool
 	Explanation       forecast.TempExplanation
+	FetchedAt         time.Time // most recent fetch among the sources used
+	UpdatedAgo        string    // e.g. "6h ago"
 }
 
 // TodayStats contains observed statistics for today.
@@ -72,12 +113,29 @@ type TodayStats struct {
 	MaxWind      float64
 	MaxGust      float64
 	HasWind      bool
+	MinHumidity  int64
+	MaxHumidity  int64
+	HasHumidity  bool
+	MinDewpoint  float64
+	HasDewpoint  bool
+	Sunshine     float64 // Hours of sunshine so far, from solar radiation
+	HasSunshine  bool
 }
 
 // StationReading pairs a station with its latest observation.
 type StationReading struct {
-	Station models.Station
-	Obs     *models.Observation
+	Station      models.Station
+	Obs          *models.Observation
+	WindCardinal string // 16-point wind direction, e.g. "NNW"; empty when calm or unknown
+}
+
+// TierSummary is the spread of current temperatures across one elevation tier.
+type TierSummary struct {
+	Tier       string  `json:"tier"`
+	Count      int     `json:"count"`
+	MedianTemp float64 `json:"median_temp"`
+	MinTemp    float64 `json:"min_temp"`
+	MaxTemp    float64 `json:"max_temp"`
 }
 
 // InversionStatus indicates whether a temperature inversion is active.
@@ -89,12 +147,21 @@ type InversionStatus struct {
 	UpperAvg  float64
 }
 
+// InversionOutlook is the expected timing of tonight's valley inversion.
+type InversionOutlook struct {
+	Onset   time.Time
+	Breakup time.Time
+}
+
 // ForecastData contains multi-day forecast information.
 type ForecastData struct {
 	Days     []ForecastDay
 	WUStats  *models.VerificationStats
 	BOMStats *models.VerificationStats
 	HasStats bool
+
+	FetchedAt  time.Time // most recent fetch among the displayed forecasts
+	UpdatedAgo string    // e.g. "6h ago"
 }
 
 // ForecastDay represents a single day's forecast.
@@ -105,13 +172,46 @@ type ForecastDay struct {
 	IsToday            bool
 	WU                 *models.Forecast
 	BOM                *models.Forecast
-	WUCorrectedMax     *float64 `json:"wu_corrected_max,omitempty"`
-	WUCorrectedMin     *float64 `json:"wu_corrected_min,omitempty"`
-	BOMCorrectedMax    *float64 `json:"bom_corrected_max,omitempty"`
-	BOMCorrectedMin    *float64 `json:"bom_corrected_min,omitempty"`
-	DisplayMax         *float64 `json:"display_max,omitempty"`
-	DisplayMin         *float64 `json:"display_min,omitempty"`
-	GeneratedNarrative string   `json:"generated_narrative"`
+	WUCorrectedMax     *float64         `json:"wu_corrected_max,omitempty"`
+	WUCorrectedMin     *float64         `json:"wu_corrected_min,omitempty"`
+	BOMCorrectedMax    *float64         `json:"bom_corrected_max,omitempty"`
+	BOMCorrectedMin    *float64         `json:"bom_corrected_min,omitempty"`
+	DisplayMax         *float64         `json:"display_max,omitempty"`
+	DisplayMin         *float64         `json:"display_min,omitempty"`
+	GeneratedNarrative string           `json:"generated_narrative"`
+	PrecipTypes        []TierPrecipType `json:"precip_types,omitempty"`
+	// Corrections is the working behind the corrected values, served
+	// separately by /api/forecast/corrections.
+	Corrections []ForecastCorrection `json:"-"`
+}
+
+// ForecastCorrection is how one source's forecast max or min for a day was
+// bias corrected.
+type ForecastCorrection struct {
+	Source        string  `json:"source"` // "wu" or "bom"
+	Target        string  `json:"target"` // "max" or "min"
+	DayOfForecast int     `json:"day_of_forecast"`
+	Raw           float64 `json:"raw"`
+	Bias          float64 `json:"bias"`          // subtracted from raw; zero when no stats apply
+	BiasDayUsed   int     `json:"bias_day_used"` // lead day whose stats were used, -1 if none
+	BiasSamples   int     `json:"bias_samples"`
+	BiasFallback  bool    `json:"bias_fallback"` // stats came from a nearby lead day
+	Corrected     float64 `json:"corrected"`
+}
+
+// ForecastCorrectionDay lists every correction made for one forecast day,
+// as returned by /api/forecast/corrections.
+type ForecastCorrectionDay struct {
+	Date        string               `json:"date"` // YYYY-MM-DD
+	Corrections []ForecastCorrection `json:"corrections"`
+}
+
+// TierPrecipType is the inferred precipitation type for one elevation tier.
+type TierPrecipType struct {
+	Tier      string  `json:"tier"`
+	Label     string  `json:"label"`
+	Elevation float64 `json:"elevation"`
+	Type      string  `json:"type"`
 }
 
 // ChartData contains data for the temperature chart.
@@ -141,12 +241,23 @@ type AccuracyData struct {
 	ChartBOMMin    []float64
 	LeadTimeData   []LeadTimeRow
 	RegimeStats    []RegimeRow
+	WorstForecasts []VerificationRow // biggest max temp misses, worst first
+	RainChanceRows []RainCalibrationRow
+
+	// Raw vs bias-corrected displayed forecast error per day, oldest first
+	CorrectionLabels       []string
+	CorrectionRawMAE       []float64
+	CorrectionCorrectedMAE []float64
+
+	// Displayed forecast accuracy per source (bom, wu or blend)
+	CorrectedBySource []store.CorrectedSourceAccuracy
 }
 
 // VerificationRow represents a single verification entry.
 type VerificationRow struct {
 	Date         string
 	Source       string
+	LeadTime     int
 	ForecastMax  float64
 	ForecastMin  float64
 	ActualMax    float64
@@ -169,6 +280,12 @@ type RegimeRow struct {
 	BOMMAEMin float64
 	WUDays    int
 	BOMDays   int
+	// WUMinDays and BOMMinDays count the days that also have a verified min.
+	WUMinDays  int
+	BOMMinDays int
+	// BestSourceNote recommends a source for this regime, e.g. "Use BOM on
+	// heatwave days". Empty when there's no clear winner yet.
+	BestSourceNote string
 }
 
 // LeadTimeRow represents accuracy by forecast lead time.
@@ -182,6 +299,16 @@ type LeadTimeRow struct {
 	BOMDays   int
 }
 
+// RainCalibrationRow compares how often it rained against the forecast rain
+// chance for one decile, e.g. "30-39%". Observed values are percentages.
+type RainCalibrationRow struct {
+	Label       string
+	WUObserved  float64
+	BOMObserved float64
+	WUDays      int
+	BOMDays     int
+}
+
 // DataPageData contains data health and statistics.
 type DataPageData struct {
 	SchemaVersion     int
@@ -189,14 +316,24 @@ type DataPageData struct {
 	TotalForecasts    int64
 	RawPayloadCount   int64
 	RawPayloadSizeKB  int64
+	RawRetentionDays  int    // 0 when payloads are kept forever
+	RawPayloadOldest  string // e.g. "Jan 2, 2025"; empty with no payloads
 	DatabaseSizeMB    float64
 	IngestHealth      []store.IngestHealthSummary
+	IngestLatency     []store.LatencyStats // by source then endpoint
 	ObsTypes          []store.ObsTypeCount
 	ForecastCoverage  []store.ForecastCoverage
 	RecentErrors      []store.RecentIngestError
 	ObsWithFlags      int64
 	CleanObservations int64
+	StationQuality    []store.StationCleanCount // most often flagged first
 	ParseErrors24h    int64
+	Rainfall          *store.RollingRainfall
+	SuspectRainGauges []string  // stations reading zero while their tier had rain
+	LongestDrySpell   int       // days, over the last precipSpellDays
+	LongestWetSpell   int       // days, over the last precipSpellDays
+	DiurnalLabels     []string  // dates for the diurnal range chart, e.g. "2 Jan"
+	DiurnalRanges     []float64 // primary station's daily max minus min (°C)
 	UpdatedAt         string
 }
 
@@ -204,13 +341,250 @@ type DataPageData struct {
 type HealthStatus struct {
 	Status   string          `json:"status"`
 	Stations []StationHealth `json:"stations"`
+	Sources  []SourceHealth  `json:"sources"`
+	Outages  []OutageHealth  `json:"outages"`
 	Errors   []string        `json:"errors,omitempty"`
 }
 
+// OutageHealth is a station outage that hasn't ended yet.
+type OutageHealth struct {
+	StationID  string    `json:"station_id"`
+	StartedAt  time.Time `json:"started_at"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SourceHealth represents how recently an upstream data source was ingested.
+type SourceHealth struct {
+	Name                  string     `json:"name"`
+	Source                string     `json:"source"`
+	Endpoint              string     `json:"endpoint"`
+	LastSuccess           *time.Time `json:"last_success,omitempty"`
+	AgeMinutes            int        `json:"age_minutes"`
+	StaleThresholdMinutes int        `json:"stale_threshold_minutes"`
+	Stale                 bool       `json:"stale"`
+}
+
 // StationHealth represents the health of a single station.
 type StationHealth struct {
+	StationID             string    `json:"station_id"`
+	LastSeen              time.Time `json:"last_seen"`
+	AgeMinutes            int       `json:"age_minutes"`
+	StaleThresholdMinutes int       `json:"stale_threshold_minutes"`
+	Stale                 bool      `json:"stale"`
+}
+
+// StationDetail is a station with its current reading and data health, as
+// returned by /api/stations/detail.
+type StationDetail struct {
+	StationID    string     `json:"station_id"`
+	Name         string     `json:"name"`
+	Tier         string     `json:"tier"`
+	Elevation    float64    `json:"elevation"`
+	Active       bool       `json:"active"`
+	IsPrimary    bool       `json:"is_primary"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
+	AgeMinutes   int        `json:"age_minutes"` // -1 when the station has never reported
+	Stale        bool       `json:"stale"`
+	Temp         *float64   `json:"temp,omitempty"`
+	Completeness float64    `json:"completeness"` // share of the last 24 hours with an observation, 0-1
+
+	// FieldCompleteness is the share of the last day's observations carrying
+	// each sensor field, keyed as store.GetFieldCompleteness.
+	FieldCompleteness map[string]float64 `json:"field_completeness"`
+	// MissingSensors lists fields the station never reported in that time
+	// though it did report others, e.g. "pressure" for a station without a
+	// barometer.
+	MissingSensors []string `json:"missing_sensors,omitempty"`
+}
+
+// PayloadInfo describes a stored raw API response, as listed by
+// /admin/payloads.
+type PayloadInfo struct {
+	ID          int64     `json:"id"`
+	IngestRunID *int64    `json:"ingest_run_id,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	Source      string    `json:"source"`
+	Endpoint    string    `json:"endpoint"`
+	StationID   string    `json:"station_id,omitempty"`
+	LocationID  string    `json:"location_id,omitempty"`
+	SizeBytes   int64     `json:"size_bytes"` // compressed
+	Hash        string    `json:"hash"`
+}
+
+// ForecastExplanation is the audit trail for a displayed forecast, as returned
+// by /api/forecast/explain.
+type ForecastExplanation struct {
+	ValidDate     string             `json:"valid_date"`
+	DisplayedAt   time.Time          `json:"displayed_at"`
+	DayOfForecast int                `json:"day_of_forecast"`
+	Max           TempExplanationRow `json:"max"`
+	Min           TempExplanationRow `json:"min"`
+}
+
+// TempExplanationRow breaks down how a single displayed temperature was derived.
+type TempExplanationRow struct {
+	Source       string   `json:"source,omitempty"`
+	Raw          *float64 `json:"raw,omitempty"`
+	Bias         *float64 `json:"bias,omitempty"`
+	BiasDayUsed  *int     `json:"bias_day_used,omitempty"`
+	BiasSamples  *int     `json:"bias_samples,omitempty"`
+	BiasFallback bool     `json:"bias_fallback"`
+	Nowcast      *float64 `json:"nowcast,omitempty"`
+	Final        *float64 `json:"final,omitempty"`
+}
+
+// RegimeResponse is the /api/regime response: the classified regime for a date
+// and the inputs the classification was based on.
+type RegimeResponse struct {
+	Date      string       `json:"date"`
+	StationID string       `json:"station_id"`
+	Regime    string       `json:"regime"`
+	Flags     RegimeFlags  `json:"flags"`
+	Inputs    RegimeInputs `json:"inputs"`
+}
+
+// RegimeFlags mirrors forecast.RegimeFlags for JSON output.
+type RegimeFlags struct {
+	Heatwave       bool `json:"heatwave"`
+	ColdSnap       bool `json:"cold_snap"`
+	InversionNight bool `json:"inversion_night"`
+	ClearCalm      bool `json:"clear_calm"`
+}
+
+// RegimeInputs are the values ClassifyRegime used. Missing values are omitted.
+type RegimeInputs struct {
+	ForecastMax       *float64  `json:"forecast_max,omitempty"`
+	InversionDetected *bool     `json:"inversion_detected,omitempty"`
+	PrecipTotal       *float64  `json:"precip_total,omitempty"`
+	SolarIntegral     *float64  `json:"solar_integral,omitempty"`
+	CalmFractionNight *float64  `json:"calm_fraction_night,omitempty"`
+	PrevDayMaxes      []float64 `json:"prev_day_maxes"`
+}
+
+// TodayTrack is the /api/today/track response: today's observed temperatures by
+// hour, with the displayed forecast lines to overlay them against.
+type TodayTrack struct {
+	Date         string       `json:"date"`
+	StationID    string       `json:"station_id"`
+	Observed     []TrackPoint `json:"observed"`
+	ForecastMax  *float64     `json:"forecast_max,omitempty"`
+	ForecastMin  *float64     `json:"forecast_min,omitempty"`
+	NowcastMax   *float64     `json:"nowcast_max,omitempty"`
+	ForecastFrom *time.Time   `json:"forecast_from,omitempty"`
+}
+
+// TrackPoint is the mean observed temperature for one local hour.
+type TrackPoint struct {
+	Time    time.Time `json:"time"`
+	Temp    float64   `json:"temp"`
+	Samples int       `json:"samples"`
+}
+
+// HistoryBucket is one time bucket of /api/history?bucket=, with observations
+// aggregated server-side.
+type HistoryBucket struct {
+	Start       time.Time `json:"start"`
+	Samples     int       `json:"samples"`
+	TempAvg     *float64  `json:"temp_avg,omitempty"`
+	WindGustMax *float64  `json:"wind_gust_max,omitempty"`
+	Precip      *float64  `json:"precip,omitempty"`
+}
+
+// ObservationsSince is the /api/observations/since response. Cursor is the id
+// of the last observation returned, or the cursor passed in if there are none
+// newer; it's omitted when polling by time finds nothing.
+type ObservationsSince struct {
+	Observations []models.Observation `json:"observations"`
+	Cursor       int64                `json:"cursor,omitempty"`
+}
+
+// StationComfort is how conditions feel at one station, as returned by /api/comfort.
+type StationComfort struct {
+	StationID       string    `json:"station_id"`
+	Name            string    `json:"name"`
+	ObservedAt      time.Time `json:"observed_at"`
+	Temp            float64   `json:"temp"`
+	Humidity        float64   `json:"humidity"`
+	ApparentTemp    float64   `json:"apparent_temp"`
+	Dewpoint        float64   `json:"dewpoint"`
+	DewpointComfort string    `json:"dewpoint_comfort"`
+	HeatStress      bool      `json:"heat_stress"`
+}
+
+// InversionExplanation is the working behind the current inversion verdict,
+// as returned by /api/inversion.
+type InversionExplanation struct {
+	// Available is false when the valley floor or upper tier has no readings,
+	// so there's nothing to compare and the figures below are zero.
+	Available       bool               `json:"available"`
+	Active          bool               `json:"active"`
+	ValleyAvg       *float64           `json:"valley_avg,omitempty"`
+	MidAvg          *float64           `json:"mid_avg,omitempty"`
+	UpperAvg        *float64           `json:"upper_avg,omitempty"`
+	ValleyElevation float64            `json:"valley_elevation"` // lowest reporting valley station, metres
+	UpperElevation  float64            `json:"upper_elevation"`  // highest reporting upper station, metres
+	LapseRate       float64            `json:"lapse_rate"`       // °C per metre
+	ExpectedDiff    float64            `json:"expected_diff"`    // upper minus valley expected from the lapse rate
+	ActualDiff      float64            `json:"actual_diff"`      // upper average minus valley average
+	Strength        float64            `json:"strength"`         // actual minus expected
+	Stations        []InversionReading `json:"stations"`
+}
+
+// InversionReading is one station's contribution to the inversion check.
+type InversionReading struct {
 	StationID  string    `json:"station_id"`
-	LastSeen   time.Time `json:"last_seen"`
-	AgeMinutes int       `json:"age_minutes"`
-	Stale      bool      `json:"stale"`
+	Name       string    `json:"name"`
+	Tier       string    `json:"tier"`
+	Elevation  float64   `json:"elevation"`
+	Temp       float64   `json:"temp"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// SchemaStatus is the database migration state, as returned by /api/schema.
+type SchemaStatus struct {
+	Version         int               `json:"version"`
+	LatestKnown     int               `json:"latest_known"`
+	Pending         bool              `json:"pending"`
+	PendingVersions []int             `json:"pending_versions"`
+	Applied         []SchemaMigration `json:"applied"`
+}
+
+// SchemaMigration is one applied migration.
+type SchemaMigration struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// PaletteView is the theme palette resolved for a condition and time of day,
+// as returned by /api/palette.
+type PaletteView struct {
+	Key       string           `json:"key"`
+	Condition string           `json:"condition"`
+	TimeOfDay string           `json:"tod"`
+	Default   bool             `json:"default"` // no curated palette for the key
+	Palette   forecast.Palette `json:"palette"`
+
+	// Set when a time is given: the palette blended for that time of day
+	Time         string            `json:"time,omitempty"`
+	Interpolated *forecast.Palette `json:"interpolated,omitempty"`
+}
+
+// VerificationScatter is every verified forecast for a source and target
+// against what was observed, as returned by /api/verification/scatter for
+// calibration plots.
+type VerificationScatter struct {
+	Source string                    `json:"source"` // empty for all sources
+	Target string                    `json:"target"` // "tmax" or "tmin"
+	Pairs  []VerificationScatterPair `json:"pairs"`
+}
+
+// VerificationScatterPair is one forecast and the observed value it verified
+// against.
+type VerificationScatterPair struct {
+	Source        string  `json:"source"`
+	DayOfForecast int     `json:"day_of_forecast"`
+	Date          string  `json:"date"` // YYYY-MM-DD
+	Forecast      float64 `json:"forecast"`
+	Actual        float64 `json:"actual"`
 }
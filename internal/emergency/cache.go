@@ -0,0 +1,74 @@
+package emergency
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// feedValidators are the HTTP cache validators returned with the events feed.
+type feedValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// apply adds conditional request headers for any validators present.
+func (v feedValidators) apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// feedCache keeps the last full events feed and its validators on disk.
+type feedCache struct {
+	dir string
+}
+
+func (c *feedCache) bodyPath() string {
+	return filepath.Join(c.dir, "events-geojson.json")
+}
+
+func (c *feedCache) metaPath() string {
+	return filepath.Join(c.dir, "events-geojson.meta.json")
+}
+
+// load returns the cached feed and its validators, or false on a cache miss.
+func (c *feedCache) load() ([]byte, feedValidators, bool) {
+	var v feedValidators
+	body, err := os.ReadFile(c.bodyPath())
+	if err != nil {
+		return nil, v, false
+	}
+	if meta, err := os.ReadFile(c.metaPath()); err == nil {
+		_ = json.Unmarshal(meta, &v)
+	}
+	return body, v, true
+}
+
+// save writes the feed and its validators using atomic writes (temp file +
+// rename), body first so validators never describe a missing body.
+func (c *feedCache) save(body []byte, v feedValidators) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.bodyPath(), body); err != nil {
+		return err
+	}
+	return writeFileAtomic(c.metaPath(), meta)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
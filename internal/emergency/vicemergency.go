@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"math"
 	"net/http"
 	"sort"
@@ -16,11 +18,13 @@ import (
 )
 
 const (
-	eventsURL = "https://emergency.vic.gov.au/public/events-geojson.json"
+	defaultEventsURL = "https://emergency.vic.gov.au/public/events-geojson.json"
 
 	// Default radius in km to search for alerts around Wandiligong
 	// 15km covers the immediate valley (Wandiligong, Bright, Harrietville)
 	DefaultRadiusKM = 15.0
+
+	userAgent = "WandiWeather/1.0 (weather site for Wandiligong)"
 )
 
 // Severity levels for sorting alerts
@@ -32,6 +36,10 @@ const (
 	SeverityUnknown
 )
 
+// CategoryLocal marks alerts raised from our own station data rather than the
+// VicEmergency feed.
+const CategoryLocal = "Local"
+
 // Alert represents a processed emergency alert relevant to our location.
 type Alert struct {
 	ID          string
@@ -50,24 +58,34 @@ type Alert struct {
 	URL         string
 	Lat         float64
 	Lon         float64
+
+	// Escalated is set by the store when the alert has become more severe
+	// since it was first stored; EscalatedFrom is the severity before.
+	Escalated     bool
+	EscalatedFrom int
 }
 
 // Client fetches and filters VicEmergency alerts.
 type Client struct {
 	httpClient *http.Client
+	eventsURL  string
 	centerLat  float64
 	centerLon  float64
 	radiusKM   float64
+	categories map[string]bool // lower-cased; nil keeps every category
+	cache      *feedCache      // nil unless SetCacheDir was called
 
-	mu          sync.RWMutex
+	mu           sync.RWMutex
 	cachedAlerts []Alert
-	lastFetch   time.Time
+	lastFetch    time.Time
+	validators   feedValidators // from the last full fetch, for conditional requests
 }
 
 // NewClient creates a new VicEmergency client centered on a location.
 func NewClient(lat, lon, radiusKM float64) *Client {
 	return &Client{
-		httpClient: httputil.NewClient(),
+		httpClient: httputil.NewClientWithOptions(httputil.DefaultTimeout, userAgent),
+		eventsURL:  defaultEventsURL,
 		centerLat:  lat,
 		centerLon:  lon,
 		radiusKM:   radiusKM,
@@ -87,13 +105,81 @@ func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
 	return c.Fetch(ctx)
 }
 
-// Fetch retrieves fresh alerts from VicEmergency.
+// SetCategories limits alerts to the given feed categories (e.g. "Fire",
+// "Met", "Flood"), matched case-insensitively. With none every category is
+// kept.
+func (c *Client) SetCategories(categories []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.categories = nil
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" {
+			continue
+		}
+		if c.categories == nil {
+			c.categories = make(map[string]bool)
+		}
+		c.categories[category] = true
+	}
+
+	// Alerts already loaded from the cache were filtered without them
+	if c.cachedAlerts != nil {
+		kept := make([]Alert, 0, len(c.cachedAlerts))
+		for _, alert := range c.cachedAlerts {
+			if c.includesCategory(alert.Category) {
+				kept = append(kept, alert)
+			}
+		}
+		c.cachedAlerts = kept
+	}
+}
+
+// includesCategory reports whether alerts in category pass SetCategories.
+func (c *Client) includesCategory(category string) bool {
+	return c.categories == nil || c.categories[strings.ToLower(category)]
+}
+
+// SetCacheDir keeps a copy of the events feed in dir, so the client can make
+// conditional requests across restarts. The directory is created on first
+// write.
+func (c *Client) SetCacheDir(dir string) {
+	c.cache = &feedCache{dir: dir}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cachedAlerts != nil {
+		return
+	}
+	body, validators, ok := c.cache.load()
+	if !ok {
+		return
+	}
+	alerts, err := c.parseFeed(body)
+	if err != nil {
+		log.Printf("emergency: ignoring cached feed: %v", err)
+		return
+	}
+	c.cachedAlerts = alerts
+	c.validators = validators
+}
+
+// Fetch retrieves fresh alerts from VicEmergency. When the last fetch left an
+// ETag or Last-Modified it makes a conditional request, and a 304 returns the
+// previously parsed alerts.
 func (c *Client) Fetch(ctx context.Context) ([]Alert, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", eventsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.eventsURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	req.Header.Set("User-Agent", "WandiWeather/1.0 (weather site for Wandiligong)")
+
+	c.mu.RLock()
+	validators, haveParse := c.validators, c.cachedAlerts != nil
+	c.mu.RUnlock()
+	if haveParse {
+		validators.apply(req)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -101,25 +187,59 @@ func (c *Client) Fetch(ctx context.Context) ([]Alert, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && haveParse {
+		c.mu.Lock()
+		alerts := c.cachedAlerts
+		c.lastFetch = time.Now()
+		c.mu.Unlock()
+		return alerts, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 
-	var geoJSON GeoJSON
-	if err := json.NewDecoder(resp.Body).Decode(&geoJSON); err != nil {
-		return nil, fmt.Errorf("decode geojson: %w", err)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read events: %w", err)
+	}
+	alerts, err := c.parseFeed(body)
+	if err != nil {
+		return nil, err
 	}
 
-	alerts := c.filterAlerts(geoJSON.Features)
+	validators = feedValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if c.cache != nil {
+		if err := c.cache.save(body, validators); err != nil {
+			log.Printf("emergency: cache feed: %v", err)
+		}
+	}
 
 	c.mu.Lock()
 	c.cachedAlerts = alerts
 	c.lastFetch = time.Now()
+	c.validators = validators
 	c.mu.Unlock()
 
 	return alerts, nil
 }
 
+// parseFeed decodes the events GeoJSON and filters it to nearby alerts. The
+// result is never nil, so an empty feed still counts as a parse.
+func (c *Client) parseFeed(body []byte) ([]Alert, error) {
+	var geoJSON GeoJSON
+	if err := json.Unmarshal(body, &geoJSON); err != nil {
+		return nil, fmt.Errorf("decode geojson: %w", err)
+	}
+	alerts := c.filterAlerts(geoJSON.Features)
+	if alerts == nil {
+		alerts = []Alert{}
+	}
+	return alerts, nil
+}
+
 // CachedAlerts returns the last fetched alerts without making a network request.
 func (c *Client) CachedAlerts() []Alert {
 	c.mu.RLock()
@@ -138,6 +258,9 @@ func (c *Client) filterAlerts(features []Feature) []Alert {
 		if feedType != "warning" && feedType != "incident" {
 			continue
 		}
+		if !c.includesCategory(f.Properties.Category1) {
+			continue
+		}
 
 		// Skip if already seen (dedupe by ID)
 		id := string(f.Properties.ID)
@@ -191,12 +314,16 @@ func (c *Client) filterAlerts(features []Feature) []Alert {
 		alerts = append(alerts, alert)
 	}
 
-	// Sort by severity (most urgent first), then distance
+	// Sort by severity (most urgent first), then distance, then most recently
+	// updated so the freshest of equally relevant alerts leads
 	sort.Slice(alerts, func(i, j int) bool {
 		if alerts[i].Severity != alerts[j].Severity {
 			return alerts[i].Severity < alerts[j].Severity
 		}
-		return alerts[i].Distance < alerts[j].Distance
+		if alerts[i].Distance != alerts[j].Distance {
+			return alerts[i].Distance < alerts[j].Distance
+		}
+		return alerts[i].Updated.After(alerts[j].Updated)
 	})
 
 	return alerts
@@ -297,6 +424,54 @@ func (a Alert) SeverityClass() string {
 	}
 }
 
+// Summary returns a short version of the alert body for the dashboard. A body
+// of at most maxChars characters is returned as is; otherwise it's the first
+// sentence if that fits, or else the body cut at the last word boundary before
+// maxChars with an ellipsis. A first word longer than maxChars is cut mid-word
+// so the summary never exceeds maxChars. There's no summary for a maxChars
+// below 1.
+func (a Alert) Summary(maxChars int) string {
+	if maxChars < 1 {
+		return ""
+	}
+	text := strings.Join(strings.Fields(a.Body), " ")
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+
+	if end := sentenceEnd(text); end > 0 && len([]rune(text[:end])) <= maxChars {
+		return text[:end]
+	}
+
+	cut := string(runes[:maxChars-1]) // leave room for the ellipsis
+	if runes[maxChars-1] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRight(cut, " ,;:-") + "…"
+}
+
+// sentenceEnd returns the index just past the first sentence's closing
+// punctuation, or 0 if text has only one sentence.
+func sentenceEnd(text string) int {
+	for i := 0; i+1 < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if text[i+1] == ' ' {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// IsLocal returns true for alerts raised from our own station data.
+func (a Alert) IsLocal() bool {
+	return a.Category == CategoryLocal
+}
+
 // IsUrgent returns true for Emergency or Watch & Act alerts.
 func (a Alert) IsUrgent() bool {
 	return a.Severity <= SeverityWatchAct
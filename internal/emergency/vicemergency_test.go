@@ -2,6 +2,8 @@ package emergency
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -66,3 +68,201 @@ func TestParseSeverity(t *testing.T) {
 }
 
 
+
+const sampleEventsGeoJSON = `{
+	"type": "FeatureCollection",
+	"features": [{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+		"properties": {
+			"feedType": "warning",
+			"id": "1234",
+			"category1": "Fire",
+			"name": "Advice",
+			"location": "Wandiligong"
+		}
+	}]
+}`
+
+func TestFilterAlerts_TieBreakByRecency(t *testing.T) {
+	// Two advice alerts at the same spot, the older one first in the feed
+	const feed = `{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+			"properties": {"feedType": "warning", "id": "older", "name": "Advice", "updated": "2026-01-10T08:00:00Z"}
+		}, {
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+			"properties": {"feedType": "warning", "id": "newer", "name": "Advice", "updated": "2026-01-10T09:30:00Z"}
+		}]
+	}`
+
+	client := NewClient(-36.794, 146.977, DefaultRadiusKM)
+	alerts, err := client.parseFeed([]byte(feed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2", len(alerts))
+	}
+	if alerts[0].ID != "newer" || alerts[1].ID != "older" {
+		t.Errorf("order = [%s %s], want [newer older]", alerts[0].ID, alerts[1].ID)
+	}
+}
+
+func TestFilterAlerts_Categories(t *testing.T) {
+	// A fire, a weather warning and a flood, all in range
+	const feed = `{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+			"properties": {"feedType": "warning", "id": "fire", "name": "Advice", "category1": "Fire"}
+		}, {
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+			"properties": {"feedType": "warning", "id": "met", "name": "Advice", "category1": "Met"}
+		}, {
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [146.98, -36.80]},
+			"properties": {"feedType": "incident", "id": "flood", "name": "Advice", "category1": "Flood"}
+		}]
+	}`
+
+	client := NewClient(-36.794, 146.977, DefaultRadiusKM)
+	alerts, err := client.parseFeed([]byte(feed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("without categories got %d alerts, want all 3", len(alerts))
+	}
+
+	client.SetCategories([]string{"Met", "fire"})
+	alerts, err = client.parseFeed([]byte(feed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, a := range alerts {
+		ids[a.ID] = true
+	}
+	if len(alerts) != 2 || !ids["fire"] || !ids["met"] {
+		t.Errorf("with Met/Fire got %v, want fire and met", ids)
+	}
+}
+
+func TestClient_FetchNotModified(t *testing.T) {
+	var requests, fullFetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullFetches++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(sampleEventsGeoJSON))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	newClient := func() *Client {
+		c := NewClient(-36.794, 146.977, DefaultRadiusKM)
+		c.eventsURL = srv.URL
+		c.SetCacheDir(dir)
+		return c
+	}
+	ctx := context.Background()
+
+	client := newClient()
+	for i := 0; i < 2; i++ {
+		alerts, err := client.Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch %d: %v", i, err)
+		}
+		if len(alerts) != 1 || alerts[0].ID != "1234" {
+			t.Fatalf("Fetch %d: alerts = %+v, want alert 1234", i, alerts)
+		}
+	}
+
+	// A restarted client picks the feed and its ETag up from disk
+	alerts, err := newClient().Fetch(ctx)
+	if err != nil {
+		t.Fatalf("Fetch after restart: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != "1234" {
+		t.Fatalf("Fetch after restart: alerts = %+v, want alert 1234", alerts)
+	}
+
+	if requests != 3 || fullFetches != 1 {
+		t.Errorf("got %d requests with %d full fetches, want 3 with 1", requests, fullFetches)
+	}
+}
+
+func TestAlertSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxChars int
+		want     string
+	}{
+		{
+			name:     "short body unchanged",
+			body:     "Smoke may be visible from Bright. No action required.",
+			maxChars: 160,
+			want:     "Smoke may be visible from Bright. No action required.",
+		},
+		{
+			name: "long body gives first sentence",
+			body: "A bushfire is burning near Wandiligong and is not yet under control. " +
+				"Leave now if you are not prepared. Conditions are changing quickly and " +
+				"embers may start spot fires ahead of the main fire front.",
+			maxChars: 100,
+			want:     "A bushfire is burning near Wandiligong and is not yet under control.",
+		},
+		{
+			name:     "long sentence truncated at a word boundary",
+			body:     "Smoke from planned burns in the Alpine National Park may affect the Ovens Valley over the weekend",
+			maxChars: 40,
+			want:     "Smoke from planned burns in the Alpine…",
+		},
+		{
+			name:     "first word longer than the limit cut mid-word",
+			body:     "https://emergency.vic.gov.au/respond/#!/warning/12345/moreinfo for details",
+			maxChars: 20,
+			want:     "https://emergency.v…",
+		},
+		{
+			name:     "whitespace collapsed",
+			body:     "Roads closed.\n\n  Avoid the area.",
+			maxChars: 160,
+			want:     "Roads closed. Avoid the area.",
+		},
+		{
+			name:     "no room",
+			body:     "Roads closed.",
+			maxChars: 0,
+			want:     "",
+		},
+		{
+			name:     "negative limit",
+			body:     "Roads closed.",
+			maxChars: -5,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Alert{Body: tt.body}.Summary(tt.maxChars)
+			if got != tt.want {
+				t.Errorf("Summary(%d) = %q, want %q", tt.maxChars, got, tt.want)
+			}
+			if n := len([]rune(got)); n > max(tt.maxChars, 0) {
+				t.Errorf("Summary(%d) is %d characters", tt.maxChars, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package emergency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lox/wandiweather/internal/httputil"
+)
+
+// Webhook events.
+const (
+	WebhookNew       = "new"
+	WebhookEscalated = "escalated"
+)
+
+// WebhookPayload is the JSON body POSTed to the alert webhook.
+type WebhookPayload struct {
+	Event       string  `json:"event"` // WebhookNew or WebhookEscalated
+	ID          string  `json:"id"`
+	Category    string  `json:"category"`
+	SubCategory string  `json:"subcategory,omitempty"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status,omitempty"`
+	Severity    string  `json:"severity"`
+	Location    string  `json:"location"`
+	DistanceKM  float64 `json:"distance_km"`
+	Headline    string  `json:"headline,omitempty"`
+	URL         string  `json:"url,omitempty"`
+}
+
+// Webhook POSTs alerts to a URL as they appear or escalate.
+type Webhook struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhook creates a notifier that POSTs to url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:        url,
+		httpClient: httputil.NewClientWithOptions(httputil.DefaultTimeout, userAgent),
+	}
+}
+
+// Notify POSTs alert to the webhook as event, failing on any non-2xx response.
+func (w *Webhook) Notify(ctx context.Context, alert Alert, event string) error {
+	payload := WebhookPayload{
+		Event:       event,
+		ID:          alert.ID,
+		Category:    alert.Category,
+		SubCategory: alert.SubCategory,
+		Name:        alert.Name,
+		Status:      alert.Status,
+		Severity:    alert.SeverityName(),
+		Location:    alert.Location,
+		DistanceKM:  alert.Distance,
+		Headline:    alert.Headline,
+		URL:         alert.URL,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return nil
+}
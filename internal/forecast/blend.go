@@ -0,0 +1,73 @@
+package forecast
+
+import (
+	"database/sql"
+	"math"
+
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
+)
+
+// minSkillMAE floors a source's MAE when weighting, so a short run of near
+// perfect forecasts can't take all of the blend.
+const minSkillMAE = 0.5
+
+// SkillKey is the key for a source's recent mean absolute error on target
+// ("tmax" or "tmin") in the skill map given to BlendForecasts.
+func SkillKey(source, target string) string {
+	return source + "/" + target
+}
+
+// SkillFromCorrectionStats builds a BlendForecasts skill map from the recent
+// verification MAE in the correction stats for dayOfForecast. Sources with
+// too few samples for a bias correction are left out.
+func SkillFromCorrectionStats(stats store.CorrectionStatsMap, dayOfForecast int) map[string]float64 {
+	skill := make(map[string]float64)
+	for source, targets := range stats {
+		for target, days := range targets {
+			if s := days[dayOfForecast]["all"]; s != nil && s.SampleSize >= minBiasSamples {
+				skill[SkillKey(source, target)] = s.MAE
+			}
+		}
+	}
+	return skill
+}
+
+// BlendForecasts combines the WU and BOM max and min temperatures, weighting
+// each source inversely to its recent MAE from skill (see SkillKey). When
+// either source's MAE for a target is unknown the two are averaged equally,
+// and when only one source has a temperature it is used as is. A temperature
+// neither source has comes back as NaN.
+func BlendForecasts(wu, bom *models.Forecast, skill map[string]float64) (blendedMax, blendedMin float64) {
+	var wuMax, wuMin, bomMax, bomMin sql.NullFloat64
+	if wu != nil {
+		wuMax, wuMin = wu.TempMax, wu.TempMin
+	}
+	if bom != nil {
+		bomMax, bomMin = bom.TempMax, bom.TempMin
+	}
+
+	blendedMax = blendTemp(wuMax, bomMax, skill[SkillKey("wu", "tmax")], skill[SkillKey("bom", "tmax")])
+	blendedMin = blendTemp(wuMin, bomMin, skill[SkillKey("wu", "tmin")], skill[SkillKey("bom", "tmin")])
+	return blendedMax, blendedMin
+}
+
+// blendTemp weights two temperatures by the inverse of their sources' MAE. A
+// non-positive MAE means the skill is unknown.
+func blendTemp(wu, bom sql.NullFloat64, wuMAE, bomMAE float64) float64 {
+	switch {
+	case !wu.Valid && !bom.Valid:
+		return math.NaN()
+	case !bom.Valid:
+		return wu.Float64
+	case !wu.Valid:
+		return bom.Float64
+	}
+
+	wuWeight, bomWeight := 1.0, 1.0
+	if wuMAE > 0 && bomMAE > 0 {
+		wuWeight = 1 / math.Max(wuMAE, minSkillMAE)
+		bomWeight = 1 / math.Max(bomMAE, minSkillMAE)
+	}
+	return (wu.Float64*wuWeight + bom.Float64*bomWeight) / (wuWeight + bomWeight)
+}
@@ -0,0 +1,142 @@
+package forecast
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
+)
+
+func TestBlendForecasts(t *testing.T) {
+	temp := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	wu := &models.Forecast{TempMax: temp(30), TempMin: temp(10)}
+	bom := &models.Forecast{TempMax: temp(26), TempMin: temp(14)}
+
+	tests := []struct {
+		name             string
+		wu, bom          *models.Forecast
+		skill            map[string]float64
+		wantMax, wantMin float64
+	}{
+		{
+			name: "BOM more skilful on max, WU on min",
+			wu:   wu, bom: bom,
+			skill: map[string]float64{
+				SkillKey("wu", "tmax"): 3, SkillKey("bom", "tmax"): 1,
+				SkillKey("wu", "tmin"): 1, SkillKey("bom", "tmin"): 3,
+			},
+			wantMax: 27, // weights 1/3 and 1: (30/3 + 26) / (4/3)
+			wantMin: 11,
+		},
+		{
+			name: "unknown skill averages equally",
+			wu:   wu, bom: bom,
+			skill:   map[string]float64{SkillKey("bom", "tmax"): 1},
+			wantMax: 28,
+			wantMin: 12,
+		},
+		{
+			name: "tiny MAE is floored",
+			wu:   wu, bom: bom,
+			skill: map[string]float64{
+				SkillKey("wu", "tmax"): 0.5, SkillKey("bom", "tmax"): 0.01,
+			},
+			wantMax: 28,
+			wantMin: 12,
+		},
+		{
+			name:    "only one source",
+			wu:      wu,
+			skill:   map[string]float64{SkillKey("wu", "tmax"): 5, SkillKey("bom", "tmax"): 1},
+			wantMax: 30,
+			wantMin: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMax, gotMin := BlendForecasts(tt.wu, tt.bom, tt.skill)
+			if math.Abs(gotMax-tt.wantMax) > 1e-9 || math.Abs(gotMin-tt.wantMin) > 1e-9 {
+				t.Errorf("BlendForecasts = %.2f/%.2f, want %.2f/%.2f", gotMax, gotMin, tt.wantMax, tt.wantMin)
+			}
+		})
+	}
+
+	if gotMax, _ := BlendForecasts(nil, nil, nil); !math.IsNaN(gotMax) {
+		t.Errorf("BlendForecasts with no forecasts = %v, want NaN", gotMax)
+	}
+}
+
+func TestComputeTodayTemps_Blend(t *testing.T) {
+	stats := store.CorrectionStatsMap{
+		"wu":  {"tmax": {0: {"all": {SampleSize: 20, MAE: 4, MeanBias: 1}}}},
+		"bom": {"tmax": {0: {"all": {SampleSize: 20, MAE: 1, MeanBias: 0}}}},
+	}
+	input := TodayTempInput{
+		WUForecast:      &models.Forecast{TempMax: sql.NullFloat64{Float64: 35, Valid: true}},
+		BOMForecast:     &models.Forecast{TempMax: sql.NullFloat64{Float64: 30, Valid: true}},
+		CorrectionStats: stats,
+		Blend:           true,
+		Skill:           SkillFromCorrectionStats(stats, 0),
+	}
+
+	result := ComputeTodayTemps(input)
+	// Raw blend (35/4 + 30) / 1.25 = 31; WU's +1 bias takes 0.2 off
+	if result.Explanation.MaxSource != "blend" {
+		t.Errorf("MaxSource = %q, want blend", result.Explanation.MaxSource)
+	}
+	if math.Abs(result.Explanation.MaxRaw-31) > 1e-9 {
+		t.Errorf("MaxRaw = %v, want 31", result.Explanation.MaxRaw)
+	}
+	if math.Abs(result.Explanation.MaxBiasApplied-0.2) > 1e-9 {
+		t.Errorf("MaxBiasApplied = %v, want 0.2", result.Explanation.MaxBiasApplied)
+	}
+	if result.TempMax != 31 {
+		t.Errorf("TempMax = %v, want 31", result.TempMax)
+	}
+
+	// With blending off the usual rules pick BOM
+	input.Blend = false
+	if result := ComputeTodayTemps(input); result.Explanation.MaxSource != "bom" || result.TempMax != 30 {
+		t.Errorf("unblended = %s %v, want bom 30", result.Explanation.MaxSource, result.TempMax)
+	}
+}
+
+func TestComputeTodayTemps_BlendSkipsRejectedBOM(t *testing.T) {
+	maxOf := func(v float64) *models.Forecast {
+		return &models.Forecast{TempMax: sql.NullFloat64{Float64: v, Valid: true}}
+	}
+	tests := []struct {
+		name  string
+		input TodayTempInput
+	}{
+		{
+			name: "current temp already past BOM",
+			input: TodayTempInput{
+				WUForecast:     maxOf(30),
+				BOMForecast:    maxOf(24),
+				CurrentTemp:    28,
+				HasCurrentTemp: true,
+			},
+		},
+		{
+			name: "BOM and WU far apart",
+			input: TodayTempInput{
+				WUForecast:  maxOf(30),
+				BOMForecast: maxOf(18),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Blend = true
+			tt.input.Skill = map[string]float64{SkillKey("wu", "tmax"): 1, SkillKey("bom", "tmax"): 1}
+			result := ComputeTodayTemps(tt.input)
+			if result.Explanation.MaxSource != "wu" || result.TempMax != 30 {
+				t.Errorf("max = %s %v, want wu 30", result.Explanation.MaxSource, result.TempMax)
+			}
+		})
+	}
+}
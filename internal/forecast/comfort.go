@@ -0,0 +1,49 @@
+package forecast
+
+import "math"
+
+// HeatStressApparentTemp is the apparent temperature (°C) at or above which
+// conditions are flagged as heat stress.
+const HeatStressApparentTemp = 35.0
+
+// Dewpoint comfort levels returned by DewpointComfort.
+const (
+	ComfortDry         = "dry"
+	ComfortComfortable = "comfortable"
+	ComfortHumid       = "humid"
+	ComfortMuggy       = "muggy"
+	ComfortOppressive  = "oppressive"
+)
+
+// ApparentTemperature returns the Steadman apparent temperature (°C) used by
+// the Bureau of Meteorology, from air temperature (°C), relative humidity (%)
+// and wind speed (km/h). It accounts for humidity and wind but not sunshine.
+func ApparentTemperature(tempC, humidity, windKmh float64) float64 {
+	vapourPressure := humidity / 100 * 6.105 * math.Exp(17.27*tempC/(237.7+tempC))
+	windMs := windKmh / 3.6
+	return tempC + 0.33*vapourPressure - 0.70*windMs - 4.00
+}
+
+// Dewpoint estimates the dewpoint (°C) from air temperature (°C) and relative
+// humidity (%) using the Magnus formula.
+func Dewpoint(tempC, humidity float64) float64 {
+	const b, c = 17.27, 237.7
+	gamma := math.Log(humidity/100) + b*tempC/(c+tempC)
+	return c * gamma / (b - gamma)
+}
+
+// DewpointComfort describes how humid the air feels at a given dewpoint (°C).
+func DewpointComfort(dewpoint float64) string {
+	switch {
+	case dewpoint < 10:
+		return ComfortDry
+	case dewpoint < 16:
+		return ComfortComfortable
+	case dewpoint < 19:
+		return ComfortHumid
+	case dewpoint < 22:
+		return ComfortMuggy
+	default:
+		return ComfortOppressive
+	}
+}
@@ -0,0 +1,54 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApparentTemperature(t *testing.T) {
+	tests := []struct {
+		name                 string
+		temp, humidity, wind float64
+		want                 float64
+	}{
+		{"hot and humid", 36, 60, 5, 42.7},
+		{"cool and breezy", 15, 50, 20, 9.9},
+		{"still and dry", 25, 20, 0, 23.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApparentTemperature(tt.temp, tt.humidity, tt.wind)
+			if math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("ApparentTemperature(%v, %v, %v) = %.2f, want %.1f", tt.temp, tt.humidity, tt.wind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDewpoint(t *testing.T) {
+	if got := Dewpoint(20, 100); math.Abs(got-20) > 0.01 {
+		t.Errorf("Dewpoint(20, 100) = %.2f, want 20", got)
+	}
+	if got := Dewpoint(25, 50); math.Abs(got-13.9) > 0.2 {
+		t.Errorf("Dewpoint(25, 50) = %.2f, want about 13.9", got)
+	}
+}
+
+func TestDewpointComfort(t *testing.T) {
+	tests := []struct {
+		dewpoint float64
+		want     string
+	}{
+		{5, ComfortDry},
+		{10, ComfortComfortable},
+		{16, ComfortHumid},
+		{19, ComfortMuggy},
+		{22, ComfortOppressive},
+	}
+	for _, tt := range tests {
+		if got := DewpointComfort(tt.dewpoint); got != tt.want {
+			t.Errorf("DewpointComfort(%v) = %q, want %q", tt.dewpoint, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"math"
 	"strings"
 	"time"
+
+	"github.com/lox/wandiweather/internal/models"
 )
 
 // WeatherCondition represents a categorized weather state for image generation.
@@ -191,6 +193,74 @@ func ExtractCondition(narrative string, tempMax, tempMin float64) WeatherConditi
 	return ConditionClearCool
 }
 
+// RefineConditionWithCloudiness adjusts a clear or cloudy condition to match an
+// observed cloud fraction (see CloudinessFromSolar). Precipitation, fog and
+// temperature-extreme conditions are left alone, as is any negative cloudiness.
+func RefineConditionWithCloudiness(condition WeatherCondition, cloudiness, tempMax float64) WeatherCondition {
+	if cloudiness < 0 {
+		return condition
+	}
+	switch condition {
+	case ConditionClearWarm, ConditionClearCool, ConditionPartlyCloudy, ConditionMostlyCloudy:
+	default:
+		return condition
+	}
+
+	switch {
+	case cloudiness >= 0.7:
+		return ConditionMostlyCloudy
+	case cloudiness >= 0.3:
+		return ConditionPartlyCloudy
+	case tempMax >= 25:
+		return ConditionClearWarm
+	default:
+		return ConditionClearCool
+	}
+}
+
+// Thresholds for deriving a condition from an observation alone.
+const (
+	obsHotTemp      = 35.0 // °C
+	obsFrostTemp    = 2.0  // °C
+	obsFogHumidity  = 97   // %
+	obsFogDewSpread = 1.0  // °C between temperature and dewpoint
+)
+
+// ConditionFromObservation derives a condition from what a station is
+// measuring, for when there's no forecast to go on: rain from the rain rate,
+// hot or frost from the temperature, fog from near-saturated air, and cloud
+// from cloudiness (see CloudinessFromSolar). With no rain, extreme or fog and
+// an unknown cloudiness it returns ConditionPartlyCloudy, which makes no
+// claim about clear skies.
+func ConditionFromObservation(obs models.Observation, cloudiness float64) WeatherCondition {
+	if obs.PrecipRate.Valid {
+		switch RainIntensity(obs.PrecipRate.Float64) {
+		case RainHeavy, RainViolent:
+			return ConditionHeavyRain
+		case RainLight, RainModerate:
+			return ConditionLightRain
+		}
+	}
+
+	temp := 20.0
+	if obs.Temp.Valid {
+		temp = obs.Temp.Float64
+		if temp >= obsHotTemp {
+			return ConditionHot
+		}
+		if temp <= obsFrostTemp {
+			return ConditionFrost
+		}
+	}
+
+	if obs.Temp.Valid && obs.Humidity.Valid && obs.Dewpoint.Valid &&
+		obs.Humidity.Int64 >= obsFogHumidity && obs.Temp.Float64-obs.Dewpoint.Float64 <= obsFogDewSpread {
+		return ConditionFog
+	}
+
+	return RefineConditionWithCloudiness(ConditionPartlyCloudy, cloudiness, temp)
+}
+
 // ConditionWithTime combines a weather condition with time of day for cache keys.
 func ConditionWithTime(condition WeatherCondition, tod TimeOfDay) WeatherCondition {
 	return WeatherCondition(fmt.Sprintf("%s_%s", condition, tod))
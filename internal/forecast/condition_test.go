@@ -1,9 +1,12 @@
 package forecast
 
 import (
+	"database/sql"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/lox/wandiweather/internal/models"
 )
 
 func TestExtractCondition(t *testing.T) {
@@ -309,3 +312,34 @@ func TestBuildPrompt_UnknownCondition(t *testing.T) {
 		t.Error("BuildPrompt with unknown condition should still return a prompt")
 	}
 }
+
+func TestConditionFromObservation(t *testing.T) {
+	f := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	tests := []struct {
+		name       string
+		obs        models.Observation
+		cloudiness float64
+		want       WeatherCondition
+	}{
+		{"light rain", models.Observation{Temp: f(14), PrecipRate: f(1.2)}, 0.9, ConditionLightRain},
+		{"moderate rain is light rain", models.Observation{Temp: f(14), PrecipRate: f(6)}, -1, ConditionLightRain},
+		{"heavy rain", models.Observation{Temp: f(14), PrecipRate: f(18)}, -1, ConditionHeavyRain},
+		{"rain beats frost", models.Observation{Temp: f(1), PrecipRate: f(0.5)}, -1, ConditionLightRain},
+		{"hot", models.Observation{Temp: f(37), PrecipRate: f(0)}, 0, ConditionHot},
+		{"frost", models.Observation{Temp: f(-1.5)}, -1, ConditionFrost},
+		{"fog", models.Observation{Temp: f(6), Dewpoint: f(5.6), Humidity: sql.NullInt64{Int64: 98, Valid: true}}, -1, ConditionFog},
+		{"humid but not saturated", models.Observation{Temp: f(18), Dewpoint: f(15), Humidity: sql.NullInt64{Int64: 97, Valid: true}}, 0.1, ConditionClearCool},
+		{"overcast", models.Observation{Temp: f(16)}, 0.8, ConditionMostlyCloudy},
+		{"sunny and warm", models.Observation{Temp: f(27)}, 0.05, ConditionClearWarm},
+		{"unknown cloud at night", models.Observation{Temp: f(12)}, -1, ConditionPartlyCloudy},
+		{"empty observation", models.Observation{}, -1, ConditionPartlyCloudy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConditionFromObservation(tt.obs, tt.cloudiness); got != tt.want {
+				t.Errorf("ConditionFromObservation = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
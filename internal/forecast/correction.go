@@ -22,50 +22,78 @@ func NewBiasCorrector(s *store.Store) *BiasCorrector {
 	return &BiasCorrector{store: s}
 }
 
-func (c *BiasCorrector) ComputeStats(windowDays int) error {
+// ComputeStats refreshes the correction stats from the last windowDays of
+// verification and returns how many it updated.
+func (c *BiasCorrector) ComputeStats(windowDays int) (updated int, err error) {
 	rows, err := c.store.GetBiasStatsFromVerification(windowDays)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	regimeRows, err := c.store.GetBiasStatsByRegime(windowDays)
+	if err != nil {
+		return 0, err
 	}
 
 	now := time.Now().UTC()
 	for _, row := range rows {
-		if row.CountMax > 0 {
-			stats := store.CorrectionStats{
-				Source:        row.Source,
-				Target:        "tmax",
-				DayOfForecast: row.DayOfForecast,
-				Regime:        "all",
-				WindowDays:    windowDays,
-				SampleSize:    row.CountMax,
-				MeanBias:      row.AvgBiasMax,
-				MAE:           row.MAEMax,
-				UpdatedAt:     now,
-			}
-			if err := c.store.UpsertCorrectionStats(stats); err != nil {
-				return err
-			}
+		row.Regime = "all"
+		n, err := c.upsertStats(row, windowDays, now)
+		updated += n
+		if err != nil {
+			return updated, err
 		}
+	}
+	for _, row := range regimeRows {
+		n, err := c.upsertStats(row, windowDays, now)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+	}
 
-		if row.CountMin > 0 {
-			stats := store.CorrectionStats{
-				Source:        row.Source,
-				Target:        "tmin",
-				DayOfForecast: row.DayOfForecast,
-				Regime:        "all",
-				WindowDays:    windowDays,
-				SampleSize:    row.CountMin,
-				MeanBias:      row.AvgBiasMin,
-				MAE:           row.MAEMin,
-				UpdatedAt:     now,
-			}
-			if err := c.store.UpsertCorrectionStats(stats); err != nil {
-				return err
-			}
+	return updated, nil
+}
+
+func (c *BiasCorrector) upsertStats(row store.BiasRow, windowDays int, now time.Time) (int, error) {
+	var updated int
+	if row.CountMax > 0 {
+		stats := store.CorrectionStats{
+			Source:        row.Source,
+			Target:        "tmax",
+			DayOfForecast: row.DayOfForecast,
+			Regime:        row.Regime,
+			WindowDays:    windowDays,
+			SampleSize:    row.CountMax,
+			MeanBias:      row.AvgBiasMax,
+			MAE:           row.MAEMax,
+			UpdatedAt:     now,
+		}
+		if err := c.store.UpsertCorrectionStats(stats); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	if row.CountMin > 0 {
+		stats := store.CorrectionStats{
+			Source:        row.Source,
+			Target:        "tmin",
+			DayOfForecast: row.DayOfForecast,
+			Regime:        row.Regime,
+			WindowDays:    windowDays,
+			SampleSize:    row.CountMin,
+			MeanBias:      row.AvgBiasMin,
+			MAE:           row.MAEMin,
+			UpdatedAt:     now,
+		}
+		if err := c.store.UpsertCorrectionStats(stats); err != nil {
+			return updated, err
 		}
+		updated++
 	}
 
-	return nil
+	return updated, nil
 }
 
 func (c *BiasCorrector) GetCorrection(source string, target string, dayOfForecast int) float64 {
@@ -0,0 +1,123 @@
+package forecast
+
+import (
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+// StandardLapseRate is the standard atmosphere's environmental lapse rate in
+// °C per metre. Inversion detection can be given a different rate, as valleys
+// often run a stronger gradient at night.
+const StandardLapseRate = 6.5 / 1000.0
+
+// inversionMargin is how far the observed valley/upper difference must exceed
+// the expected difference before an inversion is reported.
+const inversionMargin = 2.0
+
+// Inversion describes the current valley/upper temperature relationship.
+type Inversion struct {
+	Active       bool
+	Strength     float64 // observed difference minus expected difference
+	ValleyAvg    float64
+	UpperAvg     float64
+	ExpectedDiff float64 // expected difference from the lapse rate
+}
+
+// InversionStatus compares the average valley and upper temperatures against the
+// difference expected from lapseRate (°C per metre, usually StandardLapseRate)
+// between the two reference elevations. It returns nil if either tier has no
+// readings.
+func InversionStatus(valleyTemps, upperTemps []float64, valleyElev, upperElev, lapseRate float64) *Inversion {
+	if len(valleyTemps) == 0 || len(upperTemps) == 0 {
+		return nil
+	}
+
+	valleyAvg := mean(valleyTemps)
+	upperAvg := mean(upperTemps)
+	expectedDiff := (upperElev - valleyElev) * lapseRate
+	actualDiff := upperAvg - valleyAvg
+
+	return &Inversion{
+		Active:       actualDiff > expectedDiff+inversionMargin,
+		Strength:     actualDiff - expectedDiff,
+		ValleyAvg:    valleyAvg,
+		UpperAvg:     upperAvg,
+		ExpectedDiff: expectedDiff,
+	}
+}
+
+// Offsets from sunset to inversion onset and from sunrise to breakup, for the
+// weakest and strongest radiative nights. Clear, calm nights decouple the
+// valley soon after sunset and take longer to mix out after sunrise.
+const (
+	onsetDelayMarginal   = 3 * time.Hour
+	onsetDelayStrong     = 30 * time.Minute
+	breakupDelayMarginal = 90 * time.Minute
+	breakupDelayStrong   = 3*time.Hour + 30*time.Minute
+)
+
+// minInversionScore is the radiative score below which a night is too cloudy,
+// wet or windy for an inversion to be expected.
+const minInversionScore = 0.25
+
+// InversionTiming estimates when a night's valley inversion will form and
+// break, given the day's summary as a guide to how clear and calm the night
+// will be, tonight's sunset and tomorrow's sunrise. Both times are zero if
+// conditions are too mixed for an inversion to form.
+func InversionTiming(summary models.DailySummary, sunrise, sunset time.Time) (onset, breakup time.Time) {
+	score := radiativeScore(summary)
+	if score < minInversionScore {
+		return time.Time{}, time.Time{}
+	}
+
+	onset = sunset.Add(lerpDuration(onsetDelayMarginal, onsetDelayStrong, score))
+	breakup = sunrise.Add(lerpDuration(breakupDelayMarginal, breakupDelayStrong, score))
+	return onset, breakup
+}
+
+// radiativeScore rates how favourable conditions are for radiative cooling,
+// from 0 (overcast, windy or wet) to 1 (clear and calm). Missing readings
+// count as middling.
+func radiativeScore(summary models.DailySummary) float64 {
+	calm := 0.3
+	if summary.CalmFractionNight.Valid {
+		calm = clamp01(summary.CalmFractionNight.Float64 / 0.8)
+	}
+	// A clear day in the valley integrates around 25 MJ/m²
+	clearSky := 0.5
+	if summary.SolarIntegral.Valid {
+		clearSky = clamp01(summary.SolarIntegral.Float64 / 25)
+	}
+
+	score := (calm + clearSky) / 2
+	if summary.PrecipTotal.Valid && summary.PrecipTotal.Float64 >= 0.5 {
+		score /= 2
+	}
+	return score
+}
+
+func lerpDuration(from, to time.Duration, frac float64) time.Duration {
+	return from + time.Duration(float64(to-from)*frac)
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func mean(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
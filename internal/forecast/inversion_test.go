@@ -0,0 +1,151 @@
+package forecast
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestInversionStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		valleyTemps  []float64
+		upperTemps   []float64
+		valleyElev   float64
+		upperElev    float64
+		wantNil      bool
+		wantActive   bool
+		wantStrength float64
+	}{
+		{
+			name:         "strong inversion",
+			valleyTemps:  []float64{2, 4},
+			upperTemps:   []float64{10},
+			valleyElev:   313,
+			upperElev:    543,
+			wantActive:   true,
+			wantStrength: 7 - 230*StandardLapseRate,
+		},
+		{
+			name:         "normal lapse",
+			valleyTemps:  []float64{15},
+			upperTemps:   []float64{13.5},
+			valleyElev:   313,
+			upperElev:    543,
+			wantActive:   false,
+			wantStrength: -1.5 - 230*StandardLapseRate,
+		},
+		{
+			name:         "expected diff adapts to elevations",
+			valleyTemps:  []float64{5},
+			upperTemps:   []float64{9},
+			valleyElev:   100,
+			upperElev:    700,
+			wantActive:   false, // 4°C warmer is within 3.9 + 2 margin over 600m
+			wantStrength: 4 - 600*StandardLapseRate,
+		},
+		{
+			name:        "no upper readings",
+			valleyTemps: []float64{5},
+			upperTemps:  nil,
+			wantNil:     true,
+		},
+		{
+			name:        "no valley readings",
+			valleyTemps: nil,
+			upperTemps:  []float64{5},
+			wantNil:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InversionStatus(tt.valleyTemps, tt.upperTemps, tt.valleyElev, tt.upperElev, StandardLapseRate)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("InversionStatus = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("InversionStatus = nil, want result")
+			}
+			if got.Active != tt.wantActive {
+				t.Errorf("Active = %v, want %v", got.Active, tt.wantActive)
+			}
+			if math.Abs(got.Strength-tt.wantStrength) > 0.001 {
+				t.Errorf("Strength = %.3f, want %.3f", got.Strength, tt.wantStrength)
+			}
+		})
+	}
+}
+
+func TestInversionStatus_LapseRate(t *testing.T) {
+	// A 3°C warmer upper tier across 230m falls inside the margin at the
+	// standard rate but counts as an inversion at a shallower 3°C/km.
+	valley, upper := []float64{5}, []float64{8}
+
+	standard := InversionStatus(valley, upper, 313, 543, StandardLapseRate)
+	shallow := InversionStatus(valley, upper, 313, 543, 3.0/1000)
+
+	if standard.Active {
+		t.Errorf("expected no inversion at the standard lapse rate, got strength %v", standard.Strength)
+	}
+	if !shallow.Active {
+		t.Errorf("expected an inversion at 3°C/km, got strength %v", shallow.Strength)
+	}
+	if math.Abs(standard.ExpectedDiff-230*StandardLapseRate) > 1e-9 {
+		t.Errorf("standard ExpectedDiff = %v, want %v", standard.ExpectedDiff, 230*StandardLapseRate)
+	}
+	if math.Abs(shallow.ExpectedDiff-0.69) > 1e-9 {
+		t.Errorf("shallow ExpectedDiff = %v, want 0.69", shallow.ExpectedDiff)
+	}
+}
+
+func TestInversionTiming(t *testing.T) {
+	mel, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip("Australia/Melbourne timezone not available")
+	}
+	sunset := time.Date(2026, 1, 15, 20, 40, 0, 0, mel)
+	sunrise := time.Date(2026, 1, 16, 6, 10, 0, 0, mel)
+
+	strong := models.DailySummary{
+		PrecipTotal:       sql.NullFloat64{Float64: 0, Valid: true},
+		SolarIntegral:     sql.NullFloat64{Float64: 28, Valid: true},
+		CalmFractionNight: sql.NullFloat64{Float64: 0.8, Valid: true},
+	}
+	marginal := models.DailySummary{
+		PrecipTotal:       sql.NullFloat64{Float64: 0, Valid: true},
+		SolarIntegral:     sql.NullFloat64{Float64: 12, Valid: true},
+		CalmFractionNight: sql.NullFloat64{Float64: 0.3, Valid: true},
+	}
+	wetWindy := models.DailySummary{
+		PrecipTotal:       sql.NullFloat64{Float64: 8, Valid: true},
+		SolarIntegral:     sql.NullFloat64{Float64: 5, Valid: true},
+		CalmFractionNight: sql.NullFloat64{Float64: 0.05, Valid: true},
+	}
+
+	strongOnset, strongBreakup := InversionTiming(strong, sunrise, sunset)
+	marginalOnset, marginalBreakup := InversionTiming(marginal, sunrise, sunset)
+
+	if strongOnset.IsZero() || marginalOnset.IsZero() {
+		t.Fatalf("expected onsets for strong and marginal nights, got %v and %v", strongOnset, marginalOnset)
+	}
+	if !strongOnset.Before(marginalOnset) {
+		t.Errorf("strong onset %v should be before marginal onset %v", strongOnset, marginalOnset)
+	}
+	if !strongBreakup.After(marginalBreakup) {
+		t.Errorf("strong breakup %v should be after marginal breakup %v", strongBreakup, marginalBreakup)
+	}
+	if strongOnset.Before(sunset) || strongBreakup.Before(sunrise) {
+		t.Errorf("onset %v / breakup %v should follow sunset %v / sunrise %v", strongOnset, strongBreakup, sunset, sunrise)
+	}
+
+	if onset, breakup := InversionTiming(wetWindy, sunrise, sunset); !onset.IsZero() || !breakup.IsZero() {
+		t.Errorf("expected no inversion on a wet, windy night, got %v to %v", onset, breakup)
+	}
+}
@@ -1,21 +1,27 @@
 package forecast
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
 // Palette defines the color scheme for a weather condition + time of day.
 type Palette struct {
 	// Background is the main page background color
-	Background string
+	Background string `json:"background"`
 	// Card is the background for cards/panels
-	Card string
+	Card string `json:"card"`
 	// CardBorder is an optional border/highlight for cards
-	CardBorder string
+	CardBorder string `json:"card_border"`
 	// Text is the primary text color
-	Text string
+	Text string `json:"text"`
 	// TextMuted is the secondary/muted text color
-	TextMuted string
+	TextMuted string `json:"text_muted"`
 	// Accent is the primary accent color (links, highlights)
-	Accent string
+	Accent string `json:"accent"`
 	// AccentAlt is a secondary accent (temperature high, etc.)
-	AccentAlt string
+	AccentAlt string `json:"accent_alt"`
 }
 
 // DefaultPalette is the fallback dark theme.
@@ -415,9 +421,97 @@ var palettes = map[string]Palette{
 
 // GetPalette returns the color palette for a weather condition and time of day.
 func GetPalette(condition WeatherCondition, tod TimeOfDay) Palette {
+	p, _ := LookupPalette(condition, tod)
+	return p
+}
+
+// LookupPalette is GetPalette that also reports whether there's a curated
+// palette for the combination, rather than the default.
+func LookupPalette(condition WeatherCondition, tod TimeOfDay) (Palette, bool) {
 	key := string(ConditionWithTime(condition, tod))
 	if p, ok := palettes[key]; ok {
-		return p
+		return p, true
+	}
+	return DefaultPalette, false
+}
+
+// paletteBlend is how long before each time-of-day change PaletteAt starts
+// fading towards the next period's palette.
+const paletteBlend = time.Hour
+
+// todStarts lists the hour each time of day begins, matching GetTimeOfDay.
+var todStarts = []struct {
+	hour int
+	tod  TimeOfDay
+}{
+	{5, TimeDawn},
+	{7, TimeDay},
+	{17, TimeDusk},
+	{20, TimeNight},
+}
+
+// PaletteAt returns the palette for condition at t. In the paletteBlend
+// before the time of day changes, the colors are mixed towards the next
+// period's palette so the theme doesn't jump at the boundary.
+func PaletteAt(condition WeatherCondition, t time.Time) Palette {
+	current := GetPalette(condition, GetTimeOfDay(t))
+
+	for _, start := range todStarts {
+		boundary := time.Date(t.Year(), t.Month(), t.Day(), start.hour, 0, 0, 0, t.Location())
+		until := boundary.Sub(t)
+		if until <= 0 || until > paletteBlend {
+			continue
+		}
+		next := GetPalette(condition, start.tod)
+		return mixPalettes(current, next, 1-float64(until)/float64(paletteBlend))
+	}
+	return current
+}
+
+// mixPalettes blends each color of a towards b by frac, from 0 (all a) to 1
+// (all b).
+func mixPalettes(a, b Palette, frac float64) Palette {
+	return Palette{
+		Background: mixHex(a.Background, b.Background, frac),
+		Card:       mixHex(a.Card, b.Card, frac),
+		CardBorder: mixHex(a.CardBorder, b.CardBorder, frac),
+		Text:       mixHex(a.Text, b.Text, frac),
+		TextMuted:  mixHex(a.TextMuted, b.TextMuted, frac),
+		Accent:     mixHex(a.Accent, b.Accent, frac),
+		AccentAlt:  mixHex(a.AccentAlt, b.AccentAlt, frac),
+	}
+}
+
+// mixHex blends two "#rrggbb" colors. If either doesn't parse it returns
+// whichever is nearer.
+func mixHex(a, b string, frac float64) string {
+	ca, okA := parseHex(a)
+	cb, okB := parseHex(b)
+	if !okA || !okB {
+		if frac < 0.5 {
+			return a
+		}
+		return b
+	}
+	var mixed [3]int
+	for i := range mixed {
+		mixed[i] = int(float64(ca[i]) + (float64(cb[i])-float64(ca[i]))*frac + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", mixed[0], mixed[1], mixed[2])
+}
+
+// parseHex splits a "#rrggbb" color into its channels.
+func parseHex(color string) ([3]int, bool) {
+	var c [3]int
+	if len(color) != 7 || color[0] != '#' {
+		return c, false
+	}
+	for i := range c {
+		v, err := strconv.ParseUint(color[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return c, false
+		}
+		c[i] = int(v)
 	}
-	return DefaultPalette
+	return c, true
 }
@@ -0,0 +1,50 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaletteAt(t *testing.T) {
+	day := GetPalette(ConditionStorm, TimeDay)
+	dusk := GetPalette(ConditionStorm, TimeDusk)
+
+	at := func(hour, minute int) Palette {
+		return PaletteAt(ConditionStorm, time.Date(2026, 1, 15, hour, minute, 0, 0, time.UTC))
+	}
+	if got := at(12, 0); got != day {
+		t.Errorf("midday = %+v, want the day palette", got)
+	}
+	if got := at(17, 0); got != dusk {
+		t.Errorf("5pm = %+v, want the dusk palette", got)
+	}
+
+	// Halfway through the fade each channel is halfway between the two
+	mid := at(16, 30)
+	if want := mixHex(day.Background, dusk.Background, 0.5); mid.Background != want {
+		t.Errorf("4:30pm background = %s, want %s", mid.Background, want)
+	}
+	if mid == day || mid == dusk {
+		t.Errorf("4:30pm = %+v, want a blend of day and dusk", mid)
+	}
+}
+
+func TestMixHex(t *testing.T) {
+	tests := []struct {
+		a, b string
+		frac float64
+		want string
+	}{
+		{"#000000", "#ffffff", 0, "#000000"},
+		{"#000000", "#ffffff", 1, "#ffffff"},
+		{"#000000", "#ffffff", 0.5, "#808080"},
+		{"#102030", "#304050", 0.5, "#203040"},
+		{"bogus", "#ffffff", 0.25, "bogus"},
+		{"bogus", "#ffffff", 0.75, "#ffffff"},
+	}
+	for _, tt := range tests {
+		if got := mixHex(tt.a, tt.b, tt.frac); got != tt.want {
+			t.Errorf("mixHex(%s, %s, %.2f) = %s, want %s", tt.a, tt.b, tt.frac, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package forecast
+
+import "strings"
+
+// Precipitation types returned by PrecipTypeForStation.
+const (
+	PrecipRain  = "rain"
+	PrecipSleet = "sleet"
+	PrecipSnow  = "snow"
+)
+
+// Air temperatures (°C) at or below which precipitation reaches the ground as
+// snow or sleet. Snow usually survives a few hundred metres below the freezing
+// level, so these sit slightly above zero.
+const (
+	snowMaxTemp  = 0.5
+	sleetMaxTemp = 2.0
+)
+
+// FreezingLevel estimates the height in metres above the surface reading at
+// which the air reaches 0°C, assuming the standard lapse rate.
+func FreezingLevel(surfaceTempC float64) float64 {
+	return surfaceTempC / StandardLapseRate
+}
+
+// PrecipTypeForStation infers whether precipitation falls as rain, sleet or
+// snow at a station. surfaceTempC is the valley temperature and elevation is
+// the station's height in metres above where that temperature was measured.
+// A forecast narrative mentioning snow lowers the bar by a degree, since the
+// forecaster has usually seen a colder profile than the lapse rate implies.
+func PrecipTypeForStation(surfaceTempC, elevation float64, narrative string) string {
+	temp := surfaceTempC - elevation*StandardLapseRate
+	if strings.Contains(strings.ToLower(narrative), "snow") {
+		temp -= 1
+	}
+
+	switch {
+	case temp <= snowMaxTemp:
+		return PrecipSnow
+	case temp <= sleetMaxTemp:
+		return PrecipSleet
+	default:
+		return PrecipRain
+	}
+}
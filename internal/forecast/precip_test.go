@@ -0,0 +1,39 @@
+package forecast
+
+import "testing"
+
+func TestFreezingLevel(t *testing.T) {
+	// 3°C at the surface reaches 0°C about 460m up
+	got := FreezingLevel(3)
+	if got < 450 || got > 470 {
+		t.Errorf("FreezingLevel(3) = %.0f, want ~460", got)
+	}
+}
+
+func TestPrecipTypeForStation(t *testing.T) {
+	tests := []struct {
+		name      string
+		surface   float64
+		elevation float64
+		narrative string
+		want      string
+	}{
+		{"cold valley floor gets rain", 3, 0, "", PrecipRain},
+		{"same front is snow at 400m", 3, 400, "", PrecipSnow},
+		{"mid slope sleet", 3, 200, "", PrecipSleet},
+		{"warm day rain up high", 15, 400, "", PrecipRain},
+		{"freezing valley snow", -1, 0, "", PrecipSnow},
+		{"snow in narrative lowers threshold", 3, 50, "Rain and snow showers", PrecipSleet},
+		{"snow in narrative ignored when warm", 12, 0, "Snow above 1200m", PrecipRain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PrecipTypeForStation(tt.surface, tt.elevation, tt.narrative)
+			if got != tt.want {
+				t.Errorf("PrecipTypeForStation(%.1f, %.0f, %q) = %q, want %q",
+					tt.surface, tt.elevation, tt.narrative, got, tt.want)
+			}
+		})
+	}
+}
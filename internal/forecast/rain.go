@@ -0,0 +1,27 @@
+package forecast
+
+// Rain intensity classes, following the WMO rainfall rate thresholds.
+const (
+	RainLight    = "light"
+	RainModerate = "moderate"
+	RainHeavy    = "heavy"
+	RainViolent  = "violent"
+)
+
+// RainIntensity classifies a rainfall rate in mm/hr: light below 2.5,
+// moderate below 10, heavy below 50 and violent from 50. A zero or negative
+// rate returns an empty string.
+func RainIntensity(mmPerHour float64) string {
+	switch {
+	case mmPerHour <= 0:
+		return ""
+	case mmPerHour < 2.5:
+		return RainLight
+	case mmPerHour < 10:
+		return RainModerate
+	case mmPerHour < 50:
+		return RainHeavy
+	default:
+		return RainViolent
+	}
+}
@@ -0,0 +1,27 @@
+package forecast
+
+import "testing"
+
+func TestRainIntensity(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want string
+	}{
+		{0, ""},
+		{-0.2, ""},
+		{0.2, RainLight},
+		{2.49, RainLight},
+		{2.5, RainModerate},
+		{9.99, RainModerate},
+		{10, RainHeavy},
+		{49.9, RainHeavy},
+		{50, RainViolent},
+		{120, RainViolent},
+	}
+
+	for _, tt := range tests {
+		if got := RainIntensity(tt.rate); got != tt.want {
+			t.Errorf("RainIntensity(%v) = %q, want %q", tt.rate, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 type RegimeFlags struct {
 	Heatwave       bool
+	ColdSnap       bool
 	InversionNight bool
 	ClearCalm      bool
 }
@@ -17,6 +18,7 @@ func ClassifyRegime(
 ) RegimeFlags {
 	return RegimeFlags{
 		Heatwave:       classifyHeatwave(forecast, prevDays),
+		ColdSnap:       classifyColdSnap(forecast, prevDays),
 		InversionNight: summary != nil && summary.InversionDetected.Valid && summary.InversionDetected.Bool,
 		ClearCalm:      classifyClearCalm(summary),
 	}
@@ -38,6 +40,22 @@ func classifyHeatwave(fc *models.Forecast, prevDays []models.DailySummary) bool
 	return false
 }
 
+func classifyColdSnap(fc *models.Forecast, prevDays []models.DailySummary) bool {
+	// Forecast <5°C triggers cold snap
+	if fc != nil && fc.TempMax.Valid && fc.TempMax.Float64 < 5 {
+		return true
+	}
+
+	// Two consecutive days <8°C triggers cold snap
+	if len(prevDays) >= 2 {
+		if prevDays[0].TempMax.Valid && prevDays[0].TempMax.Float64 < 8 &&
+			prevDays[1].TempMax.Valid && prevDays[1].TempMax.Float64 < 8 {
+			return true
+		}
+	}
+	return false
+}
+
 func classifyClearCalm(summary *models.DailySummary) bool {
 	if summary == nil {
 		return false
@@ -66,6 +84,9 @@ func RegimeToString(flags RegimeFlags) string {
 	if flags.Heatwave {
 		return "heatwave"
 	}
+	if flags.ColdSnap {
+		return "cold_snap"
+	}
 	if flags.InversionNight {
 		return "inversion"
 	}
@@ -73,6 +73,72 @@ func TestClassifyRegime_Heatwave(t *testing.T) {
 	}
 }
 
+func TestClassifyRegime_ColdSnap(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast *models.Forecast
+		prevDays []models.DailySummary
+		want     bool
+	}{
+		{
+			name:     "forecast < 5C triggers cold snap",
+			forecast: &models.Forecast{TempMax: sql.NullFloat64{Float64: 4, Valid: true}},
+			prevDays: nil,
+			want:     true,
+		},
+		{
+			name:     "forecast 6C does not trigger cold snap alone",
+			forecast: &models.Forecast{TempMax: sql.NullFloat64{Float64: 6, Valid: true}},
+			prevDays: nil,
+			want:     false,
+		},
+		{
+			name:     "two consecutive days < 8C triggers cold snap",
+			forecast: &models.Forecast{TempMax: sql.NullFloat64{Float64: 12, Valid: true}},
+			prevDays: []models.DailySummary{
+				{TempMax: sql.NullFloat64{Float64: 7, Valid: true}},
+				{TempMax: sql.NullFloat64{Float64: 7.9, Valid: true}},
+			},
+			want: true,
+		},
+		{
+			name:     "only one day < 8C does not trigger cold snap",
+			forecast: &models.Forecast{TempMax: sql.NullFloat64{Float64: 12, Valid: true}},
+			prevDays: []models.DailySummary{
+				{TempMax: sql.NullFloat64{Float64: 6, Valid: true}},
+				{TempMax: sql.NullFloat64{Float64: 8, Valid: true}},
+			},
+			want: false,
+		},
+		{
+			name:     "nil forecast with two cold days",
+			forecast: nil,
+			prevDays: []models.DailySummary{
+				{TempMax: sql.NullFloat64{Float64: 5, Valid: true}},
+				{TempMax: sql.NullFloat64{Float64: 6, Valid: true}},
+			},
+			want: true,
+		},
+		{
+			name:     "only one previous day does not trigger",
+			forecast: nil,
+			prevDays: []models.DailySummary{
+				{TempMax: sql.NullFloat64{Float64: 3, Valid: true}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ClassifyRegime(tt.forecast, nil, tt.prevDays)
+			if result.ColdSnap != tt.want {
+				t.Errorf("ColdSnap = %v, want %v", result.ColdSnap, tt.want)
+			}
+		})
+	}
+}
+
 func TestClassifyRegime_Inversion(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -128,6 +194,11 @@ func TestRegimeToString_Priority(t *testing.T) {
 			flags: RegimeFlags{Heatwave: true, InversionNight: true, ClearCalm: true},
 			want:  "heatwave",
 		},
+		{
+			name:  "cold_snap over inversion",
+			flags: RegimeFlags{ColdSnap: true, InversionNight: true, ClearCalm: true},
+			want:  "cold_snap",
+		},
 		{
 			name:  "inversion when no heatwave",
 			flags: RegimeFlags{Heatwave: false, InversionNight: true, ClearCalm: true},
@@ -0,0 +1,163 @@
+package forecast
+
+import (
+	"math"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+const (
+	// solarConstant is the mean extraterrestrial irradiance in W/m².
+	solarConstant = 1353.0
+
+	// minClearSkyForCloudiness is the expected irradiance below which the sun is
+	// too low for a useful cloudiness estimate (W/m²).
+	minClearSkyForCloudiness = 100.0
+
+	// maxCloudAttenuation is the fraction of clear-sky irradiance removed by full
+	// overcast, after Kasten & Czeplak (1980).
+	maxCloudAttenuation = 0.75
+
+	// sunshineClearSkyFraction is the share of the expected clear-sky
+	// irradiance a reading must reach to count as sunshine.
+	sunshineClearSkyFraction = 0.6
+
+	// maxSunshineInterval caps how long one reading is taken to last, so gaps
+	// in the record aren't counted as sunshine.
+	maxSunshineInterval = 30 * time.Minute
+)
+
+// CloudinessFromSolar estimates the cloud fraction (0 clear to 1 overcast) by
+// comparing observed solar radiation against the expected clear-sky value.
+// It returns -1 if the observation has no solar reading or the sun is too low
+// for the comparison to mean anything.
+func CloudinessFromSolar(obs models.Observation, clearSkyExpected float64) float64 {
+	if !obs.SolarRadiation.Valid || clearSkyExpected < minClearSkyForCloudiness {
+		return -1
+	}
+
+	clearness := obs.SolarRadiation.Float64 / clearSkyExpected
+	cloud := (1 - clearness) / maxCloudAttenuation
+	return math.Max(0, math.Min(1, cloud))
+}
+
+// SunshineHours estimates the hours of sunshine in a day's observations,
+// ordered by time. Each reading lasts until the next one, up to
+// maxSunshineInterval, and counts as sunshine when the sun is high enough to
+// judge and the solar radiation reaches sunshineClearSkyFraction of the
+// clear-sky value. ok is false if no reading has solar radiation.
+func SunshineHours(observations []models.Observation, st models.Station) (hours float64, ok bool) {
+	for i, obs := range observations {
+		if !obs.SolarRadiation.Valid {
+			continue
+		}
+		ok = true
+
+		// The last reading is taken to last as long as the one before it
+		var span time.Duration
+		if i+1 < len(observations) {
+			span = observations[i+1].ObservedAt.Sub(obs.ObservedAt)
+		} else if i > 0 {
+			span = obs.ObservedAt.Sub(observations[i-1].ObservedAt)
+		}
+		span = min(span, maxSunshineInterval)
+
+		clearSky := ClearSkySolar(obs.ObservedAt, st.Latitude, st.Longitude, st.Elevation)
+		if clearSky < minClearSkyForCloudiness {
+			continue
+		}
+		if obs.SolarRadiation.Float64 >= sunshineClearSkyFraction*clearSky {
+			hours += span.Hours()
+		}
+	}
+	return hours, ok
+}
+
+// ObservedCloudiness estimates cloud fraction for an observation using the
+// clear-sky irradiance expected at the station's location and the observation time.
+func ObservedCloudiness(obs models.Observation, st models.Station) float64 {
+	return CloudinessFromSolar(obs, ClearSkySolar(obs.ObservedAt, st.Latitude, st.Longitude, st.Elevation))
+}
+
+// ClearSkySolar returns the expected global horizontal irradiance (W/m²) under
+// clear skies at the given time and location, using the Meinel transmittance
+// model with the Laue altitude correction. Elevation is in metres. Returns 0
+// when the sun is below the horizon.
+func ClearSkySolar(t time.Time, lat, lon, elevation float64) float64 {
+	elev := solarElevation(t, lat, lon)
+	if elev <= 0 {
+		return 0
+	}
+
+	zenith := 90 - elev
+	zenithRad := zenith * math.Pi / 180
+	// Kasten & Young (1989) air mass
+	airMass := 1 / (math.Cos(zenithRad) + 0.50572*math.Pow(96.07995-zenith, -1.6364))
+
+	altitudeKM := math.Max(0, elevation) / 1000
+	const a = 0.14
+	direct := solarConstant * ((1-a*altitudeKM)*math.Pow(0.7, math.Pow(airMass, 0.678)) + a*altitudeKM)
+
+	// Diffuse adds roughly 10% to the direct beam under clear skies
+	return 1.1 * direct * math.Sin(elev*math.Pi/180)
+}
+
+// SunTimes returns sunrise and sunset on date's calendar day at the given
+// location, in date's time zone, using the NOAA low-precision equations. At
+// latitudes where the sun doesn't rise or set both are zero.
+func SunTimes(date time.Time, lat, lon float64) (sunrise, sunset time.Time) {
+	loc := date.Location()
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	// Fractional year at local noon
+	gamma := 2 * math.Pi / 365 * (float64(midnight.YearDay()) - 1)
+
+	eqTime := 229.18 * (0.000075 + 0.001868*math.Cos(gamma) - 0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) - 0.040849*math.Sin(2*gamma))
+	decl := 0.006918 - 0.399912*math.Cos(gamma) + 0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) + 0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) + 0.00148*math.Sin(3*gamma)
+
+	// Hour angle for the sun's upper limb at the horizon, allowing for refraction
+	latRad := lat * math.Pi / 180
+	cosHA := math.Cos(90.833*math.Pi/180)/(math.Cos(latRad)*math.Cos(decl)) - math.Tan(latRad)*math.Tan(decl)
+	if cosHA < -1 || cosHA > 1 {
+		return time.Time{}, time.Time{}
+	}
+	ha := math.Acos(cosHA) * 180 / math.Pi
+
+	// Minutes from UTC midnight on the same calendar date
+	utcMidnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	riseMin := 720 - 4*(lon+ha) - eqTime
+	setMin := 720 - 4*(lon-ha) - eqTime
+	sunrise = utcMidnight.Add(time.Duration(riseMin * float64(time.Minute))).In(loc)
+	sunset = utcMidnight.Add(time.Duration(setMin * float64(time.Minute))).In(loc)
+	return sunrise, sunset
+}
+
+// solarElevation returns the sun's elevation angle in degrees above the horizon
+// using the NOAA low-precision equations.
+func solarElevation(t time.Time, lat, lon float64) float64 {
+	utc := t.UTC()
+	dayOfYear := float64(utc.YearDay())
+	hour := float64(utc.Hour()) + float64(utc.Minute())/60 + float64(utc.Second())/3600
+
+	// Fractional year in radians
+	gamma := 2 * math.Pi / 365 * (dayOfYear - 1 + (hour-12)/24)
+
+	// Equation of time (minutes) and declination (radians)
+	eqTime := 229.18 * (0.000075 + 0.001868*math.Cos(gamma) - 0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) - 0.040849*math.Sin(2*gamma))
+	decl := 0.006918 - 0.399912*math.Cos(gamma) + 0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) + 0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) + 0.00148*math.Sin(3*gamma)
+
+	// True solar time (minutes) and hour angle (radians)
+	trueSolarTime := hour*60 + eqTime + 4*lon
+	hourAngle := (trueSolarTime/4 - 180) * math.Pi / 180
+
+	latRad := lat * math.Pi / 180
+	cosZenith := math.Sin(latRad)*math.Sin(decl) + math.Cos(latRad)*math.Cos(decl)*math.Cos(hourAngle)
+	cosZenith = math.Max(-1, math.Min(1, cosZenith))
+	return 90 - math.Acos(cosZenith)*180/math.Pi
+}
@@ -0,0 +1,222 @@
+package forecast
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestCloudinessFromSolar(t *testing.T) {
+	tests := []struct {
+		name     string
+		solar    sql.NullFloat64
+		expected float64
+		wantMin  float64
+		wantMax  float64
+	}{
+		{
+			name:     "full sun",
+			solar:    sql.NullFloat64{Float64: 960, Valid: true},
+			expected: 980,
+			wantMin:  0,
+			wantMax:  0.05,
+		},
+		{
+			name:     "cloud enhancement above clear sky clamps to zero",
+			solar:    sql.NullFloat64{Float64: 1100, Valid: true},
+			expected: 980,
+			wantMin:  0,
+			wantMax:  0,
+		},
+		{
+			name:     "heavy overcast",
+			solar:    sql.NullFloat64{Float64: 150, Valid: true},
+			expected: 900,
+			wantMin:  0.95,
+			wantMax:  1,
+		},
+		{
+			name:     "partly cloudy",
+			solar:    sql.NullFloat64{Float64: 600, Valid: true},
+			expected: 900,
+			wantMin:  0.3,
+			wantMax:  0.6,
+		},
+		{
+			name:     "no solar reading",
+			solar:    sql.NullFloat64{},
+			expected: 900,
+			wantMin:  -1,
+			wantMax:  -1,
+		},
+		{
+			name:     "sun too low",
+			solar:    sql.NullFloat64{Float64: 10, Valid: true},
+			expected: 40,
+			wantMin:  -1,
+			wantMax:  -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs := models.Observation{SolarRadiation: tt.solar}
+			got := CloudinessFromSolar(obs, tt.expected)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("CloudinessFromSolar = %.3f, want between %.2f and %.2f", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestRefineConditionWithCloudiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		condition  WeatherCondition
+		cloudiness float64
+		tempMax    float64
+		want       WeatherCondition
+	}{
+		{"clear forecast but overcast", ConditionClearWarm, 0.9, 28, ConditionMostlyCloudy},
+		{"cloudy forecast but sunny and warm", ConditionMostlyCloudy, 0.05, 28, ConditionClearWarm},
+		{"cloudy forecast but sunny and cool", ConditionPartlyCloudy, 0.1, 15, ConditionClearCool},
+		{"clear forecast with some cloud", ConditionClearCool, 0.4, 15, ConditionPartlyCloudy},
+		{"rain is not overridden", ConditionLightRain, 0.05, 20, ConditionLightRain},
+		{"hot is not overridden", ConditionHot, 0.9, 38, ConditionHot},
+		{"unknown cloudiness keeps condition", ConditionClearWarm, -1, 28, ConditionClearWarm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RefineConditionWithCloudiness(tt.condition, tt.cloudiness, tt.tempMax)
+			if got != tt.want {
+				t.Errorf("RefineConditionWithCloudiness = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearSkySolar(t *testing.T) {
+	const lat, lon, elev = -36.794, 146.977, 386.0
+	melb, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Fatalf("load timezone: %v", err)
+	}
+
+	t.Run("zero at night", func(t *testing.T) {
+		for _, tm := range []time.Time{
+			time.Date(2026, 1, 15, 23, 0, 0, 0, melb),
+			time.Date(2026, 1, 15, 2, 0, 0, 0, melb),
+			time.Date(2026, 7, 15, 19, 0, 0, 0, melb),
+		} {
+			if got := ClearSkySolar(tm, lat, lon, elev); got != 0 {
+				t.Errorf("ClearSkySolar(%s) = %.1f, want 0", tm.Format(time.Kitchen), got)
+			}
+		}
+	})
+
+	t.Run("summer noon peak exceeds winter noon peak", func(t *testing.T) {
+		// Solar noon is about 1:15 PM AEDT in summer and 12:15 PM AEST in winter
+		summer := ClearSkySolar(time.Date(2026, 12, 21, 13, 15, 0, 0, melb), lat, lon, elev)
+		winter := ClearSkySolar(time.Date(2026, 6, 21, 12, 15, 0, 0, melb), lat, lon, elev)
+		if summer <= winter {
+			t.Errorf("summer noon = %.1f, winter noon = %.1f, want summer > winter", summer, winter)
+		}
+		if summer < 900 || summer > 1200 {
+			t.Errorf("summer noon = %.1f, want roughly 900-1200 W/m²", summer)
+		}
+		if winter < 300 || winter > 700 {
+			t.Errorf("winter noon = %.1f, want roughly 300-700 W/m²", winter)
+		}
+	})
+
+	t.Run("increases through the morning", func(t *testing.T) {
+		prev := -1.0
+		for hour := 6; hour <= 13; hour++ {
+			got := ClearSkySolar(time.Date(2026, 1, 15, hour, 0, 0, 0, melb), lat, lon, elev)
+			if got <= prev {
+				t.Errorf("ClearSkySolar at %d:00 = %.1f, not greater than previous %.1f", hour, got, prev)
+			}
+			prev = got
+		}
+	})
+
+	t.Run("higher elevation receives more", func(t *testing.T) {
+		noon := time.Date(2026, 1, 15, 13, 15, 0, 0, melb)
+		low := ClearSkySolar(noon, lat, lon, 0)
+		high := ClearSkySolar(noon, lat, lon, 1500)
+		if high <= low {
+			t.Errorf("1500m = %.1f, sea level = %.1f, want higher elevation > sea level", high, low)
+		}
+	})
+}
+
+func TestSunTimes(t *testing.T) {
+	mel, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip("Australia/Melbourne timezone not available")
+	}
+
+	// Wandiligong at the solstices, from solar noon and day length
+	tests := []struct {
+		date              time.Time
+		wantRise, wantSet string
+	}{
+		{time.Date(2025, 12, 21, 0, 0, 0, 0, mel), "05:53", "20:27"},
+		{time.Date(2025, 6, 21, 0, 0, 0, 0, mel), "07:21", "17:06"},
+	}
+	for _, tt := range tests {
+		rise, set := SunTimes(tt.date, -36.794, 146.977)
+		wantRise, _ := time.ParseInLocation("2006-01-02 15:04", tt.date.Format("2006-01-02 ")+tt.wantRise, mel)
+		wantSet, _ := time.ParseInLocation("2006-01-02 15:04", tt.date.Format("2006-01-02 ")+tt.wantSet, mel)
+		if d := rise.Sub(wantRise); d < -10*time.Minute || d > 10*time.Minute {
+			t.Errorf("%s sunrise = %s, want about %s", tt.date.Format("Jan 2"), rise.Format("15:04"), tt.wantRise)
+		}
+		if d := set.Sub(wantSet); d < -10*time.Minute || d > 10*time.Minute {
+			t.Errorf("%s sunset = %s, want about %s", tt.date.Format("Jan 2"), set.Format("15:04"), tt.wantSet)
+		}
+	}
+}
+
+func TestSunshineHours(t *testing.T) {
+	melb, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Fatalf("load timezone: %v", err)
+	}
+	st := models.Station{Latitude: -36.794, Longitude: 146.977, Elevation: 386}
+
+	// A summer day of 10-minute readings at the given share of clear sky
+	day := func(clearness func(hour int) float64) []models.Observation {
+		var observations []models.Observation
+		for at := time.Date(2026, 1, 15, 0, 0, 0, 0, melb); at.Day() == 15; at = at.Add(10 * time.Minute) {
+			solar := clearness(at.Hour()) * ClearSkySolar(at, st.Latitude, st.Longitude, st.Elevation)
+			observations = append(observations, models.Observation{
+				ObservedAt:     at,
+				SolarRadiation: sql.NullFloat64{Float64: solar, Valid: true},
+			})
+		}
+		return observations
+	}
+
+	clear, ok := SunshineHours(day(func(int) float64 { return 0.95 }), st)
+	if !ok || clear < 11 || clear > 14 {
+		t.Errorf("clear day = %.1f hours (ok %v), want roughly 11-14", clear, ok)
+	}
+
+	// Sunny until 1pm, then clouding over
+	mixed, ok := SunshineHours(day(func(hour int) float64 {
+		if hour < 13 {
+			return 0.95
+		}
+		return 0.3
+	}), st)
+	if !ok || mixed < 5 || mixed > 7 {
+		t.Errorf("sunny then cloudy = %.1f hours (ok %v), want roughly 5-7", mixed, ok)
+	}
+
+	if _, ok := SunshineHours([]models.Observation{{ObservedAt: time.Date(2026, 1, 15, 12, 0, 0, 0, melb)}}, st); ok {
+		t.Error("no solar readings: ok = true, want false")
+	}
+}
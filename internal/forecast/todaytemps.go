@@ -12,7 +12,8 @@ import (
 type TodayTempInput struct {
 	WUForecast       *models.Forecast
 	BOMForecast      *models.Forecast
-	CorrectionStats  map[string]map[string]map[int]*store.CorrectionStats
+	CorrectionStats  store.CorrectionStatsMap
+	Regime           string // regime from RegimeToString; regime-specific bias is preferred when available
 	BiasCorrector    *BiasCorrector
 	Nowcaster        *Nowcaster
 	PrimaryStationID string
@@ -23,8 +24,44 @@ type TodayTempInput struct {
 	ObservedMin      float64
 	ObservedMinValid bool
 	Hour             int
-	TempFalling      bool // true if temp is falling > 0.5°C/hr
+	TempFalling      bool // true if temp is falling faster than FallingRate
 	LogNowcast       bool // whether to log nowcast to DB
+
+	// Blend weights WU and BOM by Skill (see BlendForecasts) when both have a
+	// temperature, instead of picking one source. Its results are recorded
+	// with source "blend" so they can be compared against the usual choice.
+	Blend bool
+	Skill map[string]float64
+}
+
+// FallingRate is the change in °C/hr below which the temperature counts as
+// falling.
+const FallingRate = -0.5
+
+const (
+	// BustMargin is how far (°C) the current temperature must exceed the
+	// forecast max before the forecast counts as busted and the max is
+	// projected from the current temperature instead.
+	BustMargin = 2.0
+	// bustWarmingPerHour is the further warming expected for each hour left
+	// before bustPeakHour, capped at maxBustWarming.
+	bustWarmingPerHour = 0.5
+	bustPeakHour       = 15
+	maxBustWarming     = 3.0
+)
+
+// BustedMax projects today's max from a current temperature that has already
+// run well past the forecast: the current temperature plus the warming still
+// likely before the mid-afternoon peak.
+func BustedMax(currentTemp float64, hour int) float64 {
+	remaining := float64(max(bustPeakHour-hour, 0))
+	return currentTemp + min(remaining*bustWarmingPerHour, maxBustWarming)
+}
+
+// LikelyPastPeak reports whether the day's max has probably already occurred:
+// it's after ~3 PM local time and the temperature is falling.
+func LikelyPastPeak(hour int, falling bool) bool {
+	return hour >= 15 && falling
 }
 
 // TodayTempResult contains the computed display temperatures and explanation.
@@ -41,13 +78,16 @@ type TodayTempResult struct {
 
 // TempExplanation tracks how the forecast was calculated.
 type TempExplanation struct {
-	MaxSource       string  // "bom" or "wu"
+	MaxSource       string  // "bom", "wu" or "blend"
 	MaxRaw          float64 // raw forecast value
 	MaxBiasApplied  float64 // bias correction applied
 	MaxBiasDayUsed  int     // which day's bias was used (-1 if none)
 	MaxBiasSamples  int     // how many samples the bias is based on
 	MaxBiasFallback bool    // true if fallback day was used
 	MaxNowcast      float64 // nowcast adjustment (if any)
+	MaxBusted       bool    // current temp ran past the forecast by BustMargin, so the max was projected from it
+	MaxBustedFrom   float64 // the max it replaced
+	MaxForecast     float64 // max before any bust override, logged as the displayed forecast
 	MaxFinal        float64 // final displayed value
 	MinSource       string
 	MinRaw          float64
@@ -64,30 +104,40 @@ type BiasLookupResult struct {
 	DayUsed    int  // which day's stats were used (-1 if none)
 	Samples    int  // sample size the bias is based on
 	IsFallback bool // true if a fallback day was used
+	Regime     string // regime whose stats were used ("all" unless a regime-specific bias applied)
 }
 
 // LookupBiasWithFallback returns the bias correction for a source/target/day,
-// falling back to nearby days if the exact day doesn't have enough samples.
-func LookupBiasWithFallback(stats map[string]map[string]map[int]*store.CorrectionStats, source, target string, dayOfForecast int) BiasLookupResult {
+// preferring the given regime's stats for that day when they have enough samples,
+// then falling back to the all-regime stats for the exact day or nearby days.
+func LookupBiasWithFallback(stats store.CorrectionStatsMap, source, target string, dayOfForecast int, regime string) BiasLookupResult {
 	if stats == nil || stats[source] == nil || stats[source][target] == nil {
 		return BiasLookupResult{DayUsed: -1}
 	}
 
 	targetStats := stats[source][target]
 
-	// First, try the exact day
-	if s := targetStats[dayOfForecast]; s != nil && s.SampleSize >= minBiasSamples {
-		bias := s.MeanBias
-		if bias > MaxBiasCorrection {
-			bias = MaxBiasCorrection
-		} else if bias < -MaxBiasCorrection {
-			bias = -MaxBiasCorrection
+	// Prefer regime-specific stats for the exact day
+	if regime != "" && regime != "all" {
+		if s := targetStats[dayOfForecast][regime]; s != nil && s.SampleSize >= minRegimeSamples {
+			return BiasLookupResult{
+				Bias:       capCorrection(s.MeanBias, MaxBiasCorrection),
+				DayUsed:    dayOfForecast,
+				Samples:    s.SampleSize,
+				IsFallback: false,
+				Regime:     regime,
+			}
 		}
+	}
+
+	// Then the all-regime stats for the exact day
+	if s := targetStats[dayOfForecast]["all"]; s != nil && s.SampleSize >= minBiasSamples {
 		return BiasLookupResult{
-			Bias:       bias,
+			Bias:       capCorrection(s.MeanBias, MaxBiasCorrection),
 			DayUsed:    dayOfForecast,
 			Samples:    s.SampleSize,
 			IsFallback: false,
+			Regime:     "all",
 		}
 	}
 
@@ -105,18 +155,13 @@ func LookupBiasWithFallback(stats map[string]map[string]map[int]*store.Correctio
 	}
 
 	for _, day := range searchOrder {
-		if s := targetStats[day]; s != nil && s.SampleSize >= minBiasSamples {
-			bias := s.MeanBias
-			if bias > MaxBiasCorrection {
-				bias = MaxBiasCorrection
-			} else if bias < -MaxBiasCorrection {
-				bias = -MaxBiasCorrection
-			}
+		if s := targetStats[day]["all"]; s != nil && s.SampleSize >= minBiasSamples {
 			return BiasLookupResult{
-				Bias:       bias,
+				Bias:       capCorrection(s.MeanBias, MaxBiasCorrection),
 				DayUsed:    day,
 				Samples:    s.SampleSize,
 				IsFallback: true,
+				Regime:     "all",
 			}
 		}
 	}
@@ -125,8 +170,9 @@ func LookupBiasWithFallback(stats map[string]map[string]map[int]*store.Correctio
 }
 
 // LookupBias returns just the bias value for a source/target/day (convenience wrapper).
-func LookupBias(stats map[string]map[string]map[int]*store.CorrectionStats, source, target string, dayOfForecast int) float64 {
-	return LookupBiasWithFallback(stats, source, target, dayOfForecast).Bias
+// Only the all-regime stats are used, since regimes aren't known for future days.
+func LookupBias(stats store.CorrectionStatsMap, source, target string, dayOfForecast int) float64 {
+	return LookupBiasWithFallback(stats, source, target, dayOfForecast, "all").Bias
 }
 
 // ComputeTodayTemps calculates today's display temperatures using standardized logic:
@@ -138,6 +184,10 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 
 	wuForecast := input.WUForecast
 	bomForecast := input.BOMForecast
+	// A narrative-only BOM period has nothing to prefer over WU
+	if bomForecast != nil && bomForecast.NarrativeOnly() {
+		bomForecast = nil
+	}
 
 	// MAX TEMP: prefer BOM (better accuracy), but fall back to WU if BOM is unreasonable
 	// "Unreasonable" = current temp already exceeds BOM forecast by >3°C, or BOM differs from WU by >10°C
@@ -151,13 +201,33 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		}
 	}
 
-	if useBOMMax {
+	// Blending needs a BOM max that passed the checks above; when BOM is
+	// rejected WU stands alone as usual
+	blendMax := input.Blend && useBOMMax && wuForecast != nil && wuForecast.TempMax.Valid
+
+	if blendMax {
+		raw, corrected := blendTemps(input, "tmax")
+		exp.MaxSource = "blend"
+		exp.MaxRaw = raw
+		exp.MaxBiasApplied = raw - corrected
+		exp.MaxBiasDayUsed = -1 // each source's own correction is folded into the blend
+		result.TempMax = corrected
+		result.HaveMax = true
+		result.TempMaxPreNowcast = math.Round(result.TempMax)
+
+		// Nowcast using the blend as base
+		if bomForecast.DayOfForecast == 0 {
+			applyMaxNowcast(input, &result, raw, raw-corrected)
+		}
+		result.TempMax = math.Round(result.TempMax)
+		exp.MaxFinal = result.TempMax
+	} else if useBOMMax {
 		exp.MaxSource = "bom"
 		exp.MaxRaw = bomForecast.TempMax.Float64
 		result.TempMax = bomForecast.TempMax.Float64
 		result.HaveMax = true
 
-		biasResult := LookupBiasWithFallback(input.CorrectionStats, "bom", "tmax", bomForecast.DayOfForecast)
+		biasResult := LookupBiasWithFallback(input.CorrectionStats, "bom", "tmax", bomForecast.DayOfForecast, input.Regime)
 		if biasResult.DayUsed >= 0 {
 			exp.MaxBiasApplied = biasResult.Bias
 			exp.MaxBiasDayUsed = biasResult.DayUsed
@@ -170,20 +240,8 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		result.TempMaxPreNowcast = math.Round(result.TempMax)
 
 		// Nowcast using BOM as base
-		if bomForecast.DayOfForecast == 0 && input.PrimaryStationID != "" && input.BiasCorrector != nil && input.Nowcaster != nil {
-			biasMax := input.BiasCorrector.GetCorrection("bom", "tmax", 0)
-			nowcast, err := input.Nowcaster.ComputeNowcast(input.PrimaryStationID, bomForecast.TempMax.Float64, biasMax)
-			if err == nil && nowcast != nil {
-				exp.MaxNowcast = nowcast.Adjustment
-				result.TempMax = nowcast.CorrectedMax
-				result.NowcastApplied = true
-				result.NowcastAdjustment = nowcast.Adjustment
-				if input.LogNowcast {
-					if err := input.Nowcaster.LogNowcast(input.PrimaryStationID, bomForecast.TempMax.Float64, nowcast); err != nil {
-						log.Printf("forecast: log nowcast: %v", err)
-					}
-				}
-			}
+		if bomForecast.DayOfForecast == 0 && input.BiasCorrector != nil {
+			applyMaxNowcast(input, &result, bomForecast.TempMax.Float64, input.BiasCorrector.GetCorrection("bom", "tmax", 0))
 		}
 		result.TempMax = math.Round(result.TempMax)
 		exp.MaxFinal = result.TempMax
@@ -194,7 +252,7 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		result.TempMax = wuForecast.TempMax.Float64
 		result.HaveMax = true
 
-		biasResult := LookupBiasWithFallback(input.CorrectionStats, "wu", "tmax", wuForecast.DayOfForecast)
+		biasResult := LookupBiasWithFallback(input.CorrectionStats, "wu", "tmax", wuForecast.DayOfForecast, input.Regime)
 		if biasResult.DayUsed >= 0 {
 			exp.MaxBiasApplied = biasResult.Bias
 			exp.MaxBiasDayUsed = biasResult.DayUsed
@@ -209,6 +267,9 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		exp.MaxFinal = result.TempMax
 	}
 
+	// The forecast-derived max, before observations override it
+	forecastMax := result.TempMax
+
 	// Use observed max as floor if it exceeds the corrected forecast
 	if result.HaveMax && input.ObservedMaxValid && input.ObservedMax > result.TempMax {
 		result.TempMax = math.Round(input.ObservedMax)
@@ -217,7 +278,7 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 
 	// After ~3 PM local time, if temp is falling, just use observed max
 	// The day's max has likely already occurred
-	if result.HaveMax && input.Hour >= 15 && input.TempFalling && input.ObservedMaxValid && input.ObservedMax > 0 {
+	if result.HaveMax && LikelyPastPeak(input.Hour, input.TempFalling) && input.ObservedMaxValid && input.ObservedMax > 0 {
 		result.TempMax = math.Round(input.ObservedMax)
 		exp.MaxFinal = result.TempMax
 	}
@@ -239,14 +300,45 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		}
 	}
 
+	// The bust override below is projected from observations rather than
+	// forecast, so accuracy tracking logs the max from before it
+	exp.MaxForecast = result.TempMax
+
+	// A forecast busting in progress: the current temp has already run well
+	// past even the fallback max, so rather than hold a max we know is too
+	// low, project one from where the temperature is now. This compares
+	// against the forecast, since the observed max floor already includes the
+	// current reading.
+	if result.HaveMax && input.HasCurrentTemp && input.CurrentTemp > forecastMax+BustMargin &&
+		!LikelyPastPeak(input.Hour, input.TempFalling) {
+		if revised := math.Round(BustedMax(input.CurrentTemp, input.Hour)); revised > result.TempMax {
+			exp.MaxBusted = true
+			exp.MaxBustedFrom = forecastMax
+			result.TempMax = revised
+			exp.MaxFinal = result.TempMax
+		}
+	}
+
 	// MIN TEMP: prefer WU (better accuracy)
-	if wuForecast != nil && wuForecast.TempMin.Valid {
+	blendMin := input.Blend && wuForecast != nil && wuForecast.TempMin.Valid &&
+		bomForecast != nil && bomForecast.TempMin.Valid
+
+	if blendMin {
+		raw, corrected := blendTemps(input, "tmin")
+		exp.MinSource = "blend"
+		exp.MinRaw = raw
+		exp.MinBiasApplied = raw - corrected
+		exp.MinBiasDayUsed = -1
+		result.TempMin = math.Round(corrected)
+		result.HaveMin = true
+		exp.MinFinal = result.TempMin
+	} else if wuForecast != nil && wuForecast.TempMin.Valid {
 		exp.MinSource = "wu"
 		exp.MinRaw = wuForecast.TempMin.Float64
 		result.TempMin = wuForecast.TempMin.Float64
 		result.HaveMin = true
 
-		biasResult := LookupBiasWithFallback(input.CorrectionStats, "wu", "tmin", wuForecast.DayOfForecast)
+		biasResult := LookupBiasWithFallback(input.CorrectionStats, "wu", "tmin", wuForecast.DayOfForecast, input.Regime)
 		if biasResult.DayUsed >= 0 {
 			exp.MinBiasApplied = biasResult.Bias
 			exp.MinBiasDayUsed = biasResult.DayUsed
@@ -265,7 +357,7 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 		result.TempMin = bomForecast.TempMin.Float64
 		result.HaveMin = true
 
-		biasResult := LookupBiasWithFallback(input.CorrectionStats, "bom", "tmin", bomForecast.DayOfForecast)
+		biasResult := LookupBiasWithFallback(input.CorrectionStats, "bom", "tmin", bomForecast.DayOfForecast, input.Regime)
 		if biasResult.DayUsed >= 0 {
 			exp.MinBiasApplied = biasResult.Bias
 			exp.MinBiasDayUsed = biasResult.DayUsed
@@ -287,3 +379,50 @@ func ComputeTodayTemps(input TodayTempInput) TodayTempResult {
 
 	return result
 }
+
+// applyMaxNowcast adjusts result's max by the morning nowcast for a forecast
+// max with the given bias, logging it when input.LogNowcast is set. It leaves
+// result alone when there's no nowcast to apply.
+func applyMaxNowcast(input TodayTempInput, result *TodayTempResult, forecastMax, bias float64) {
+	if input.PrimaryStationID == "" || input.Nowcaster == nil {
+		return
+	}
+	nowcast, err := input.Nowcaster.ComputeNowcast(input.PrimaryStationID, forecastMax, bias)
+	if err != nil || nowcast == nil {
+		return
+	}
+	result.Explanation.MaxNowcast = nowcast.Adjustment
+	result.TempMax = nowcast.CorrectedMax
+	result.NowcastApplied = true
+	result.NowcastAdjustment = nowcast.Adjustment
+	if input.LogNowcast {
+		if err := input.Nowcaster.LogNowcast(input.PrimaryStationID, forecastMax, nowcast); err != nil {
+			log.Printf("forecast: log nowcast: %v", err)
+		}
+	}
+}
+
+// blendTemps returns the skill-weighted blend of the WU and BOM forecasts for
+// target ("tmax" or "tmin"), before and after each source's bias correction.
+func blendTemps(input TodayTempInput, target string) (raw, corrected float64) {
+	wu, bom := *input.WUForecast, *input.BOMForecast
+	rawMax, rawMin := BlendForecasts(&wu, &bom, input.Skill)
+
+	for source, fc := range map[string]*models.Forecast{"wu": &wu, "bom": &bom} {
+		bias := LookupBiasWithFallback(input.CorrectionStats, source, target, fc.DayOfForecast, input.Regime)
+		if bias.DayUsed < 0 {
+			continue
+		}
+		if target == "tmax" {
+			fc.TempMax.Float64 -= bias.Bias
+		} else {
+			fc.TempMin.Float64 -= bias.Bias
+		}
+	}
+	correctedMax, correctedMin := BlendForecasts(&wu, &bom, input.Skill)
+
+	if target == "tmax" {
+		return rawMax, correctedMax
+	}
+	return rawMin, correctedMin
+}
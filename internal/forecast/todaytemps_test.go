@@ -115,10 +115,10 @@ func TestComputeTodayTemps(t *testing.T) {
 					TempMax:       sql.NullFloat64{Float64: 30, Valid: true},
 					DayOfForecast: 0,
 				},
-				CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+				CorrectionStats: store.CorrectionStatsMap{
 					"bom": {
 						"tmax": {
-							0: {MeanBias: 2.0, SampleSize: 10},
+							0: {"all": {MeanBias: 2.0, SampleSize: 10}},
 						},
 					},
 				},
@@ -133,10 +133,10 @@ func TestComputeTodayTemps(t *testing.T) {
 					TempMin:       sql.NullFloat64{Float64: 10, Valid: true},
 					DayOfForecast: 0,
 				},
-				CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+				CorrectionStats: store.CorrectionStatsMap{
 					"wu": {
 						"tmin": {
-							0: {MeanBias: -1.5, SampleSize: 10},
+							0: {"all": {MeanBias: -1.5, SampleSize: 10}},
 						},
 					},
 				},
@@ -151,10 +151,10 @@ func TestComputeTodayTemps(t *testing.T) {
 					TempMax:       sql.NullFloat64{Float64: 30, Valid: true},
 					DayOfForecast: 2,
 				},
-				CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+				CorrectionStats: store.CorrectionStatsMap{
 					"bom": {
 						"tmax": {
-							1: {MeanBias: 1.5, SampleSize: 10}, // Day 1 has data, day 2 doesn't
+							1: {"all": {MeanBias: 1.5, SampleSize: 10}}, // Day 1 has data, day 2 doesn't
 						},
 					},
 				},
@@ -169,10 +169,10 @@ func TestComputeTodayTemps(t *testing.T) {
 					TempMax:       sql.NullFloat64{Float64: 30, Valid: true},
 					DayOfForecast: 0,
 				},
-				CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+				CorrectionStats: store.CorrectionStatsMap{
 					"bom": {
 						"tmax": {
-							0: {MeanBias: 10.0, SampleSize: 10}, // Exceeds MaxBiasCorrection
+							0: {"all": {MeanBias: 10.0, SampleSize: 10}}, // Exceeds MaxBiasCorrection
 						},
 					},
 				},
@@ -227,10 +227,10 @@ func TestComputeTodayTemps(t *testing.T) {
 					TempMax:       sql.NullFloat64{Float64: 25, Valid: true},
 					DayOfForecast: 0,
 				},
-				CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+				CorrectionStats: store.CorrectionStatsMap{
 					"bom": {
 						"tmax": {
-							0: {MeanBias: -6.0, SampleSize: 10}, // Would push to 31
+							0: {"all": {MeanBias: -6.0, SampleSize: 10}}, // Would push to 31
 						},
 					},
 				},
@@ -272,12 +272,12 @@ func TestComputeTodayTemps_Explanation(t *testing.T) {
 			TempMin:       sql.NullFloat64{Float64: 12, Valid: true},
 			DayOfForecast: 0,
 		},
-		CorrectionStats: map[string]map[string]map[int]*store.CorrectionStats{
+		CorrectionStats: store.CorrectionStatsMap{
 			"bom": {
-				"tmax": {0: {MeanBias: 2.0, SampleSize: 15}},
+				"tmax": {0: {"all": {MeanBias: 2.0, SampleSize: 15}}},
 			},
 			"wu": {
-				"tmin": {0: {MeanBias: -1.0, SampleSize: 10}},
+				"tmin": {0: {"all": {MeanBias: -1.0, SampleSize: 10}}},
 			},
 		},
 	}
@@ -317,16 +317,163 @@ func TestComputeTodayTemps_Explanation(t *testing.T) {
 	}
 }
 
+func TestComputeTodayTemps_Busted(t *testing.T) {
+	maxOf := func(v float64) *models.Forecast {
+		return &models.Forecast{TempMax: sql.NullFloat64{Float64: v, Valid: true}}
+	}
+	tests := []struct {
+		name         string
+		input        TodayTempInput
+		wantMax      float64
+		wantBusted   bool
+		wantFrom     float64
+		wantForecast float64 // the max before the bust override, when busted
+	}{
+		{
+			// BOM is dropped for running 5° under, and WU is still 4° under
+			name: "mid-morning reading past both sources",
+			input: TodayTempInput{
+				BOMForecast:    maxOf(25),
+				WUForecast:     maxOf(26),
+				CurrentTemp:    30,
+				HasCurrentTemp: true,
+				Hour:           11,
+			},
+			wantMax:      32, // 30 plus 4 hours of 0.5°/hr to the peak
+			wantBusted:   true,
+			wantFrom:     26,
+			wantForecast: 26,
+		},
+		{
+			name: "early morning warming is capped",
+			input: TodayTempInput{
+				WUForecast:     maxOf(20),
+				CurrentTemp:    24,
+				HasCurrentTemp: true,
+				Hour:           8,
+			},
+			wantMax:      27,
+			wantBusted:   true,
+			wantFrom:     20,
+			wantForecast: 20,
+		},
+		{
+			name: "early afternoon leaves little warming",
+			input: TodayTempInput{
+				WUForecast:     maxOf(26),
+				CurrentTemp:    29.4,
+				HasCurrentTemp: true,
+				Hour:           14,
+			},
+			wantMax:      30,
+			wantBusted:   true,
+			wantFrom:     26,
+			wantForecast: 26,
+		},
+		{
+			// In production the observed max already includes the current reading
+			name: "observed max equal to the current reading",
+			input: TodayTempInput{
+				WUForecast:       maxOf(26),
+				CurrentTemp:      30,
+				HasCurrentTemp:   true,
+				ObservedMax:      30,
+				ObservedMaxValid: true,
+				Hour:             11,
+			},
+			wantMax:      32,
+			wantBusted:   true,
+			wantFrom:     26,
+			wantForecast: 30,
+		},
+		{
+			name: "observed max above the current reading",
+			input: TodayTempInput{
+				BOMForecast:      maxOf(27),
+				CurrentTemp:      29.6,
+				HasCurrentTemp:   true,
+				ObservedMax:      30.6,
+				ObservedMaxValid: true,
+				Hour:             11,
+			},
+			wantMax:      32,
+			wantBusted:   true,
+			wantFrom:     27,
+			wantForecast: 31,
+		},
+		{
+			name: "observed max already past the projection",
+			input: TodayTempInput{
+				WUForecast:       maxOf(26),
+				CurrentTemp:      29,
+				HasCurrentTemp:   true,
+				ObservedMax:      33,
+				ObservedMaxValid: true,
+				Hour:             11,
+			},
+			wantMax: 33,
+		},
+		{
+			name: "within the margin keeps the forecast",
+			input: TodayTempInput{
+				WUForecast:     maxOf(26),
+				CurrentTemp:    27.5,
+				HasCurrentTemp: true,
+				Hour:           11,
+			},
+			wantMax: 26,
+		},
+		{
+			name: "past the peak uses the observed max",
+			input: TodayTempInput{
+				WUForecast:       maxOf(26),
+				CurrentTemp:      30,
+				HasCurrentTemp:   true,
+				ObservedMax:      31.2,
+				ObservedMaxValid: true,
+				Hour:             16,
+				TempFalling:      true,
+			},
+			wantMax: 31,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ComputeTodayTemps(tt.input)
+			exp := result.Explanation
+			if result.TempMax != tt.wantMax || exp.MaxFinal != tt.wantMax {
+				t.Errorf("TempMax = %v, MaxFinal = %v, want %v", result.TempMax, exp.MaxFinal, tt.wantMax)
+			}
+			if exp.MaxBusted != tt.wantBusted {
+				t.Errorf("MaxBusted = %v, want %v", exp.MaxBusted, tt.wantBusted)
+			}
+			if tt.wantBusted && exp.MaxBustedFrom != tt.wantFrom {
+				t.Errorf("MaxBustedFrom = %v, want %v", exp.MaxBustedFrom, tt.wantFrom)
+			}
+			wantForecast := tt.wantMax
+			if tt.wantBusted {
+				wantForecast = tt.wantForecast
+			}
+			if exp.MaxForecast != wantForecast {
+				t.Errorf("MaxForecast = %v, want %v", exp.MaxForecast, wantForecast)
+			}
+		})
+	}
+}
+
 func TestLookupBiasWithFallback(t *testing.T) {
 	tests := []struct {
 		name          string
-		stats         map[string]map[string]map[int]*store.CorrectionStats
+		stats         store.CorrectionStatsMap
 		source        string
 		target        string
 		dayOfForecast int
+		regime        string
 		wantBias      float64
 		wantDayUsed   int
 		wantFallback  bool
+		wantRegime    string
 	}{
 		{
 			name:          "nil stats returns no bias",
@@ -338,8 +485,8 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "exact day match",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
-				"bom": {"tmax": {0: {MeanBias: 2.0, SampleSize: 10}}},
+			stats: store.CorrectionStatsMap{
+				"bom": {"tmax": {0: {"all": {MeanBias: 2.0, SampleSize: 10}}}},
 			},
 			source:        "bom",
 			target:        "tmax",
@@ -350,8 +497,8 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "falls back to lower day",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
-				"bom": {"tmax": {1: {MeanBias: 1.5, SampleSize: 10}}},
+			stats: store.CorrectionStatsMap{
+				"bom": {"tmax": {1: {"all": {MeanBias: 1.5, SampleSize: 10}}}},
 			},
 			source:        "bom",
 			target:        "tmax",
@@ -362,8 +509,8 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "falls back to higher day when lower unavailable",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
-				"bom": {"tmax": {3: {MeanBias: 1.0, SampleSize: 10}}},
+			stats: store.CorrectionStatsMap{
+				"bom": {"tmax": {3: {"all": {MeanBias: 1.0, SampleSize: 10}}}},
 			},
 			source:        "bom",
 			target:        "tmax",
@@ -374,10 +521,10 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "skips days with insufficient samples",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
+			stats: store.CorrectionStatsMap{
 				"bom": {"tmax": {
-					0: {MeanBias: 5.0, SampleSize: 3}, // Too few
-					1: {MeanBias: 2.0, SampleSize: 10},
+					0: {"all": {MeanBias: 5.0, SampleSize: 3}}, // Too few
+					1: {"all": {MeanBias: 2.0, SampleSize: 10}},
 				}},
 			},
 			source:        "bom",
@@ -389,8 +536,8 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "caps positive bias at max",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
-				"wu": {"tmin": {0: {MeanBias: 10.0, SampleSize: 10}}},
+			stats: store.CorrectionStatsMap{
+				"wu": {"tmin": {0: {"all": {MeanBias: 10.0, SampleSize: 10}}}},
 			},
 			source:        "wu",
 			target:        "tmin",
@@ -401,8 +548,8 @@ func TestLookupBiasWithFallback(t *testing.T) {
 		},
 		{
 			name: "caps negative bias at max",
-			stats: map[string]map[string]map[int]*store.CorrectionStats{
-				"wu": {"tmax": {0: {MeanBias: -10.0, SampleSize: 10}}},
+			stats: store.CorrectionStatsMap{
+				"wu": {"tmax": {0: {"all": {MeanBias: -10.0, SampleSize: 10}}}},
 			},
 			source:        "wu",
 			target:        "tmax",
@@ -411,11 +558,62 @@ func TestLookupBiasWithFallback(t *testing.T) {
 			wantDayUsed:   0,
 			wantFallback:  false,
 		},
+		{
+			name: "prefers regime-specific bias when available",
+			stats: store.CorrectionStatsMap{
+				"bom": {"tmax": {1: {
+					"all":      {MeanBias: 1.0, SampleSize: 30},
+					"heatwave": {MeanBias: 3.0, SampleSize: 20},
+				}}},
+			},
+			source:        "bom",
+			target:        "tmax",
+			dayOfForecast: 1,
+			regime:        "heatwave",
+			wantBias:      3.0,
+			wantDayUsed:   1,
+			wantFallback:  false,
+			wantRegime:    "heatwave",
+		},
+		{
+			name: "uses all-regime bias when regime has no stats",
+			stats: store.CorrectionStatsMap{
+				"bom": {"tmax": {1: {
+					"all":       {MeanBias: 1.0, SampleSize: 30},
+					"inversion": {MeanBias: -2.0, SampleSize: 20},
+				}}},
+			},
+			source:        "bom",
+			target:        "tmax",
+			dayOfForecast: 1,
+			regime:        "heatwave",
+			wantBias:      1.0,
+			wantDayUsed:   1,
+			wantFallback:  false,
+			wantRegime:    "all",
+		},
+		{
+			name: "uses all-regime bias when regime has too few samples",
+			stats: store.CorrectionStatsMap{
+				"wu": {"tmin": {0: {
+					"all":       {MeanBias: 0.5, SampleSize: 30},
+					"inversion": {MeanBias: 4.0, SampleSize: 5},
+				}}},
+			},
+			source:        "wu",
+			target:        "tmin",
+			dayOfForecast: 0,
+			regime:        "inversion",
+			wantBias:      0.5,
+			wantDayUsed:   0,
+			wantFallback:  false,
+			wantRegime:    "all",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := LookupBiasWithFallback(tt.stats, tt.source, tt.target, tt.dayOfForecast)
+			result := LookupBiasWithFallback(tt.stats, tt.source, tt.target, tt.dayOfForecast, tt.regime)
 
 			if result.DayUsed != tt.wantDayUsed {
 				t.Errorf("DayUsed = %v, want %v", result.DayUsed, tt.wantDayUsed)
@@ -426,6 +624,9 @@ func TestLookupBiasWithFallback(t *testing.T) {
 			if result.IsFallback != tt.wantFallback {
 				t.Errorf("IsFallback = %v, want %v", result.IsFallback, tt.wantFallback)
 			}
+			if tt.wantRegime != "" && result.Regime != tt.wantRegime {
+				t.Errorf("Regime = %q, want %q", result.Regime, tt.wantRegime)
+			}
 		})
 	}
 }
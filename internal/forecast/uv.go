@@ -0,0 +1,33 @@
+package forecast
+
+import "math"
+
+// UV index categories, following the WHO bands.
+const (
+	UVLow      = "Low"
+	UVModerate = "Moderate"
+	UVHigh     = "High"
+	UVVeryHigh = "Very high"
+	UVExtreme  = "Extreme"
+)
+
+// UVAdvice maps a UV index onto its WHO category and sun-protection advice.
+// The index is rounded to a whole number first, as it is reported. Negative
+// values return empty strings.
+func UVAdvice(uv float64) (category string, advice string) {
+	if uv < 0 {
+		return "", ""
+	}
+	switch index := math.Round(uv); {
+	case index <= 2:
+		return UVLow, "No sun protection needed."
+	case index <= 5:
+		return UVModerate, "Wear a hat and sunscreen, and seek shade around midday."
+	case index <= 7:
+		return UVHigh, "Cover up with a shirt, hat, sunglasses and SPF 30+ sunscreen; seek shade around midday."
+	case index <= 10:
+		return UVVeryHigh, "Extra protection needed: avoid the midday sun and cover up with SPF 50+ sunscreen."
+	default:
+		return UVExtreme, "Avoid being outside around midday; shirt, hat, sunglasses and sunscreen are a must."
+	}
+}
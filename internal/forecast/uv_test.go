@@ -0,0 +1,37 @@
+package forecast
+
+import "testing"
+
+func TestUVAdvice(t *testing.T) {
+	tests := []struct {
+		uv   float64
+		want string
+	}{
+		{0, UVLow},
+		{2, UVLow},
+		{2.4, UVLow},
+		{2.5, UVModerate},
+		{3, UVModerate},
+		{5, UVModerate},
+		{6, UVHigh},
+		{7, UVHigh},
+		{8, UVVeryHigh},
+		{10, UVVeryHigh},
+		{11, UVExtreme},
+		{14, UVExtreme},
+	}
+
+	for _, tt := range tests {
+		category, advice := UVAdvice(tt.uv)
+		if category != tt.want {
+			t.Errorf("UVAdvice(%v) category = %q, want %q", tt.uv, category, tt.want)
+		}
+		if advice == "" {
+			t.Errorf("UVAdvice(%v) advice is empty", tt.uv)
+		}
+	}
+
+	if category, advice := UVAdvice(-1); category != "" || advice != "" {
+		t.Errorf("UVAdvice(-1) = %q, %q, want empty", category, advice)
+	}
+}
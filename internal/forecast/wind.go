@@ -0,0 +1,76 @@
+package forecast
+
+import "math"
+
+// cardinalPoints are the 16 compass points clockwise from north, each
+// covering 22.5° centred on its bearing.
+var cardinalPoints = [16]string{
+	"N", "NNE", "NE", "ENE",
+	"E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW",
+	"W", "WNW", "NW", "NNW",
+}
+
+// DegreesToCardinal returns the 16-point compass direction for a bearing in
+// degrees, e.g. 315 is "NW". North covers 348.75° through 11.25°, and bearings
+// outside 0-359 are wrapped.
+func DegreesToCardinal(deg int) string {
+	return cardinalPoints[int(math.Floor((wrapBearing(deg)+11.25)/22.5))%16]
+}
+
+// DegreesToCompassPoint returns the nearest of the eight principal compass
+// points to a bearing in degrees, e.g. 100 is "E", for prose such as "light
+// NW wind". Bearings outside 0-359 are wrapped.
+func DegreesToCompassPoint(deg int) string {
+	return cardinalPoints[2*(int(math.Floor((wrapBearing(deg)+22.5)/45))%8)]
+}
+
+// wrapBearing returns deg as a bearing from 0 up to 360.
+func wrapBearing(deg int) float64 {
+	bearing := math.Mod(float64(deg), 360)
+	if bearing < 0 {
+		bearing += 360
+	}
+	return bearing
+}
+
+// Wind advisories for outdoor activities such as paragliding and cycling.
+const (
+	WindCalm        = "calm"
+	WindBreezy      = "breezy"
+	WindStrongGusts = "strong gusts — caution"
+	WindDangerous   = "dangerous"
+)
+
+// Advisory thresholds in km/h. Gusts matter more than the mean wind for
+// paragliders and cyclists, so each band has a gust limit as well.
+const (
+	breezySustained    = 12.0
+	breezyGust         = 20.0
+	strongGust         = 30.0
+	dangerousSustained = 40.0
+	dangerousGust      = 55.0
+)
+
+// WindAdvisory grades sustained wind and gusts (km/h) for outdoor activities,
+// returning WindCalm, WindBreezy, WindStrongGusts or WindDangerous. A gust
+// below the sustained speed (e.g. none reported) counts as the sustained
+// speed. A negative or NaN sustained speed returns "".
+func WindAdvisory(sustainedKmh, gustKmh float64) string {
+	if math.IsNaN(sustainedKmh) || sustainedKmh < 0 {
+		return ""
+	}
+	if math.IsNaN(gustKmh) || gustKmh < sustainedKmh {
+		gustKmh = sustainedKmh
+	}
+	switch {
+	case sustainedKmh >= dangerousSustained || gustKmh >= dangerousGust:
+		return WindDangerous
+	case gustKmh >= strongGust:
+		return WindStrongGusts
+	case sustainedKmh >= breezySustained || gustKmh >= breezyGust:
+		return WindBreezy
+	default:
+		return WindCalm
+	}
+}
@@ -0,0 +1,110 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDegreesToCardinal(t *testing.T) {
+	tests := []struct {
+		deg  int
+		want string
+	}{
+		// Each sector's centre
+		{0, "N"},
+		{22, "NNE"},
+		{45, "NE"},
+		{68, "ENE"},
+		{90, "E"},
+		{112, "ESE"},
+		{135, "SE"},
+		{158, "SSE"},
+		{180, "S"},
+		{202, "SSW"},
+		{225, "SW"},
+		{248, "WSW"},
+		{270, "W"},
+		{292, "WNW"},
+		{315, "NW"},
+		{338, "NNW"},
+
+		// North wraps around 0°, from 348.75° to 11.25°
+		{348, "NNW"},
+		{349, "N"},
+		{359, "N"},
+		{360, "N"},
+		{11, "N"},
+		{12, "NNE"},
+
+		// Sector edges elsewhere
+		{33, "NNE"},
+		{34, "NE"},
+		{191, "S"},
+		{192, "SSW"},
+
+		// Out-of-range bearings wrap
+		{-45, "NW"},
+		{405, "NE"},
+	}
+
+	for _, tt := range tests {
+		if got := DegreesToCardinal(tt.deg); got != tt.want {
+			t.Errorf("DegreesToCardinal(%d) = %q, want %q", tt.deg, got, tt.want)
+		}
+	}
+}
+
+func TestDegreesToCompassPoint(t *testing.T) {
+	tests := []struct {
+		deg  int
+		want string
+	}{
+		{0, "N"},
+		{22, "N"},
+		{23, "NE"},
+		{100, "E"},
+		{158, "S"},
+		{315, "NW"},
+		{337, "NW"},
+		{338, "N"},
+		{-90, "W"},
+		{405, "NE"},
+	}
+
+	for _, tt := range tests {
+		if got := DegreesToCompassPoint(tt.deg); got != tt.want {
+			t.Errorf("DegreesToCompassPoint(%d) = %q, want %q", tt.deg, got, tt.want)
+		}
+	}
+}
+
+func TestWindAdvisory(t *testing.T) {
+	tests := []struct {
+		name            string
+		sustained, gust float64
+		want            string
+	}{
+		{"still air", 0, 0, WindCalm},
+		{"light wind", 8, 15, WindCalm},
+		{"no gust reported", 8, math.NaN(), WindCalm},
+		{"gust below sustained", 8, 0, WindCalm},
+
+		{"breezy sustained", 12, 12, WindBreezy},
+		{"breezy gusts", 5, 20, WindBreezy},
+		{"just under strong gusts", 18, 29.9, WindBreezy},
+
+		{"strong gusts", 15, 30, WindStrongGusts},
+		{"just under dangerous", 39.9, 54.9, WindStrongGusts},
+
+		{"dangerous sustained", 40, 0, WindDangerous},
+		{"dangerous gusts", 20, 55, WindDangerous},
+
+		{"negative sustained", -1, 10, ""},
+		{"NaN sustained", math.NaN(), 10, ""},
+	}
+	for _, tt := range tests {
+		if got := WindAdvisory(tt.sustained, tt.gust); got != tt.want {
+			t.Errorf("%s: WindAdvisory(%v, %v) = %q, want %q", tt.name, tt.sustained, tt.gust, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// zambrettiTrendThreshold is the 3-hour pressure change (hPa) beyond which the
+// barometer counts as rising or falling rather than steady.
+const zambrettiTrendThreshold = 1.6
+
+// zambrettiForecasts are the Negretti & Zambra forecasts, lettered A to Z.
+var zambrettiForecasts = [26]string{
+	"Settled fine",
+	"Fine weather",
+	"Becoming fine",
+	"Fine, becoming less settled",
+	"Fine, possible showers",
+	"Fairly fine, improving",
+	"Fairly fine, possible showers early",
+	"Fairly fine, showery later",
+	"Showery early, improving",
+	"Changeable, mending",
+	"Fairly fine, showers likely",
+	"Rather unsettled clearing later",
+	"Unsettled, probably improving",
+	"Showery, bright intervals",
+	"Showery, becoming less settled",
+	"Changeable, some rain",
+	"Unsettled, short fine intervals",
+	"Unsettled, rain later",
+	"Unsettled, some rain",
+	"Mostly very unsettled",
+	"Occasional rain, worsening",
+	"Rain at times, very unsettled",
+	"Rain at frequent intervals",
+	"Rain, very unsettled",
+	"Stormy, may improve",
+	"Stormy, much rain",
+}
+
+// Forecast letters (0 = A) for each Z number, by pressure tendency.
+var (
+	zambrettiFalling = []int{0, 1, 3, 7, 14, 17, 20, 23, 25}              // Z 1-9
+	zambrettiSteady  = []int{0, 1, 4, 10, 13, 15, 18, 22, 23, 25}         // Z 10-19
+	zambrettiRising  = []int{0, 1, 2, 5, 6, 8, 9, 11, 12, 16, 19, 24, 25} // Z 20-32
+)
+
+// zambrettiWindAdjust is the pressure correction (hPa) for each of the 16
+// compass points, starting at north, for the northern hemisphere. Winds from
+// the pole are fairer than winds from the equator.
+var zambrettiWindAdjust = [16]float64{
+	6, 5, 5, 2, -0.5, -2, -5, -8.5, -12, -10, -6, -4.5, -3, -0.5, 1.5, 3,
+}
+
+// Zambretti returns the Zambretti forecaster's verdict for the next 12 hours
+// or so from sea-level pressure (hPa), its change over the last three hours
+// (hPa), the month and the wind direction in degrees (negative if calm or
+// unknown). It is tuned for the southern hemisphere, so southerlies count as
+// fair and October to March as summer.
+func Zambretti(pressureHpa, trend float64, month time.Month, windDir int) string {
+	p := pressureHpa
+
+	if windDir >= 0 {
+		// Mirror north and south to use the northern hemisphere table
+		mirrored := math.Mod(540-float64(windDir), 360)
+		point := int(math.Round(mirrored/22.5)) % 16
+		p += zambrettiWindAdjust[point]
+	}
+
+	// Summer exaggerates the tendency in either direction
+	summer := month >= time.October || month <= time.March
+	switch {
+	case trend >= zambrettiTrendThreshold:
+		if summer {
+			p += 7
+		}
+		return zambrettiLookup(185-0.16*p, 20, zambrettiRising)
+	case trend <= -zambrettiTrendThreshold:
+		if summer {
+			p -= 7
+		}
+		return zambrettiLookup(127-0.12*p, 1, zambrettiFalling)
+	default:
+		return zambrettiLookup(144-0.13*p, 10, zambrettiSteady)
+	}
+}
+
+// zambrettiLookup maps a Z number onto a tendency's letters, clamping to the
+// ends of the table for exceptionally high or low pressure.
+func zambrettiLookup(z float64, first int, letters []int) string {
+	i := int(math.Round(z)) - first
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(letters) {
+		i = len(letters) - 1
+	}
+	return zambrettiForecasts[letters[i]]
+}
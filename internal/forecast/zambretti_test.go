@@ -0,0 +1,38 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZambretti(t *testing.T) {
+	tests := []struct {
+		name     string
+		pressure float64
+		trend    float64
+		month    time.Month
+		windDir  int
+		want     string
+	}{
+		{"high steady", 1030, 0, time.July, -1, "Settled fine"},
+		{"mid steady", 1015, 0.5, time.July, -1, "Fine, possible showers"},
+		{"high falling", 1025, -2, time.July, -1, "Fairly fine, showery later"},
+		{"low falling", 1000, -3, time.July, -1, "Occasional rain, worsening"},
+		{"mid rising", 1020, 2, time.July, -1, "Becoming fine"},
+		{"low rising", 990, 2, time.July, -1, "Rather unsettled clearing later"},
+		{"very low falling clamps", 940, -3, time.July, -1, "Stormy, much rain"},
+		{"summer rising is fairer", 1020, 2, time.January, -1, "Fine weather"},
+		{"summer falling is worse", 1025, -2, time.January, -1, "Showery, becoming less settled"},
+		{"southerly is fairer", 1015, 0, time.July, 180, "Fine weather"},
+		{"northerly is worse", 1015, 0, time.July, 0, "Showery, bright intervals"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Zambretti(tt.pressure, tt.trend, tt.month, tt.windDir)
+			if got != tt.want {
+				t.Errorf("Zambretti(%v, %v, %v, %d) = %q, want %q", tt.pressure, tt.trend, tt.month, tt.windDir, got, tt.want)
+			}
+		})
+	}
+}
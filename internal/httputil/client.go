@@ -1,15 +1,83 @@
 package httputil
 
 import (
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"syscall"
 	"time"
+
+	"github.com/cenkalti/backoff/v4"
 )
 
 const DefaultTimeout = 30 * time.Second
 
+// maxRetries is how many times RetryTransport retries a request after the
+// first attempt fails.
+const maxRetries = 3
+
 // NewClient returns an HTTP client with standard timeout configuration.
 func NewClient() *http.Client {
 	return &http.Client{
 		Timeout: DefaultTimeout,
 	}
 }
+
+// NewClientWithOptions returns an HTTP client that retries idempotent
+// requests on transient network errors and sends userAgent on requests that
+// don't set their own. The timeout covers the whole request, retries
+// included.
+func NewClientWithOptions(timeout time.Duration, userAgent string) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &RetryTransport{UserAgent: userAgent},
+	}
+}
+
+// RetryTransport is an http.RoundTripper that retries GET and HEAD requests
+// with exponential backoff when they fail with a transient network error,
+// such as a reset connection or a timeout. Responses are returned as-is,
+// whatever their status.
+type RetryTransport struct {
+	Base      http.RoundTripper // nil uses http.DefaultTransport
+	UserAgent string            // set on requests without a User-Agent
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.UserAgent)
+	}
+
+	// A body can only be sent once, so only bodiless reads are retried
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	if !idempotent || (req.Body != nil && req.Body != http.NoBody) {
+		return base.RoundTrip(req)
+	}
+
+	operation := func() (*http.Response, error) {
+		resp, err := base.RoundTrip(req)
+		if err != nil && !isTransient(err) {
+			return nil, backoff.Permanent(err)
+		}
+		return resp, err
+	}
+	bo := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries)
+	return backoff.RetryWithData(operation, backoff.WithContext(bo, req.Context()))
+}
+
+// isTransient reports whether err is a network failure that may succeed if
+// the request is tried again.
+func isTransient(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,51 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport_RecoversFromDroppedConnection(t *testing.T) {
+	var attempts atomic.Int32
+	var userAgent atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Drop the first connection without a response
+		if attempts.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		userAgent.Store(r.UserAgent())
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := NewClientWithOptions(10*time.Second, "WandiWeather/test")
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want %q", body, "ok")
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if got := userAgent.Load(); got != "WandiWeather/test" {
+		t.Errorf("User-Agent = %v, want WandiWeather/test", got)
+	}
+}
@@ -1,32 +1,53 @@
 package ingest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/jlaffaye/ftp"
+	"github.com/lox/wandiweather/internal/httputil"
 	"github.com/lox/wandiweather/internal/models"
 )
 
 const (
 	bomFTPHost     = "ftp.bom.gov.au:21"
 	bomForecastFile = "/anon/gen/fwo/IDV10753.xml"
+	bomHTTPURL     = "http://reg.bom.gov.au/fwo/IDV10753.xml" // HTTP mirror, used when FTP is down
 	wangarattaAAC  = "VIC_PT075"
 )
 
+// Transports recorded in FetchResult.Transport for BOM fetches.
+const (
+	TransportFTP  = "ftp"
+	TransportHTTP = "http"
+)
+
 type BOMClient struct {
-	areaCode string
+	areaCode   string
+	loc        *time.Location // local days forecast periods are assigned to
+	ftpHost    string
+	httpURL    string
+	httpClient *http.Client
 }
 
-func NewBOMClient(areaCode string) *BOMClient {
+func NewBOMClient(areaCode string, loc *time.Location) *BOMClient {
 	if areaCode == "" {
 		areaCode = wangarattaAAC
 	}
-	return &BOMClient{areaCode: areaCode}
+	return &BOMClient{
+		areaCode:   areaCode,
+		loc:        loc,
+		ftpHost:    bomFTPHost,
+		httpURL:    bomHTTPURL,
+		httpClient: httputil.NewClient(),
+	}
 }
 
 type bomProduct struct {
@@ -69,35 +90,26 @@ type bomText struct {
 	Value string `xml:",chardata"`
 }
 
-func (b *BOMClient) FetchForecasts() ([]models.Forecast, string, *FetchResult, error) {
+func (b *BOMClient) FetchForecasts(ctx context.Context) ([]models.Forecast, string, *FetchResult, error) {
 	result := &FetchResult{}
 
-	conn, err := ftp.Dial(bomFTPHost, ftp.DialWithTimeout(30*time.Second))
-	if err != nil {
-		result.Error = fmt.Errorf("ftp dial: %w", err)
-		return nil, "", result, result.Error
-	}
-	defer conn.Quit()
-
-	if err := conn.Login("anonymous", "anonymous"); err != nil {
-		result.Error = fmt.Errorf("ftp login: %w", err)
-		return nil, "", result, result.Error
-	}
-
-	resp, err := conn.Retr(bomForecastFile)
-	if err != nil {
-		result.Error = fmt.Errorf("ftp retr: %w", err)
-		return nil, "", result, result.Error
-	}
-	defer resp.Close()
-
-	body, err := io.ReadAll(resp)
-	if err != nil {
-		result.Error = fmt.Errorf("read body: %w", err)
-		return nil, "", result, result.Error
+	body, ftpErr := b.fetchFTP(ctx)
+	if ftpErr == nil {
+		result.Transport = TransportFTP
+		result.HTTPStatus = 200 // FTP success
+	} else {
+		log.Printf("bom: %v, falling back to HTTP", ftpErr)
+		var status int
+		var err error
+		body, status, err = b.fetchHTTP(ctx)
+		result.HTTPStatus = status
+		if err != nil {
+			result.Error = fmt.Errorf("%v; http fallback: %w", ftpErr, err)
+			return nil, "", result, result.Error
+		}
+		result.Transport = TransportHTTP
 	}
 	result.ResponseSize = len(body)
-	result.HTTPStatus = 200 // FTP success
 
 	var product bomProduct
 	if err := xml.Unmarshal(body, &product); err != nil {
@@ -121,15 +133,13 @@ func (b *BOMClient) FetchForecasts() ([]models.Forecast, string, *FetchResult, e
 	var forecasts []models.Forecast
 	var parseErrors []string
 
-	mel, _ := time.LoadLocation("Australia/Melbourne")
-
 	for _, period := range targetArea.Periods {
 		startTime, err := time.Parse(time.RFC3339, period.StartTime)
 		if err != nil {
 			parseErrors = append(parseErrors, fmt.Sprintf("period[%d].StartTime=%q: %v", period.Index, period.StartTime, err))
 			continue
 		}
-		localStart := startTime.In(mel)
+		localStart := startTime.In(b.loc)
 		validDate := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, time.UTC)
 
 		fc := models.Forecast{
@@ -170,6 +180,13 @@ func (b *BOMClient) FetchForecasts() ([]models.Forecast, string, *FetchResult, e
 			}
 		}
 
+		// Late in the day BOM drops today's temperatures and leaves just the
+		// precis. Keep that as a narrative-only forecast, but skip a period
+		// with nothing usable at all.
+		if fc.NarrativeOnly() && !fc.Narrative.Valid && !fc.PrecipChance.Valid {
+			continue
+		}
+
 		forecasts = append(forecasts, fc)
 	}
 
@@ -181,3 +198,57 @@ func (b *BOMClient) FetchForecasts() ([]models.Forecast, string, *FetchResult, e
 
 	return forecasts, string(body), result, nil
 }
+
+// fetchFTP retrieves the forecast XML from the BoM anonymous FTP server.
+// The FTP library only takes a context for the dial, so cancelling ctx closes
+// the connection to abort a login or transfer that's in progress.
+func (b *BOMClient) fetchFTP(ctx context.Context) ([]byte, error) {
+	conn, err := ftp.Dial(b.ftpHost, ftp.DialWithTimeout(30*time.Second), ftp.DialWithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("ftp dial: %w", err)
+	}
+	defer conn.Quit()
+	stop := context.AfterFunc(ctx, func() { conn.Quit() })
+	defer stop()
+
+	if err := conn.Login("anonymous", "anonymous"); err != nil {
+		return nil, fmt.Errorf("ftp login: %w", err)
+	}
+
+	resp, err := conn.Retr(bomForecastFile)
+	if err != nil {
+		return nil, fmt.Errorf("ftp retr: %w", err)
+	}
+	defer resp.Close()
+
+	body, err := io.ReadAll(resp)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	return body, nil
+}
+
+// fetchHTTP retrieves the forecast XML from the BoM HTTP mirror.
+func (b *BOMClient) fetchHTTP(ctx context.Context) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", b.httpURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("http request: %w", err)
+	}
+	// The BoM rejects requests with Go's default user agent
+	req.Header.Set("User-Agent", "WandiWeather/1.0")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("http get: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	return body, resp.StatusCode, nil
+}
@@ -12,40 +12,91 @@ import (
 )
 
 type DailyJobs struct {
-	store *store.Store
+	store            *store.Store
+	rawRetentionDays int
 }
 
 func NewDailyJobs(store *store.Store) *DailyJobs {
-	return &DailyJobs{store: store}
+	return &DailyJobs{store: store, rawRetentionDays: DefaultRawRetentionDays}
 }
 
-const rawPayloadRetentionDays = 90
+// DefaultRawRetentionDays is how long raw API payloads are kept when no
+// retention is configured.
+const DefaultRawRetentionDays = 90
 
-func (d *DailyJobs) RunAll(forDate time.Time) error {
-	log.Printf("daily: running jobs for %s", forDate.Format("2006-01-02"))
+// SetRawRetentionDays sets how many days of raw payloads the nightly run
+// keeps. Zero or less keeps them forever.
+func (d *DailyJobs) SetRawRetentionDays(days int) {
+	d.rawRetentionDays = days
+}
+
+// DailyResult summarises what a daily or backfill run did, for scripting the
+// CLI commands. In a dry run the counts are what would have been written.
+type DailyResult struct {
+	Date                   string   `json:"date,omitempty"` // YYYY-MM-DD; empty for backfills
+	DryRun                 bool     `json:"dry_run"`
+	SummariesComputed      int      `json:"summaries_computed"`
+	ForecastsVerified      int      `json:"forecasts_verified"`
+	CorrectionStatsUpdated int      `json:"correction_stats_updated"`
+	Errors                 []string `json:"errors"`
+}
+
+// Merge adds another run's counts and errors to r.
+func (r *DailyResult) Merge(other DailyResult) {
+	r.SummariesComputed += other.SummariesComputed
+	r.ForecastsVerified += other.ForecastsVerified
+	r.CorrectionStatsUpdated += other.CorrectionStatsUpdated
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
+// RunAll runs the daily jobs for forDate. With dryRun set, summaries and
+// verifications are computed and logged but nothing in the database changes,
+// so backfills can be checked before they are written. The result is filled
+// in even when an error is returned.
+func (d *DailyJobs) RunAll(forDate time.Time, dryRun bool) (DailyResult, error) {
+	result := DailyResult{Date: forDate.Format("2006-01-02"), DryRun: dryRun, Errors: []string{}}
+	if dryRun {
+		log.Printf("daily: dry run for %s, nothing will be written", result.Date)
+	} else {
+		log.Printf("daily: running jobs for %s", result.Date)
+	}
 
 	var errs []error
 
-	if err := d.ComputeDailySummaries(forDate); err != nil {
+	if summaries, err := d.ComputeDailySummaries(forDate, dryRun); err != nil {
 		log.Printf("daily: summaries error: %v", err)
 		errs = append(errs, fmt.Errorf("summaries: %w", err))
+	} else {
+		result.SummariesComputed = len(summaries)
 	}
 
-	if err := d.VerifyForecasts(forDate); err != nil {
+	if verifications, err := d.VerifyForecasts(forDate, dryRun); err != nil {
 		log.Printf("daily: verification error: %v", err)
 		errs = append(errs, fmt.Errorf("verification: %w", err))
+	} else {
+		result.ForecastsVerified = len(verifications)
+	}
+
+	if dryRun {
+		log.Println("daily: dry run, skipping correction stats, cleanup and vacuum")
+		d.LogIngestHealth()
+		return result, dailyError(&result, errs)
 	}
 
 	corrector := forecast.NewBiasCorrector(d.store)
-	if err := corrector.ComputeStats(30); err != nil {
+	updated, err := corrector.ComputeStats(30)
+	result.CorrectionStatsUpdated = updated
+	if err != nil {
 		log.Printf("daily: correction stats error: %v", err)
 		errs = append(errs, fmt.Errorf("correction stats: %w", err))
 	}
 
-	if deleted, err := d.store.CleanupOldRawPayloads(rawPayloadRetentionDays); err != nil {
-		log.Printf("daily: cleanup raw payloads error: %v", err)
-	} else if deleted > 0 {
-		log.Printf("daily: cleaned up %d old raw payloads (>%d days)", deleted, rawPayloadRetentionDays)
+	if d.rawRetentionDays > 0 {
+		if deleted, err := d.store.CleanupOldRawPayloads(d.rawRetentionDays); err != nil {
+			log.Printf("daily: cleanup raw payloads error: %v", err)
+		} else {
+			log.Printf("daily: cleaned up %d old raw payloads (>%d days)", deleted, d.rawRetentionDays)
+		}
 	}
 
 	if err := d.store.VacuumDatabase(); err != nil {
@@ -56,6 +107,14 @@ func (d *DailyJobs) RunAll(forDate time.Time) error {
 
 	d.LogIngestHealth()
 
+	return result, dailyError(&result, errs)
+}
+
+// dailyError records errs on result and folds them into a single error.
+func dailyError(result *DailyResult, errs []error) error {
+	for _, err := range errs {
+		result.Errors = append(result.Errors, err.Error())
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("daily jobs had %d errors", len(errs))
 	}
@@ -94,10 +153,13 @@ func (d *DailyJobs) LogIngestHealth() {
 	}
 }
 
-func (d *DailyJobs) ComputeDailySummaries(forDate time.Time) error {
+// ComputeDailySummaries computes each active station's summary for forDate
+// and returns the ones it produced. Summaries are only stored when dryRun is
+// false.
+func (d *DailyJobs) ComputeDailySummaries(forDate time.Time, dryRun bool) ([]models.DailySummary, error) {
 	stations, err := d.store.GetActiveStations()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	overnightMins, err := d.store.GetOvernightMinByTier(forDate)
@@ -154,7 +216,17 @@ func (d *DailyJobs) ComputeDailySummaries(forDate time.Time) error {
 		}
 	}
 
-	computed := 0
+	// Each station's readings for the day, loaded together for sunshine hours
+	stationIDs := make([]string, len(stations))
+	for i, st := range stations {
+		stationIDs[i] = st.StationID
+	}
+	dayObservations, err := d.store.GetDayObservationsMulti(stationIDs, forDate, forDate.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("daily: failed to get observations for sunshine hours: %v", err)
+	}
+
+	var computed []models.DailySummary
 	for _, station := range stations {
 		summary, err := d.store.ComputeDailySummary(station.StationID, forDate)
 		if err != nil {
@@ -165,6 +237,10 @@ func (d *DailyJobs) ComputeDailySummaries(forDate time.Time) error {
 			continue
 		}
 
+		if hours, ok := forecast.SunshineHours(dayObservations[station.StationID], station); ok {
+			summary.SunshineHours = sql.NullFloat64{Float64: hours, Valid: true}
+		}
+
 		if station.ElevationTier == "valley_floor" || station.ElevationTier == "local" {
 			summary.InversionDetected = sql.NullBool{Bool: inversionDetected, Valid: true}
 			summary.InversionStrength = sql.NullFloat64{Float64: inversionStrength, Valid: inversionDetected}
@@ -176,53 +252,60 @@ func (d *DailyJobs) ComputeDailySummaries(forDate time.Time) error {
 			summary.RegimeHeatwave = sql.NullBool{Bool: regimes.Heatwave, Valid: true}
 			summary.RegimeInversion = sql.NullBool{Bool: regimes.InversionNight, Valid: true}
 			summary.RegimeClearCalm = sql.NullBool{Bool: regimes.ClearCalm, Valid: true}
+			summary.RegimeColdSnap = sql.NullBool{Bool: regimes.ColdSnap, Valid: true}
+			summary.Regime = sql.NullString{String: forecast.RegimeToString(regimes), Valid: true}
+			if todayForecast != nil && todayForecast.Narrative.Valid {
+				condition := forecast.ExtractCondition(todayForecast.Narrative.String, summary.TempMax.Float64, summary.TempMin.Float64)
+				summary.Condition = sql.NullString{String: string(condition), Valid: true}
+			}
 
-			if regimes.Heatwave || regimes.InversionNight {
-				log.Printf("daily: regime for %s: heatwave=%v inversion=%v",
-					forDate.Format("2006-01-02"), regimes.Heatwave, regimes.InversionNight)
+			if regimes.Heatwave || regimes.ColdSnap || regimes.InversionNight {
+				log.Printf("daily: regime for %s: heatwave=%v cold_snap=%v inversion=%v",
+					forDate.Format("2006-01-02"), regimes.Heatwave, regimes.ColdSnap, regimes.InversionNight)
 			}
 		}
 
-		if err := d.store.UpsertDailySummary(*summary); err != nil {
+		if dryRun {
+			log.Printf("daily: dry run: would upsert summary %s for %s: max=%.1f°C min=%.1f°C regime=%s",
+				station.StationID, forDate.Format("2006-01-02"),
+				summary.TempMax.Float64, summary.TempMin.Float64, summary.Regime.String)
+		} else if err := d.store.UpsertDailySummary(*summary); err != nil {
 			log.Printf("daily: upsert summary %s: %v", station.StationID, err)
 			continue
 		}
-		computed++
+		computed = append(computed, *summary)
 	}
 
-	log.Printf("daily: computed %d summaries for %s", computed, forDate.Format("2006-01-02"))
-	return nil
+	log.Printf("daily: computed %d summaries for %s", len(computed), forDate.Format("2006-01-02"))
+	return computed, nil
 }
 
-func (d *DailyJobs) VerifyForecasts(forDate time.Time) error {
-	hasVerification, err := d.store.HasVerificationForDate(forDate)
-	if err != nil {
-		return err
-	}
-	if hasVerification {
-		log.Printf("daily: verification already exists for %s", forDate.Format("2006-01-02"))
-		return nil
-	}
-
+// VerifyForecasts compares forecasts for forDate against the primary station's
+// actuals and returns the verifications. Re-running it for a date updates the
+// existing verifications; with dryRun set they are only logged.
+func (d *DailyJobs) VerifyForecasts(forDate time.Time, dryRun bool) ([]models.ForecastVerification, error) {
 	primary, err := d.store.GetPrimaryStation()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if primary == nil {
 		log.Println("daily: no primary station configured")
-		return nil
+		return nil, nil
 	}
 
 	actuals, err := d.store.GetActualsForDate(primary.StationID, forDate)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !actuals.TempMax.Valid || !actuals.TempMin.Valid {
 		log.Printf("daily: no actuals for %s on %s", primary.StationID, forDate.Format("2006-01-02"))
-		return nil
+		return nil, nil
 	}
 
-	if actuals.TempMax.Valid {
+	if dryRun {
+		log.Printf("daily: dry run: would set nowcast actual max for %s on %s to %.1f°C",
+			primary.StationID, forDate.Format("2006-01-02"), actuals.TempMax.Float64)
+	} else {
 		if err := d.store.UpdateNowcastActualMax(primary.StationID, forDate, actuals.TempMax.Float64); err != nil {
 			log.Printf("daily: update nowcast actual: %v", err)
 		}
@@ -233,10 +316,10 @@ func (d *DailyJobs) VerifyForecasts(forDate time.Time) error {
 	// not same-day adjustments, and captures complete data (e.g., BOM min temps).
 	forecasts, err := d.store.GetVerificationForecasts(forDate)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	verified := 0
+	var verified []models.ForecastVerification
 	for _, fc := range forecasts {
 		// Each forecast is already unique per (source, day_of_forecast) from the query
 		v := models.ForecastVerification{
@@ -246,6 +329,7 @@ func (d *DailyJobs) VerifyForecasts(forDate time.Time) error {
 			ActualTempMin:  actuals.TempMin,
 			ActualWindGust: actuals.WindGust,
 			ActualPrecip:   actuals.PrecipSum,
+			ActualHumidity: actuals.HumidityAvg,
 		}
 
 		if fc.TempMax.Valid {
@@ -276,89 +360,116 @@ func (d *DailyJobs) VerifyForecasts(forDate time.Time) error {
 				Valid:   true,
 			}
 		}
+		if fc.Humidity.Valid && actuals.HumidityAvg.Valid {
+			v.ForecastHumidity = sql.NullFloat64{Float64: float64(fc.Humidity.Int64), Valid: true}
+			v.BiasHumidity = sql.NullFloat64{
+				Float64: float64(fc.Humidity.Int64) - actuals.HumidityAvg.Float64,
+				Valid:   true,
+			}
+		}
 
-		if err := d.store.InsertForecastVerification(v); err != nil {
-			log.Printf("daily: insert verification: %v", err)
-			continue
+		if !dryRun {
+			if err := d.store.UpsertForecastVerification(v); err != nil {
+				log.Printf("daily: upsert verification: %v", err)
+				continue
+			}
 		}
 
 		log.Printf("daily: verified %s day-%d forecast for %s: temp bias=%.1f/%.1f°C",
 			fc.Source, fc.DayOfForecast, forDate.Format("2006-01-02"),
 			v.BiasTempMax.Float64, v.BiasTempMin.Float64)
-		verified++
+		verified = append(verified, v)
 	}
 
-	log.Printf("daily: verified %d forecasts for %s", verified, forDate.Format("2006-01-02"))
-	return nil
+	log.Printf("daily: verified %d forecasts for %s", len(verified), forDate.Format("2006-01-02"))
+	return verified, nil
 }
 
-func (d *DailyJobs) BackfillSummaries() error {
+// BackfillSummaries recomputes the daily summaries for every date with
+// observations. Failures on individual dates are logged and recorded in the
+// result without stopping the backfill.
+func (d *DailyJobs) BackfillSummaries() (DailyResult, error) {
+	result := DailyResult{Errors: []string{}}
 	log.Println("daily: backfilling all daily summaries")
 
 	stations, err := d.store.GetActiveStations()
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	if len(stations) == 0 {
 		log.Println("daily: no active stations found")
-		return nil
+		return result, nil
 	}
 
 	log.Printf("daily: found %d active stations, using %s for date range", len(stations), stations[0].StationID)
 
 	dates, err := d.store.GetObservationDates(stations[0].StationID)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	log.Printf("daily: found %d dates to backfill", len(dates))
 
 	for _, date := range dates {
-		if err := d.ComputeDailySummaries(date); err != nil {
+		summaries, err := d.ComputeDailySummaries(date, false)
+		if err != nil {
 			log.Printf("daily: backfill %s: %v", date.Format("2006-01-02"), err)
+			result.Errors = append(result.Errors, fmt.Sprintf("summaries %s: %v", date.Format("2006-01-02"), err))
+			continue
 		}
+		result.SummariesComputed += len(summaries)
 	}
 
-	return nil
+	return result, nil
 }
 
-func (d *DailyJobs) BackfillVerification() error {
+// BackfillVerification clears and re-runs forecast verification for every
+// past date with observations, then refreshes the correction stats.
+func (d *DailyJobs) BackfillVerification() (DailyResult, error) {
+	result := DailyResult{Errors: []string{}}
 	log.Println("daily: backfilling forecast verification")
 
 	// Clear existing verification to re-run with updated methodology
 	if err := d.store.ClearVerification(); err != nil {
-		return fmt.Errorf("clear verification: %w", err)
+		return result, fmt.Errorf("clear verification: %w", err)
 	}
 	log.Println("daily: cleared existing verification records")
 
 	primary, err := d.store.GetPrimaryStation()
 	if err != nil {
-		return err
+		return result, err
 	}
 	if primary == nil {
 		log.Println("daily: no primary station")
-		return nil
+		return result, nil
 	}
 
 	dates, err := d.store.GetObservationDates(primary.StationID)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	for _, date := range dates {
 		if date.After(time.Now().Add(-24 * time.Hour)) {
 			continue
 		}
-		if err := d.VerifyForecasts(date); err != nil {
+		verifications, err := d.VerifyForecasts(date, false)
+		if err != nil {
 			log.Printf("daily: verify %s: %v", date.Format("2006-01-02"), err)
+			result.Errors = append(result.Errors, fmt.Sprintf("verification %s: %v", date.Format("2006-01-02"), err))
+			continue
 		}
+		result.ForecastsVerified += len(verifications)
 	}
 
 	corrector := forecast.NewBiasCorrector(d.store)
-	if err := corrector.ComputeStats(30); err != nil {
+	updated, err := corrector.ComputeStats(30)
+	result.CorrectionStatsUpdated = updated
+	if err != nil {
 		log.Printf("daily: correction stats error: %v", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("correction stats: %v", err))
 	}
 
-	return nil
+	return result, nil
 }
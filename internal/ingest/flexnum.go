@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FlexFloat is a nullable number from the WU API, which occasionally sends
+// numbers as quoted strings (e.g. "65"). It accepts either form; null and
+// empty strings leave it invalid.
+type FlexFloat sql.NullFloat64
+
+func (f *FlexFloat) UnmarshalJSON(data []byte) error {
+	v, ok, err := parseFlexNumber(data)
+	if err != nil {
+		return err
+	}
+	*f = FlexFloat{Float64: v, Valid: ok}
+	return nil
+}
+
+// FlexInt is the integer counterpart of FlexFloat. Fractional values are
+// rounded.
+type FlexInt sql.NullInt64
+
+func (f *FlexInt) UnmarshalJSON(data []byte) error {
+	v, ok, err := parseFlexNumber(data)
+	if err != nil {
+		return err
+	}
+	*f = FlexInt{Int64: int64(math.Round(v)), Valid: ok}
+	return nil
+}
+
+// parseFlexNumber reads a JSON number or numeric string. It returns ok=false
+// for null or an empty string.
+func parseFlexNumber(data []byte) (v float64, ok bool, err error) {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return 0, false, nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if s, err = strconv.Unquote(s); err != nil {
+			return 0, false, fmt.Errorf("parse number %s: %w", data, err)
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			return 0, false, nil
+		}
+	}
+	v, err = strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse number %s: %w", data, err)
+	}
+	return v, true, nil
+}
@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -12,19 +13,23 @@ import (
 	"github.com/lox/wandiweather/internal/models"
 )
 
+const wuDailyForecastURL = "https://api.weather.com/v3/wx/forecast/daily"
+
 type ForecastClient struct {
-	apiKey string
-	client *http.Client
-	lat    float64
-	lon    float64
+	apiKey  string
+	client  *http.Client
+	lat     float64
+	lon     float64
+	baseURL string
 }
 
 func NewForecastClient(apiKey string, lat, lon float64) *ForecastClient {
 	return &ForecastClient{
-		apiKey: apiKey,
-		client: httputil.NewClient(),
-		lat:    lat,
-		lon:    lon,
+		apiKey:  apiKey,
+		client:  httputil.NewClient(),
+		lat:     lat,
+		lon:     lon,
+		baseURL: wuDailyForecastURL,
 	}
 }
 
@@ -52,12 +57,30 @@ type Daypart struct {
 	WindSpeed         []*int     `json:"windSpeed"`
 }
 
-func (f *ForecastClient) Fetch5Day() ([]models.Forecast, string, *FetchResult, error) {
+// Fetch5Day fetches the WU 5-day daily forecast.
+func (f *ForecastClient) Fetch5Day(ctx context.Context) ([]models.Forecast, string, *FetchResult, error) {
+	return f.fetchDaily(ctx, "5day")
+}
+
+// FetchExtended fetches the WU 15-day daily forecast for the longer outlook.
+// Days are numbered from 0 like Fetch5Day, so the first five overlap it.
+func (f *ForecastClient) FetchExtended(ctx context.Context) ([]models.Forecast, string, *FetchResult, error) {
+	return f.fetchDaily(ctx, "15day")
+}
+
+// fetchDaily fetches and parses one of WU's daily forecast products
+// ("5day", "15day"), which share a response shape and differ only in length.
+func (f *ForecastClient) fetchDaily(ctx context.Context, product string) ([]models.Forecast, string, *FetchResult, error) {
 	geocode := fmt.Sprintf("%.3f,%.3f", f.lat, f.lon)
-	url := fmt.Sprintf("https://api.weather.com/v3/wx/forecast/daily/5day?geocode=%.4f,%.4f&format=json&units=m&language=en-AU&apiKey=%s", f.lat, f.lon, f.apiKey)
+	url := fmt.Sprintf("%s/%s?geocode=%.4f,%.4f&format=json&units=m&language=en-AU&apiKey=%s", f.baseURL, product, f.lat, f.lon, f.apiKey)
 	result := &FetchResult{}
 
-	resp, err := f.client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		result.Error = fmt.Errorf("fetch forecast: %w", err)
+		return nil, "", result, result.Error
+	}
+	resp, err := f.client.Do(req)
 	if err != nil {
 		result.Error = fmt.Errorf("fetch forecast: %w", err)
 		return nil, "", result, result.Error
@@ -164,6 +187,9 @@ func (f *ForecastClient) Fetch5Day() ([]models.Forecast, string, *FetchResult, e
 			if dayIdx < len(daypart.WindDirectionCard) && daypart.WindDirectionCard[dayIdx] != nil {
 				fc.WindDir = sql.NullString{String: *daypart.WindDirectionCard[dayIdx], Valid: true}
 			}
+			if dayIdx < len(daypart.RelativeHumidity) && daypart.RelativeHumidity[dayIdx] != nil {
+				fc.Humidity = sql.NullInt64{Int64: int64(*daypart.RelativeHumidity[dayIdx]), Valid: true}
+			}
 		}
 
 		forecasts = append(forecasts, fc)
@@ -1,13 +1,23 @@
 package ingest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/lox/wandiweather/internal/emergency"
+	"github.com/lox/wandiweather/internal/forecast"
 	"github.com/lox/wandiweather/internal/models"
+	"github.com/lox/wandiweather/internal/store"
+	_ "modernc.org/sqlite"
 )
 
 func TestValidateObservation(t *testing.T) {
@@ -151,6 +161,45 @@ func TestValidateObservation(t *testing.T) {
 			},
 			wantFlags: nil,
 		},
+		{
+			name: "gusty but realistic wind - valid",
+			obs: &models.Observation{
+				WindSpeed: sql.NullFloat64{Float64: 25.0, Valid: true},
+				WindGust:  sql.NullFloat64{Float64: 70.0, Valid: true},
+			},
+			wantFlags: nil,
+		},
+		{
+			name: "light wind with big relative gust below floor - valid",
+			obs: &models.Observation{
+				WindSpeed: sql.NullFloat64{Float64: 2.0, Valid: true},
+				WindGust:  sql.NullFloat64{Float64: 20.0, Valid: true},
+			},
+			wantFlags: nil,
+		},
+		{
+			name: "glitched gust spike",
+			obs: &models.Observation{
+				WindSpeed: sql.NullFloat64{Float64: 5.0, Valid: true},
+				WindGust:  sql.NullFloat64{Float64: 180.0, Valid: true},
+			},
+			wantFlags: []string{FlagGustImplausible},
+		},
+		{
+			name: "gust without sustained wind - not checked",
+			obs: &models.Observation{
+				WindGust: sql.NullFloat64{Float64: 180.0, Valid: true},
+			},
+			wantFlags: nil,
+		},
+		{
+			name: "absolute wind limit still applies",
+			obs: &models.Observation{
+				WindSpeed: sql.NullFloat64{Float64: 210.0, Valid: true},
+				WindGust:  sql.NullFloat64{Float64: 230.0, Valid: true},
+			},
+			wantFlags: []string{FlagWindSpeedUnlikely},
+		},
 	}
 
 	for _, tt := range tests {
@@ -270,14 +319,60 @@ func TestParseCurrentResponse(t *testing.T) {
 				if obs.StationID != "IWANDI23" {
 					t.Errorf("StationID = %q, want IWANDI23", obs.StationID)
 				}
-				if obs.Humidity == nil || *obs.Humidity != 65 {
+				if !obs.Humidity.Valid || obs.Humidity.Int64 != 65 {
 					t.Errorf("Humidity = %v, want 65", obs.Humidity)
 				}
-				if obs.Metric == nil || obs.Metric.Temp == nil || *obs.Metric.Temp != 25.5 {
+				if obs.Metric == nil || !obs.Metric.Temp.Valid || obs.Metric.Temp.Float64 != 25.5 {
 					t.Error("Metric.Temp not parsed correctly")
 				}
 			},
 		},
+		{
+			name: "numbers sent as strings",
+			jsonData: `{
+				"observations": [{
+					"stationID": "IWANDI23",
+					"obsTimeUtc": "2025-01-15T10:00:00Z",
+					"humidity": "65",
+					"uv": "3.5",
+					"winddir": "",
+					"metric": {
+						"temp": "25.5",
+						"pressure": " 1015.2 ",
+						"windSpeed": ""
+					}
+				}]
+			}`,
+			wantLen: 1,
+			wantErr: false,
+			checkFirst: func(t *testing.T, obs CurrentObservation) {
+				if !obs.Humidity.Valid || obs.Humidity.Int64 != 65 {
+					t.Errorf("Humidity = %v, want 65", obs.Humidity)
+				}
+				if !obs.UV.Valid || obs.UV.Float64 != 3.5 {
+					t.Errorf("UV = %v, want 3.5", obs.UV)
+				}
+				if obs.WindDir.Valid {
+					t.Errorf("WindDir = %v, want null for an empty string", obs.WindDir)
+				}
+				if obs.Metric == nil || !obs.Metric.Temp.Valid || obs.Metric.Temp.Float64 != 25.5 {
+					t.Errorf("Metric.Temp = %v, want 25.5", obs.Metric.Temp)
+				}
+				if !obs.Metric.Pressure.Valid || obs.Metric.Pressure.Float64 != 1015.2 {
+					t.Errorf("Metric.Pressure = %v, want 1015.2", obs.Metric.Pressure)
+				}
+				if obs.Metric.WindSpeed.Valid {
+					t.Errorf("Metric.WindSpeed = %v, want null for an empty string", obs.Metric.WindSpeed)
+				}
+			},
+		},
+		{
+			name: "non-numeric string",
+			jsonData: `{
+				"observations": [{"stationID": "IWANDI23", "humidity": "high"}]
+			}`,
+			wantErr: true,
+		},
 		{
 			name: "response with null fields",
 			jsonData: `{
@@ -295,14 +390,14 @@ func TestParseCurrentResponse(t *testing.T) {
 			wantLen: 1,
 			wantErr: false,
 			checkFirst: func(t *testing.T, obs CurrentObservation) {
-				if obs.Humidity != nil {
-					t.Error("Humidity should be nil")
+				if obs.Humidity.Valid {
+					t.Error("Humidity should be null")
 				}
-				if obs.Metric == nil || obs.Metric.Temp == nil || *obs.Metric.Temp != 20.0 {
+				if obs.Metric == nil || !obs.Metric.Temp.Valid || obs.Metric.Temp.Float64 != 20.0 {
 					t.Error("Metric.Temp should be 20.0")
 				}
-				if obs.Metric.WindSpeed != nil {
-					t.Error("Metric.WindSpeed should be nil")
+				if obs.Metric.WindSpeed.Valid {
+					t.Error("Metric.WindSpeed should be null")
 				}
 			},
 		},
@@ -322,6 +417,9 @@ func TestParseCurrentResponse(t *testing.T) {
 				t.Errorf("Unmarshal error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil {
+				return
+			}
 			if len(resp.Observations) != tt.wantLen {
 				t.Errorf("len(Observations) = %d, want %d", len(resp.Observations), tt.wantLen)
 				return
@@ -380,17 +478,58 @@ func TestParseHistoryResponse(t *testing.T) {
 	if obs.Epoch != 1736899200 {
 		t.Errorf("Epoch = %d, want 1736899200", obs.Epoch)
 	}
-	if obs.HumidityAvg == nil || *obs.HumidityAvg != 70 {
+	if !obs.HumidityAvg.Valid || obs.HumidityAvg.Int64 != 70 {
 		t.Errorf("HumidityAvg = %v, want 70", obs.HumidityAvg)
 	}
-	if obs.Metric == nil || obs.Metric.TempAvg == nil || *obs.Metric.TempAvg != 22.5 {
+	if obs.Metric == nil || !obs.Metric.TempAvg.Valid || obs.Metric.TempAvg.Float64 != 22.5 {
 		t.Error("Metric.TempAvg not parsed correctly")
 	}
-	if obs.Metric.PrecipTotal == nil || *obs.Metric.PrecipTotal != 2.5 {
+	if !obs.Metric.PrecipTotal.Valid || obs.Metric.PrecipTotal.Float64 != 2.5 {
 		t.Error("Metric.PrecipTotal not parsed correctly")
 	}
 }
 
+func TestParseHistoryResponse_StringNumbers(t *testing.T) {
+	// WU occasionally quotes its numbers, or sends an empty string for none
+	jsonData := `{
+		"observations": [
+			{
+				"stationID": "IWANDI23",
+				"obsTimeUtc": "2025-01-15T01:00:00Z",
+				"epoch": 1736902800,
+				"humidityAvg": "75",
+				"uvHigh": "",
+				"metric": {
+					"tempAvg": "21.0",
+					"precipTotal": "2.5"
+				}
+			}
+		]
+	}`
+
+	var resp HistoryResponse
+	if err := json.Unmarshal([]byte(jsonData), &resp); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(resp.Observations) != 1 {
+		t.Fatalf("len(Observations) = %d, want 1", len(resp.Observations))
+	}
+
+	obs := resp.Observations[0]
+	if !obs.HumidityAvg.Valid || obs.HumidityAvg.Int64 != 75 {
+		t.Errorf("HumidityAvg = %v, want 75", obs.HumidityAvg)
+	}
+	if obs.UVHigh.Valid {
+		t.Errorf("UVHigh = %v, want null for an empty string", obs.UVHigh)
+	}
+	if obs.Metric == nil || !obs.Metric.TempAvg.Valid || obs.Metric.TempAvg.Float64 != 21.0 {
+		t.Errorf("Metric.TempAvg = %v, want 21.0", obs.Metric.TempAvg)
+	}
+	if !obs.Metric.PrecipTotal.Valid || obs.Metric.PrecipTotal.Float64 != 2.5 {
+		t.Errorf("Metric.PrecipTotal = %v, want 2.5", obs.Metric.PrecipTotal)
+	}
+}
+
 func TestParseForecastResponse(t *testing.T) {
 	jsonData := `{
 		"dayOfWeek": ["Monday", "Tuesday", "Wednesday"],
@@ -434,6 +573,124 @@ func TestParseForecastResponse(t *testing.T) {
 	}
 }
 
+// wuDailyPayload builds a WU daily forecast payload of days days from start:
+// day i has max 20+i, min 5+i and i*5% rain chance.
+func wuDailyPayload(t *testing.T, start time.Time, days int) []byte {
+	t.Helper()
+	var resp ForecastResponse
+	dp := Daypart{}
+	for i := 0; i < days; i++ {
+		resp.ValidTimeLocal = append(resp.ValidTimeLocal, start.AddDate(0, 0, i).Format("2006-01-02T15:04:05-0700"))
+		resp.CalendarDayTempMax = append(resp.CalendarDayTempMax, float64(20+i))
+		resp.CalendarDayTempMin = append(resp.CalendarDayTempMin, float64(5+i))
+		resp.Narrative = append(resp.Narrative, fmt.Sprintf("Day %d", i))
+		day, night := i*5, 0
+		dp.PrecipChance = append(dp.PrecipChance, &day, &night)
+	}
+	resp.Daypart = []Daypart{dp}
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestForecastFetchExtended(t *testing.T) {
+	start := time.Date(2025, 1, 20, 7, 0, 0, 0, time.FixedZone("AEDT", 11*60*60))
+	payload := wuDailyPayload(t, start, 15)
+
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	client := NewForecastClient("key", -36.79, 146.98)
+	client.baseURL = srv.URL
+
+	forecasts, _, result, err := client.FetchExtended(context.Background())
+	if err != nil {
+		t.Fatalf("FetchExtended: %v", err)
+	}
+	if gotPath != "/15day" {
+		t.Errorf("path = %q, want /15day", gotPath)
+	}
+	if result.RecordCount != 15 || len(forecasts) != 15 {
+		t.Fatalf("got %d forecasts (RecordCount %d), want 15", len(forecasts), result.RecordCount)
+	}
+	for i, fc := range forecasts {
+		if fc.DayOfForecast != i {
+			t.Errorf("forecasts[%d].DayOfForecast = %d, want %d", i, fc.DayOfForecast, i)
+		}
+	}
+
+	// Day 11 is well past what the 5-day product covers
+	fc := forecasts[11]
+	if want := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC); !fc.ValidDate.Equal(want) {
+		t.Errorf("day 11 ValidDate = %v, want %v", fc.ValidDate, want)
+	}
+	if !fc.TempMax.Valid || fc.TempMax.Float64 != 31 {
+		t.Errorf("day 11 TempMax = %v, want 31", fc.TempMax)
+	}
+	if !fc.TempMin.Valid || fc.TempMin.Float64 != 16 {
+		t.Errorf("day 11 TempMin = %v, want 16", fc.TempMin)
+	}
+	if !fc.PrecipChance.Valid || fc.PrecipChance.Int64 != 55 {
+		t.Errorf("day 11 PrecipChance = %v, want 55", fc.PrecipChance)
+	}
+}
+
+func TestIngestForecasts_Extended(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	start := time.Date(2025, 1, 20, 7, 0, 0, 0, time.FixedZone("AEDT", 11*60*60))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/5day":
+			w.Write(wuDailyPayload(t, start, 5))
+		case "/15day":
+			w.Write(wuDailyPayload(t, start, 15))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewForecastClient("key", -36.79, 146.98)
+	client.baseURL = srv.URL
+	sched := NewScheduler(st, nil, client, loc)
+	sched.bom = nil
+	sched.ingestForecasts(context.Background())
+
+	// Each day is stored once: the first five from the 5-day product and the
+	// rest from the 15-day one
+	for day := 0; day < 15; day++ {
+		forecasts, err := st.GetForecastsForDate(time.Date(2025, 1, 20+day, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("GetForecastsForDate: %v", err)
+		}
+		if len(forecasts) != 1 {
+			t.Fatalf("day %d: got %d forecasts, want 1", day, len(forecasts))
+		}
+		if fc := forecasts[0]; fc.DayOfForecast != day || fc.TempMax.Float64 != float64(20+day) {
+			t.Errorf("day %d: DayOfForecast %d, TempMax %v, want day %d with max %d", day, fc.DayOfForecast, fc.TempMax, day, 20+day)
+		}
+	}
+
+	runs, err := st.GetLastSuccessBySource()
+	if err != nil {
+		t.Fatalf("GetLastSuccessBySource: %v", err)
+	}
+	var endpoints []string
+	for _, run := range runs {
+		endpoints = append(endpoints, run.Source+" "+run.Endpoint)
+	}
+	if want := "wu forecast/daily/15day,wu forecast/daily/5day"; strings.Join(endpoints, ",") != want {
+		t.Errorf("successful runs = %v, want %s", endpoints, want)
+	}
+}
+
 func TestTruncateBody(t *testing.T) {
 	t.Run("short string unchanged", func(t *testing.T) {
 		input := "hello world"
@@ -561,3 +818,794 @@ func TestValidateObservation_BoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+const sampleBOMXML = `<?xml version="1.0" encoding="UTF-8"?>
+<product>
+  <amoc><issue-time-utc>2026-01-14T18:00:00Z</issue-time-utc></amoc>
+  <forecast>
+    <area aac="VIC_PT075" description="Wangaratta" type="location">
+      <forecast-period index="0" start-time-utc="2026-01-14T19:00:00Z" end-time-utc="2026-01-15T13:00:00Z">
+        <element type="air_temperature_maximum" units="Celsius">31</element>
+        <text type="precis">Sunny.</text>
+        <text type="probability_of_precipitation">5%</text>
+      </forecast-period>
+      <forecast-period index="1" start-time-utc="2026-01-15T13:00:00Z" end-time-utc="2026-01-16T13:00:00Z">
+        <element type="air_temperature_minimum" units="Celsius">14</element>
+        <element type="air_temperature_maximum" units="Celsius">28</element>
+        <text type="precis">Possible shower.</text>
+        <text type="probability_of_precipitation">40%</text>
+      </forecast-period>
+    </area>
+  </forecast>
+</product>`
+
+func TestBOMFetchForecasts_HTTPFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(sampleBOMXML))
+	}))
+	defer srv.Close()
+
+	client := NewBOMClient("", time.UTC)
+	client.ftpHost = "127.0.0.1:1" // nothing listens here, so the dial fails
+	client.httpURL = srv.URL
+
+	forecasts, _, result, err := client.FetchForecasts(context.Background())
+	if err != nil {
+		t.Fatalf("FetchForecasts: %v", err)
+	}
+	if result.Transport != TransportHTTP {
+		t.Errorf("Transport = %q, want %q", result.Transport, TransportHTTP)
+	}
+	if result.HTTPStatus != http.StatusOK {
+		t.Errorf("HTTPStatus = %d, want 200", result.HTTPStatus)
+	}
+	if len(forecasts) != 2 {
+		t.Fatalf("got %d forecasts, want 2", len(forecasts))
+	}
+	if !forecasts[1].TempMax.Valid || forecasts[1].TempMax.Float64 != 28 {
+		t.Errorf("day 1 TempMax = %v, want 28", forecasts[1].TempMax)
+	}
+	if !forecasts[1].PrecipChance.Valid || forecasts[1].PrecipChance.Int64 != 40 {
+		t.Errorf("day 1 PrecipChance = %v, want 40", forecasts[1].PrecipChance)
+	}
+}
+
+func TestBOMFetchForecasts_NarrativeOnlyPeriod(t *testing.T) {
+	st, _ := setupTestStore(t)
+
+	// BOM periods start in the morning, so "today" here is Melbourne's
+	mel, _ := time.LoadLocation("Australia/Melbourne")
+	now := time.Now().In(mel)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 7, 0, 0, 0, mel).UTC()
+	tomorrow := today.AddDate(0, 0, 1)
+	period := func(index int, start time.Time, body string) string {
+		return fmt.Sprintf(`<forecast-period index="%d" start-time-utc="%s" end-time-utc="%s">%s</forecast-period>`,
+			index, start.Format(time.RFC3339), start.Add(18*time.Hour).Format(time.RFC3339), body)
+	}
+	product := func(periods ...string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?><product><forecast>
+			<area aac="VIC_PT075" description="Wangaratta" type="location">` + strings.Join(periods, "") + `</area>
+			</forecast></product>`
+	}
+	fetch := func(xml string) []models.Forecast {
+		t.Helper()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(xml))
+		}))
+		defer srv.Close()
+		client := NewBOMClient("", mel)
+		client.ftpHost = "127.0.0.1:1"
+		client.httpURL = srv.URL
+		forecasts, _, _, err := client.FetchForecasts(context.Background())
+		if err != nil {
+			t.Fatalf("FetchForecasts: %v", err)
+		}
+		for _, fc := range forecasts {
+			if err := st.InsertForecast(fc); err != nil {
+				t.Fatalf("InsertForecast: %v", err)
+			}
+		}
+		return forecasts
+	}
+
+	// The morning issue has today's max
+	fetch(product(
+		period(0, today, `<element type="air_temperature_maximum">31</element><text type="precis">Sunny.</text>`),
+		period(1, tomorrow, `<element type="air_temperature_minimum">14</element><element type="air_temperature_maximum">28</element>`),
+	))
+
+	// The late issue has only a precis for today, and an empty period
+	late := fetch(product(
+		period(0, today, `<text type="precis">Clearing shower.</text><text type="probability_of_precipitation">30%</text>`),
+		period(1, tomorrow, `<element type="air_temperature_minimum">15</element><element type="air_temperature_maximum">27</element>`),
+		period(2, tomorrow.AddDate(0, 0, 1), ``),
+	))
+	if len(late) != 2 {
+		t.Fatalf("got %d forecasts, want 2 (empty period skipped)", len(late))
+	}
+	if !late[0].NarrativeOnly() || late[0].Narrative.String != "Clearing shower." {
+		t.Errorf("today = %+v, want narrative-only with the precis", late[0])
+	}
+
+	latest, err := st.GetLatestForecasts()
+	if err != nil {
+		t.Fatalf("GetLatestForecasts: %v", err)
+	}
+	todayKey := today.In(mel).Format("2006-01-02")
+	var found bool
+	for _, fc := range latest["bom"] {
+		if fc.ValidDate.Format("2006-01-02") != todayKey {
+			continue
+		}
+		found = true
+		if !fc.TempMax.Valid || fc.TempMax.Float64 != 31 {
+			t.Errorf("today's BOM max = %v, want the morning's 31", fc.TempMax)
+		}
+	}
+	if !found {
+		t.Fatal("no BOM forecast for today")
+	}
+
+	// Nor does a narrative-only forecast displace WU in today's temperatures
+	result := forecast.ComputeTodayTemps(forecast.TodayTempInput{
+		WUForecast:  &models.Forecast{Source: "wu", TempMax: sql.NullFloat64{Float64: 29, Valid: true}},
+		BOMForecast: &late[0],
+	})
+	if result.Explanation.MaxSource != "wu" || result.TempMax != 29 {
+		t.Errorf("max = %v from %q, want 29 from wu", result.TempMax, result.Explanation.MaxSource)
+	}
+}
+
+func TestBOMFetchForecasts_BothTransportsFail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	client := NewBOMClient("", time.UTC)
+	client.ftpHost = "127.0.0.1:1"
+	client.httpURL = srv.URL
+
+	_, _, result, err := client.FetchForecasts(context.Background())
+	if err == nil {
+		t.Fatal("expected error when FTP and HTTP both fail")
+	}
+	if result.HTTPStatus != http.StatusForbidden {
+		t.Errorf("HTTPStatus = %d, want 403", result.HTTPStatus)
+	}
+	if !strings.Contains(err.Error(), "ftp dial") {
+		t.Errorf("error %q should mention the FTP failure", err)
+	}
+}
+
+func TestQCThresholds_Override(t *testing.T) {
+	// A cold alpine morning with low station pressure
+	obs := &models.Observation{
+		Temp:     sql.NullFloat64{Float64: -14.0, Valid: true},
+		Pressure: sql.NullFloat64{Float64: 870.0, Valid: true},
+	}
+
+	got := DefaultQCThresholds().Validate(obs)
+	sort.Strings(got)
+	want := []string{FlagPressureOutOfRange, FlagTempOutOfRange}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("default thresholds flags = %v, want %v", got, want)
+	}
+
+	alpine := DefaultQCThresholds()
+	alpine.TempMin = -25
+	alpine.PressureMin = 800
+	if got := alpine.Validate(obs); len(got) != 0 {
+		t.Errorf("alpine thresholds flags = %v, want none", got)
+	}
+}
+
+func TestValidateLocation(t *testing.T) {
+	// IWANDI23's configured location
+	const wantLat, wantLon = -36.794, 146.977
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     []string
+	}{
+		{"at configured location", -36.794, 146.977, nil},
+		{"small GPS drift", -36.80, 146.98, nil},
+		{"jumped 50km north", -36.344, 146.977, []string{FlagLocationMismatch}},
+		{"no location reported", 0, 0, nil},
+		{"swapped coordinates", 146.977, -36.794, nil},
+	}
+
+	qc := DefaultQCThresholds()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := qc.ValidateLocation(tt.lat, tt.lon, wantLat, wantLon)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ValidateLocation(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		observedAt time.Time
+		want       []string
+	}{
+		{"a few minutes old", now.Add(-4 * time.Minute), nil},
+		{"slightly ahead", now.Add(3 * time.Minute), nil},
+		{"an hour ahead", now.Add(time.Hour), []string{FlagFutureTimestamp}},
+	}
+
+	qc := DefaultQCThresholds()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := qc.ValidateTimestamp(tt.observedAt, now)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ValidateTimestamp(%v) = %v, want %v", tt.observedAt, got, tt.want)
+			}
+		})
+	}
+}
+
+func setupTestStore(t *testing.T) (*store.Store, *time.Location) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	loc, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Fatalf("load timezone: %v", err)
+	}
+	st := store.New(db, loc)
+	if err := st.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return st, loc
+}
+
+func TestDailyJobs_DryRun(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	if err := st.UpsertStation(models.Station{
+		StationID: "TEST1", Name: "Test", ElevationTier: "valley_floor", IsPrimary: true, Active: true,
+	}); err != nil {
+		t.Fatalf("UpsertStation: %v", err)
+	}
+
+	forDate := time.Date(2025, 1, 15, 0, 0, 0, 0, loc)
+	for hour, temp := range map[int]float64{6: 12, 9: 18, 12: 24, 15: 28, 18: 22} {
+		if err := st.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: forDate.Add(time.Duration(hour) * time.Hour).UTC(),
+			Temp:       sql.NullFloat64{Float64: temp, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+	if err := st.InsertForecast(models.Forecast{
+		Source:        "wu",
+		FetchedAt:     forDate.Add(-12 * time.Hour).UTC(),
+		ValidDate:     time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		DayOfForecast: 1,
+		TempMax:       sql.NullFloat64{Float64: 30, Valid: true},
+		TempMin:       sql.NullFloat64{Float64: 11, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertForecast: %v", err)
+	}
+
+	daily := NewDailyJobs(st)
+	result, err := daily.RunAll(forDate, true)
+	if err != nil {
+		t.Fatalf("RunAll dry run: %v", err)
+	}
+	want := DailyResult{Date: "2025-01-15", DryRun: true, SummariesComputed: 1, ForecastsVerified: 1}
+	if result.Date != want.Date || result.DryRun != want.DryRun || result.SummariesComputed != want.SummariesComputed ||
+		result.ForecastsVerified != want.ForecastsVerified || len(result.Errors) != 0 {
+		t.Errorf("dry run result = %+v, want %+v", result, want)
+	}
+
+	summaries, err := daily.ComputeDailySummaries(forDate, true)
+	if err != nil {
+		t.Fatalf("ComputeDailySummaries: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].TempMax.Float64 != 28 {
+		t.Errorf("summaries = %+v, want one with max 28", summaries)
+	}
+
+	verifications, err := daily.VerifyForecasts(forDate, true)
+	if err != nil {
+		t.Fatalf("VerifyForecasts: %v", err)
+	}
+	if len(verifications) != 1 || verifications[0].BiasTempMax.Float64 != 2 {
+		t.Errorf("verifications = %+v, want one with max bias 2", verifications)
+	}
+
+	if got, err := st.GetDailySummary("TEST1", forDate); err != nil {
+		t.Fatalf("GetDailySummary: %v", err)
+	} else if got != nil {
+		t.Errorf("dry run stored a daily summary: %+v", got)
+	}
+	if got, err := st.GetVerificationHistory("wu", 10); err != nil {
+		t.Fatalf("GetVerificationHistory: %v", err)
+	} else if len(got) != 0 {
+		t.Errorf("dry run stored %d verifications", len(got))
+	}
+
+	// The same run without dryRun writes both
+	result, err = daily.RunAll(forDate, false)
+	if err != nil {
+		t.Fatalf("RunAll: %v", err)
+	}
+	if result.DryRun || result.SummariesComputed != 1 || result.ForecastsVerified != 1 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v, want 1 summary and 1 verification written", result)
+	}
+	if got, _ := st.GetDailySummary("TEST1", forDate); got == nil {
+		t.Error("expected a stored daily summary")
+	}
+	if got, _ := st.GetVerificationHistory("wu", 10); len(got) != 1 {
+		t.Errorf("stored %d verifications, want 1", len(got))
+	}
+}
+
+func TestCheckInversionAlert(t *testing.T) {
+	st, loc := setupTestStore(t)
+	for _, station := range []models.Station{
+		{StationID: "VALLEY1", ElevationTier: "valley_floor", IsPrimary: true, Active: true},
+		{StationID: "VALLEY2", ElevationTier: "valley_floor", Active: true},
+		{StationID: "UPPER1", ElevationTier: "upper", Active: true},
+	} {
+		if err := st.UpsertStation(station); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	sched := NewScheduler(st, nil, nil, loc)
+	now := time.Now()
+
+	// A mild night: upper only 2°C warmer, no alert
+	sched.checkInversionAlert(map[string]float64{"VALLEY1": 4, "VALLEY2": 5, "UPPER1": 6.5}, now)
+	alerts, err := st.GetActiveAlerts(time.Hour)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("got %d alerts for a weak inversion, want 0", len(alerts))
+	}
+
+	// Valley floor at 1°C under an 8°C upper station raises the alert
+	sched.checkInversionAlert(map[string]float64{"VALLEY1": 0.5, "VALLEY2": 1.5, "UPPER1": 8}, now)
+	alerts, err = st.GetActiveAlerts(time.Hour)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	alert := alerts[0]
+	if !alert.IsLocal() || !alert.IsUrgent() {
+		t.Errorf("alert = %+v, want an urgent local alert", alert)
+	}
+	if alert.ID != inversionAlertID(now.In(loc)) {
+		t.Errorf("ID = %q, want %q", alert.ID, inversionAlertID(now.In(loc)))
+	}
+	if !strings.Contains(alert.Body, "7.0°C colder") {
+		t.Errorf("Body = %q, want the 7.0°C spread", alert.Body)
+	}
+
+	// Feed polling must not clear the local alert
+	if cleared, err := st.ClearMissingAlerts(nil, now); err != nil {
+		t.Fatalf("ClearMissingAlerts: %v", err)
+	} else if cleared != 0 {
+		t.Errorf("ClearMissingAlerts cleared %d alerts, want 0", cleared)
+	}
+
+	// Once the upper station cools the alert is cleared
+	later := now.Add(time.Hour)
+	sched.checkInversionAlert(map[string]float64{"VALLEY1": 0.5, "VALLEY2": 1.5, "UPPER1": 3}, later)
+	history, err := st.GetAlertHistory(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetAlertHistory: %v", err)
+	}
+	if len(history) != 1 || !history[0].ClearedAt.Valid {
+		t.Errorf("history = %+v, want the alert cleared", history)
+	}
+}
+
+func TestInversionAlertID(t *testing.T) {
+	loc, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Fatalf("load timezone: %v", err)
+	}
+	evening := time.Date(2025, 6, 10, 21, 0, 0, 0, loc)
+	morning := time.Date(2025, 6, 11, 6, 0, 0, 0, loc)
+	if inversionAlertID(evening) != inversionAlertID(morning) {
+		t.Errorf("evening %q and morning %q should share an alert", inversionAlertID(evening), inversionAlertID(morning))
+	}
+	if got := inversionAlertID(morning); got != "local-inversion-2025-06-11" {
+		t.Errorf("ID = %q, want local-inversion-2025-06-11", got)
+	}
+
+	// Daylight saving starts overnight on 5 October 2025, so twelve hours
+	// after late morning on the 4th is already past midnight
+	if got := inversionAlertID(time.Date(2025, 10, 4, 11, 30, 0, 0, loc)); got != "local-inversion-2025-10-04" {
+		t.Errorf("late morning before daylight saving: ID = %q, want local-inversion-2025-10-04", got)
+	}
+	if got := inversionAlertID(time.Date(2025, 10, 4, 12, 0, 0, 0, loc)); got != "local-inversion-2025-10-05" {
+		t.Errorf("noon before daylight saving: ID = %q, want local-inversion-2025-10-05", got)
+	}
+}
+
+func TestStoreAlerts_Webhook(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	var posted []emergency.WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		var payload emergency.WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		posted = append(posted, payload)
+	}))
+	defer srv.Close()
+
+	sched := NewScheduler(st, nil, nil, loc)
+	sched.SetAlertWebhook(emergency.NewWebhook(srv.URL))
+
+	fire := emergency.Alert{ID: "fire-1", Category: "Fire", Name: "Advice", Location: "Bright", Severity: emergency.SeverityAdvice}
+	now := time.Now().Add(-10 * time.Minute)
+	steps := []struct {
+		name     string
+		severity int
+		want     string // event posted, if any
+	}{
+		{"first seen", emergency.SeverityAdvice, emergency.WebhookNew},
+		{"unchanged", emergency.SeverityAdvice, ""},
+		{"advice to emergency", emergency.SeverityEmergency, emergency.WebhookEscalated},
+		{"still emergency", emergency.SeverityEmergency, ""},
+	}
+	for i, step := range steps {
+		posted = nil
+		fire.Severity = step.severity
+		if stored := sched.storeAlerts(context.Background(), []emergency.Alert{fire}, now.Add(time.Duration(i)*time.Minute)); stored != 1 {
+			t.Fatalf("%s: stored %d alerts, want 1", step.name, stored)
+		}
+
+		if step.want == "" {
+			if len(posted) != 0 {
+				t.Errorf("%s: posted %+v, want nothing", step.name, posted)
+			}
+			continue
+		}
+		if len(posted) != 1 {
+			t.Fatalf("%s: posted %d payloads, want 1", step.name, len(posted))
+		}
+		if got := posted[0]; got.Event != step.want || got.ID != "fire-1" || got.Severity != fire.SeverityName() || got.Location != "Bright" {
+			t.Errorf("%s: payload = %+v, want %s for fire-1 at %s", step.name, got, step.want, fire.SeverityName())
+		}
+	}
+}
+
+func TestBuildWeeklySummary(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	if err := st.UpsertStation(models.Station{StationID: "PRIMARY", Active: true, IsPrimary: true}); err != nil {
+		t.Fatalf("UpsertStation: %v", err)
+	}
+
+	week := time.Date(2025, 1, 13, 0, 0, 0, 0, loc)
+	days := []struct {
+		max, min, rain float64
+	}{
+		{24, 8, 0},
+		{28, 1.5, 0},  // frost
+		{33, 12, 4.2}, // hottest
+		{19, 9, 12.6},
+		{21, -0.5, 0.1}, // coldest, frost, too little rain to count
+		{25, 10, 0},
+		{27, 11, 0},
+	}
+	for i, d := range days {
+		if err := st.UpsertDailySummary(models.DailySummary{
+			Date:        week.AddDate(0, 0, i),
+			StationID:   "PRIMARY",
+			TempMax:     sql.NullFloat64{Float64: d.max, Valid: true},
+			TempMin:     sql.NullFloat64{Float64: d.min, Valid: true},
+			PrecipTotal: sql.NullFloat64{Float64: d.rain, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+	// The following Monday belongs to next week's recap
+	if err := st.UpsertDailySummary(models.DailySummary{
+		Date:        week.AddDate(0, 0, 7),
+		StationID:   "PRIMARY",
+		TempMax:     sql.NullFloat64{Float64: 40, Valid: true},
+		TempMin:     sql.NullFloat64{Float64: -5, Valid: true},
+		PrecipTotal: sql.NullFloat64{Float64: 50, Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertDailySummary: %v", err)
+	}
+
+	// WU is off by 1°C all week, BOM by 3°C; a WU bust after the week is ignored
+	var forecastID int64
+	verify := func(source string, date time.Time, bias float64) {
+		t.Helper()
+		if err := st.InsertForecast(models.Forecast{
+			Source:        source,
+			FetchedAt:     date.Add(-24 * time.Hour),
+			ValidDate:     date,
+			DayOfForecast: 1,
+		}); err != nil {
+			t.Fatalf("InsertForecast: %v", err)
+		}
+		forecastID++
+		if err := st.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:  forecastID,
+			ValidDate:   date,
+			BiasTempMax: sql.NullFloat64{Float64: bias, Valid: true},
+			BiasTempMin: sql.NullFloat64{Float64: -bias, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertForecastVerification: %v", err)
+		}
+	}
+	for i := range days {
+		date := time.Date(2025, 1, 13+i, 0, 0, 0, 0, time.UTC)
+		verify("wu", date, 1)
+		verify("bom", date, 3)
+	}
+	verify("wu", time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), 10)
+
+	summary, err := BuildWeeklySummary(st, week)
+	if err != nil {
+		t.Fatalf("BuildWeeklySummary: %v", err)
+	}
+
+	if summary.WeekStart != "2025-01-13" || summary.WeekEnd != "2025-01-19" || summary.Days != 7 {
+		t.Errorf("week = %s..%s with %d days, want 2025-01-13..2025-01-19 with 7", summary.WeekStart, summary.WeekEnd, summary.Days)
+	}
+	if summary.HighestMax == nil || *summary.HighestMax != (WeeklyExtreme{Temp: 33, Date: "2025-01-15"}) {
+		t.Errorf("HighestMax = %+v, want 33 on 2025-01-15", summary.HighestMax)
+	}
+	if summary.LowestMin == nil || *summary.LowestMin != (WeeklyExtreme{Temp: -0.5, Date: "2025-01-17"}) {
+		t.Errorf("LowestMin = %+v, want -0.5 on 2025-01-17", summary.LowestMin)
+	}
+	if math.Abs(summary.RainTotal-16.9) > 1e-9 || summary.RainDays != 2 {
+		t.Errorf("rain = %.1f mm over %d days, want 16.9 mm over 2", summary.RainTotal, summary.RainDays)
+	}
+	if summary.FrostNights != 2 {
+		t.Errorf("FrostNights = %d, want 2", summary.FrostNights)
+	}
+	// One week of history isn't enough for a climatology
+	if summary.MaxAnomaly != nil || summary.MinAnomaly != nil {
+		t.Errorf("anomalies = %v, %v, want none without climatology", summary.MaxAnomaly, summary.MinAnomaly)
+	}
+	if summary.BestSource != "wu" || summary.WorstSource != "bom" {
+		t.Errorf("best/worst = %s/%s, want wu/bom", summary.BestSource, summary.WorstSource)
+	}
+	if len(summary.Sources) != 2 || summary.Sources[0].Days != 7 || summary.Sources[0].MAEMax != 1 {
+		t.Errorf("Sources = %+v, want wu first over 7 days with MAE 1", summary.Sources)
+	}
+}
+
+func TestBackfillHistory7Day_PartialFailure(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stationID := r.URL.Query().Get("stationId")
+		if r.URL.Path != "/hourly/7day" {
+			t.Errorf("path = %q, want /hourly/7day", r.URL.Path)
+		}
+		// A client error isn't retried, so the failing station fails fast
+		if stationID == "BROKEN" {
+			http.Error(w, "station not found", http.StatusNotFound)
+			return
+		}
+		if stationID == "RETIRED" {
+			t.Errorf("fetched inactive station %s", stationID)
+		}
+		fmt.Fprintf(w, `{"observations": [
+			{"stationID": %[1]q, "epoch": 1736899200, "qcStatus": 1, "metric": {"tempAvg": 18.5}},
+			{"stationID": %[1]q, "epoch": 1736902800, "qcStatus": 1, "metric": {"tempAvg": 19.5}}
+		]}`, stationID)
+	}))
+	defer srv.Close()
+
+	for _, station := range []models.Station{
+		{StationID: "GOOD1", Active: true},
+		{StationID: "BROKEN", Active: true},
+		{StationID: "GOOD2", Active: true},
+		{StationID: "RETIRED", Active: false},
+	} {
+		if err := st.UpsertStation(station); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	pws := NewPWS("key")
+	pws.baseURL = srv.URL
+	sched := NewScheduler(st, pws, nil, loc)
+
+	result, err := sched.BackfillHistory7Day()
+	if err == nil || !strings.Contains(err.Error(), "BROKEN") {
+		t.Errorf("err = %v, want one naming BROKEN", err)
+	}
+	if result != (BackfillResult{Succeeded: 2, Failed: 1, Observations: 4}) {
+		t.Errorf("result = %+v, want 2 succeeded, 1 failed, 4 observations", result)
+	}
+	for _, stationID := range []string{"GOOD1", "GOOD2"} {
+		obs, err := st.GetLatestObservation(stationID, false)
+		if err != nil {
+			t.Fatalf("GetLatestObservation: %v", err)
+		}
+		if obs == nil || obs.Temp.Float64 != 19.5 {
+			t.Errorf("%s latest = %+v, want the 19.5°C hour", stationID, obs)
+		}
+	}
+}
+
+func TestObservationRounding_CollapsesJitteredTimestamps(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		rounding time.Duration
+		want     int
+	}{
+		{"as reported", 0, 2},
+		{"minute rounding", time.Minute, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			st, loc := setupTestStore(t)
+
+			// The station reports the same reading twice, 3 seconds apart
+			reports := []string{"2026-01-15T10:00:00Z", "2026-01-15T10:00:03Z"}
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"observations": [{"stationID": "JITTER", "obsTimeUtc": %q, "qcStatus": 1, "metric": {"temp": 18.5}}]}`, reports[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			if err := st.UpsertStation(models.Station{StationID: "JITTER", Active: true}); err != nil {
+				t.Fatalf("UpsertStation: %v", err)
+			}
+
+			pws := NewPWS("key")
+			pws.baseURL = srv.URL
+			sched := NewScheduler(st, pws, nil, loc)
+			sched.SetObservationRounding(tt.rounding)
+			for range reports {
+				sched.ingestObservations(context.Background())
+			}
+
+			start := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+			observations, err := st.GetObservationsPage("JITTER", start, start.Add(2*time.Hour), 10, 0)
+			if err != nil {
+				t.Fatalf("GetObservationsPage: %v", err)
+			}
+			if len(observations) != tt.want {
+				t.Fatalf("stored %d observations, want %d", len(observations), tt.want)
+			}
+			if tt.rounding > 0 && !observations[0].ObservedAt.Equal(time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)) {
+				t.Errorf("ObservedAt = %v, want 10:00:00", observations[0].ObservedAt)
+			}
+		})
+	}
+}
+
+func TestSchedulerRun_CancelMidCycle(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	// The first station's fetch hangs until the client gives up on it
+	requested := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	for _, stationID := range []string{"SLOW1", "SLOW2"} {
+		if err := st.UpsertStation(models.Station{StationID: stationID, Active: true}); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	pws := NewPWS("key")
+	pws.baseURL = srv.URL
+	sched := NewScheduler(st, pws, nil, loc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go sched.Run(ctx)
+
+	select {
+	case <-requested:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler never fetched observations")
+	}
+	cancel()
+
+	select {
+	case <-sched.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return after cancellation")
+	}
+
+	for _, stationID := range []string{"SLOW1", "SLOW2"} {
+		observations, err := st.GetObservationsPage(stationID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 10, 0)
+		if err != nil {
+			t.Fatalf("GetObservationsPage: %v", err)
+		}
+		if len(observations) != 0 {
+			t.Errorf("%s: stored %d observations, want none", stationID, len(observations))
+		}
+	}
+
+	// The interrupted fetch is recorded as a finished failure, and the second
+	// station is never started
+	runs, err := st.GetRecentIngestErrors(10)
+	if err != nil {
+		t.Fatalf("GetRecentIngestErrors: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("got %d failed ingest runs, want 1", len(runs))
+	}
+	if runs[0].StationID.String != "SLOW1" || !runs[0].FinishedAt.Valid {
+		t.Errorf("run = station %q finished %v, want SLOW1 finished", runs[0].StationID.String, runs[0].FinishedAt.Valid)
+	}
+}
+
+func TestComputeDailySummaries_SunshineHours(t *testing.T) {
+	st, loc := setupTestStore(t)
+
+	station := models.Station{
+		StationID: "SUNNY", Name: "Sunny", ElevationTier: "valley_floor", IsPrimary: true, Active: true,
+		Latitude: -36.794, Longitude: 146.977, Elevation: 386,
+	}
+	if err := st.UpsertStation(station); err != nil {
+		t.Fatalf("UpsertStation: %v", err)
+	}
+
+	// Clear until 1pm, then cloud cuts the sun to a third of clear sky
+	forDate := time.Date(2026, 1, 15, 0, 0, 0, 0, loc)
+	for at := forDate; at.Before(forDate.AddDate(0, 0, 1)); at = at.Add(10 * time.Minute) {
+		clearness := 0.95
+		if at.Hour() >= 13 {
+			clearness = 0.3
+		}
+		solar := clearness * forecast.ClearSkySolar(at, station.Latitude, station.Longitude, station.Elevation)
+		if err := st.InsertObservation(models.Observation{
+			StationID:      "SUNNY",
+			ObservedAt:     at.UTC(),
+			Temp:           sql.NullFloat64{Float64: 20, Valid: true},
+			SolarRadiation: sql.NullFloat64{Float64: solar, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	if _, err := NewDailyJobs(st).ComputeDailySummaries(forDate, false); err != nil {
+		t.Fatalf("ComputeDailySummaries: %v", err)
+	}
+	summary, err := st.GetDailySummary("SUNNY", forDate)
+	if err != nil {
+		t.Fatalf("GetDailySummary: %v", err)
+	}
+	if summary == nil || !summary.SunshineHours.Valid {
+		t.Fatalf("summary = %+v, want sunshine hours", summary)
+	}
+	if got := summary.SunshineHours.Float64; got < 5 || got > 7 {
+		t.Errorf("sunshine hours = %.1f, want roughly 5-7 for a morning of sun", got)
+	}
+}
@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lox/wandiweather/internal/emergency"
+)
+
+// InversionAlertThresholds decide when the live tier spread raises a local
+// frost alert. The alert fires when the upper stations are at least Spread °C
+// warmer than the valley floor and the valley floor is at or below ValleyTemp.
+type InversionAlertThresholds struct {
+	Spread     float64 // °C, upper average minus valley floor average
+	ValleyTemp float64 // °C, valley floor average
+}
+
+// DefaultInversionAlertThresholds returns the thresholds used when none are
+// configured. Screen temperatures around 3°C usually mean frost on the ground.
+func DefaultInversionAlertThresholds() InversionAlertThresholds {
+	return InversionAlertThresholds{
+		Spread:     5,
+		ValleyTemp: 3,
+	}
+}
+
+// inversionAlertID names the local alert for the frost night containing now,
+// keyed by the local date of the morning it runs into so one alert spans
+// midnight. The date is taken from now's calendar rather than a fixed offset
+// so a daylight saving change doesn't move the boundary off noon.
+func inversionAlertID(now time.Time) string {
+	morning := now
+	if now.Hour() >= 12 {
+		morning = now.AddDate(0, 0, 1)
+	}
+	return "local-inversion-" + morning.Format("2006-01-02")
+}
+
+// checkInversionAlert compares this cycle's valley floor and upper temperatures
+// and stores a local alert while the inversion is strong enough to frost the
+// valley. The alert is cleared once the spread or valley temperature recovers.
+func (s *Scheduler) checkInversionAlert(temps map[string]float64, now time.Time) {
+	stations, err := s.store.GetActiveStations()
+	if err != nil {
+		log.Printf("scheduler: inversion alert: get stations: %v", err)
+		return
+	}
+
+	var valleyTemps, upperTemps []float64
+	for _, st := range stations {
+		temp, ok := temps[st.StationID]
+		if !ok {
+			continue
+		}
+		switch st.ElevationTier {
+		case "valley_floor":
+			valleyTemps = append(valleyTemps, temp)
+		case "upper":
+			upperTemps = append(upperTemps, temp)
+		}
+	}
+	if len(valleyTemps) == 0 || len(upperTemps) == 0 {
+		return
+	}
+
+	now = now.In(s.loc)
+	id := inversionAlertID(now)
+	valley := mean(valleyTemps)
+	spread := mean(upperTemps) - valley
+
+	if spread < s.inversionAlert.Spread || valley > s.inversionAlert.ValleyTemp {
+		if cleared, err := s.store.ClearAlert(id, now); err != nil {
+			log.Printf("scheduler: inversion alert: clear: %v", err)
+		} else if cleared {
+			log.Printf("scheduler: inversion alert cleared: valley=%.1f°C spread=%.1f°C", valley, spread)
+		}
+		return
+	}
+
+	alert := emergency.Alert{
+		ID:          id,
+		Category:    emergency.CategoryLocal,
+		SubCategory: "Frost",
+		Name:        "Inversion",
+		Status:      "Active",
+		Location:    "Valley floor",
+		Severity:    emergency.SeverityWatchAct,
+		Created:     now,
+		Updated:     now,
+		Headline:    fmt.Sprintf("Strong inversion: valley floor %.1f°C", valley),
+		Body: fmt.Sprintf("The valley floor is %.1f°C, %.1f°C colder than the upper stations. "+
+			"Expect frost in low-lying gardens; cover sensitive plants.", valley, spread),
+	}
+	if _, _, err := s.store.UpsertAlert(alert, now); err != nil {
+		log.Printf("scheduler: inversion alert: %v", err)
+		return
+	}
+	log.Printf("scheduler: inversion alert: valley=%.1f°C spread=%.1f°C", valley, spread)
+}
+
+func mean(vals []float64) float64 {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
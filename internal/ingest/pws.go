@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -14,16 +15,55 @@ import (
 	"github.com/lox/wandiweather/internal/models"
 )
 
+const wuPWSObservationsURL = "https://api.weather.com/v2/pws/observations"
+
 type PWS struct {
-	apiKey string
-	client *http.Client
+	apiKey    string
+	client    *http.Client
+	qc        QCThresholds
+	locations map[string]models.Station
+	baseURL   string
+	roundTo   time.Duration // observation timestamps are rounded to this; zero keeps them as reported
 }
 
 func NewPWS(apiKey string) *PWS {
 	return &PWS{
-		apiKey: apiKey,
-		client: httputil.NewClient(),
+		apiKey:  apiKey,
+		client:  httputil.NewClient(),
+		qc:      DefaultQCThresholds(),
+		baseURL: wuPWSObservationsURL,
+	}
+}
+
+// SetStationLocations sets the configured coordinates that reported station
+// locations are checked against. Stations without coordinates are skipped.
+func (p *PWS) SetStationLocations(stations []models.Station) {
+	p.locations = make(map[string]models.Station, len(stations))
+	for _, st := range stations {
+		if st.Latitude != 0 || st.Longitude != 0 {
+			p.locations[st.StationID] = st
+		}
+	}
+}
+
+// roundObservedAt rounds a reported observation time to the configured
+// interval, so a station re-reporting the same reading with a jittered
+// timestamp collides with the stored row instead of adding a near-duplicate.
+func (p *PWS) roundObservedAt(t time.Time) time.Time {
+	if p.roundTo <= 0 {
+		return t
+	}
+	return t.Round(p.roundTo)
+}
+
+// locationFlags returns FlagLocationMismatch if the station reported
+// coordinates far from where it's configured.
+func (p *PWS) locationFlags(stationID string, lat, lon float64) []string {
+	st, ok := p.locations[stationID]
+	if !ok {
+		return nil
 	}
+	return p.qc.ValidateLocation(lat, lon, st.Latitude, st.Longitude)
 }
 
 func truncateBody(b []byte) string {
@@ -39,28 +79,28 @@ type CurrentResponse struct {
 }
 
 type CurrentObservation struct {
-	StationID      string  `json:"stationID"`
-	ObsTimeUtc     string  `json:"obsTimeUtc"`
-	ObsTimeLocal   string  `json:"obsTimeLocal"`
-	Neighborhood   string  `json:"neighborhood"`
-	Lat            float64 `json:"lat"`
-	Lon            float64 `json:"lon"`
-	Humidity       *int    `json:"humidity"`
-	UV             *float64 `json:"uv"`
-	WindDir        *int    `json:"winddir"`
-	SolarRadiation *float64 `json:"solarRadiation"`
-	QCStatus       int     `json:"qcStatus"`
+	StationID      string    `json:"stationID"`
+	ObsTimeUtc     string    `json:"obsTimeUtc"`
+	ObsTimeLocal   string    `json:"obsTimeLocal"`
+	Neighborhood   string    `json:"neighborhood"`
+	Lat            float64   `json:"lat"`
+	Lon            float64   `json:"lon"`
+	Humidity       FlexInt   `json:"humidity"`
+	UV             FlexFloat `json:"uv"`
+	WindDir        FlexInt   `json:"winddir"`
+	SolarRadiation FlexFloat `json:"solarRadiation"`
+	QCStatus       int       `json:"qcStatus"`
 	Metric         *struct {
-		Temp        *float64 `json:"temp"`
-		HeatIndex   *float64 `json:"heatIndex"`
-		Dewpt       *float64 `json:"dewpt"`
-		WindChill   *float64 `json:"windChill"`
-		WindSpeed   *float64 `json:"windSpeed"`
-		WindGust    *float64 `json:"windGust"`
-		Pressure    *float64 `json:"pressure"`
-		PrecipRate  *float64 `json:"precipRate"`
-		PrecipTotal *float64 `json:"precipTotal"`
-		Elev        *float64 `json:"elev"`
+		Temp        FlexFloat `json:"temp"`
+		HeatIndex   FlexFloat `json:"heatIndex"`
+		Dewpt       FlexFloat `json:"dewpt"`
+		WindChill   FlexFloat `json:"windChill"`
+		WindSpeed   FlexFloat `json:"windSpeed"`
+		WindGust    FlexFloat `json:"windGust"`
+		Pressure    FlexFloat `json:"pressure"`
+		PrecipRate  FlexFloat `json:"precipRate"`
+		PrecipTotal FlexFloat `json:"precipTotal"`
+		Elev        FlexFloat `json:"elev"`
 	} `json:"metric"`
 }
 
@@ -72,17 +112,24 @@ type FetchResult struct {
 	ParseErrors  int    // Number of records that failed to parse
 	Error        error  // Fatal error (if any)
 	ParseError   string // Description of parse errors (if any)
+	Transport    string // How the payload was fetched, if the source has more than one
 }
 
-func (p *PWS) FetchCurrent(stationID string) (*models.Observation, string, *FetchResult, error) {
-	url := fmt.Sprintf("https://api.weather.com/v2/pws/observations/current?stationId=%s&format=json&units=m&apiKey=%s", stationID, p.apiKey)
+// FetchCurrent fetches a station's latest observation. Cancelling ctx aborts
+// the request and any retry backoff.
+func (p *PWS) FetchCurrent(ctx context.Context, stationID string) (*models.Observation, string, *FetchResult, error) {
+	url := fmt.Sprintf("%s/current?stationId=%s&format=json&units=m&apiKey=%s", p.baseURL, stationID, p.apiKey)
 	start := time.Now()
 	result := &FetchResult{}
 
 	var body []byte
 	var lastStatus int
 	operation := func() error {
-		resp, err := p.client.Get(url)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("fetch current: %w", err))
+		}
+		resp, err := p.client.Do(req)
 		if err != nil {
 			return fmt.Errorf("fetch current: %w", err)
 		}
@@ -118,7 +165,7 @@ func (p *PWS) FetchCurrent(stationID string) (*models.Observation, string, *Fetc
 
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxElapsedTime = 2 * time.Minute
-	if err := backoff.Retry(operation, bo); err != nil {
+	if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
 		result.HTTPStatus = lastStatus
 		result.Error = err
 		return nil, "", result, err
@@ -152,57 +199,35 @@ func (p *PWS) FetchCurrent(stationID string) (*models.Observation, string, *Fetc
 
 	observation := &models.Observation{
 		StationID:  obs.StationID,
-		ObservedAt: observedAt,
+		ObservedAt: p.roundObservedAt(observedAt),
 		QCStatus:   obs.QCStatus,
 		ObsType:    models.ObsTypeInstant,
 	}
 
 	// First populate all fields, then validate
-	if obs.Humidity != nil {
-		observation.Humidity = sql.NullInt64{Int64: int64(*obs.Humidity), Valid: true}
-	}
-	if obs.UV != nil {
-		observation.UV = sql.NullFloat64{Float64: *obs.UV, Valid: true}
-	}
-	if obs.WindDir != nil {
-		observation.WindDir = sql.NullInt64{Int64: int64(*obs.WindDir), Valid: true}
-	}
-	if obs.SolarRadiation != nil {
-		observation.SolarRadiation = sql.NullFloat64{Float64: *obs.SolarRadiation, Valid: true}
-	}
+	observation.Humidity = sql.NullInt64(obs.Humidity)
+	observation.UV = sql.NullFloat64(obs.UV)
+	observation.WindDir = sql.NullInt64(obs.WindDir)
+	observation.SolarRadiation = sql.NullFloat64(obs.SolarRadiation)
 
 	if obs.Metric != nil {
-		if obs.Metric.Temp != nil {
-			observation.Temp = sql.NullFloat64{Float64: *obs.Metric.Temp, Valid: true}
-		}
-		if obs.Metric.Dewpt != nil {
-			observation.Dewpoint = sql.NullFloat64{Float64: *obs.Metric.Dewpt, Valid: true}
-		}
-		if obs.Metric.Pressure != nil {
-			observation.Pressure = sql.NullFloat64{Float64: *obs.Metric.Pressure, Valid: true}
-		}
-		if obs.Metric.WindSpeed != nil {
-			observation.WindSpeed = sql.NullFloat64{Float64: *obs.Metric.WindSpeed, Valid: true}
-		}
-		if obs.Metric.WindGust != nil {
-			observation.WindGust = sql.NullFloat64{Float64: *obs.Metric.WindGust, Valid: true}
-		}
-		if obs.Metric.PrecipRate != nil {
-			observation.PrecipRate = sql.NullFloat64{Float64: *obs.Metric.PrecipRate, Valid: true}
-		}
-		if obs.Metric.PrecipTotal != nil {
-			observation.PrecipTotal = sql.NullFloat64{Float64: *obs.Metric.PrecipTotal, Valid: true}
-		}
-		if obs.Metric.HeatIndex != nil {
-			observation.HeatIndex = sql.NullFloat64{Float64: *obs.Metric.HeatIndex, Valid: true}
-		}
-		if obs.Metric.WindChill != nil {
-			observation.WindChill = sql.NullFloat64{Float64: *obs.Metric.WindChill, Valid: true}
-		}
+		observation.Temp = sql.NullFloat64(obs.Metric.Temp)
+		observation.Dewpoint = sql.NullFloat64(obs.Metric.Dewpt)
+		observation.Pressure = sql.NullFloat64(obs.Metric.Pressure)
+		observation.WindSpeed = sql.NullFloat64(obs.Metric.WindSpeed)
+		observation.WindGust = sql.NullFloat64(obs.Metric.WindGust)
+		observation.PrecipRate = sql.NullFloat64(obs.Metric.PrecipRate)
+		observation.PrecipTotal = sql.NullFloat64(obs.Metric.PrecipTotal)
+		observation.HeatIndex = sql.NullFloat64(obs.Metric.HeatIndex)
+		observation.WindChill = sql.NullFloat64(obs.Metric.WindChill)
 	}
 
+	observation.EnsureDerived()
+
 	// Validate and set quality flags
-	flags := ValidateObservation(observation)
+	flags := p.qc.Validate(observation)
+	flags = append(flags, p.locationFlags(stationID, obs.Lat, obs.Lon)...)
+	flags = append(flags, p.qc.ValidateTimestamp(observedAt, time.Now())...)
 	if len(flags) > 0 {
 		observation.QualityFlags = sql.NullString{String: QualityFlagsToJSON(flags), Valid: true}
 	}
@@ -215,61 +240,65 @@ type HistoryResponse struct {
 }
 
 type HistoryObservation struct {
-	StationID    string   `json:"stationID"`
-	Tz           string   `json:"tz"`
-	ObsTimeUtc   string   `json:"obsTimeUtc"`
-	ObsTimeLocal string   `json:"obsTimeLocal"`
-	Epoch        int64    `json:"epoch"`
-	Lat          float64  `json:"lat"`
-	Lon          float64  `json:"lon"`
-	HumidityHigh *int     `json:"humidityHigh"`
-	HumidityLow  *int     `json:"humidityLow"`
-	HumidityAvg  *int     `json:"humidityAvg"`
-	WinddirAvg   *int     `json:"winddirAvg"`
-	UVHigh       *float64 `json:"uvHigh"`
-	SolarRadHigh *float64 `json:"solarRadiationHigh"`
-	QCStatus     int      `json:"qcStatus"`
+	StationID    string    `json:"stationID"`
+	Tz           string    `json:"tz"`
+	ObsTimeUtc   string    `json:"obsTimeUtc"`
+	ObsTimeLocal string    `json:"obsTimeLocal"`
+	Epoch        int64     `json:"epoch"`
+	Lat          float64   `json:"lat"`
+	Lon          float64   `json:"lon"`
+	HumidityHigh FlexInt   `json:"humidityHigh"`
+	HumidityLow  FlexInt   `json:"humidityLow"`
+	HumidityAvg  FlexInt   `json:"humidityAvg"`
+	WinddirAvg   FlexInt   `json:"winddirAvg"`
+	UVHigh       FlexFloat `json:"uvHigh"`
+	SolarRadHigh FlexFloat `json:"solarRadiationHigh"`
+	QCStatus     int       `json:"qcStatus"`
 	Metric       *struct {
-		TempHigh      *float64 `json:"tempHigh"`
-		TempLow       *float64 `json:"tempLow"`
-		TempAvg       *float64 `json:"tempAvg"`
-		DewptHigh     *float64 `json:"dewptHigh"`
-		DewptLow      *float64 `json:"dewptLow"`
-		DewptAvg      *float64 `json:"dewptAvg"`
-		WindspeedHigh *float64 `json:"windspeedHigh"`
-		WindspeedLow  *float64 `json:"windspeedLow"`
-		WindspeedAvg  *float64 `json:"windspeedAvg"`
-		WindgustHigh  *float64 `json:"windgustHigh"`
-		WindgustLow   *float64 `json:"windgustLow"`
-		WindgustAvg   *float64 `json:"windgustAvg"`
-		PressureMax   *float64 `json:"pressureMax"`
-		PressureMin   *float64 `json:"pressureMin"`
-		PrecipRate    *float64 `json:"precipRate"`
-		PrecipTotal   *float64 `json:"precipTotal"`
-		HeatindexHigh *float64 `json:"heatindexHigh"`
-		HeatindexLow  *float64 `json:"heatindexLow"`
-		HeatindexAvg  *float64 `json:"heatindexAvg"`
-		WindchillHigh *float64 `json:"windchillHigh"`
-		WindchillLow  *float64 `json:"windchillLow"`
-		WindchillAvg  *float64 `json:"windchillAvg"`
+		TempHigh      FlexFloat `json:"tempHigh"`
+		TempLow       FlexFloat `json:"tempLow"`
+		TempAvg       FlexFloat `json:"tempAvg"`
+		DewptHigh     FlexFloat `json:"dewptHigh"`
+		DewptLow      FlexFloat `json:"dewptLow"`
+		DewptAvg      FlexFloat `json:"dewptAvg"`
+		WindspeedHigh FlexFloat `json:"windspeedHigh"`
+		WindspeedLow  FlexFloat `json:"windspeedLow"`
+		WindspeedAvg  FlexFloat `json:"windspeedAvg"`
+		WindgustHigh  FlexFloat `json:"windgustHigh"`
+		WindgustLow   FlexFloat `json:"windgustLow"`
+		WindgustAvg   FlexFloat `json:"windgustAvg"`
+		PressureMax   FlexFloat `json:"pressureMax"`
+		PressureMin   FlexFloat `json:"pressureMin"`
+		PrecipRate    FlexFloat `json:"precipRate"`
+		PrecipTotal   FlexFloat `json:"precipTotal"`
+		HeatindexHigh FlexFloat `json:"heatindexHigh"`
+		HeatindexLow  FlexFloat `json:"heatindexLow"`
+		HeatindexAvg  FlexFloat `json:"heatindexAvg"`
+		WindchillHigh FlexFloat `json:"windchillHigh"`
+		WindchillLow  FlexFloat `json:"windchillLow"`
+		WindchillAvg  FlexFloat `json:"windchillAvg"`
 	} `json:"metric"`
 }
 
-func (p *PWS) FetchHistory1Day(stationID string) ([]models.Observation, error) {
-	return p.fetchHistory(stationID, "all/1day")
+func (p *PWS) FetchHistory1Day(ctx context.Context, stationID string) ([]models.Observation, error) {
+	return p.fetchHistory(ctx, stationID, "all/1day")
 }
 
-func (p *PWS) FetchHistory7Day(stationID string) ([]models.Observation, error) {
-	return p.fetchHistory(stationID, "hourly/7day")
+func (p *PWS) FetchHistory7Day(ctx context.Context, stationID string) ([]models.Observation, error) {
+	return p.fetchHistory(ctx, stationID, "hourly/7day")
 }
 
-func (p *PWS) fetchHistory(stationID, endpoint string) ([]models.Observation, error) {
-	url := fmt.Sprintf("https://api.weather.com/v2/pws/observations/%s?stationId=%s&format=json&units=m&apiKey=%s", endpoint, stationID, p.apiKey)
+func (p *PWS) fetchHistory(ctx context.Context, stationID, endpoint string) ([]models.Observation, error) {
+	url := fmt.Sprintf("%s/%s?stationId=%s&format=json&units=m&apiKey=%s", p.baseURL, endpoint, stationID, p.apiKey)
 	start := time.Now()
 
 	var body []byte
 	operation := func() error {
-		resp, err := p.client.Get(url)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("fetch history: %w", err))
+		}
+		resp, err := p.client.Do(req)
 		if err != nil {
 			return fmt.Errorf("fetch history: %w", err)
 		}
@@ -304,7 +333,7 @@ func (p *PWS) fetchHistory(stationID, endpoint string) ([]models.Observation, er
 
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxElapsedTime = 2 * time.Minute
-	if err := backoff.Retry(operation, bo); err != nil {
+	if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
 		return nil, err
 	}
 
@@ -322,57 +351,35 @@ func (p *PWS) fetchHistory(stationID, endpoint string) ([]models.Observation, er
 
 		result := models.Observation{
 			StationID:         obs.StationID,
-			ObservedAt:        observedAt,
+			ObservedAt:        p.roundObservedAt(observedAt),
 			QCStatus:          obs.QCStatus,
 			ObsType:           models.ObsTypeHourlyAggregate,
 			AggregationPeriod: sql.NullInt64{Int64: 60, Valid: true},
 		}
 
-		if obs.HumidityAvg != nil {
-			result.Humidity = sql.NullInt64{Int64: int64(*obs.HumidityAvg), Valid: true}
-		}
-		if obs.UVHigh != nil {
-			result.UV = sql.NullFloat64{Float64: *obs.UVHigh, Valid: true}
-		}
-		if obs.WinddirAvg != nil {
-			result.WindDir = sql.NullInt64{Int64: int64(*obs.WinddirAvg), Valid: true}
-		}
-		if obs.SolarRadHigh != nil {
-			result.SolarRadiation = sql.NullFloat64{Float64: *obs.SolarRadHigh, Valid: true}
-		}
+		result.Humidity = sql.NullInt64(obs.HumidityAvg)
+		result.UV = sql.NullFloat64(obs.UVHigh)
+		result.WindDir = sql.NullInt64(obs.WinddirAvg)
+		result.SolarRadiation = sql.NullFloat64(obs.SolarRadHigh)
 
 		if obs.Metric != nil {
-			if obs.Metric.TempAvg != nil {
-				result.Temp = sql.NullFloat64{Float64: *obs.Metric.TempAvg, Valid: true}
-			}
-			if obs.Metric.DewptAvg != nil {
-				result.Dewpoint = sql.NullFloat64{Float64: *obs.Metric.DewptAvg, Valid: true}
-			}
-			if obs.Metric.PressureMax != nil {
-				result.Pressure = sql.NullFloat64{Float64: *obs.Metric.PressureMax, Valid: true}
-			}
-			if obs.Metric.WindspeedAvg != nil {
-				result.WindSpeed = sql.NullFloat64{Float64: *obs.Metric.WindspeedAvg, Valid: true}
-			}
-			if obs.Metric.WindgustHigh != nil {
-				result.WindGust = sql.NullFloat64{Float64: *obs.Metric.WindgustHigh, Valid: true}
-			}
-			if obs.Metric.PrecipRate != nil {
-				result.PrecipRate = sql.NullFloat64{Float64: *obs.Metric.PrecipRate, Valid: true}
-			}
-			if obs.Metric.PrecipTotal != nil {
-				result.PrecipTotal = sql.NullFloat64{Float64: *obs.Metric.PrecipTotal, Valid: true}
-			}
-			if obs.Metric.HeatindexAvg != nil {
-				result.HeatIndex = sql.NullFloat64{Float64: *obs.Metric.HeatindexAvg, Valid: true}
-			}
-			if obs.Metric.WindchillAvg != nil {
-				result.WindChill = sql.NullFloat64{Float64: *obs.Metric.WindchillAvg, Valid: true}
-			}
+			result.Temp = sql.NullFloat64(obs.Metric.TempAvg)
+			result.Dewpoint = sql.NullFloat64(obs.Metric.DewptAvg)
+			result.Pressure = sql.NullFloat64(obs.Metric.PressureMax)
+			result.WindSpeed = sql.NullFloat64(obs.Metric.WindspeedAvg)
+			result.WindGust = sql.NullFloat64(obs.Metric.WindgustHigh)
+			result.PrecipRate = sql.NullFloat64(obs.Metric.PrecipRate)
+			result.PrecipTotal = sql.NullFloat64(obs.Metric.PrecipTotal)
+			result.HeatIndex = sql.NullFloat64(obs.Metric.HeatindexAvg)
+			result.WindChill = sql.NullFloat64(obs.Metric.WindchillAvg)
 		}
 
+		result.EnsureDerived()
+
 		// Validate and set quality flags
-		flags := ValidateObservation(&result)
+		flags := p.qc.Validate(&result)
+		flags = append(flags, p.locationFlags(stationID, obs.Lat, obs.Lon)...)
+		flags = append(flags, p.qc.ValidateTimestamp(result.ObservedAt, time.Now())...)
 		if len(flags) > 0 {
 			result.QualityFlags = sql.NullString{String: QualityFlagsToJSON(flags), Valid: true}
 		}
@@ -3,6 +3,7 @@ package ingest
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -23,28 +24,33 @@ type Scheduler struct {
 	forecast         *ForecastClient
 	bom              *BOMClient
 	daily            *DailyJobs
-	stationIDs       []string
 	loc              *time.Location
 	obsInterval      time.Duration
 	imageGen         *imagegen.Generator
 	imageCache       *imagegen.Cache
 	imageGenMu       *sync.Mutex // Shared with server to prevent duplicate API calls
 	emergencyClient  *emergency.Client
+	alertWebhook     *emergency.Webhook
 	fireDangerClient *firedanger.Client
+	onObservations   func()
+	inversionAlert   InversionAlertThresholds
 	cron             *cron.Cron
+	background       sync.WaitGroup // Image generation started by Run
+	done             chan struct{}  // Closed when Run returns
 }
 
-func NewScheduler(store *store.Store, pws *PWS, forecast *ForecastClient, stationIDs []string, loc *time.Location) *Scheduler {
+func NewScheduler(store *store.Store, pws *PWS, forecast *ForecastClient, loc *time.Location) *Scheduler {
 	return &Scheduler{
 		store:           store,
 		pws:             pws,
 		forecast:        forecast,
-		bom:             NewBOMClient(""),
+		bom:             NewBOMClient("", loc),
 		daily:           NewDailyJobs(store),
-		stationIDs:      stationIDs,
 		loc:             loc,
 		obsInterval:     5 * time.Minute,
 		emergencyClient: nil, // Set via SetEmergencyClient
+		inversionAlert:  DefaultInversionAlertThresholds(),
+		done:            make(chan struct{}),
 	}
 }
 
@@ -53,6 +59,47 @@ func (s *Scheduler) SetEmergencyClient(client *emergency.Client) {
 	s.emergencyClient = client
 }
 
+// SetAlertWebhook configures the scheduler to POST new and escalated
+// emergency alerts to a webhook.
+func (s *Scheduler) SetAlertWebhook(webhook *emergency.Webhook) {
+	s.alertWebhook = webhook
+}
+
+// SetQCThresholds overrides the quality-control limits applied to ingested
+// observations.
+func (s *Scheduler) SetQCThresholds(t QCThresholds) {
+	if s.pws != nil {
+		s.pws.qc = t
+	}
+}
+
+// SetObservationRounding rounds PWS observation timestamps to the nearest
+// interval (e.g. a minute) before they're stored, collapsing re-reports of the
+// same reading whose timestamps differ by a few seconds. Zero disables it.
+func (s *Scheduler) SetObservationRounding(interval time.Duration) {
+	if s.pws != nil {
+		s.pws.roundTo = interval
+	}
+}
+
+// SetObservationHook registers a function to call after new observations are
+// stored, such as invalidating the server's current-data cache.
+func (s *Scheduler) SetObservationHook(fn func()) {
+	s.onObservations = fn
+}
+
+// SetRawRetentionDays sets how many days of raw API payloads the nightly
+// daily jobs keep before pruning them. Zero or less keeps them forever.
+func (s *Scheduler) SetRawRetentionDays(days int) {
+	s.daily.SetRawRetentionDays(days)
+}
+
+// SetInversionAlertThresholds overrides when the live tier spread raises a
+// local frost alert.
+func (s *Scheduler) SetInversionAlertThresholds(t InversionAlertThresholds) {
+	s.inversionAlert = t
+}
+
 // SetFireDangerClient configures the scheduler to poll for fire danger ratings.
 func (s *Scheduler) SetFireDangerClient(client *firedanger.Client) {
 	s.fireDangerClient = client
@@ -66,13 +113,25 @@ func (s *Scheduler) SetImageGenerator(gen *imagegen.Generator, cache *imagegen.C
 	s.imageGenMu = mu
 }
 
+// Run polls every source until ctx is cancelled. Cancellation aborts any
+// in-flight fetch, and Run doesn't return until the running job, scheduled
+// jobs and background image generation have all finished, so callers can wait
+// on Done before closing the database.
 func (s *Scheduler) Run(ctx context.Context) {
+	defer close(s.done)
+	defer s.background.Wait()
+
 	// Initial ingestion on startup
-	s.ingestObservations()
-	s.ingestForecasts()
-	s.ingestAlerts()
-	s.ingestFireDanger()
-	s.checkWeatherImage()
+	s.ingestObservations(ctx)
+	s.checkOutages()
+	s.ingestForecasts(ctx)
+	s.ingestAlerts(ctx)
+	s.ingestFireDanger(ctx)
+	s.checkWeatherImage(ctx)
+	if ctx.Err() != nil {
+		log.Println("scheduler: shutting down")
+		return
+	}
 
 	// Set up cron scheduler for fixed-time forecast fetching
 	// Times are in Melbourne timezone (AEDT/AEST)
@@ -82,26 +141,26 @@ func (s *Scheduler) Run(ctx context.Context) {
 
 	s.cron.AddFunc("0 5 * * *", func() {
 		log.Println("scheduler: 5am forecast fetch (pre-dawn)")
-		s.ingestForecasts()
+		s.ingestForecasts(ctx)
 	})
 	s.cron.AddFunc("0 11 * * *", func() {
 		log.Println("scheduler: 11am forecast fetch")
-		s.ingestForecasts()
+		s.ingestForecasts(ctx)
 	})
 	s.cron.AddFunc("0 17 * * *", func() {
 		log.Println("scheduler: 5pm forecast fetch")
-		s.ingestForecasts()
+		s.ingestForecasts(ctx)
 	})
 	s.cron.AddFunc("0 23 * * *", func() {
 		log.Println("scheduler: 11pm forecast fetch")
-		s.ingestForecasts()
+		s.ingestForecasts(ctx)
 	})
 
 	// Daily jobs at 6am
 	s.cron.AddFunc("0 6 * * *", func() {
 		log.Println("scheduler: 6am daily jobs")
 		yesterday := time.Now().In(s.loc).AddDate(0, 0, -1)
-		s.daily.RunAll(yesterday)
+		s.daily.RunAll(yesterday, false)
 	})
 
 	s.cron.Start()
@@ -121,81 +180,54 @@ func (s *Scheduler) Run(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			log.Println("scheduler: shutting down")
-			s.cron.Stop()
+			<-s.cron.Stop().Done()
 			return
 		case <-obsTicker.C:
-			s.ingestObservations()
+			s.ingestObservations(ctx)
+			s.checkOutages()
 		case <-alertTicker.C:
-			s.ingestAlerts()
+			s.ingestAlerts(ctx)
 		case <-fdrTicker.C:
-			s.ingestFireDanger()
+			s.ingestFireDanger(ctx)
 		case <-imageTicker.C:
-			s.checkWeatherImage()
+			s.checkWeatherImage(ctx)
 		}
 	}
 }
 
+// Done returns a channel that's closed once Run has returned.
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.done
+}
 
+// Wait blocks until Run has returned. It must only be called after Run has
+// been started.
+func (s *Scheduler) Wait() {
+	<-s.done
+}
 
-func (s *Scheduler) ingestForecasts() {
+func (s *Scheduler) ingestForecasts(ctx context.Context) {
 	if s.forecast == nil {
 		return
 	}
 
-	geocode := fmt.Sprintf("%.4f,%.4f", s.forecast.lat, s.forecast.lon)
-
 	log.Println("scheduler: ingesting WU forecasts")
-	run, _ := s.store.StartIngestRun("wu", "forecast/daily/5day", nil, &geocode)
-	forecasts, rawBody, fetchResult, err := s.forecast.Fetch5Day()
-
-	if run != nil {
-		run.Success = err == nil
-		if fetchResult != nil {
-			run.HTTPStatus = sql.NullInt64{Int64: int64(fetchResult.HTTPStatus), Valid: fetchResult.HTTPStatus > 0}
-			run.ResponseSizeBytes = sql.NullInt64{Int64: int64(fetchResult.ResponseSize), Valid: fetchResult.ResponseSize > 0}
-			run.RecordsParsed = sql.NullInt64{Int64: int64(fetchResult.RecordCount), Valid: true}
-			if fetchResult.ParseErrors > 0 {
-				run.ParseErrors = sql.NullInt64{Int64: int64(fetchResult.ParseErrors), Valid: true}
-				run.ErrorMessage = sql.NullString{String: fetchResult.ParseError, Valid: true}
-				log.Printf("scheduler: WU forecast parse errors: %s", fetchResult.ParseError)
-			}
-		}
-		if err != nil {
-			run.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
-		}
-	}
-
-	if len(rawBody) > 0 && run != nil {
-		if _, err := s.store.StoreRawPayload(&run.ID, "wu", "forecast/daily/5day", nil, &geocode, []byte(rawBody)); err != nil {
-			log.Printf("scheduler: store WU raw payload: %v", err)
-		}
-	}
-
-	if err != nil {
-		log.Printf("scheduler: fetch WU forecast: %v", err)
-	} else {
-		inserted := 0
-		for _, fc := range forecasts {
-			if err := s.store.InsertForecast(fc); err != nil {
-				log.Printf("scheduler: insert WU forecast: %v", err)
-				continue
-			}
-			inserted++
-		}
-		log.Printf("scheduler: inserted %d WU forecast days", inserted)
-		if run != nil {
-			run.RecordsStored = sql.NullInt64{Int64: int64(inserted), Valid: true}
-		}
+	forecasts := s.ingestWUForecast(ctx, "5day", s.forecast.Fetch5Day, 0)
+	if ctx.Err() != nil {
+		return
 	}
 
-	if run != nil {
-		s.store.CompleteIngestRun(run)
+	// The 15-day product extends the outlook; only the days past the 5-day
+	// product are kept so each fetch stores one forecast per day
+	s.ingestWUForecast(ctx, "15day", s.forecast.FetchExtended, wuFiveDayDays)
+	if ctx.Err() != nil {
+		return
 	}
 
 	if s.bom != nil {
 		log.Println("scheduler: ingesting BOM forecasts")
 		bomRun, _ := s.store.StartIngestRun("bom", "forecast/fwo", nil, &s.bom.areaCode)
-		bomForecasts, bomRawBody, bomFetchResult, err := s.bom.FetchForecasts()
+		bomForecasts, bomRawBody, bomFetchResult, err := s.bom.FetchForecasts(ctx)
 
 		if bomRun != nil {
 			bomRun.Success = err == nil
@@ -203,6 +235,7 @@ func (s *Scheduler) ingestForecasts() {
 				bomRun.HTTPStatus = sql.NullInt64{Int64: int64(bomFetchResult.HTTPStatus), Valid: bomFetchResult.HTTPStatus > 0}
 				bomRun.ResponseSizeBytes = sql.NullInt64{Int64: int64(bomFetchResult.ResponseSize), Valid: bomFetchResult.ResponseSize > 0}
 				bomRun.RecordsParsed = sql.NullInt64{Int64: int64(bomFetchResult.RecordCount), Valid: true}
+				bomRun.Transport = sql.NullString{String: bomFetchResult.Transport, Valid: bomFetchResult.Transport != ""}
 				if bomFetchResult.ParseErrors > 0 {
 					bomRun.ParseErrors = sql.NullInt64{Int64: int64(bomFetchResult.ParseErrors), Valid: true}
 					bomRun.ErrorMessage = sql.NullString{String: bomFetchResult.ParseError, Valid: true}
@@ -242,12 +275,74 @@ func (s *Scheduler) ingestForecasts() {
 		}
 	}
 
-	s.ensureWeatherImage(forecasts)
+	s.ensureWeatherImage(ctx, forecasts)
+}
+
+// wuFiveDayDays is how many days WU's 5-day product covers.
+const wuFiveDayDays = 5
+
+// ingestWUForecast fetches one of WU's daily forecast products, recording the
+// ingest run and raw payload, and stores the days from minDay on. It returns
+// the forecasts fetched, including any days it didn't store.
+func (s *Scheduler) ingestWUForecast(ctx context.Context, product string, fetch func(context.Context) ([]models.Forecast, string, *FetchResult, error), minDay int) []models.Forecast {
+	geocode := fmt.Sprintf("%.4f,%.4f", s.forecast.lat, s.forecast.lon)
+	endpoint := "forecast/daily/" + product
+
+	run, _ := s.store.StartIngestRun("wu", endpoint, nil, &geocode)
+	forecasts, rawBody, fetchResult, err := fetch(ctx)
+
+	if run != nil {
+		run.Success = err == nil
+		if fetchResult != nil {
+			run.HTTPStatus = sql.NullInt64{Int64: int64(fetchResult.HTTPStatus), Valid: fetchResult.HTTPStatus > 0}
+			run.ResponseSizeBytes = sql.NullInt64{Int64: int64(fetchResult.ResponseSize), Valid: fetchResult.ResponseSize > 0}
+			run.RecordsParsed = sql.NullInt64{Int64: int64(fetchResult.RecordCount), Valid: true}
+			if fetchResult.ParseErrors > 0 {
+				run.ParseErrors = sql.NullInt64{Int64: int64(fetchResult.ParseErrors), Valid: true}
+				run.ErrorMessage = sql.NullString{String: fetchResult.ParseError, Valid: true}
+				log.Printf("scheduler: WU %s forecast parse errors: %s", product, fetchResult.ParseError)
+			}
+		}
+		if err != nil {
+			run.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+		}
+	}
+
+	if len(rawBody) > 0 && run != nil {
+		if _, err := s.store.StoreRawPayload(&run.ID, "wu", endpoint, nil, &geocode, []byte(rawBody)); err != nil {
+			log.Printf("scheduler: store WU raw payload: %v", err)
+		}
+	}
+
+	if err != nil {
+		log.Printf("scheduler: fetch WU %s forecast: %v", product, err)
+	} else {
+		inserted := 0
+		for _, fc := range forecasts {
+			if fc.DayOfForecast < minDay {
+				continue
+			}
+			if err := s.store.InsertForecast(fc); err != nil {
+				log.Printf("scheduler: insert WU forecast: %v", err)
+				continue
+			}
+			inserted++
+		}
+		log.Printf("scheduler: inserted %d WU %s forecast days", inserted, product)
+		if run != nil {
+			run.RecordsStored = sql.NullInt64{Int64: int64(inserted), Valid: true}
+		}
+	}
+
+	if run != nil {
+		s.store.CompleteIngestRun(run)
+	}
+	return forecasts
 }
 
 // checkWeatherImage checks if the current time-of-day image is cached and generates if needed.
 // Called hourly to handle dawn/day/dusk/night transitions.
-func (s *Scheduler) checkWeatherImage() {
+func (s *Scheduler) checkWeatherImage(ctx context.Context) {
 	if s.imageGen == nil || s.imageCache == nil {
 		return
 	}
@@ -264,11 +359,11 @@ func (s *Scheduler) checkWeatherImage() {
 		return
 	}
 
-	s.ensureWeatherImage(wuForecasts)
+	s.ensureWeatherImage(ctx, wuForecasts)
 }
 
 // ensureWeatherImage pre-generates weather images for the current time of day.
-func (s *Scheduler) ensureWeatherImage(forecasts []models.Forecast) {
+func (s *Scheduler) ensureWeatherImage(ctx context.Context, forecasts []models.Forecast) {
 	if s.imageGen == nil || s.imageCache == nil {
 		return
 	}
@@ -314,7 +409,9 @@ func (s *Scheduler) ensureWeatherImage(forecasts []models.Forecast) {
 	}
 
 	// Generate in background with shared mutex
+	s.background.Add(1)
 	go func() {
+		defer s.background.Done()
 		if s.imageGenMu != nil {
 			s.imageGenMu.Lock()
 			defer s.imageGenMu.Unlock()
@@ -325,7 +422,7 @@ func (s *Scheduler) ensureWeatherImage(forecasts []models.Forecast) {
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 		defer cancel()
 
 		log.Printf("scheduler: pre-generating weather image for %s", condition)
@@ -343,12 +440,12 @@ func (s *Scheduler) ensureWeatherImage(forecasts []models.Forecast) {
 	}()
 }
 
-func (s *Scheduler) ingestFireDanger() {
+func (s *Scheduler) ingestFireDanger(ctx context.Context) {
 	if s.fireDangerClient == nil {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	forecasts, err := s.fireDangerClient.Fetch(ctx)
@@ -375,41 +472,120 @@ func (s *Scheduler) ingestFireDanger() {
 	}
 }
 
-func (s *Scheduler) ingestAlerts() {
+// notifyAlert POSTs alert to the alert webhook, if one is configured.
+func (s *Scheduler) notifyAlert(ctx context.Context, alert emergency.Alert, event string) {
+	if s.alertWebhook == nil {
+		return
+	}
+	if err := s.alertWebhook.Notify(ctx, alert, event); err != nil {
+		log.Printf("scheduler: notify %s alert %s: %v", event, alert.ID, err)
+	}
+}
+
+func (s *Scheduler) ingestAlerts(ctx context.Context) {
 	if s.emergencyClient == nil {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	run, _ := s.store.StartIngestRun("vicemergency", "events", nil, nil)
 	alerts, err := s.emergencyClient.Fetch(ctx)
 	if err != nil {
 		log.Printf("scheduler: fetch alerts: %v", err)
+		if run != nil {
+			run.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+			s.store.CompleteIngestRun(run)
+		}
 		return
 	}
 
 	now := time.Now()
+	inserted := s.storeAlerts(ctx, alerts, now)
+
+	if len(alerts) > 0 {
+		log.Printf("scheduler: stored %d emergency alerts", inserted)
+	}
+
+	if run != nil {
+		run.Success = true
+		run.RecordsParsed = sql.NullInt64{Int64: int64(len(alerts)), Valid: true}
+		run.RecordsStored = sql.NullInt64{Int64: int64(inserted), Valid: true}
+		s.store.CompleteIngestRun(run)
+	}
+
+	activeIDs := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		activeIDs = append(activeIDs, alert.ID)
+	}
+	if cleared, err := s.store.ClearMissingAlerts(activeIDs, now); err != nil {
+		log.Printf("scheduler: clear missing alerts: %v", err)
+	} else if cleared > 0 {
+		log.Printf("scheduler: %d emergency alerts cleared", cleared)
+	}
+}
+
+// storeAlerts upserts fetched alerts, notifying the alert webhook of any that
+// are new or have escalated, and returns how many were stored.
+func (s *Scheduler) storeAlerts(ctx context.Context, alerts []emergency.Alert, now time.Time) int {
 	inserted := 0
 	for _, alert := range alerts {
-		if err := s.store.UpsertAlert(alert, now); err != nil {
+		created, escalated, err := s.store.UpsertAlert(alert, now)
+		if err != nil {
 			log.Printf("scheduler: upsert alert %s: %v", alert.ID, err)
 			continue
 		}
+		if created {
+			s.notifyAlert(ctx, alert, emergency.WebhookNew)
+		}
+		if escalated {
+			log.Printf("scheduler: alert %s escalated to %s: %s", alert.ID, alert.SeverityName(), alert.Location)
+			s.notifyAlert(ctx, alert, emergency.WebhookEscalated)
+		}
 		inserted++
 	}
+	return inserted
+}
 
-	if len(alerts) > 0 {
-		log.Printf("scheduler: stored %d emergency alerts", inserted)
+// StationIngestResult is the outcome of fetching one station's current observation.
+type StationIngestResult struct {
+	StationID string   `json:"station_id"`
+	Success   bool     `json:"success"`
+	Temp      *float64 `json:"temp,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// activeStationIDs returns the stations to poll. They are read from the store
+// each cycle so changes made through /admin/stations apply without a restart.
+func (s *Scheduler) activeStationIDs() ([]string, error) {
+	stations, err := s.store.GetActiveStations()
+	if err != nil {
+		return nil, err
 	}
+	ids := make([]string, len(stations))
+	for i, st := range stations {
+		ids[i] = st.StationID
+	}
+	return ids, nil
 }
 
-func (s *Scheduler) ingestObservations() {
+func (s *Scheduler) ingestObservations(ctx context.Context) []StationIngestResult {
 	log.Println("scheduler: ingesting observations")
-	for _, stationID := range s.stationIDs {
+	stationIDs, err := s.activeStationIDs()
+	if err != nil {
+		log.Printf("scheduler: get stations: %v", err)
+		return nil
+	}
+	results := make([]StationIngestResult, 0, len(stationIDs))
+	temps := make(map[string]float64, len(stationIDs))
+	for _, stationID := range stationIDs {
+		if ctx.Err() != nil {
+			break
+		}
 		run, _ := s.store.StartIngestRun("wu", "pws/observations/current", &stationID, nil)
 
-		obs, rawJSON, fetchResult, err := s.pws.FetchCurrent(stationID)
+		obs, rawJSON, fetchResult, err := s.pws.FetchCurrent(ctx, stationID)
 
 		if run != nil {
 			run.Success = err == nil
@@ -434,6 +610,7 @@ func (s *Scheduler) ingestObservations() {
 			if run != nil {
 				s.store.CompleteIngestRun(run)
 			}
+			results = append(results, StationIngestResult{StationID: stationID, Error: err.Error()})
 			continue
 		}
 
@@ -445,6 +622,7 @@ func (s *Scheduler) ingestObservations() {
 				run.ErrorMessage = sql.NullString{String: fmt.Sprintf("insert: %v", err), Valid: true}
 				s.store.CompleteIngestRun(run)
 			}
+			results = append(results, StationIngestResult{StationID: stationID, Error: fmt.Sprintf("insert: %v", err)})
 			continue
 		}
 
@@ -453,26 +631,90 @@ func (s *Scheduler) ingestObservations() {
 			s.store.CompleteIngestRun(run)
 		}
 
+		result := StationIngestResult{StationID: stationID, Success: true}
 		if obs.Temp.Valid {
 			log.Printf("scheduler: %s: %.1f°C", stationID, obs.Temp.Float64)
+			result.Temp = &obs.Temp.Float64
+			temps[stationID] = obs.Temp.Float64
 		}
+		results = append(results, result)
 	}
+
+	s.checkInversionAlert(temps, time.Now())
+
+	if s.onObservations != nil {
+		for _, r := range results {
+			if r.Success {
+				s.onObservations()
+				break
+			}
+		}
+	}
+	return results
+}
+
+// checkOutages opens an outage for each active station that has gone stale and
+// closes it once observations resume.
+func (s *Scheduler) checkOutages() {
+	stations, err := s.store.GetActiveStations()
+	if err != nil {
+		log.Printf("scheduler: outages: get stations: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, st := range stations {
+		opened, closed, err := s.store.CheckStationOutage(st, now)
+		if err != nil {
+			log.Printf("scheduler: outages: %s: %v", st.StationID, err)
+			continue
+		}
+		if opened {
+			log.Printf("scheduler: outage opened for %s", st.StationID)
+		}
+		if closed {
+			log.Printf("scheduler: outage closed for %s", st.StationID)
+		}
+	}
+}
+
+// IngestOnce runs every ingest job once and returns the per-station
+// observation results.
+func (s *Scheduler) IngestOnce() ([]StationIngestResult, error) {
+	ctx := context.Background()
+	results := s.ingestObservations(ctx)
+	s.ingestForecasts(ctx)
+	s.ingestAlerts(ctx)
+	s.ingestFireDanger(ctx)
+	return results, nil
 }
 
-func (s *Scheduler) IngestOnce() error {
-	s.ingestObservations()
-	s.ingestForecasts()
-	s.ingestAlerts()
-	s.ingestFireDanger()
-	return nil
+// BackfillResult counts the stations a 7-day history backfill fetched.
+type BackfillResult struct {
+	Succeeded    int
+	Failed       int
+	Observations int // hourly observations inserted
 }
 
-func (s *Scheduler) BackfillHistory7Day() error {
+// BackfillHistory7Day fetches the last seven days of hourly history for every
+// station. The fetches already retry transient API errors, so a station that
+// still fails is recorded and skipped rather than abandoning the rest. The
+// returned error joins every station's failure; callers can check Succeeded
+// to tell a partial backfill from one where nothing was fetched.
+func (s *Scheduler) BackfillHistory7Day() (BackfillResult, error) {
 	log.Println("scheduler: backfilling 7-day history (hourly)")
-	for _, stationID := range s.stationIDs {
-		observations, err := s.pws.FetchHistory7Day(stationID)
+	var result BackfillResult
+	stationIDs, err := s.activeStationIDs()
+	if err != nil {
+		return result, fmt.Errorf("get stations: %w", err)
+	}
+	var errs []error
+	for _, stationID := range stationIDs {
+		observations, err := s.pws.FetchHistory7Day(context.Background(), stationID)
 		if err != nil {
 			log.Printf("scheduler: backfill7d %s: %v", stationID, err)
+			result.Failed++
+			errs = append(errs, fmt.Errorf("%s: %w", stationID, err))
 			continue
 		}
 		inserted := 0
@@ -483,20 +725,25 @@ func (s *Scheduler) BackfillHistory7Day() error {
 			}
 			inserted++
 		}
+		result.Succeeded++
+		result.Observations += inserted
 		log.Printf("scheduler: backfilled %s: %d hourly observations", stationID, inserted)
 	}
-	return nil
+	log.Printf("scheduler: backfill7d: %d stations succeeded, %d failed", result.Succeeded, result.Failed)
+	return result, errors.Join(errs...)
 }
 
-func (s *Scheduler) RunDailyJobs() error {
+// RunDailyJobs runs the daily jobs for yesterday. With dryRun set nothing is
+// written to the database.
+func (s *Scheduler) RunDailyJobs(dryRun bool) (DailyResult, error) {
 	yesterday := time.Now().AddDate(0, 0, -1)
-	return s.daily.RunAll(yesterday)
+	return s.daily.RunAll(yesterday, dryRun)
 }
 
-func (s *Scheduler) BackfillDailySummaries() error {
+func (s *Scheduler) BackfillDailySummaries() (DailyResult, error) {
 	return s.daily.BackfillSummaries()
 }
 
-func (s *Scheduler) BackfillVerification() error {
+func (s *Scheduler) BackfillVerification() (DailyResult, error) {
 	return s.daily.BackfillVerification()
 }
@@ -2,6 +2,8 @@ package ingest
 
 import (
 	"encoding/json"
+	"math"
+	"time"
 
 	"github.com/lox/wandiweather/internal/models"
 )
@@ -14,13 +16,61 @@ const (
 	FlagPressureOutOfRange  = "pressure_out_of_range"
 	FlagSolarNegative       = "solar_negative"
 	FlagPrecipNegative      = "precip_negative"
+	FlagGustImplausible     = "gust_implausible"
+	FlagLocationMismatch    = "location_mismatch"
+	FlagFutureTimestamp     = "future_timestamp"
 )
 
+// QCThresholds are the limits ValidateObservation checks readings against.
+// The defaults suit Wandiligong; deployments in other climates can override
+// them via Scheduler.SetQCThresholds.
+type QCThresholds struct {
+	TempMin      float64 // °C
+	TempMax      float64 // °C
+	WindSpeedMax float64 // km/h
+	PressureMin  float64 // hPa
+	PressureMax  float64 // hPa
+
+	// A gust more than GustSpikeMultiple times the sustained wind, and above
+	// GustSpikeMin km/h, is a sensor glitch rather than weather.
+	GustSpikeMultiple float64
+	GustSpikeMin      float64
+
+	// A station reporting coordinates more than LocationToleranceKm from its
+	// configured location has probably been moved or had its ID reassigned.
+	LocationToleranceKm float64
+
+	// An observation stamped more than FutureTolerance after it was fetched
+	// comes from a station with a wrong clock.
+	FutureTolerance time.Duration
+}
+
+// DefaultQCThresholds returns the thresholds used when none are configured.
+func DefaultQCThresholds() QCThresholds {
+	return QCThresholds{
+		TempMin:           -10,
+		TempMax:           50,
+		WindSpeedMax:      200,
+		PressureMin:       900,
+		PressureMax:       1100,
+		GustSpikeMultiple:   5,
+		GustSpikeMin:        60,
+		LocationToleranceKm: 10,
+		FutureTolerance:     10 * time.Minute,
+	}
+}
+
+// ValidateObservation checks an observation against the default thresholds.
 func ValidateObservation(obs *models.Observation) []string {
+	return DefaultQCThresholds().Validate(obs)
+}
+
+// Validate returns the quality flags for any readings outside the thresholds.
+func (t QCThresholds) Validate(obs *models.Observation) []string {
 	var flags []string
 
 	if obs.Temp.Valid {
-		if obs.Temp.Float64 < -10 || obs.Temp.Float64 > 50 {
+		if obs.Temp.Float64 < t.TempMin || obs.Temp.Float64 > t.TempMax {
 			flags = append(flags, FlagTempOutOfRange)
 		}
 	}
@@ -38,13 +88,20 @@ func ValidateObservation(obs *models.Observation) []string {
 	}
 
 	if obs.WindSpeed.Valid {
-		if obs.WindSpeed.Float64 < 0 || obs.WindSpeed.Float64 > 200 {
+		if obs.WindSpeed.Float64 < 0 || obs.WindSpeed.Float64 > t.WindSpeedMax {
 			flags = append(flags, FlagWindSpeedUnlikely)
 		}
 	}
 
+	if obs.WindGust.Valid && obs.WindSpeed.Valid {
+		gust := obs.WindGust.Float64
+		if gust > t.GustSpikeMin && gust > obs.WindSpeed.Float64*t.GustSpikeMultiple {
+			flags = append(flags, FlagGustImplausible)
+		}
+	}
+
 	if obs.Pressure.Valid {
-		if obs.Pressure.Float64 < 900 || obs.Pressure.Float64 > 1100 {
+		if obs.Pressure.Float64 < t.PressureMin || obs.Pressure.Float64 > t.PressureMax {
 			flags = append(flags, FlagPressureOutOfRange)
 		}
 	}
@@ -65,6 +122,43 @@ func ValidateObservation(obs *models.Observation) []string {
 	return flags
 }
 
+// ValidateLocation checks a station's reported coordinates against its
+// configured location. A report of (0,0) means the station sent no location,
+// and swapped latitude and longitude are a known PWS quirk, so neither is
+// flagged.
+func (t QCThresholds) ValidateLocation(lat, lon, wantLat, wantLon float64) []string {
+	if lat == 0 && lon == 0 {
+		return nil
+	}
+	if math.Abs(lat) > 90 {
+		lat, lon = lon, lat
+	}
+	if distanceKm(lat, lon, wantLat, wantLon) > t.LocationToleranceKm {
+		return []string{FlagLocationMismatch}
+	}
+	return nil
+}
+
+// ValidateTimestamp flags an observation timestamped further ahead of now than
+// the tolerance. Such a reading would otherwise sort as the latest one until
+// real time caught up with it.
+func (t QCThresholds) ValidateTimestamp(observedAt, now time.Time) []string {
+	if observedAt.Sub(now) > t.FutureTolerance {
+		return []string{FlagFutureTimestamp}
+	}
+	return nil
+}
+
+// distanceKm is the great-circle distance between two coordinates.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
 func QualityFlagsToJSON(flags []string) string {
 	if len(flags) == 0 {
 		return ""
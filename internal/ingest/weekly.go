@@ -0,0 +1,157 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lox/wandiweather/internal/store"
+)
+
+const (
+	// weeklyRainDayMM is the least rain that counts a day as wet.
+	weeklyRainDayMM = 0.2
+	// weeklyFrostTemp is the overnight minimum at or below which a night
+	// counts as frosty, matching the forecast frost condition.
+	weeklyFrostTemp = 2.0
+)
+
+// WeeklySummary is a recap of one week at the primary station, shaped to be
+// POSTed to a webhook as JSON or rendered into an email.
+type WeeklySummary struct {
+	StationID   string                 `json:"station_id"`
+	WeekStart   string                 `json:"week_start"` // YYYY-MM-DD
+	WeekEnd     string                 `json:"week_end"`   // YYYY-MM-DD, inclusive
+	Days        int                    `json:"days"`       // days with a daily summary
+	HighestMax  *WeeklyExtreme         `json:"highest_max,omitempty"`
+	LowestMin   *WeeklyExtreme         `json:"lowest_min,omitempty"`
+	MaxAnomaly  *float64               `json:"max_anomaly,omitempty"` // mean max minus climatology, °C
+	MinAnomaly  *float64               `json:"min_anomaly,omitempty"` // mean min minus climatology, °C
+	RainTotal   float64                `json:"rain_total"`            // mm
+	RainDays    int                    `json:"rain_days"`
+	FrostNights int                    `json:"frost_nights"`
+	Sources     []WeeklySourceAccuracy `json:"sources"` // best first
+	BestSource  string                 `json:"best_source,omitempty"`
+	WorstSource string                 `json:"worst_source,omitempty"` // empty with fewer than two sources
+}
+
+// WeeklyExtreme is a temperature and the day it occurred.
+type WeeklyExtreme struct {
+	Temp float64 `json:"temp"`
+	Date string  `json:"date"` // YYYY-MM-DD
+}
+
+// WeeklySourceAccuracy is one forecast source's day-1 error over the week.
+type WeeklySourceAccuracy struct {
+	Source string  `json:"source"`
+	Days   int     `json:"days"`
+	MAEMax float64 `json:"mae_max"`
+	MAEMin float64 `json:"mae_min"`
+}
+
+func (a WeeklySourceAccuracy) mae() float64 {
+	return (a.MAEMax + a.MAEMin) / 2
+}
+
+// BuildWeeklySummary recaps the seven days starting at week (a local date) for
+// the primary station from its daily summaries, climatology and forecast
+// verification. Days without a summary are left out rather than failing.
+func BuildWeeklySummary(st *store.Store, week time.Time) (*WeeklySummary, error) {
+	primary, err := st.GetPrimaryStation()
+	if err != nil {
+		return nil, fmt.Errorf("get primary station: %w", err)
+	}
+	if primary == nil {
+		return nil, fmt.Errorf("no primary station configured")
+	}
+
+	start := time.Date(week.Year(), week.Month(), week.Day(), 0, 0, 0, 0, week.Location())
+	last := start.AddDate(0, 0, 6)
+	summary := &WeeklySummary{
+		StationID: primary.StationID,
+		WeekStart: start.Format("2006-01-02"),
+		WeekEnd:   last.Format("2006-01-02"),
+		Sources:   []WeeklySourceAccuracy{},
+	}
+
+	days, err := st.GetDailySummaries(primary.StationID, start, start.AddDate(0, 0, 7).Add(-time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("get daily summaries: %w", err)
+	}
+	var maxSum, minSum float64
+	var maxCount, minCount int
+	for _, d := range days {
+		summary.Days++
+		date := d.Date.Format("2006-01-02")
+		if d.TempMax.Valid {
+			maxSum += d.TempMax.Float64
+			maxCount++
+			if summary.HighestMax == nil || d.TempMax.Float64 > summary.HighestMax.Temp {
+				summary.HighestMax = &WeeklyExtreme{Temp: d.TempMax.Float64, Date: date}
+			}
+		}
+		if d.TempMin.Valid {
+			minSum += d.TempMin.Float64
+			minCount++
+			if summary.LowestMin == nil || d.TempMin.Float64 < summary.LowestMin.Temp {
+				summary.LowestMin = &WeeklyExtreme{Temp: d.TempMin.Float64, Date: date}
+			}
+			if d.TempMin.Float64 <= weeklyFrostTemp {
+				summary.FrostNights++
+			}
+		}
+		if d.PrecipTotal.Valid {
+			summary.RainTotal += d.PrecipTotal.Float64
+			if d.PrecipTotal.Float64 >= weeklyRainDayMM {
+				summary.RainDays++
+			}
+		}
+	}
+
+	// Compare against the typical temperatures for the middle of the week
+	clim, err := st.GetClimatology(primary.StationID, start.AddDate(0, 0, 3))
+	if err != nil {
+		return nil, fmt.Errorf("get climatology: %w", err)
+	}
+	if clim != nil {
+		if maxCount > 0 {
+			anomaly := maxSum/float64(maxCount) - clim.MedianMax
+			summary.MaxAnomaly = &anomaly
+		}
+		if minCount > 0 {
+			anomaly := minSum/float64(minCount) - clim.MedianMin
+			summary.MinAnomaly = &anomaly
+		}
+	}
+
+	stats, err := st.GetDay1VerificationStatsBetween(start, last)
+	if err != nil {
+		return nil, fmt.Errorf("get verification stats: %w", err)
+	}
+	for source, s := range stats {
+		if !s.MAEMax.Valid || !s.MAEMin.Valid {
+			continue
+		}
+		summary.Sources = append(summary.Sources, WeeklySourceAccuracy{
+			Source: source,
+			Days:   s.Count,
+			MAEMax: s.MAEMax.Float64,
+			MAEMin: s.MAEMin.Float64,
+		})
+	}
+	sort.Slice(summary.Sources, func(i, j int) bool {
+		a, b := summary.Sources[i], summary.Sources[j]
+		if a.mae() != b.mae() {
+			return a.mae() < b.mae()
+		}
+		return a.Source < b.Source
+	})
+	if n := len(summary.Sources); n > 0 {
+		summary.BestSource = summary.Sources[0].Source
+		if n > 1 {
+			summary.WorstSource = summary.Sources[n-1].Source
+		}
+	}
+
+	return summary, nil
+}
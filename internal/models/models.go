@@ -2,6 +2,9 @@ package models
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -14,6 +17,45 @@ type Station struct {
 	ElevationTier string // "valley_floor", "mid_slope", "upper"
 	IsPrimary     bool
 	Active        bool
+
+	// StaleThresholdMinutes is how long since the last observation before the
+	// station is considered stale. Zero means DefaultStaleThresholdMinutes.
+	StaleThresholdMinutes int
+}
+
+// DefaultStaleThresholdMinutes is the staleness threshold for stations that
+// don't specify their own.
+const DefaultStaleThresholdMinutes = 60
+
+// elevationTiers are the tiers stations are grouped into for inversion
+// detection and tier comparisons. "local" is an older name for valley_floor.
+var elevationTiers = map[string]bool{
+	"valley_floor": true,
+	"local":        true,
+	"mid_slope":    true,
+	"upper":        true,
+}
+
+// ValidateStations checks a station configuration before it's seeded. It
+// rejects duplicate station IDs, which UpsertStation would otherwise silently
+// merge, primary stations that aren't active, and unknown elevation tiers,
+// reporting every problem found.
+func ValidateStations(stations []Station) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, st := range stations {
+		if seen[st.StationID] {
+			errs = append(errs, fmt.Errorf("station %s: duplicate station ID", st.StationID))
+		}
+		seen[st.StationID] = true
+		if st.IsPrimary && !st.Active {
+			errs = append(errs, fmt.Errorf("station %s: primary station is inactive", st.StationID))
+		}
+		if !elevationTiers[st.ElevationTier] {
+			errs = append(errs, fmt.Errorf("station %s: unknown elevation tier %q", st.StationID, st.ElevationTier))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 type Observation struct {
@@ -74,6 +116,13 @@ type Forecast struct {
 	LocationID    sql.NullString // Geocode (WU) or AAC code (BOM)
 }
 
+// NarrativeOnly reports whether the forecast has no temperatures, as with
+// BOM's current-day period once the max has passed. Such forecasts still
+// carry a precis and rain chance but must not stand in for a temperature.
+func (f Forecast) NarrativeOnly() bool {
+	return !f.TempMax.Valid && !f.TempMin.Valid
+}
+
 type DailySummary struct {
 	Date              time.Time
 	StationID         string
@@ -91,6 +140,9 @@ type DailySummary struct {
 	RegimeHeatwave    sql.NullBool
 	RegimeInversion   sql.NullBool
 	RegimeClearCalm   sql.NullBool
+	RegimeColdSnap    sql.NullBool
+	Regime            sql.NullString // forecast.RegimeToString of the flags above
+	Condition         sql.NullString // forecast.WeatherCondition from the day's forecast
 
 	// Extended features for regime classification
 	WindMeanNight               sql.NullFloat64
@@ -100,6 +152,7 @@ type DailySummary struct {
 	SolarIntegral               sql.NullFloat64
 	SolarMax                    sql.NullFloat64
 	SolarMiddayAvg              sql.NullFloat64
+	SunshineHours               sql.NullFloat64 // forecast.SunshineHours from solar radiation
 	DewpointMin                 sql.NullFloat64
 	DewpointAvg                 sql.NullFloat64
 	DewpointDepressionAfternoon sql.NullFloat64
@@ -125,6 +178,9 @@ type ForecastVerification struct {
 	ForecastPrecip    sql.NullFloat64
 	ActualPrecip      sql.NullFloat64
 	BiasPrecip        sql.NullFloat64
+	ForecastHumidity  sql.NullFloat64
+	ActualHumidity    sql.NullFloat64 // mean observed humidity from 7am to 7pm, WU's day part
+	BiasHumidity      sql.NullFloat64
 	CreatedAt         time.Time
 }
 
@@ -138,6 +194,8 @@ type VerificationStats struct {
 	MAEWind      sql.NullFloat64
 	AvgPrecipBias sql.NullFloat64
 	MAEPrecip    sql.NullFloat64
+	AvgHumidityBias sql.NullFloat64
+	MAEHumidity  sql.NullFloat64
 }
 
 type DisplayedForecast struct {
@@ -159,6 +217,57 @@ type DisplayedForecast struct {
 	BiasSamplesMin   sql.NullInt64
 	BiasFallbackMax  sql.NullBool
 	BiasFallbackMin  sql.NullBool
+	NowcastMax       sql.NullFloat64
 	SourceMax        sql.NullString
 	SourceMin        sql.NullString
 }
+
+// EnsureDerived fills in heat index and wind chill when the station didn't
+// send them, as PWS only report these conditionally. Heat index needs a
+// temperature of at least 27°C and a humidity reading; wind chill needs 10°C
+// or colder and a wind speed. Values supplied by the station are kept.
+func (o *Observation) EnsureDerived() {
+	if !o.Temp.Valid {
+		return
+	}
+	temp := o.Temp.Float64
+
+	if !o.HeatIndex.Valid && temp >= 27 && o.Humidity.Valid {
+		o.HeatIndex = sql.NullFloat64{Float64: heatIndex(temp, float64(o.Humidity.Int64)), Valid: true}
+	}
+	if !o.WindChill.Valid && temp <= 10 && o.WindSpeed.Valid {
+		o.WindChill = sql.NullFloat64{Float64: windChill(temp, o.WindSpeed.Float64), Valid: true}
+	}
+}
+
+// heatIndex is the NWS heat index (Rothfusz regression with its low and high
+// humidity adjustments) for a temperature in °C and relative humidity in %.
+func heatIndex(tempC, rh float64) float64 {
+	t := tempC*9/5 + 32
+
+	// Steadman's simple formula is used when it gives a heat index below 80°F
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + rh*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+			0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+			0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+		switch {
+		case rh < 13 && t >= 80 && t <= 112:
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		case rh > 85 && t >= 80 && t <= 87:
+			hi += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+	return (hi - 32) * 5 / 9
+}
+
+// windChill is the North American wind chill index for a temperature in °C
+// and wind speed in km/h. Below 4.8 km/h the formula doesn't apply and the
+// air temperature is returned.
+func windChill(tempC, windKmh float64) float64 {
+	if windKmh <= 4.8 {
+		return tempC
+	}
+	v := math.Pow(windKmh, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v
+}
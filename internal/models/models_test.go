@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestObservationEnsureDerived(t *testing.T) {
+	valid := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	humidity := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+
+	tests := []struct {
+		name          string
+		obs           Observation
+		wantHeatIndex sql.NullFloat64
+		wantWindChill sql.NullFloat64
+	}{
+		{
+			// 90°F at 70% is 106°F on the NWS heat index chart
+			name:          "hot and humid fills heat index",
+			obs:           Observation{Temp: valid(32.22), Humidity: humidity(70)},
+			wantHeatIndex: valid(41.06),
+		},
+		{
+			name:          "cold and windy fills wind chill",
+			obs:           Observation{Temp: valid(-10), WindSpeed: valid(20)},
+			wantWindChill: valid(-17.86),
+		},
+		{
+			name:          "calm wind chill is the air temperature",
+			obs:           Observation{Temp: valid(5), WindSpeed: valid(3)},
+			wantWindChill: valid(5),
+		},
+		{
+			name:          "station-supplied values are kept",
+			obs:           Observation{Temp: valid(32.22), Humidity: humidity(70), HeatIndex: valid(38)},
+			wantHeatIndex: valid(38),
+		},
+		{
+			name:          "station-supplied wind chill is kept",
+			obs:           Observation{Temp: valid(-10), WindSpeed: valid(20), WindChill: valid(-12)},
+			wantWindChill: valid(-12),
+		},
+		{
+			name: "mild temperature derives neither",
+			obs:  Observation{Temp: valid(18), Humidity: humidity(60), WindSpeed: valid(20)},
+		},
+		{
+			name: "hot without humidity",
+			obs:  Observation{Temp: valid(35)},
+		},
+		{
+			name: "cold without wind",
+			obs:  Observation{Temp: valid(2), Humidity: humidity(90)},
+		},
+		{
+			name: "no temperature",
+			obs:  Observation{Humidity: humidity(70), WindSpeed: valid(20)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs := tt.obs
+			obs.EnsureDerived()
+			if !nullFloatNear(obs.HeatIndex, tt.wantHeatIndex) {
+				t.Errorf("HeatIndex = %+v, want %+v", obs.HeatIndex, tt.wantHeatIndex)
+			}
+			if !nullFloatNear(obs.WindChill, tt.wantWindChill) {
+				t.Errorf("WindChill = %+v, want %+v", obs.WindChill, tt.wantWindChill)
+			}
+		})
+	}
+}
+
+func nullFloatNear(got, want sql.NullFloat64) bool {
+	if got.Valid != want.Valid {
+		return false
+	}
+	return !got.Valid || math.Abs(got.Float64-want.Float64) < 0.01
+}
+
+func TestValidateStations(t *testing.T) {
+	valid := []Station{
+		{StationID: "PRIMARY", ElevationTier: "valley_floor", IsPrimary: true, Active: true},
+		{StationID: "SHADE", ElevationTier: "local", Active: true},
+		{StationID: "SLOPE", ElevationTier: "mid_slope", Active: false},
+		{StationID: "UPPER", ElevationTier: "upper", Active: true},
+	}
+	if err := ValidateStations(valid); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		stations []Station
+		want     string
+	}{
+		{
+			name: "duplicate station ID",
+			stations: []Station{
+				{StationID: "IWANDI23", ElevationTier: "valley_floor", Active: true},
+				{StationID: "IWANDI23", ElevationTier: "upper", Active: true},
+			},
+			want: "station IWANDI23: duplicate station ID",
+		},
+		{
+			name: "inactive primary",
+			stations: []Station{
+				{StationID: "IWANDI23", ElevationTier: "valley_floor", IsPrimary: true, Active: false},
+			},
+			want: "station IWANDI23: primary station is inactive",
+		},
+		{
+			name: "unknown tier",
+			stations: []Station{
+				{StationID: "IHARRI19", ElevationTier: "summit", Active: true},
+			},
+			want: `station IHARRI19: unknown elevation tier "summit"`,
+		},
+		{
+			name: "missing tier",
+			stations: []Station{
+				{StationID: "IHARRI19", Active: true},
+			},
+			want: `station IHARRI19: unknown elevation tier ""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStations(tt.stations)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// Every problem is reported, not just the first
+	err := ValidateStations([]Station{
+		{StationID: "A", ElevationTier: "valley_floor", IsPrimary: true},
+		{StationID: "A", ElevationTier: "summit", Active: true},
+	})
+	if err == nil || !strings.Contains(err.Error(), "inactive") || !strings.Contains(err.Error(), "duplicate") || !strings.Contains(err.Error(), "summit") {
+		t.Errorf("error = %v, want all three problems", err)
+	}
+}
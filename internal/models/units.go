@@ -0,0 +1,29 @@
+package models
+
+// Everything is stored in metric units; these convert for display.
+
+// CelsiusToFahrenheit converts a temperature in °C to °F.
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// CelsiusDeltaToFahrenheit converts a temperature difference (a rate, bias
+// or anomaly) in °C to °F. Unlike an absolute temperature there's no offset.
+func CelsiusDeltaToFahrenheit(c float64) float64 {
+	return c * 9 / 5
+}
+
+// KmhToMph converts a speed in km/h to mph.
+func KmhToMph(kmh float64) float64 {
+	return kmh / 1.609344
+}
+
+// HPaToInHg converts a pressure in hPa to inches of mercury.
+func HPaToInHg(hpa float64) float64 {
+	return hpa / 33.8639
+}
+
+// MmToInches converts a length (rainfall) in mm to inches.
+func MmToInches(mm float64) float64 {
+	return mm / 25.4
+}
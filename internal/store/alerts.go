@@ -1,34 +1,80 @@
 package store
 
 import (
+	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/lox/wandiweather/internal/emergency"
 )
 
+// AlertHistoryEntry is a stored alert with its lifecycle timestamps.
+type AlertHistoryEntry struct {
+	emergency.Alert
+	FirstSeen time.Time
+	LastSeen  time.Time
+	ClearedAt sql.NullTime
+}
+
 // UpsertAlert inserts or updates an emergency alert.
-// Updates last_seen_at on conflict to track when alerts are still active.
-func (s *Store) UpsertAlert(alert emergency.Alert, now time.Time) error {
-	_, err := s.db.Exec(`
+// Updates last_seen_at on conflict to track when alerts are still active, and
+// un-clears an alert that has reappeared in the feed. It reports whether the
+// alert wasn't stored before, and whether an existing alert has escalated, i.e.
+// come back more severe than stored; the escalation stays marked until the
+// alert is downgraded again.
+func (s *Store) UpsertAlert(alert emergency.Alert, now time.Time) (created, escalated bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, false, err
+	}
+	defer tx.Rollback()
+
+	var stored sql.NullInt64
+	err = tx.QueryRow(`SELECT severity FROM emergency_alerts WHERE id = ?`, alert.ID).Scan(&stored)
+	if err != nil && err != sql.ErrNoRows {
+		return false, false, err
+	}
+	created = err == sql.ErrNoRows
+
+	var escalatedAt sql.NullTime
+	var escalatedFrom sql.NullInt64
+	if stored.Valid && alert.Severity < int(stored.Int64) {
+		escalated = true
+		escalatedAt = sql.NullTime{Time: now, Valid: true}
+		escalatedFrom = stored
+	}
+
+	_, err = tx.Exec(`
 		INSERT INTO emergency_alerts (
 			id, category, subcategory, name, status, location, distance_km,
 			severity, lat, lon, headline, body, url,
-			first_seen_at, last_seen_at, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			first_seen_at, last_seen_at, created_at, updated_at,
+			escalated_at, escalated_from
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			status = excluded.status,
 			name = excluded.name,
+			severity = excluded.severity,
 			headline = excluded.headline,
 			body = excluded.body,
 			last_seen_at = excluded.last_seen_at,
-			updated_at = excluded.updated_at
+			updated_at = excluded.updated_at,
+			cleared_at = NULL,
+			escalated_at = CASE WHEN excluded.severity > emergency_alerts.severity THEN NULL
+				ELSE COALESCE(excluded.escalated_at, emergency_alerts.escalated_at) END,
+			escalated_from = CASE WHEN excluded.severity > emergency_alerts.severity THEN NULL
+				ELSE COALESCE(excluded.escalated_from, emergency_alerts.escalated_from) END
 	`,
 		alert.ID, alert.Category, alert.SubCategory, alert.Name, alert.Status,
 		alert.Location, alert.Distance, alert.Severity, alert.Lat, alert.Lon,
 		alert.Headline, alert.Body, alert.URL,
 		now, now, alert.Created, alert.Updated,
+		escalatedAt, escalatedFrom,
 	)
-	return err
+	if err != nil {
+		return false, false, err
+	}
+	return created, escalated, tx.Commit()
 }
 
 // GetActiveAlerts returns alerts that were seen within the given duration.
@@ -37,7 +83,7 @@ func (s *Store) GetActiveAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 
 	rows, err := s.db.Query(`
 		SELECT id, category, subcategory, name, status, location, distance_km,
-		       severity, lat, lon, headline, body, url, created_at, updated_at
+		       severity, lat, lon, headline, body, url, created_at, updated_at, escalated_from
 		FROM emergency_alerts
 		WHERE last_seen_at > ?
 		ORDER BY severity ASC, distance_km ASC
@@ -51,10 +97,11 @@ func (s *Store) GetActiveAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 	for rows.Next() {
 		var a emergency.Alert
 		var createdAt, updatedAt *time.Time
+		var escalatedFrom sql.NullInt64
 		if err := rows.Scan(
 			&a.ID, &a.Category, &a.SubCategory, &a.Name, &a.Status,
 			&a.Location, &a.Distance, &a.Severity, &a.Lat, &a.Lon,
-			&a.Headline, &a.Body, &a.URL, &createdAt, &updatedAt,
+			&a.Headline, &a.Body, &a.URL, &createdAt, &updatedAt, &escalatedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -64,6 +111,9 @@ func (s *Store) GetActiveAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 		if updatedAt != nil {
 			a.Updated = *updatedAt
 		}
+		if escalatedFrom.Valid {
+			a.Escalated, a.EscalatedFrom = true, int(escalatedFrom.Int64)
+		}
 		alerts = append(alerts, a)
 	}
 
@@ -76,7 +126,7 @@ func (s *Store) GetUrgentAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 
 	rows, err := s.db.Query(`
 		SELECT id, category, subcategory, name, status, location, distance_km,
-		       severity, lat, lon, headline, body, url, created_at, updated_at
+		       severity, lat, lon, headline, body, url, created_at, updated_at, escalated_from
 		FROM emergency_alerts
 		WHERE last_seen_at > ? AND severity <= ?
 		ORDER BY severity ASC, distance_km ASC
@@ -90,10 +140,11 @@ func (s *Store) GetUrgentAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 	for rows.Next() {
 		var a emergency.Alert
 		var createdAt, updatedAt *time.Time
+		var escalatedFrom sql.NullInt64
 		if err := rows.Scan(
 			&a.ID, &a.Category, &a.SubCategory, &a.Name, &a.Status,
 			&a.Location, &a.Distance, &a.Severity, &a.Lat, &a.Lon,
-			&a.Headline, &a.Body, &a.URL, &createdAt, &updatedAt,
+			&a.Headline, &a.Body, &a.URL, &createdAt, &updatedAt, &escalatedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -103,8 +154,89 @@ func (s *Store) GetUrgentAlerts(maxAge time.Duration) ([]emergency.Alert, error)
 		if updatedAt != nil {
 			a.Updated = *updatedAt
 		}
+		if escalatedFrom.Valid {
+			a.Escalated, a.EscalatedFrom = true, int(escalatedFrom.Int64)
+		}
 		alerts = append(alerts, a)
 	}
 
 	return alerts, rows.Err()
 }
+
+// ClearMissingAlerts marks feed alerts not in activeIDs as cleared at now.
+// Alerts already cleared keep their original cleared_at, and local alerts are
+// left to ClearAlert. It returns the number of alerts newly cleared.
+func (s *Store) ClearMissingAlerts(activeIDs []string, now time.Time) (int64, error) {
+	query := `UPDATE emergency_alerts SET cleared_at = ? WHERE cleared_at IS NULL AND category != ?`
+	args := []any{now, emergency.CategoryLocal}
+	if len(activeIDs) > 0 {
+		query += ` AND id NOT IN (?` + strings.Repeat(", ?", len(activeIDs)-1) + `)`
+		for _, id := range activeIDs {
+			args = append(args, id)
+		}
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClearAlert marks a single alert as cleared at now. It reports whether the
+// alert was still open.
+func (s *Store) ClearAlert(id string, now time.Time) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE emergency_alerts SET cleared_at = ?
+		WHERE id = ? AND cleared_at IS NULL
+	`, now, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetAlertHistory returns alerts seen since the given time, including those
+// that have since cleared, most recently seen first.
+func (s *Store) GetAlertHistory(since time.Time) ([]AlertHistoryEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, category, subcategory, name, status, location, distance_km,
+		       severity, lat, lon, headline, body, url, created_at, updated_at, escalated_from,
+		       first_seen_at, last_seen_at, cleared_at
+		FROM emergency_alerts
+		WHERE last_seen_at > ?
+		ORDER BY last_seen_at DESC, severity ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AlertHistoryEntry
+	for rows.Next() {
+		var e AlertHistoryEntry
+		var createdAt, updatedAt *time.Time
+		var escalatedFrom sql.NullInt64
+		if err := rows.Scan(
+			&e.ID, &e.Category, &e.SubCategory, &e.Name, &e.Status,
+			&e.Location, &e.Distance, &e.Severity, &e.Lat, &e.Lon,
+			&e.Headline, &e.Body, &e.URL, &createdAt, &updatedAt, &escalatedFrom,
+			&e.FirstSeen, &e.LastSeen, &e.ClearedAt,
+		); err != nil {
+			return nil, err
+		}
+		if createdAt != nil {
+			e.Created = *createdAt
+		}
+		if updatedAt != nil {
+			e.Updated = *updatedAt
+		}
+		if escalatedFrom.Valid {
+			e.Escalated, e.EscalatedFrom = true, int(escalatedFrom.Int64)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/emergency"
+)
+
+func TestUpsertAlert_HistoryAndClearing(t *testing.T) {
+	store := setupTestStore(t)
+
+	first := time.Now().UTC().Add(-20 * time.Minute).Truncate(time.Second)
+	second := first.Add(10 * time.Minute)
+
+	fire := emergency.Alert{ID: "fire-1", Category: "Fire", Name: "Watch & Act", Severity: emergency.SeverityWatchAct}
+	flood := emergency.Alert{ID: "flood-1", Category: "Flood", Name: "Advice", Severity: emergency.SeverityAdvice}
+
+	for _, a := range []emergency.Alert{fire, flood} {
+		if _, _, err := store.UpsertAlert(a, first); err != nil {
+			t.Fatalf("UpsertAlert %s: %v", a.ID, err)
+		}
+	}
+
+	// Next poll only sees the fire
+	if _, _, err := store.UpsertAlert(fire, second); err != nil {
+		t.Fatalf("UpsertAlert: %v", err)
+	}
+	cleared, err := store.ClearMissingAlerts([]string{fire.ID}, second)
+	if err != nil {
+		t.Fatalf("ClearMissingAlerts: %v", err)
+	}
+	if cleared != 1 {
+		t.Errorf("cleared = %d, want 1", cleared)
+	}
+
+	history, err := store.GetAlertHistory(first.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetAlertHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d history entries, want 2", len(history))
+	}
+
+	byID := make(map[string]AlertHistoryEntry)
+	for _, e := range history {
+		byID[e.ID] = e
+	}
+
+	gotFire := byID[fire.ID]
+	if !gotFire.FirstSeen.Equal(first) {
+		t.Errorf("fire first_seen = %v, want %v", gotFire.FirstSeen, first)
+	}
+	if !gotFire.LastSeen.Equal(second) {
+		t.Errorf("fire last_seen = %v, want %v", gotFire.LastSeen, second)
+	}
+	if gotFire.ClearedAt.Valid {
+		t.Errorf("fire should not be cleared, got %v", gotFire.ClearedAt.Time)
+	}
+
+	gotFlood := byID[flood.ID]
+	if !gotFlood.LastSeen.Equal(first) {
+		t.Errorf("flood last_seen = %v, want %v", gotFlood.LastSeen, first)
+	}
+	if !gotFlood.ClearedAt.Valid || !gotFlood.ClearedAt.Time.Equal(second) {
+		t.Errorf("flood cleared_at = %v, want %v", gotFlood.ClearedAt, second)
+	}
+
+	// A cleared alert that reappears is active again
+	if _, _, err := store.UpsertAlert(flood, second.Add(time.Minute)); err != nil {
+		t.Fatalf("UpsertAlert: %v", err)
+	}
+	history, err = store.GetAlertHistory(first.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetAlertHistory: %v", err)
+	}
+	for _, e := range history {
+		if e.ID == flood.ID && e.ClearedAt.Valid {
+			t.Errorf("reappeared flood alert still cleared at %v", e.ClearedAt.Time)
+		}
+	}
+}
+
+func TestUpsertAlert_Escalation(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC().Add(-10 * time.Minute).Truncate(time.Second)
+	fire := emergency.Alert{ID: "fire-1", Category: "Fire", Name: "Advice", Severity: emergency.SeverityAdvice}
+
+	steps := []struct {
+		name          string
+		severity      int
+		wantEscalated bool // returned by this upsert
+		wantMarked    bool // on the stored alert afterwards
+	}{
+		{"first seen", emergency.SeverityAdvice, false, false},
+		{"unchanged", emergency.SeverityAdvice, false, false},
+		{"advice to emergency", emergency.SeverityEmergency, true, true},
+		{"still emergency", emergency.SeverityEmergency, false, true},
+		{"downgraded", emergency.SeverityWatchAct, false, false},
+	}
+	for i, step := range steps {
+		fire.Severity = step.severity
+		created, escalated, err := store.UpsertAlert(fire, now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("%s: UpsertAlert: %v", step.name, err)
+		}
+		if created != (i == 0) {
+			t.Errorf("%s: created = %v, want %v", step.name, created, i == 0)
+		}
+		if escalated != step.wantEscalated {
+			t.Errorf("%s: escalated = %v, want %v", step.name, escalated, step.wantEscalated)
+		}
+
+		alerts, err := store.GetActiveAlerts(time.Hour)
+		if err != nil {
+			t.Fatalf("%s: GetActiveAlerts: %v", step.name, err)
+		}
+		if len(alerts) != 1 {
+			t.Fatalf("%s: got %d active alerts, want 1", step.name, len(alerts))
+		}
+		got := alerts[0]
+		if got.Severity != step.severity {
+			t.Errorf("%s: stored severity = %d, want %d", step.name, got.Severity, step.severity)
+		}
+		if got.Escalated != step.wantMarked {
+			t.Errorf("%s: Escalated = %v, want %v", step.name, got.Escalated, step.wantMarked)
+		}
+		if step.wantMarked && got.EscalatedFrom != emergency.SeverityAdvice {
+			t.Errorf("%s: EscalatedFrom = %d, want advice", step.name, got.EscalatedFrom)
+		}
+	}
+}
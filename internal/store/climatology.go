@@ -0,0 +1,138 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// climatologyWindowDays is how many days either side of the calendar date are
+// pooled from previous years.
+const climatologyWindowDays = 7
+
+// minClimatologySamples is how many historical days are needed before the
+// medians are trusted.
+const minClimatologySamples = 10
+
+// Climatology holds typical temperatures for a calendar date, drawn from
+// daily summaries within a week of the same date in previous years.
+type Climatology struct {
+	MedianMax float64
+	MedianMin float64
+	Samples   int
+}
+
+// GetClimatology returns the median daily max and min for the station around
+// date's day of year. It returns nil if there isn't enough history yet.
+func (s *Store) GetClimatology(stationID string, date time.Time) (*Climatology, error) {
+	date = date.In(s.loc)
+	// Exclude the current window so recent days don't count as history
+	cutoff := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -climatologyWindowDays)
+
+	rows, err := s.db.Query(`
+		SELECT SUBSTR(date, 1, 10), temp_max, temp_min
+		FROM daily_summaries
+		WHERE station_id = ? AND SUBSTR(date, 1, 10) < ?
+			AND temp_max IS NOT NULL AND temp_min IS NOT NULL
+	`, stationID, cutoff.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var maxes, mins []float64
+	for rows.Next() {
+		var dateStr string
+		var tMax, tMin float64
+		if err := rows.Scan(&dateStr, &tMax, &tMin); err != nil {
+			return nil, err
+		}
+		d, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			continue
+		}
+		if dayOfYearDistance(d, date) > climatologyWindowDays {
+			continue
+		}
+		maxes = append(maxes, tMax)
+		mins = append(mins, tMin)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(maxes) < minClimatologySamples {
+		return nil, nil
+	}
+	return &Climatology{
+		MedianMax: medianOf(maxes),
+		MedianMin: medianOf(mins),
+		Samples:   len(maxes),
+	}, nil
+}
+
+// minSameDayYears is how many earlier years of a calendar day are needed
+// before their median is trusted.
+const minSameDayYears = 2
+
+// SameDayClimatology is the typical daily mean temperature for a calendar day,
+// drawn from the same day in previous years.
+type SameDayClimatology struct {
+	MedianTemp float64
+	Years      int
+}
+
+// GetSameDayClimatology returns the median of the station's daily mean
+// temperatures on date's calendar day in earlier years. It returns nil if
+// fewer than minSameDayYears have a summary for that day.
+func (s *Store) GetSameDayClimatology(stationID string, date time.Time) (*SameDayClimatology, error) {
+	date = date.In(s.loc)
+	rows, err := s.db.Query(`
+		SELECT temp_avg
+		FROM daily_summaries
+		WHERE station_id = ? AND SUBSTR(date, 6, 5) = ? AND SUBSTR(date, 1, 4) < ?
+			AND temp_avg IS NOT NULL
+	`, stationID, date.Format("01-02"), date.Format("2006"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var temps []float64
+	for rows.Next() {
+		var temp float64
+		if err := rows.Scan(&temp); err != nil {
+			return nil, err
+		}
+		temps = append(temps, temp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(temps) < minSameDayYears {
+		return nil, nil
+	}
+	return &SameDayClimatology{MedianTemp: medianOf(temps), Years: len(temps)}, nil
+}
+
+// dayOfYearDistance returns the number of days between a and b's calendar
+// dates ignoring the year, wrapping around the new year.
+func dayOfYearDistance(a, b time.Time) int {
+	diff := a.YearDay() - b.YearDay()
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > 182 {
+		diff = 365 - diff
+	}
+	return diff
+}
+
+func medianOf(vals []float64) float64 {
+	sort.Float64s(vals)
+	n := len(vals)
+	if n%2 == 0 {
+		return (vals[n/2-1] + vals[n/2]) / 2
+	}
+	return vals[n/2]
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestGetClimatology(t *testing.T) {
+	s := setupTestStore(t)
+	date := time.Date(2026, 1, 3, 15, 0, 0, 0, s.loc)
+
+	insert := func(d time.Time, max, min float64) {
+		t.Helper()
+		if err := s.UpsertDailySummary(models.DailySummary{
+			Date:      d,
+			StationID: "TEST1",
+			TempMax:   sql.NullFloat64{Float64: max, Valid: true},
+			TempMin:   sql.NullFloat64{Float64: min, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Recent days this year aren't history
+	for i := 1; i <= 20; i++ {
+		insert(time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -i), 40, 20)
+	}
+	clim, err := s.GetClimatology("TEST1", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clim != nil {
+		t.Fatalf("expected nil with no prior-year history, got %+v", clim)
+	}
+
+	// A year earlier, spanning the new year; days outside the window are ignored
+	for i := -7; i <= 7; i++ {
+		insert(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i), 28, 12)
+	}
+	insert(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), 10, 0)
+
+	clim, err = s.GetClimatology("TEST1", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clim == nil {
+		t.Fatal("expected climatology")
+	}
+	if clim.Samples != 15 {
+		t.Errorf("Samples = %d, want 15", clim.Samples)
+	}
+	if clim.MedianMax != 28 || clim.MedianMin != 12 {
+		t.Errorf("medians = %.1f/%.1f, want 28/12", clim.MedianMax, clim.MedianMin)
+	}
+}
+
+func TestGetSameDayClimatology(t *testing.T) {
+	s := setupTestStore(t)
+	date := time.Date(2026, 3, 10, 15, 0, 0, 0, s.loc)
+
+	insert := func(d time.Time, avg float64) {
+		t.Helper()
+		if err := s.UpsertDailySummary(models.DailySummary{
+			Date:      d,
+			StationID: "TEST1",
+			TempAvg:   sql.NullFloat64{Float64: avg, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Neighbouring days and this year's date aren't the same-day history
+	insert(time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), 40)
+	insert(time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), 40)
+	insert(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), 40)
+	insert(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), 18)
+
+	clim, err := s.GetSameDayClimatology("TEST1", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clim != nil {
+		t.Fatalf("expected nil with one earlier year, got %+v", clim)
+	}
+
+	insert(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), 14)
+	insert(time.Date(2023, 3, 10, 0, 0, 0, 0, time.UTC), 15)
+
+	clim, err = s.GetSameDayClimatology("TEST1", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clim == nil {
+		t.Fatal("expected climatology")
+	}
+	if clim.Years != 3 || clim.MedianTemp != 15 {
+		t.Errorf("climatology = %+v, want median 15 over 3 years", clim)
+	}
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"database/sql"
+	"math"
+	"sort"
 	"time"
 )
 
@@ -21,6 +23,7 @@ type IngestRun struct {
 	ParseErrors       sql.NullInt64 // Number of records that failed to parse
 	Success           bool
 	ErrorMessage      sql.NullString
+	Transport         sql.NullString // "ftp" or "http" for sources with a fallback
 }
 
 // StartIngestRun creates a new ingest run record and returns it.
@@ -70,13 +73,53 @@ func (s *Store) CompleteIngestRun(run *IngestRun) error {
 			records_stored = ?,
 			parse_errors = ?,
 			success = ?,
-			error_message = ?
+			error_message = ?,
+			transport = ?
 		WHERE id = ?
 	`, run.FinishedAt, run.HTTPStatus, run.ResponseSizeBytes, run.RecordsParsed,
-		run.RecordsStored, run.ParseErrors, run.Success, run.ErrorMessage, run.ID)
+		run.RecordsStored, run.ParseErrors, run.Success, run.ErrorMessage, run.Transport, run.ID)
 	return err
 }
 
+// SourceLastSuccess is the most recent successful ingest run for a source and endpoint.
+type SourceLastSuccess struct {
+	Source      string
+	Endpoint    string
+	LastSuccess time.Time
+}
+
+// GetLastSuccessBySource returns the start time of the latest successful run
+// for each source/endpoint pair.
+func (s *Store) GetLastSuccessBySource() ([]SourceLastSuccess, error) {
+	rows, err := s.db.Query(`
+		SELECT source, endpoint, MAX(started_at)
+		FROM ingest_runs
+		WHERE success
+		GROUP BY source, endpoint
+		ORDER BY source, endpoint
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SourceLastSuccess
+	for rows.Next() {
+		var r SourceLastSuccess
+		var startedAt string
+		if err := rows.Scan(&r.Source, &r.Endpoint, &startedAt); err != nil {
+			return nil, err
+		}
+		// MAX() loses the column type, so the timestamp comes back as text
+		r.LastSuccess, err = time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", startedAt)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // IngestHealthSummary represents a daily ingest health summary.
 type IngestHealthSummary struct {
 	Date            string
@@ -123,6 +166,66 @@ func (s *Store) GetIngestHealth(days int) ([]IngestHealthSummary, error) {
 	return results, rows.Err()
 }
 
+// LatencyStats is the distribution of ingest run durations for one source
+// and endpoint.
+type LatencyStats struct {
+	Source   string
+	Endpoint string
+	Runs     int
+	P50      time.Duration
+	P95      time.Duration
+	Max      time.Duration
+}
+
+// GetIngestLatencyStats returns the p50, p95 and max duration of finished
+// ingest runs over the last N days, keyed by "source/endpoint". Percentiles
+// use the nearest-rank method.
+func (s *Store) GetIngestLatencyStats(days int) (map[string]LatencyStats, error) {
+	rows, err := s.db.Query(`
+		SELECT source, endpoint, started_at, finished_at
+		FROM ingest_runs
+		WHERE finished_at IS NOT NULL
+		  AND SUBSTR(started_at, 1, 19) > datetime('now', '-' || ? || ' days')
+	`, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	durations := make(map[string][]time.Duration)
+	stats := make(map[string]LatencyStats)
+	for rows.Next() {
+		var source, endpoint string
+		var startedAt, finishedAt time.Time
+		if err := rows.Scan(&source, &endpoint, &startedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+		key := source + "/" + endpoint
+		durations[key] = append(durations[key], max(0, finishedAt.Sub(startedAt)))
+		stats[key] = LatencyStats{Source: source, Endpoint: endpoint}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		st := stats[key]
+		st.Runs = len(ds)
+		st.P50 = nearestRank(ds, 0.50)
+		st.P95 = nearestRank(ds, 0.95)
+		st.Max = ds[len(ds)-1]
+		stats[key] = st
+	}
+	return stats, nil
+}
+
+// nearestRank returns the p-th percentile of sorted, non-empty durations.
+func nearestRank(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
 // GetRecentIngestErrors returns recent failed ingest runs.
 func (s *Store) GetRecentIngestErrors(limit int) ([]IngestRun, error) {
 	rows, err := s.db.Query(`
@@ -487,6 +487,118 @@ WHERE obs_type = 'unknown'
 UPDATE observations 
 SET obs_type = 'instant'
 WHERE obs_type = 'unknown';
+`,
+	},
+	{
+		Version:     24,
+		Description: "Add cold snap regime column to daily_summaries",
+		SQL: `
+ALTER TABLE daily_summaries ADD COLUMN regime_cold_snap BOOLEAN;
+`,
+	},
+	{
+		Version:     25,
+		Description: "Add per-station staleness threshold for health checks",
+		SQL: `
+ALTER TABLE stations ADD COLUMN stale_threshold_minutes INTEGER NOT NULL DEFAULT 60;
+`,
+	},
+	{
+		Version:     26,
+		Description: "Add nowcast adjustment to displayed_forecasts",
+		SQL: `
+ALTER TABLE displayed_forecasts ADD COLUMN nowcast_max REAL;
+`,
+	},
+	{
+		Version:     27,
+		Description: "Add indexes for accuracy queries on verification and displayed forecasts",
+		SQL: `
+CREATE INDEX IF NOT EXISTS idx_verification_valid_date ON forecast_verification(valid_date);
+CREATE INDEX IF NOT EXISTS idx_verification_forecast_id ON forecast_verification(forecast_id);
+CREATE INDEX IF NOT EXISTS idx_displayed_forecasts_valid_date ON displayed_forecasts(valid_date);
+`,
+	},
+	{
+		Version:     28,
+		Description: "Track when emergency alerts drop out of the feed",
+		SQL: `
+ALTER TABLE emergency_alerts ADD COLUMN cleared_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_alerts_cleared_at ON emergency_alerts(cleared_at);
+`,
+	},
+	{
+		Version:     29,
+		Description: "Record fetch transport on ingest runs",
+		SQL: `
+ALTER TABLE ingest_runs ADD COLUMN transport TEXT;
+`,
+	},
+	{
+		Version:     30,
+		Description: "Store classified regime and condition on daily summaries",
+		SQL: `
+ALTER TABLE daily_summaries ADD COLUMN regime TEXT;
+ALTER TABLE daily_summaries ADD COLUMN condition TEXT;
+`,
+	},
+	{
+		Version:     31,
+		Description: "Track station outages",
+		SQL: `
+CREATE TABLE IF NOT EXISTS station_outages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    station_id TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    detected_at DATETIME NOT NULL,
+    ended_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_station_outages_open ON station_outages(station_id, ended_at);
+`,
+	},
+	{
+		Version:     32,
+		Description: "Add humidity to forecast verification",
+		SQL: `
+ALTER TABLE forecast_verification ADD COLUMN forecast_humidity REAL;
+ALTER TABLE forecast_verification ADD COLUMN actual_humidity REAL;
+ALTER TABLE forecast_verification ADD COLUMN bias_humidity REAL;
+`,
+	},
+	{
+		Version:     33,
+		Description: "Make forecast verification unique per forecast and date",
+		SQL: `
+DELETE FROM forecast_verification WHERE id NOT IN (
+    SELECT MAX(id) FROM forecast_verification GROUP BY forecast_id, SUBSTR(valid_date, 1, 10)
+);
+UPDATE forecast_verification SET valid_date = SUBSTR(valid_date, 1, 10) || ' 00:00:00+00:00';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_forecast_verification_unique ON forecast_verification(forecast_id, valid_date);
+`,
+	},
+	{
+		Version:     34,
+		Description: "Track emergency alert escalations",
+		SQL: `
+ALTER TABLE emergency_alerts ADD COLUMN escalated_at DATETIME;
+ALTER TABLE emergency_alerts ADD COLUMN escalated_from INTEGER;
+`,
+	},
+	{
+		Version:     35,
+		Description: "Add sunshine hours to daily summaries",
+		SQL: `
+ALTER TABLE daily_summaries ADD COLUMN sunshine_hours REAL;
+`,
+	},
+	{
+		Version:     36,
+		Description: "Normalize forecast verification dates written since version 33",
+		SQL: `
+DELETE FROM forecast_verification WHERE id NOT IN (
+    SELECT MAX(id) FROM forecast_verification GROUP BY forecast_id, SUBSTR(valid_date, 1, 10)
+);
+UPDATE forecast_verification SET valid_date = SUBSTR(valid_date, 1, 10) || ' 00:00:00+00:00';
 `,
 	},
 }
@@ -576,3 +688,56 @@ func (s *Store) MigrationVersion() (int, error) {
 	}
 	return int(version.Int64), nil
 }
+
+// AppliedMigration is a migration recorded in schema_migrations.
+type AppliedMigration struct {
+	Version     int
+	Description string
+	AppliedAt   time.Time
+}
+
+// GetAppliedMigrationDetails returns every applied migration, oldest version
+// first.
+func (s *Store) GetAppliedMigrationDetails() ([]AppliedMigration, error) {
+	rows, err := s.db.Query("SELECT version, description, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []AppliedMigration
+	for rows.Next() {
+		var m AppliedMigration
+		var description sql.NullString
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&m.Version, &description, &appliedAt); err != nil {
+			return nil, err
+		}
+		m.Description = description.String
+		m.AppliedAt = appliedAt.Time
+		results = append(results, m)
+	}
+	return results, rows.Err()
+}
+
+// LatestMigrationVersion is the version of the newest migration this build
+// knows about.
+func LatestMigrationVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// PendingMigrations returns the versions of known migrations that haven't
+// been applied, in order.
+func (s *Store) PendingMigrations() ([]int, error) {
+	applied, err := s.getAppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []int
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m.Version)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+// StationOutage is a period during which a station stopped reporting.
+// StartedAt is the last observation before the gap and EndedAt the first one
+// after it; DetectedAt is when the staleness threshold was crossed.
+type StationOutage struct {
+	ID         int64
+	StationID  string
+	StartedAt  time.Time
+	DetectedAt time.Time
+	EndedAt    sql.NullTime
+}
+
+// CheckStationOutage opens an outage for the station if its latest observation
+// is older than its staleness threshold, or closes the open outage if data has
+// resumed. Stations that have never reported are left alone.
+func (s *Store) CheckStationOutage(st models.Station, now time.Time) (opened, closed bool, err error) {
+	obs, err := s.GetLatestObservation(st.StationID, true)
+	if err != nil || obs == nil {
+		return false, false, err
+	}
+
+	thresholdMinutes := st.StaleThresholdMinutes
+	if thresholdMinutes <= 0 {
+		thresholdMinutes = models.DefaultStaleThresholdMinutes
+	}
+	lastSeen := obs.ObservedAt.UTC()
+
+	if now.Sub(lastSeen) > time.Duration(thresholdMinutes)*time.Minute {
+		opened, err = s.openOutage(st.StationID, lastSeen, now.UTC())
+		return opened, false, err
+	}
+	closed, err = s.closeOutage(st.StationID)
+	return false, closed, err
+}
+
+func (s *Store) openOutage(stationID string, startedAt, detectedAt time.Time) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO station_outages (station_id, started_at, detected_at)
+		SELECT ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM station_outages WHERE station_id = ? AND ended_at IS NULL
+		)
+	`, stationID, startedAt, detectedAt, stationID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// closeOutage ends the station's open outage at its first observation after
+// the gap, not the latest one, which may be several polls later.
+func (s *Store) closeOutage(stationID string) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE station_outages SET ended_at = (
+			SELECT MIN(observed_at) FROM observations
+			WHERE station_id = station_outages.station_id AND observed_at > station_outages.started_at
+		)
+		WHERE station_id = ? AND ended_at IS NULL
+	`, stationID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetOpenOutages returns outages that haven't ended yet, oldest first.
+// Outages for inactive stations are skipped: they're no longer checked, so
+// they'd otherwise stay open until the station is reactivated.
+func (s *Store) GetOpenOutages() ([]StationOutage, error) {
+	rows, err := s.db.Query(`
+		SELECT o.id, o.station_id, o.started_at, o.detected_at, o.ended_at
+		FROM station_outages o
+		JOIN stations s ON s.station_id = o.station_id
+		WHERE o.ended_at IS NULL AND s.active = TRUE
+		ORDER BY o.started_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outages []StationOutage
+	for rows.Next() {
+		var o StationOutage
+		if err := rows.Scan(&o.ID, &o.StationID, &o.StartedAt, &o.DetectedAt, &o.EndedAt); err != nil {
+			return nil, err
+		}
+		outages = append(outages, o)
+	}
+	return outages, rows.Err()
+}
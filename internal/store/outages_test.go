@@ -0,0 +1,124 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestCheckStationOutage_OpenAndClose(t *testing.T) {
+	store := setupTestStore(t)
+
+	station := models.Station{StationID: "TEST001", Active: true, StaleThresholdMinutes: 30}
+	if err := store.UpsertStation(station); err != nil {
+		t.Fatalf("UpsertStation: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	lastSeen := now.Add(-45 * time.Minute)
+
+	if err := store.InsertObservation(models.Observation{
+		StationID:  station.StationID,
+		ObservedAt: lastSeen,
+		Temp:       sql.NullFloat64{Float64: 15, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+
+	opened, closed, err := store.CheckStationOutage(station, now)
+	if err != nil {
+		t.Fatalf("CheckStationOutage: %v", err)
+	}
+	if !opened || closed {
+		t.Errorf("opened, closed = %v, %v, want true, false", opened, closed)
+	}
+
+	// A second check while still stale must not open a duplicate
+	opened, _, err = store.CheckStationOutage(station, now.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("CheckStationOutage: %v", err)
+	}
+	if opened {
+		t.Error("opened a second outage while one was already open")
+	}
+
+	outages, err := store.GetOpenOutages()
+	if err != nil {
+		t.Fatalf("GetOpenOutages: %v", err)
+	}
+	if len(outages) != 1 {
+		t.Fatalf("len(outages) = %d, want 1", len(outages))
+	}
+	if outages[0].StationID != station.StationID {
+		t.Errorf("StationID = %q, want %q", outages[0].StationID, station.StationID)
+	}
+	if !outages[0].StartedAt.Equal(lastSeen) {
+		t.Errorf("StartedAt = %v, want %v", outages[0].StartedAt, lastSeen)
+	}
+	if !outages[0].DetectedAt.Equal(now) {
+		t.Errorf("DetectedAt = %v, want %v", outages[0].DetectedAt, now)
+	}
+
+	// Data resumes, and a further poll lands before the next check; the
+	// outage ends at the first of them
+	resumed := now.Add(10 * time.Minute)
+	for _, at := range []time.Time{resumed, resumed.Add(5 * time.Minute)} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:  station.StationID,
+			ObservedAt: at,
+			Temp:       sql.NullFloat64{Float64: 16, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	opened, closed, err = store.CheckStationOutage(station, resumed.Add(6*time.Minute))
+	if err != nil {
+		t.Fatalf("CheckStationOutage: %v", err)
+	}
+	if opened || !closed {
+		t.Errorf("opened, closed = %v, %v, want false, true", opened, closed)
+	}
+
+	outages, err = store.GetOpenOutages()
+	if err != nil {
+		t.Fatalf("GetOpenOutages: %v", err)
+	}
+	if len(outages) != 0 {
+		t.Errorf("len(outages) = %d, want 0 after data resumed", len(outages))
+	}
+
+	var endedAt time.Time
+	if err := store.db.QueryRow(`SELECT ended_at FROM station_outages WHERE station_id = ?`, station.StationID).Scan(&endedAt); err != nil {
+		t.Fatalf("query ended_at: %v", err)
+	}
+	if !endedAt.Equal(resumed) {
+		t.Errorf("ended_at = %v, want %v", endedAt, resumed)
+	}
+}
+
+func TestGetOpenOutages_SkipsInactiveStations(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, st := range []models.Station{
+		{StationID: "ACTIVE1", Active: true},
+		{StationID: "RETIRED1", Active: false},
+	} {
+		if err := store.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+		if _, err := store.openOutage(st.StationID, now.Add(-time.Hour), now); err != nil {
+			t.Fatalf("openOutage: %v", err)
+		}
+	}
+
+	outages, err := store.GetOpenOutages()
+	if err != nil {
+		t.Fatalf("GetOpenOutages: %v", err)
+	}
+	if len(outages) != 1 || outages[0].StationID != "ACTIVE1" {
+		t.Errorf("outages = %+v, want just ACTIVE1", outages)
+	}
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A station is a broken rain gauge suspect if it reports exactly zero while
+// its same-tier peers' median rainfall over the window is at least this (mm).
+const brokenGaugeMinPeerRain = 5.0
+
+// RollingRainfall holds rainfall totals (mm) over trailing windows ending now.
+type RollingRainfall struct {
+	Last24h      float64
+	Last7d       float64
+	Last30d      float64
+	SeasonToDate float64
+	SeasonStart  time.Time // local midnight on the first day of the season
+	SeasonName   string
+}
+
+// GetRollingRainfall totals rainfall for a station over the last 24 hours, 7
+// days and 30 days, and since the start of the current (southern hemisphere)
+// season. precip_total is a daily counter that resets at local midnight, so
+// each local day's rainfall is its highest total. A window that starts part
+// way through a day counts that day's rain after its start.
+func (s *Store) GetRollingRainfall(stationID string, now time.Time) (*RollingRainfall, error) {
+	now = now.In(s.loc)
+	seasonStart, seasonName := seasonStartFor(now)
+
+	result := &RollingRainfall{SeasonStart: seasonStart, SeasonName: seasonName}
+	windows := []struct {
+		start time.Time
+		total *float64
+	}{
+		{now.Add(-24 * time.Hour), &result.Last24h},
+		{now.AddDate(0, 0, -7), &result.Last7d},
+		{now.AddDate(0, 0, -30), &result.Last30d},
+		{seasonStart, &result.SeasonToDate},
+	}
+
+	earliest := now
+	for _, w := range windows {
+		if w.start.Before(earliest) {
+			earliest = w.start
+		}
+	}
+
+	daily, err := s.dailyPrecipMax(stationID, earliest, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range windows {
+		firstDay := time.Date(w.start.Year(), w.start.Month(), w.start.Day(), 0, 0, 0, 0, s.loc)
+		for day, total := range daily {
+			if day.After(firstDay) {
+				*w.total += total
+			}
+		}
+
+		// Rain on the first day up to the window's start belongs to an
+		// earlier window
+		var before sql.NullFloat64
+		if w.start.After(firstDay) {
+			if err := s.db.QueryRow(`
+				SELECT MAX(precip_total)
+				FROM observations
+				WHERE station_id = ? AND observed_at >= ? AND observed_at <= ?
+			`, stationID, firstDay.UTC(), w.start.UTC()).Scan(&before); err != nil {
+				return nil, err
+			}
+		}
+		*w.total += max(daily[firstDay]-before.Float64, 0)
+	}
+
+	return result, nil
+}
+
+// dailyPrecipMax returns a station's highest precip_total on each local day
+// from the day containing start up to end, keyed by local midnight. Days are
+// bounded in Go so they follow the configured time zone, including daylight
+// saving changes, and aggregated in SQL.
+func (s *Store) dailyPrecipMax(stationID string, start, end time.Time) (map[time.Time]float64, error) {
+	start, end = start.In(s.loc), end.In(s.loc)
+
+	var values []string
+	var args []any
+	var days []time.Time
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, s.loc); !day.After(end); day = day.AddDate(0, 0, 1) {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, len(days), day.UTC(), day.AddDate(0, 0, 1).UTC())
+		days = append(days, day)
+	}
+	args = append(args, stationID, end.UTC())
+
+	rows, err := s.db.Query(`
+		WITH days(idx, day_start, day_end) AS (VALUES `+strings.Join(values, ", ")+`)
+		SELECT d.idx, MAX(o.precip_total)
+		FROM days d
+		JOIN observations o ON o.observed_at >= d.day_start AND o.observed_at < d.day_end
+		WHERE o.station_id = ? AND o.observed_at <= ? AND o.precip_total IS NOT NULL
+		GROUP BY d.idx
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[time.Time]float64)
+	for rows.Next() {
+		var idx int
+		var total float64
+		if err := rows.Scan(&idx, &total); err != nil {
+			return nil, err
+		}
+		totals[days[idx]] = total
+	}
+	return totals, rows.Err()
+}
+
+// GetPrecipSpells returns the longest runs of consecutive dry and wet days in a
+// station's daily summaries from start to end inclusive. A wet day has at least
+// rainDayThreshold, so a gauge's 0.1mm of dew doesn't end a dry spell. A day
+// with no summary or no rain total breaks the run either side of it.
+func (s *Store) GetPrecipSpells(stationID string, start, end time.Time) (longestDryDays, longestWetDays int, err error) {
+	rows, err := s.db.Query(`
+		SELECT date, precip_total
+		FROM daily_summaries
+		WHERE station_id = ?
+		  AND SUBSTR(date, 1, 10) BETWEEN ? AND ?
+		  AND precip_total IS NOT NULL
+		ORDER BY date ASC
+	`, stationID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var prevDate time.Time
+	var run int
+	var prevWet bool
+	for rows.Next() {
+		var date time.Time
+		var precip float64
+		if err := rows.Scan(&date, &precip); err != nil {
+			return 0, 0, err
+		}
+		wet := precip >= rainDayThreshold
+		if run > 0 && wet == prevWet && prevDate.AddDate(0, 0, 1).Format("2006-01-02") == date.Format("2006-01-02") {
+			run++
+		} else {
+			run = 1
+		}
+		if wet {
+			longestWetDays = max(longestWetDays, run)
+		} else {
+			longestDryDays = max(longestDryDays, run)
+		}
+		prevDate, prevWet = date, wet
+	}
+	return longestDryDays, longestWetDays, rows.Err()
+}
+
+// DetectBrokenRainGauges returns active stations whose rain gauge looks stuck:
+// every reading over the window is exactly zero while same-tier stations
+// recorded significant rain. Stations with no gauge readings are ignored.
+func (s *Store) DetectBrokenRainGauges(window time.Duration) ([]string, error) {
+	stations, err := s.GetActiveStations()
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	start := end.Add(-window)
+
+	type gauge struct {
+		stationID string
+		total     float64
+		max       float64
+	}
+	byTier := make(map[string][]gauge)
+	for _, st := range stations {
+		readings, err := s.precipReadings(st.StationID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if len(readings) == 0 {
+			continue
+		}
+
+		g := gauge{stationID: st.StationID}
+		for i, r := range readings {
+			g.max = max(g.max, r.total)
+			if i == 0 {
+				continue
+			}
+			if prev := readings[i-1].total; r.total >= prev {
+				g.total += r.total - prev
+			} else {
+				g.total += r.total
+			}
+		}
+		tier := st.ElevationTier
+		if tier == "local" {
+			tier = "valley_floor"
+		}
+		byTier[tier] = append(byTier[tier], g)
+	}
+
+	var suspects []string
+	for _, gauges := range byTier {
+		for _, g := range gauges {
+			if g.max != 0 {
+				continue
+			}
+			var peerTotals []float64
+			for _, peer := range gauges {
+				if peer.stationID != g.stationID {
+					peerTotals = append(peerTotals, peer.total)
+				}
+			}
+			if len(peerTotals) > 0 && medianOf(peerTotals) >= brokenGaugeMinPeerRain {
+				suspects = append(suspects, g.stationID)
+			}
+		}
+	}
+	sort.Strings(suspects)
+	return suspects, nil
+}
+
+type precipReading struct {
+	at    time.Time
+	total float64
+}
+
+// precipReadings returns a station's precip_total readings between start and
+// end, oldest first.
+func (s *Store) precipReadings(stationID string, start, end time.Time) ([]precipReading, error) {
+	rows, err := s.db.Query(`
+		SELECT observed_at, precip_total
+		FROM observations
+		WHERE station_id = ? AND observed_at >= ? AND observed_at <= ? AND precip_total IS NOT NULL
+		ORDER BY observed_at ASC
+	`, stationID, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []precipReading
+	for rows.Next() {
+		var r precipReading
+		if err := rows.Scan(&r.at, &r.total); err != nil {
+			return nil, err
+		}
+		readings = append(readings, r)
+	}
+	return readings, rows.Err()
+}
+
+// seasonStartFor returns the start of the meteorological season containing t
+// (summer from December, autumn from March, winter from June, spring from
+// September) in t's location.
+func seasonStartFor(t time.Time) (time.Time, string) {
+	year := t.Year()
+	var month time.Month
+	var name string
+	switch t.Month() {
+	case time.December:
+		month, name = time.December, "Summer"
+	case time.January, time.February:
+		month, name = time.December, "Summer"
+		year--
+	case time.March, time.April, time.May:
+		month, name = time.March, "Autumn"
+	case time.June, time.July, time.August:
+		month, name = time.June, "Winter"
+	default:
+		month, name = time.September, "Spring"
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, t.Location()), name
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestGetRollingRainfall(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Date(2026, 7, 20, 12, 0, 0, 0, store.loc)
+
+	// Daily counter readings: each day accumulates then resets at midnight.
+	// Days are relative to now; the 10-days-ago rain is outside the 7d window.
+	days := []struct {
+		daysAgo int
+		totals  []float64 // readings at 06:00, 12:00, 18:00
+	}{
+		{10, []float64{0, 4, 9}},
+		{6, []float64{1, 3, 3}},
+		{3, []float64{0, 0, 2.5}},
+		{1, []float64{0.5, 1.5, 6}},
+		{0, []float64{0, 2}}, // 06:00 and 12:00 today
+	}
+	for _, d := range days {
+		day := time.Date(now.Year(), now.Month(), now.Day()-d.daysAgo, 0, 0, 0, 0, store.loc)
+		for i, total := range d.totals {
+			at := day.Add(time.Duration(6*(i+1)) * time.Hour)
+			if err := store.InsertObservation(models.Observation{
+				StationID:   "TEST1",
+				ObservedAt:  at.UTC(),
+				PrecipTotal: sql.NullFloat64{Float64: total, Valid: true},
+			}); err != nil {
+				t.Fatalf("InsertObservation: %v", err)
+			}
+		}
+	}
+
+	got, err := store.GetRollingRainfall("TEST1", now)
+	if err != nil {
+		t.Fatalf("GetRollingRainfall: %v", err)
+	}
+
+	// 7d window starts 12:00 seven days ago, a day with no readings, so every
+	// later day's highest total counts: 3 + 2.5 + 6 + 2
+	if want := 13.5; got.Last7d != want {
+		t.Errorf("Last7d = %.1f, want %.1f", got.Last7d, want)
+	}
+	// 24h: yesterday's rain after its 12:00 total of 1.5, then today's: 4.5 + 2
+	if want := 6.5; got.Last24h != want {
+		t.Errorf("Last24h = %.1f, want %.1f", got.Last24h, want)
+	}
+	// 30d: every day's highest total counts: 9 + 3 + 2.5 + 6 + 2
+	if want := 22.5; got.Last30d != want {
+		t.Errorf("Last30d = %.1f, want %.1f", got.Last30d, want)
+	}
+	if got.SeasonName != "Winter" || !got.SeasonStart.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, store.loc)) {
+		t.Errorf("season = %s from %v, want Winter from 2026-06-01", got.SeasonName, got.SeasonStart)
+	}
+	if got.SeasonToDate != got.Last30d {
+		t.Errorf("SeasonToDate = %.1f, want %.1f", got.SeasonToDate, got.Last30d)
+	}
+}
+
+func TestGetRollingRainfall_ResetAfterGap(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Date(2026, 7, 20, 12, 0, 0, 0, store.loc)
+
+	// The station goes quiet after 2mm two days ago and next reports 3mm this
+	// morning. The counter reset at midnight even though 3 is above 2.
+	for _, r := range []struct {
+		at    time.Time
+		total float64
+	}{
+		{time.Date(2026, 7, 18, 18, 0, 0, 0, store.loc), 2},
+		{time.Date(2026, 7, 20, 9, 0, 0, 0, store.loc), 3},
+	} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:   "TEST1",
+			ObservedAt:  r.at.UTC(),
+			PrecipTotal: sql.NullFloat64{Float64: r.total, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	got, err := store.GetRollingRainfall("TEST1", now)
+	if err != nil {
+		t.Fatalf("GetRollingRainfall: %v", err)
+	}
+	if want := 5.0; got.Last7d != want {
+		t.Errorf("Last7d = %.1f, want %.1f", got.Last7d, want)
+	}
+	if want := 3.0; got.Last24h != want {
+		t.Errorf("Last24h = %.1f, want %.1f", got.Last24h, want)
+	}
+}
+
+func TestGetPrecipSpells(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Daily totals from 1 March; -1 leaves the total unset. The dry days
+	// either side of the gap would make a run of 5 if it didn't break it,
+	// and 0.1mm is below the 0.2mm rain day threshold.
+	precip := []float64{
+		0, 0.1, 0, // dry x3
+		1.2,    // wet x1
+		0,      // dry x1
+		0.4, 5, // wet x2
+		0, 0, 0, 0, // dry x4
+		2, 3, 0.2, // wet x3
+		0, 0, // dry x2
+		-1,      // missing total breaks the run
+		0, 0, 0, // dry x3
+	}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, p := range precip {
+		ds := models.DailySummary{Date: start.AddDate(0, 0, i), StationID: "TEST1"}
+		if p >= 0 {
+			ds.PrecipTotal = sql.NullFloat64{Float64: p, Valid: true}
+		}
+		if err := store.UpsertDailySummary(ds); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+	// A long dry run at another station must not leak in.
+	for i := range 10 {
+		if err := store.UpsertDailySummary(models.DailySummary{
+			Date:        start.AddDate(0, 0, i),
+			StationID:   "OTHER",
+			PrecipTotal: sql.NullFloat64{Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+
+	end := start.AddDate(0, 0, len(precip)-1)
+	dry, wet, err := store.GetPrecipSpells("TEST1", start, end)
+	if err != nil {
+		t.Fatalf("GetPrecipSpells: %v", err)
+	}
+	if dry != 4 || wet != 3 {
+		t.Errorf("GetPrecipSpells = dry %d, wet %d, want dry 4, wet 3", dry, wet)
+	}
+
+	// Trimming the window to the first week leaves runs of 3 dry and 2 wet.
+	dry, wet, err = store.GetPrecipSpells("TEST1", start, start.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatalf("GetPrecipSpells: %v", err)
+	}
+	if dry != 3 || wet != 2 {
+		t.Errorf("GetPrecipSpells(first week) = dry %d, wet %d, want dry 3, wet 2", dry, wet)
+	}
+}
+
+func TestSeasonStartFor(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		date time.Time
+		want time.Time
+		name string
+	}{
+		{time.Date(2026, 1, 15, 0, 0, 0, 0, loc), time.Date(2025, 12, 1, 0, 0, 0, 0, loc), "Summer"},
+		{time.Date(2026, 12, 2, 0, 0, 0, 0, loc), time.Date(2026, 12, 1, 0, 0, 0, 0, loc), "Summer"},
+		{time.Date(2026, 4, 30, 0, 0, 0, 0, loc), time.Date(2026, 3, 1, 0, 0, 0, 0, loc), "Autumn"},
+		{time.Date(2026, 10, 16, 0, 0, 0, 0, loc), time.Date(2026, 9, 1, 0, 0, 0, 0, loc), "Spring"},
+	}
+	for _, tt := range tests {
+		got, name := seasonStartFor(tt.date)
+		if !got.Equal(tt.want) || name != tt.name {
+			t.Errorf("seasonStartFor(%s) = %v %s, want %v %s", tt.date.Format("2006-01-02"), got, name, tt.want, tt.name)
+		}
+	}
+}
+
+func TestDetectBrokenRainGauges(t *testing.T) {
+	store := setupTestStore(t)
+
+	stations := []models.Station{
+		{StationID: "VALLEY1", ElevationTier: "valley_floor", Active: true},
+		{StationID: "VALLEY2", ElevationTier: "local", Active: true},
+		{StationID: "STUCK", ElevationTier: "valley_floor", Active: true},
+		{StationID: "UPPER1", ElevationTier: "upper", Active: true},
+	}
+	for _, st := range stations {
+		if err := store.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	// Rain over the last few hours: the valley peers record 8mm and 12mm,
+	// STUCK stays flat at zero, and the dry upper station has no peers.
+	now := time.Now().UTC().Truncate(time.Minute)
+	totals := map[string][]float64{
+		"VALLEY1": {0, 3, 8},
+		"VALLEY2": {0, 5, 12},
+		"STUCK":   {0, 0, 0},
+		"UPPER1":  {0, 0, 0},
+	}
+	for stationID, readings := range totals {
+		for i, total := range readings {
+			if err := store.InsertObservation(models.Observation{
+				StationID:   stationID,
+				ObservedAt:  now.Add(time.Duration(i-len(readings)) * time.Hour),
+				PrecipTotal: sql.NullFloat64{Float64: total, Valid: true},
+			}); err != nil {
+				t.Fatalf("InsertObservation: %v", err)
+			}
+		}
+	}
+
+	suspects, err := store.DetectBrokenRainGauges(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("DetectBrokenRainGauges: %v", err)
+	}
+	if len(suspects) != 1 || suspects[0] != "STUCK" {
+		t.Errorf("suspects = %v, want [STUCK]", suspects)
+	}
+}
@@ -23,6 +23,7 @@ type RawPayload struct {
 	PayloadCompressed []byte
 	PayloadHash       string
 	SchemaVersion     int
+	SizeBytes         int64 // compressed size, set by ListRawPayloads
 }
 
 // StoreRawPayload stores a compressed API response payload.
@@ -78,14 +79,68 @@ func (s *Store) StoreRawPayload(runID *int64, source, endpoint string,
 
 // GetRawPayload retrieves and decompresses a stored payload by ID.
 func (s *Store) GetRawPayload(id int64) ([]byte, error) {
-	var compressed []byte
+	var p RawPayload
 	err := s.db.QueryRow(`SELECT payload_compressed FROM raw_payloads WHERE id = ?`, id).
-		Scan(&compressed)
+		Scan(&p.PayloadCompressed)
+	if err != nil {
+		return nil, err
+	}
+	return p.Decompress()
+}
+
+// GetRawPayloadByID retrieves a stored payload and its metadata by ID, or nil
+// if there is no such payload.
+func (s *Store) GetRawPayloadByID(id int64) (*RawPayload, error) {
+	row := s.db.QueryRow(`
+		SELECT id, ingest_run_id, fetched_at, source, endpoint, station_id, location_id,
+		       payload_compressed, payload_hash, schema_version
+		FROM raw_payloads WHERE id = ?
+	`, id)
+
+	var p RawPayload
+	err := row.Scan(&p.ID, &p.IngestRunID, &p.FetchedAt, &p.Source, &p.Endpoint,
+		&p.StationID, &p.LocationID, &p.PayloadCompressed, &p.PayloadHash, &p.SchemaVersion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListRawPayloads returns metadata for the most recently fetched payloads,
+// newest first, optionally limited to one source. PayloadCompressed is left
+// empty; SizeBytes holds its length.
+func (s *Store) ListRawPayloads(source string, limit int) ([]RawPayload, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ingest_run_id, fetched_at, source, endpoint, station_id, location_id,
+		       LENGTH(payload_compressed), payload_hash, schema_version
+		FROM raw_payloads
+		WHERE ? = '' OR source = ?
+		ORDER BY fetched_at DESC, id DESC
+		LIMIT ?
+	`, source, source, limit)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var payloads []RawPayload
+	for rows.Next() {
+		var p RawPayload
+		if err := rows.Scan(&p.ID, &p.IngestRunID, &p.FetchedAt, &p.Source, &p.Endpoint,
+			&p.StationID, &p.LocationID, &p.SizeBytes, &p.PayloadHash, &p.SchemaVersion); err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, p)
+	}
+	return payloads, rows.Err()
+}
 
-	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+// Decompress returns the original payload body.
+func (p *RawPayload) Decompress() ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(p.PayloadCompressed))
 	if err != nil {
 		return nil, fmt.Errorf("create gzip reader: %w", err)
 	}
@@ -136,15 +191,24 @@ func (s *Store) GetRawPayloadStats() (*RawPayloadStats, error) {
 		       MIN(fetched_at), MAX(fetched_at)
 		FROM raw_payloads
 	`)
-	var oldest, newest sql.NullTime
+	// MIN() and MAX() lose the column type, so the timestamps come back as text
+	var oldest, newest sql.NullString
 	if err := row.Scan(&stats.TotalCount, &stats.TotalSizeBytes, &oldest, &newest); err != nil {
 		return nil, err
 	}
 	if oldest.Valid {
-		stats.OldestFetchedAt = oldest.Time
+		t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", oldest.String)
+		if err != nil {
+			return nil, err
+		}
+		stats.OldestFetchedAt = t
 	}
 	if newest.Valid {
-		stats.NewestFetchedAt = newest.Time
+		t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", newest.String)
+		if err != nil {
+			return nil, err
+		}
+		stats.NewestFetchedAt = t
 	}
 
 	rows, err := s.db.Query(`
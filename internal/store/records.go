@@ -0,0 +1,156 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Record kinds returned by CheckRecordsBroken.
+const (
+	RecordAllTimeHigh = "all_time_high"
+	RecordAllTimeLow  = "all_time_low"
+	RecordMonthlyHigh = "monthly_high"
+	RecordMonthlyLow  = "monthly_low"
+)
+
+// minRecordHistoryDays is how many prior daily summaries are needed before a
+// record is meaningful. Without it every day of a new station is a record.
+const minRecordHistoryDays = 30
+
+// RecordBroken describes a temperature record beaten by today's observations.
+type RecordBroken struct {
+	Kind       string    // one of the Record* constants
+	Value      float64   // today's observed value
+	Previous   float64   // the record being beaten
+	PrevDate   time.Time // when the previous record was set
+	HistoryLen int       // number of days the record was drawn from
+}
+
+// Label returns a human-readable description of the record.
+func (r RecordBroken) Label() string {
+	switch r.Kind {
+	case RecordAllTimeHigh:
+		return "All-time high"
+	case RecordAllTimeLow:
+		return "All-time low"
+	case RecordMonthlyHigh:
+		return "Monthly high"
+	case RecordMonthlyLow:
+		return "Monthly low"
+	default:
+		return r.Kind
+	}
+}
+
+// IsHigh reports whether the record is a maximum temperature record.
+func (r RecordBroken) IsHigh() bool {
+	return r.Kind == RecordAllTimeHigh || r.Kind == RecordMonthlyHigh
+}
+
+// CheckRecordsBroken compares the observed max/min for localDate against prior
+// daily summaries for the station. Only clean observations count, so a spike
+// QC has flagged can't set a record. An all-time record supersedes the monthly
+// record for the same extreme, so at most one high and one low are returned.
+func (s *Store) CheckRecordsBroken(stationID string, localDate time.Time) ([]RecordBroken, error) {
+	dayStart := time.Date(localDate.Year(), localDate.Month(), localDate.Day(), 0, 0, 0, 0, s.loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	var todayMax, todayMin sql.NullFloat64
+	err := s.db.QueryRow(`
+		SELECT MAX(temp), MIN(temp)
+		FROM observations
+		WHERE station_id = ? AND observed_at >= ? AND observed_at < ? AND temp IS NOT NULL
+		  AND qc_status IN (0, 1)
+		  AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
+	`, stationID, dayStart.UTC(), dayEnd.UTC()).Scan(&todayMax, &todayMin)
+	if err != nil {
+		return nil, err
+	}
+	if !todayMax.Valid && !todayMin.Valid {
+		return nil, nil
+	}
+
+	dateStr := dayStart.Format("2006-01-02")
+	monthStr := dayStart.Format("01")
+
+	var records []RecordBroken
+	if todayMax.Valid {
+		r, err := s.checkRecord(stationID, dateStr, "", "temp_max", true, todayMax.Float64, RecordAllTimeHigh)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r, err = s.checkRecord(stationID, dateStr, monthStr, "temp_max", true, todayMax.Float64, RecordMonthlyHigh)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if r != nil {
+			records = append(records, *r)
+		}
+	}
+	if todayMin.Valid {
+		r, err := s.checkRecord(stationID, dateStr, "", "temp_min", false, todayMin.Float64, RecordAllTimeLow)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r, err = s.checkRecord(stationID, dateStr, monthStr, "temp_min", false, todayMin.Float64, RecordMonthlyLow)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if r != nil {
+			records = append(records, *r)
+		}
+	}
+	return records, nil
+}
+
+// checkRecord returns a RecordBroken if value beats the historical extreme of
+// column before dateStr, optionally restricted to the given two-digit month.
+func (s *Store) checkRecord(stationID, dateStr, month, column string, high bool, value float64, kind string) (*RecordBroken, error) {
+	order := "ASC"
+	if high {
+		order = "DESC"
+	}
+
+	query := `
+		SELECT ` + column + `, date, COUNT(*) OVER ()
+		FROM daily_summaries
+		WHERE station_id = ? AND SUBSTR(date, 1, 10) < ? AND ` + column + ` IS NOT NULL`
+	args := []any{stationID, dateStr}
+	if month != "" {
+		query += ` AND SUBSTR(date, 6, 2) = ?`
+		args = append(args, month)
+	}
+	query += ` ORDER BY ` + column + ` ` + order + ` LIMIT 1`
+
+	var prev float64
+	var prevDate string
+	var historyLen int
+	err := s.db.QueryRow(query, args...).Scan(&prev, &prevDate, &historyLen)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if historyLen < minRecordHistoryDays {
+		return nil, nil
+	}
+	if (high && value <= prev) || (!high && value >= prev) {
+		return nil, nil
+	}
+
+	r := &RecordBroken{
+		Kind:       kind,
+		Value:      value,
+		Previous:   prev,
+		HistoryLen: historyLen,
+	}
+	if len(prevDate) >= 10 {
+		r.PrevDate, _ = time.Parse("2006-01-02", prevDate[:10])
+	}
+	return r, nil
+}
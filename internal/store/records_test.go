@@ -0,0 +1,108 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lox/wandiweather/internal/models"
+)
+
+func TestCheckRecordsBroken_AllTimeHigh(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "TEST001", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Date(2026, 1, 15, 0, 0, 0, 0, store.loc)
+
+	// 40 days of history with a prior all-time max of 35°C and min of 5°C
+	for i := 1; i <= 40; i++ {
+		date := time.Date(2026, 1, 15-i, 0, 0, 0, 0, time.UTC)
+		tempMax := 25.0
+		if i == 10 {
+			tempMax = 35.0
+		}
+		if err := store.UpsertDailySummary(models.DailySummary{
+			Date:      date,
+			StationID: "TEST001",
+			TempMax:   sql.NullFloat64{Float64: tempMax, Valid: true},
+			TempMin:   sql.NullFloat64{Float64: 5, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+
+	for _, obs := range []struct {
+		hour int
+		temp float64
+	}{{6, 15}, {15, 36.2}} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:  "TEST001",
+			ObservedAt: today.Add(time.Duration(obs.hour) * time.Hour).UTC(),
+			Temp:       sql.NullFloat64{Float64: obs.temp, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	// A sensor glitch QC flagged mustn't become the record
+	if err := store.InsertObservation(models.Observation{
+		StationID:    "TEST001",
+		ObservedAt:   today.Add(16 * time.Hour).UTC(),
+		Temp:         sql.NullFloat64{Float64: 60, Valid: true},
+		QualityFlags: sql.NullString{String: `["temp_out_of_range"]`, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+
+	records, err := store.CheckRecordsBroken("TEST001", today)
+	if err != nil {
+		t.Fatalf("CheckRecordsBroken: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("len(records) = %d, want 1: %+v", len(records), records)
+	}
+	r := records[0]
+	if r.Kind != RecordAllTimeHigh {
+		t.Errorf("Kind = %q, want %q", r.Kind, RecordAllTimeHigh)
+	}
+	if r.Value != 36.2 {
+		t.Errorf("Value = %v, want 36.2", r.Value)
+	}
+	if r.Previous != 35.0 {
+		t.Errorf("Previous = %v, want 35.0", r.Previous)
+	}
+	if got := r.PrevDate.Format("2006-01-02"); got != "2026-01-05" {
+		t.Errorf("PrevDate = %s, want 2026-01-05", got)
+	}
+}
+
+func TestCheckRecordsBroken_InsufficientHistory(t *testing.T) {
+	store := setupTestStore(t)
+
+	today := time.Date(2026, 1, 15, 0, 0, 0, 0, store.loc)
+	if err := store.UpsertDailySummary(models.DailySummary{
+		Date:      time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC),
+		StationID: "TEST001",
+		TempMax:   sql.NullFloat64{Float64: 20, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.InsertObservation(models.Observation{
+		StationID:  "TEST001",
+		ObservedAt: today.Add(14 * time.Hour).UTC(),
+		Temp:       sql.NullFloat64{Float64: 30, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.CheckRecordsBroken("TEST001", today)
+	if err != nil {
+		t.Fatalf("CheckRecordsBroken: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("len(records) = %d, want 0 with one day of history", len(records))
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lox/wandiweather/internal/models"
@@ -17,10 +18,23 @@ func New(db *sql.DB, loc *time.Location) *Store {
 	return &Store{db: db, loc: loc}
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 func (s *Store) UpsertStation(st models.Station) error {
-	_, err := s.db.Exec(`
-		INSERT INTO stations (station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	return upsertStation(s.db, st)
+}
+
+func upsertStation(db execer, st models.Station) error {
+	staleThreshold := st.StaleThresholdMinutes
+	if staleThreshold <= 0 {
+		staleThreshold = models.DefaultStaleThresholdMinutes
+	}
+	_, err := db.Exec(`
+		INSERT INTO stations (station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(station_id) DO UPDATE SET
 			name = excluded.name,
 			latitude = excluded.latitude,
@@ -28,13 +42,50 @@ func (s *Store) UpsertStation(st models.Station) error {
 			elevation = excluded.elevation,
 			elevation_tier = excluded.elevation_tier,
 			is_primary = excluded.is_primary,
-			active = excluded.active
-	`, st.StationID, st.Name, st.Latitude, st.Longitude, st.Elevation, st.ElevationTier, st.IsPrimary, st.Active)
+			active = excluded.active,
+			stale_threshold_minutes = excluded.stale_threshold_minutes
+	`, st.StationID, st.Name, st.Latitude, st.Longitude, st.Elevation, st.ElevationTier, st.IsPrimary, st.Active, staleThreshold)
 	return err
 }
 
+// SeedStations makes the stations table match the configured stations in one
+// transaction: each is upserted, any station no longer configured is
+// deactivated, and the configured primary becomes the only primary.
+func (s *Store) SeedStations(stations []models.Station) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ids := make([]any, len(stations))
+	for i, st := range stations {
+		if err := upsertStation(tx, st); err != nil {
+			return fmt.Errorf("upsert station %s: %w", st.StationID, err)
+		}
+		ids[i] = st.StationID
+	}
+
+	query := `UPDATE stations SET active = FALSE, is_primary = FALSE`
+	if len(ids) > 0 {
+		query += ` WHERE station_id NOT IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+	}
+	if _, err := tx.Exec(query, ids...); err != nil {
+		return fmt.Errorf("deactivate unconfigured stations: %w", err)
+	}
+
+	for _, st := range stations {
+		if st.IsPrimary {
+			if err := setPrimaryStation(tx, st.StationID); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
 func (s *Store) GetActiveStations() ([]models.Station, error) {
-	rows, err := s.db.Query(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active FROM stations WHERE active = TRUE`)
+	rows, err := s.db.Query(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes FROM stations WHERE active = TRUE`)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +94,7 @@ func (s *Store) GetActiveStations() ([]models.Station, error) {
 	var stations []models.Station
 	for rows.Next() {
 		var st models.Station
-		if err := rows.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active); err != nil {
+		if err := rows.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active, &st.StaleThresholdMinutes); err != nil {
 			return nil, err
 		}
 		stations = append(stations, st)
@@ -51,6 +102,71 @@ func (s *Store) GetActiveStations() ([]models.Station, error) {
 	return stations, rows.Err()
 }
 
+// GetAllStations returns every station, active or not, ordered by ID.
+func (s *Store) GetAllStations() ([]models.Station, error) {
+	rows, err := s.db.Query(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes FROM stations ORDER BY station_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []models.Station
+	for rows.Next() {
+		var st models.Station
+		if err := rows.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active, &st.StaleThresholdMinutes); err != nil {
+			return nil, err
+		}
+		stations = append(stations, st)
+	}
+	return stations, rows.Err()
+}
+
+// CountObservedHours returns how many distinct clock hours since the given
+// time have at least one observation from the station.
+func (s *Store) CountObservedHours(stationID string, since time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(DISTINCT SUBSTR(observed_at, 1, 13))
+		FROM observations
+		WHERE station_id = ? AND observed_at >= ?
+	`, stationID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+// GetFieldCompleteness returns, for each sensor field (temp, humidity,
+// pressure, wind, solar, precip), the fraction of the station's observations
+// within window that have it, 0-1. A field at 0 while others aren't usually
+// means the station has no such sensor. Every field is 0 with no observations.
+func (s *Store) GetFieldCompleteness(stationID string, window time.Duration) (map[string]float64, error) {
+	var total, temp, humidity, pressure, wind, solar, precip int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COUNT(temp), COUNT(humidity), COUNT(pressure),
+		       COUNT(wind_speed), COUNT(solar_radiation), COUNT(precip_total)
+		FROM observations
+		WHERE station_id = ? AND observed_at >= ?
+	`, stationID, time.Now().Add(-window).UTC()).Scan(&total, &temp, &humidity, &pressure, &wind, &solar, &precip)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{
+		"temp":     temp,
+		"humidity": humidity,
+		"pressure": pressure,
+		"wind":     wind,
+		"solar":    solar,
+		"precip":   precip,
+	}
+	completeness := make(map[string]float64, len(counts))
+	for field, n := range counts {
+		completeness[field] = 0
+		if total > 0 {
+			completeness[field] = float64(n) / float64(total)
+		}
+	}
+	return completeness, nil
+}
+
 func (s *Store) InsertObservation(obs models.Observation) error {
 	obsType := obs.ObsType
 	if obsType == "" {
@@ -64,11 +180,52 @@ func (s *Store) InsertObservation(obs models.Observation) error {
 	return err
 }
 
-func (s *Store) GetLatestObservation(stationID string) (*models.Observation, error) {
+// futureTimestampFlag is the quality flag ingest sets on observations stamped
+// ahead of when they were fetched (ingest.FlagFutureTimestamp).
+const futureTimestampFlag = "future_timestamp"
+
+// GetLatestObservation returns the station's most recent observation, or nil
+// if it has none. With excludeFuture set, observations flagged as coming from
+// a clock running ahead are skipped so they can't pin the latest reading.
+func (s *Store) GetLatestObservation(stationID string, excludeFuture bool) (*models.Observation, error) {
+	query := `
+		SELECT id, station_id, observed_at, temp, humidity, dewpoint, pressure, wind_speed, wind_gust, wind_dir, precip_rate, precip_total, solar_radiation, uv, heat_index, wind_chill, qc_status, raw_json, created_at, obs_type, aggregation_period_minutes, quality_flags
+		FROM observations
+		WHERE station_id = ?`
+	args := []any{stationID}
+	if excludeFuture {
+		query += ` AND (quality_flags IS NULL OR quality_flags NOT LIKE ?)`
+		args = append(args, `%"`+futureTimestampFlag+`"%`)
+	}
+	query += `
+		ORDER BY observed_at DESC
+		LIMIT 1`
+	row := s.db.QueryRow(query, args...)
+
+	var obs models.Observation
+	var obsType sql.NullString
+	err := row.Scan(&obs.ID, &obs.StationID, &obs.ObservedAt, &obs.Temp, &obs.Humidity, &obs.Dewpoint, &obs.Pressure, &obs.WindSpeed, &obs.WindGust, &obs.WindDir, &obs.PrecipRate, &obs.PrecipTotal, &obs.SolarRadiation, &obs.UV, &obs.HeatIndex, &obs.WindChill, &obs.QCStatus, &obs.RawJSON, &obs.CreatedAt, &obsType, &obs.AggregationPeriod, &obs.QualityFlags)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	obs.ObsType = obsType.String
+	return &obs, nil
+}
+
+// GetLatestCleanObservation returns the station's most recent observation that
+// passed QC (status 0 or 1, no quality flags), or nil if there is none. It's
+// what the dashboard displays, so a flagged spike falls back to the last good
+// reading; staleness checks should still use GetLatestObservation.
+func (s *Store) GetLatestCleanObservation(stationID string) (*models.Observation, error) {
 	row := s.db.QueryRow(`
 		SELECT id, station_id, observed_at, temp, humidity, dewpoint, pressure, wind_speed, wind_gust, wind_dir, precip_rate, precip_total, solar_radiation, uv, heat_index, wind_chill, qc_status, raw_json, created_at, obs_type, aggregation_period_minutes, quality_flags
 		FROM observations
 		WHERE station_id = ?
+		  AND qc_status IN (0, 1)
+		  AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
 		ORDER BY observed_at DESC
 		LIMIT 1
 	`, stationID)
@@ -111,6 +268,162 @@ func (s *Store) GetObservations(stationID string, start, end time.Time) ([]model
 	return observations, rows.Err()
 }
 
+// GetDayObservations returns a station's observations on date's calendar day
+// in the store's time zone, up to asOf if that's earlier, ordered by time.
+func (s *Store) GetDayObservations(stationID string, date, asOf time.Time) ([]models.Observation, error) {
+	local := date.In(s.loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc)
+	dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if asOf.Before(dayEnd) {
+		dayEnd = asOf
+	}
+	return s.GetObservations(stationID, dayStart.UTC(), dayEnd.UTC())
+}
+
+// GetObservationsPage returns up to limit observations in [start, end] ordered by
+// time, skipping the first offset rows.
+func (s *Store) GetObservationsPage(stationID string, start, end time.Time, limit, offset int) ([]models.Observation, error) {
+	rows, err := s.db.Query(`
+		SELECT id, station_id, observed_at, temp, humidity, dewpoint, pressure, wind_speed, wind_gust, wind_dir, precip_rate, precip_total, solar_radiation, uv, heat_index, wind_chill, qc_status, raw_json, created_at, obs_type, aggregation_period_minutes, quality_flags
+		FROM observations
+		WHERE station_id = ? AND observed_at >= ? AND observed_at <= ?
+		ORDER BY observed_at ASC
+		LIMIT ? OFFSET ?
+	`, stationID, start, end, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var observations []models.Observation
+	for rows.Next() {
+		var obs models.Observation
+		var obsType sql.NullString
+		if err := rows.Scan(&obs.ID, &obs.StationID, &obs.ObservedAt, &obs.Temp, &obs.Humidity, &obs.Dewpoint, &obs.Pressure, &obs.WindSpeed, &obs.WindGust, &obs.WindDir, &obs.PrecipRate, &obs.PrecipTotal, &obs.SolarRadiation, &obs.UV, &obs.HeatIndex, &obs.WindChill, &obs.QCStatus, &obs.RawJSON, &obs.CreatedAt, &obsType, &obs.AggregationPeriod, &obs.QualityFlags); err != nil {
+			return nil, err
+		}
+		obs.ObsType = obsType.String
+		observations = append(observations, obs)
+	}
+	return observations, rows.Err()
+}
+
+// GetObservationsAfterID returns up to limit of a station's observations with
+// an id greater than afterID, oldest first. Ids only ever increase, so the last
+// id returned is a cursor for fetching newer rows.
+func (s *Store) GetObservationsAfterID(stationID string, afterID int64, limit int) ([]models.Observation, error) {
+	return s.queryObservations(`WHERE station_id = ? AND id > ? ORDER BY id ASC LIMIT ?`, stationID, afterID, limit)
+}
+
+// GetObservationsAfterTime returns up to limit of a station's observations
+// observed after the given time, in id order, for clients without a cursor yet.
+func (s *Store) GetObservationsAfterTime(stationID string, after time.Time, limit int) ([]models.Observation, error) {
+	return s.queryObservations(`WHERE station_id = ? AND observed_at > ? ORDER BY id ASC LIMIT ?`, stationID, after, limit)
+}
+
+func (s *Store) queryObservations(where string, args ...any) ([]models.Observation, error) {
+	rows, err := s.db.Query(`
+		SELECT id, station_id, observed_at, temp, humidity, dewpoint, pressure, wind_speed, wind_gust, wind_dir, precip_rate, precip_total, solar_radiation, uv, heat_index, wind_chill, qc_status, raw_json, created_at, obs_type, aggregation_period_minutes, quality_flags
+		FROM observations
+		`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var observations []models.Observation
+	for rows.Next() {
+		var obs models.Observation
+		var obsType sql.NullString
+		if err := rows.Scan(&obs.ID, &obs.StationID, &obs.ObservedAt, &obs.Temp, &obs.Humidity, &obs.Dewpoint, &obs.Pressure, &obs.WindSpeed, &obs.WindGust, &obs.WindDir, &obs.PrecipRate, &obs.PrecipTotal, &obs.SolarRadiation, &obs.UV, &obs.HeatIndex, &obs.WindChill, &obs.QCStatus, &obs.RawJSON, &obs.CreatedAt, &obsType, &obs.AggregationPeriod, &obs.QualityFlags); err != nil {
+			return nil, err
+		}
+		obs.ObsType = obsType.String
+		observations = append(observations, obs)
+	}
+	return observations, rows.Err()
+}
+
+// ObservationBucket summarises a station's observations over one time bucket.
+type ObservationBucket struct {
+	Start       time.Time
+	Samples     int
+	TempAvg     sql.NullFloat64
+	HumidityAvg sql.NullFloat64
+	PressureAvg sql.NullFloat64
+	WindGustMax sql.NullFloat64
+	Precip      sql.NullFloat64 // rain that fell during the bucket
+}
+
+// GetObservationBuckets aggregates a station's observations between start and
+// end into buckets of the given width, aligned to the Unix epoch. precip_total
+// is a running daily total, so each bucket's rainfall is the sum of the
+// increases since each reading's predecessor, which may be the last reading
+// before start on the same local day. A drop is the daily reset, after which
+// the new total is all fresh rain. Totals from QC-flagged readings are ignored.
+func (s *Store) GetObservationBuckets(stationID string, start, end time.Time, bucket time.Duration) ([]ObservationBucket, error) {
+	width := int64(bucket / time.Second)
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid bucket width %v", bucket)
+	}
+
+	local := start.In(s.loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc).UTC()
+
+	rows, err := s.db.Query(`
+		WITH readings AS (
+			SELECT
+				observed_at, temp, humidity, pressure, wind_gust,
+				CASE WHEN qc_status IN (0, 1) AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
+					THEN precip_total END as precip_total
+			FROM observations
+			WHERE station_id = ? AND observed_at <= ? AND observed_at >= COALESCE((
+				SELECT MAX(observed_at) FROM observations
+				WHERE station_id = ? AND observed_at >= ? AND observed_at < ? AND precip_total IS NOT NULL
+				  AND qc_status IN (0, 1)
+				  AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
+			), ?)
+		),
+		steps AS (
+			SELECT
+				*,
+				-- Partitioning on nullness makes LAG skip readings without a total
+				LAG(precip_total) OVER (PARTITION BY precip_total IS NULL ORDER BY observed_at) as prev_precip
+			FROM readings
+		)
+		SELECT
+			(CAST(strftime('%s', SUBSTR(observed_at, 1, 19)) AS INTEGER) / ?) * ? as bucket_start,
+			COUNT(*),
+			AVG(temp),
+			AVG(humidity),
+			AVG(pressure),
+			MAX(wind_gust),
+			CASE WHEN COUNT(precip_total) > 0 THEN COALESCE(SUM(
+				CASE WHEN precip_total < prev_precip THEN precip_total ELSE precip_total - prev_precip END
+			), 0) END
+		FROM steps
+		WHERE observed_at >= ?
+		GROUP BY bucket_start
+		ORDER BY bucket_start ASC
+	`, stationID, end, stationID, dayStart, start, start, width, width, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []ObservationBucket
+	for rows.Next() {
+		var b ObservationBucket
+		var startUnix int64
+		if err := rows.Scan(&startUnix, &b.Samples, &b.TempAvg, &b.HumidityAvg, &b.PressureAvg, &b.WindGustMax, &b.Precip); err != nil {
+			return nil, err
+		}
+		b.Start = time.Unix(startUnix, 0).UTC()
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
 // GetCleanObservations returns observations suitable for ML training:
 // - Good QC status (0 or 1)
 // - No quality flags set
@@ -144,6 +457,67 @@ func (s *Store) GetCleanObservations(stationID string, start, end time.Time) ([]
 	return observations, rows.Err()
 }
 
+// GetCleanObservationsMulti returns clean observations for several stations in
+// a single query, keyed by station ID. It applies the same filters as
+// GetCleanObservations.
+func (s *Store) GetCleanObservationsMulti(stationIDs []string, start, end time.Time) (map[string][]models.Observation, error) {
+	return s.observationsMulti(stationIDs, start, end, `
+		  AND qc_status IN (0, 1)
+		  AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
+		  AND obs_type IN ('instant', 'hourly_aggregate')`)
+}
+
+// GetDayObservationsMulti is GetDayObservations for several stations in a
+// single query, keyed by station ID.
+func (s *Store) GetDayObservationsMulti(stationIDs []string, date, asOf time.Time) (map[string][]models.Observation, error) {
+	local := date.In(s.loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc)
+	dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if asOf.Before(dayEnd) {
+		dayEnd = asOf
+	}
+	return s.observationsMulti(stationIDs, dayStart, dayEnd, "")
+}
+
+// observationsMulti returns the observations for several stations between
+// start and end that match filter, an extra SQL condition, keyed by station ID.
+func (s *Store) observationsMulti(stationIDs []string, start, end time.Time, filter string) (map[string][]models.Observation, error) {
+	result := make(map[string][]models.Observation)
+	if len(stationIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(stationIDs)), ", ")
+	args := make([]any, 0, len(stationIDs)+2)
+	for _, id := range stationIDs {
+		args = append(args, id)
+	}
+	args = append(args, start.UTC(), end.UTC())
+
+	rows, err := s.db.Query(`
+		SELECT id, station_id, observed_at, temp, humidity, dewpoint, pressure, wind_speed, wind_gust, wind_dir, precip_rate, precip_total, solar_radiation, uv, heat_index, wind_chill, qc_status, raw_json, created_at, obs_type, aggregation_period_minutes, quality_flags
+		FROM observations
+		WHERE station_id IN (`+placeholders+`)
+		  AND observed_at >= ? AND observed_at <= ?`+filter+`
+		ORDER BY station_id, observed_at ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var obs models.Observation
+		var obsType sql.NullString
+		if err := rows.Scan(&obs.ID, &obs.StationID, &obs.ObservedAt, &obs.Temp, &obs.Humidity, &obs.Dewpoint, &obs.Pressure, &obs.WindSpeed, &obs.WindGust, &obs.WindDir, &obs.PrecipRate, &obs.PrecipTotal, &obs.SolarRadiation, &obs.UV, &obs.HeatIndex, &obs.WindChill, &obs.QCStatus, &obs.RawJSON, &obs.CreatedAt, &obsType, &obs.AggregationPeriod, &obs.QualityFlags); err != nil {
+			return nil, err
+		}
+		obs.ObsType = obsType.String
+		result[obs.StationID] = append(result[obs.StationID], obs)
+	}
+	return result, rows.Err()
+}
+
 func (s *Store) InsertForecast(f models.Forecast) error {
 	source := f.Source
 	if source == "" {
@@ -158,11 +532,7 @@ func (s *Store) InsertForecast(f models.Forecast) error {
 }
 
 func (s *Store) ComputeDailySummary(stationID string, date time.Time) (*models.DailySummary, error) {
-	loc, err := time.LoadLocation("Australia/Melbourne")
-	if err != nil {
-		return nil, fmt.Errorf("load Melbourne timezone: %w", err)
-	}
-
+	loc := s.loc
 	localDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
 
 	y, m, d := localDate.Date()
@@ -357,12 +727,13 @@ func (s *Store) UpsertDailySummary(ds models.DailySummary) error {
 	_, err := s.db.Exec(`
 		INSERT INTO daily_summaries (date, station_id, temp_max, temp_max_time, temp_min, temp_min_time, 
 		    temp_avg, humidity_avg, pressure_avg, precip_total, wind_max_gust, 
-		    inversion_detected, inversion_strength, regime_heatwave, regime_inversion, regime_clear_calm,
+		    inversion_detected, inversion_strength, regime_heatwave, regime_inversion, regime_clear_calm, regime_cold_snap,
 		    wind_mean_night, wind_mean_evening, wind_mean_afternoon, calm_fraction_night,
 		    solar_integral, solar_max, solar_midday_avg,
 		    dewpoint_min, dewpoint_avg, dewpoint_depression_afternoon,
-		    pressure_change_24h, temp_rise_9to12, diurnal_range, midday_gradient)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		    pressure_change_24h, temp_rise_9to12, diurnal_range, midday_gradient,
+		    regime, condition, sunshine_hours)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(date, station_id) DO UPDATE SET
 			temp_max = excluded.temp_max,
 			temp_max_time = excluded.temp_max_time,
@@ -378,6 +749,7 @@ func (s *Store) UpsertDailySummary(ds models.DailySummary) error {
 			regime_heatwave = excluded.regime_heatwave,
 			regime_inversion = excluded.regime_inversion,
 			regime_clear_calm = excluded.regime_clear_calm,
+			regime_cold_snap = excluded.regime_cold_snap,
 			wind_mean_night = excluded.wind_mean_night,
 			wind_mean_evening = excluded.wind_mean_evening,
 			wind_mean_afternoon = excluded.wind_mean_afternoon,
@@ -391,14 +763,18 @@ func (s *Store) UpsertDailySummary(ds models.DailySummary) error {
 			pressure_change_24h = excluded.pressure_change_24h,
 			temp_rise_9to12 = excluded.temp_rise_9to12,
 			diurnal_range = excluded.diurnal_range,
-			midday_gradient = excluded.midday_gradient
+			midday_gradient = excluded.midday_gradient,
+			regime = excluded.regime,
+			condition = excluded.condition,
+			sunshine_hours = excluded.sunshine_hours
 	`, ds.Date, ds.StationID, ds.TempMax, ds.TempMaxTime, ds.TempMin, ds.TempMinTime,
 		ds.TempAvg, ds.HumidityAvg, ds.PressureAvg, ds.PrecipTotal, ds.WindMaxGust,
-		ds.InversionDetected, ds.InversionStrength, ds.RegimeHeatwave, ds.RegimeInversion, ds.RegimeClearCalm,
+		ds.InversionDetected, ds.InversionStrength, ds.RegimeHeatwave, ds.RegimeInversion, ds.RegimeClearCalm, ds.RegimeColdSnap,
 		ds.WindMeanNight, ds.WindMeanEvening, ds.WindMeanAfternoon, ds.CalmFractionNight,
 		ds.SolarIntegral, ds.SolarMax, ds.SolarMiddayAvg,
 		ds.DewpointMin, ds.DewpointAvg, ds.DewpointDepressionAfternoon,
-		ds.PressureChange24h, ds.TempRise9to12, ds.DiurnalRange, ds.MiddayGradient)
+		ds.PressureChange24h, ds.TempRise9to12, ds.DiurnalRange, ds.MiddayGradient,
+		ds.Regime, ds.Condition, ds.SunshineHours)
 	return err
 }
 
@@ -425,8 +801,68 @@ func (s *Store) GetDailySummaries(stationID string, start, end time.Time) ([]mod
 	return summaries, rows.Err()
 }
 
+// DiurnalRange is the spread between a day's max and min temperatures.
+type DiurnalRange struct {
+	Date  time.Time
+	Range float64 // °C
+}
+
+// GetDiurnalRange returns a station's daily temperature range for each day
+// from start to end inclusive, oldest first. Days missing a max or min are
+// skipped.
+func (s *Store) GetDiurnalRange(stationID string, start, end time.Time) ([]DiurnalRange, error) {
+	rows, err := s.db.Query(`
+		SELECT date, temp_max - temp_min
+		FROM daily_summaries
+		WHERE station_id = ?
+		  AND SUBSTR(date, 1, 10) BETWEEN ? AND ?
+		  AND temp_max IS NOT NULL AND temp_min IS NOT NULL
+		ORDER BY date ASC
+	`, stationID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranges []DiurnalRange
+	for rows.Next() {
+		var r DiurnalRange
+		if err := rows.Scan(&r.Date, &r.Range); err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, rows.Err()
+}
+
+// GetDailySummary returns the stored summary for a station and date, or nil if
+// there isn't one. Only the fields used for regime classification are loaded.
+func (s *Store) GetDailySummary(stationID string, date time.Time) (*models.DailySummary, error) {
+	row := s.db.QueryRow(`
+		SELECT date, station_id, temp_max, temp_min, precip_total,
+		       inversion_detected, inversion_strength,
+		       regime_heatwave, regime_inversion, regime_clear_calm, regime_cold_snap,
+		       calm_fraction_night, solar_integral, regime, condition, sunshine_hours
+		FROM daily_summaries
+		WHERE station_id = ? AND SUBSTR(date, 1, 10) = ?
+	`, stationID, date.Format("2006-01-02"))
+
+	var ds models.DailySummary
+	err := row.Scan(&ds.Date, &ds.StationID, &ds.TempMax, &ds.TempMin, &ds.PrecipTotal,
+		&ds.InversionDetected, &ds.InversionStrength,
+		&ds.RegimeHeatwave, &ds.RegimeInversion, &ds.RegimeClearCalm, &ds.RegimeColdSnap,
+		&ds.CalmFractionNight, &ds.SolarIntegral, &ds.Regime, &ds.Condition, &ds.SunshineHours)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}
+
 func (s *Store) GetStationsByTier(tier string) ([]models.Station, error) {
-	rows, err := s.db.Query(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active FROM stations WHERE elevation_tier = ? AND active = TRUE ORDER BY elevation ASC`, tier)
+	rows, err := s.db.Query(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes FROM stations WHERE elevation_tier = ? AND active = TRUE ORDER BY elevation ASC`, tier)
 	if err != nil {
 		return nil, err
 	}
@@ -435,7 +871,7 @@ func (s *Store) GetStationsByTier(tier string) ([]models.Station, error) {
 	var stations []models.Station
 	for rows.Next() {
 		var st models.Station
-		if err := rows.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active); err != nil {
+		if err := rows.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active, &st.StaleThresholdMinutes); err != nil {
 			return nil, err
 		}
 		stations = append(stations, st)
@@ -598,11 +1034,19 @@ func (s *Store) GetVerificationForecasts(validDate time.Time) ([]models.Forecast
 	return forecasts, rows.Err()
 }
 
+// WU's day part runs from 7am to 7pm local; its humidity forecast is verified
+// against observations over the same hours.
+const (
+	dayPartStartHour = 7
+	dayPartEndHour   = 19
+)
+
 type DayActuals struct {
-	TempMax   sql.NullFloat64
-	TempMin   sql.NullFloat64
-	WindGust  sql.NullFloat64
-	PrecipSum sql.NullFloat64
+	TempMax     sql.NullFloat64
+	TempMin     sql.NullFloat64
+	WindGust    sql.NullFloat64
+	PrecipSum   sql.NullFloat64
+	HumidityAvg sql.NullFloat64 // mean over the day part, not the whole day
 }
 
 func (s *Store) GetActualsForDate(stationID string, date time.Time) (*DayActuals, error) {
@@ -611,50 +1055,89 @@ func (s *Store) GetActualsForDate(stationID string, date time.Time) (*DayActuals
 
 	startUTC := localDate.UTC()
 	endUTC := time.Date(y, m, d+1, 0, 0, 0, 0, s.loc).UTC()
+	dayPartStart := time.Date(y, m, d, dayPartStartHour, 0, 0, 0, s.loc).UTC()
+	dayPartEnd := time.Date(y, m, d, dayPartEndHour, 0, 0, 0, s.loc).UTC()
 
 	var a DayActuals
 	err := s.db.QueryRow(`
-		SELECT MAX(temp), MIN(temp), MAX(wind_gust), MAX(precip_total)
+		SELECT MAX(temp), MIN(temp), MAX(wind_gust), MAX(precip_total),
+			AVG(CASE WHEN observed_at >= ? AND observed_at < ? THEN humidity END)
 		FROM observations
 		WHERE station_id = ? AND observed_at >= ? AND observed_at < ?
-	`, stationID, startUTC, endUTC).Scan(&a.TempMax, &a.TempMin, &a.WindGust, &a.PrecipSum)
+	`, dayPartStart, dayPartEnd, stationID, startUTC, endUTC).Scan(&a.TempMax, &a.TempMin, &a.WindGust, &a.PrecipSum, &a.HumidityAvg)
 	if err != nil {
 		return nil, err
 	}
 	return &a, nil
 }
 
-func (s *Store) InsertForecastVerification(v models.ForecastVerification) error {
+// verificationDate formats t's calendar date the way forecast_verification
+// stores valid_date: "YYYY-MM-DD 00:00:00+00:00".
+func verificationDate(t time.Time) string {
+	return t.Format("2006-01-02") + " 00:00:00+00:00"
+}
+
+// UpsertForecastVerification stores a verification, replacing any earlier one
+// for the same forecast and date so re-verifying picks up corrected actuals.
+// valid_date is written as verificationDate so the unique index matches rows
+// normalised by migration.
+func (s *Store) UpsertForecastVerification(v models.ForecastVerification) error {
+	validDate := verificationDate(v.ValidDate)
 	_, err := s.db.Exec(`
 		INSERT INTO forecast_verification (
 			forecast_id, valid_date, 
 			forecast_temp_max, forecast_temp_min, actual_temp_max, actual_temp_min, bias_temp_max, bias_temp_min,
 			forecast_wind_speed, actual_wind_gust, bias_wind,
-			forecast_precip, actual_precip, bias_precip
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, v.ForecastID, v.ValidDate,
+			forecast_precip, actual_precip, bias_precip,
+			forecast_humidity, actual_humidity, bias_humidity
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(forecast_id, valid_date) DO UPDATE SET
+			forecast_temp_max = excluded.forecast_temp_max,
+			forecast_temp_min = excluded.forecast_temp_min,
+			actual_temp_max = excluded.actual_temp_max,
+			actual_temp_min = excluded.actual_temp_min,
+			bias_temp_max = excluded.bias_temp_max,
+			bias_temp_min = excluded.bias_temp_min,
+			forecast_wind_speed = excluded.forecast_wind_speed,
+			actual_wind_gust = excluded.actual_wind_gust,
+			bias_wind = excluded.bias_wind,
+			forecast_precip = excluded.forecast_precip,
+			actual_precip = excluded.actual_precip,
+			bias_precip = excluded.bias_precip,
+			forecast_humidity = excluded.forecast_humidity,
+			actual_humidity = excluded.actual_humidity,
+			bias_humidity = excluded.bias_humidity
+	`, v.ForecastID, validDate,
 		v.ForecastTempMax, v.ForecastTempMin, v.ActualTempMax, v.ActualTempMin, v.BiasTempMax, v.BiasTempMin,
 		v.ForecastWindSpeed, v.ActualWindGust, v.BiasWind,
-		v.ForecastPrecip, v.ActualPrecip, v.BiasPrecip)
+		v.ForecastPrecip, v.ActualPrecip, v.BiasPrecip,
+		v.ForecastHumidity, v.ActualHumidity, v.BiasHumidity)
 	return err
 }
 
-func (s *Store) HasVerificationForDate(validDate time.Time) (bool, error) {
-	var count int
-	dateStr := validDate.Format("2006-01-02")
-	err := s.db.QueryRow(`SELECT COUNT(*) FROM forecast_verification WHERE SUBSTR(valid_date, 1, 10) = ?`, dateStr).Scan(&count)
-	return count > 0, err
-}
-
 func (s *Store) ClearVerification() error {
 	_, err := s.db.Exec(`DELETE FROM forecast_verification`)
 	return err
 }
 
 func (s *Store) GetPrimaryStation() (*models.Station, error) {
-	row := s.db.QueryRow(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active FROM stations WHERE is_primary = TRUE LIMIT 1`)
+	row := s.db.QueryRow(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes FROM stations WHERE is_primary = TRUE LIMIT 1`)
+	var st models.Station
+	err := row.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active, &st.StaleThresholdMinutes)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// GetStation returns a station by ID, active or not, or nil if it doesn't exist.
+func (s *Store) GetStation(stationID string) (*models.Station, error) {
+	row := s.db.QueryRow(`SELECT station_id, name, latitude, longitude, elevation, elevation_tier, is_primary, active, stale_threshold_minutes FROM stations WHERE station_id = ?`, stationID)
 	var st models.Station
-	err := row.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active)
+	err := row.Scan(&st.StationID, &st.Name, &st.Latitude, &st.Longitude, &st.Elevation, &st.ElevationTier, &st.IsPrimary, &st.Active, &st.StaleThresholdMinutes)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -664,6 +1147,63 @@ func (s *Store) GetPrimaryStation() (*models.Station, error) {
 	return &st, nil
 }
 
+// SetPrimaryStation makes stationID the only primary station, clearing the
+// flag on any other in the same transaction so there's never zero or two.
+func (s *Store) SetPrimaryStation(stationID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setPrimaryStation(tx, stationID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateStationFlags sets a station's active and primary flags in one
+// transaction. Making it primary clears the flag on every other station.
+func (s *Store) UpdateStationFlags(stationID string, active, primary bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE stations SET active = ?, is_primary = ? WHERE station_id = ?`, active, primary, stationID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("station %s not found", stationID)
+	}
+	if primary {
+		if err := setPrimaryStation(tx, stationID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func setPrimaryStation(tx *sql.Tx, stationID string) error {
+	if _, err := tx.Exec(`UPDATE stations SET is_primary = FALSE WHERE is_primary = TRUE AND station_id != ?`, stationID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`UPDATE stations SET is_primary = TRUE WHERE station_id = ?`, stationID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("station %s not found", stationID)
+	}
+	return nil
+}
+
 type TodayStatsResult struct {
 	MinTemp     sql.NullFloat64
 	MaxTemp     sql.NullFloat64
@@ -672,28 +1212,39 @@ type TodayStatsResult struct {
 	RainTotal   sql.NullFloat64
 	MaxWind     sql.NullFloat64
 	MaxGust     sql.NullFloat64
+	MinHumidity sql.NullInt64
+	MaxHumidity sql.NullInt64
+	MinDewpoint sql.NullFloat64
 }
 
-func (s *Store) GetTodayStats(stationID string, localDate time.Time) (minTemp, maxTemp, rainTotal, maxWind, maxGust sql.NullFloat64, err error) {
-	result, err := s.GetTodayStatsExtended(stationID, localDate)
+// GetTodayStats returns the extremes GetTodayStatsExtended reports, for the
+// local day containing asOf up to asOf.
+func (s *Store) GetTodayStats(stationID string, asOf time.Time) (minTemp, maxTemp, rainTotal, maxWind, maxGust sql.NullFloat64, err error) {
+	result, err := s.GetTodayStatsExtended(stationID, asOf)
 	if err != nil {
 		return
 	}
 	return result.MinTemp, result.MaxTemp, result.RainTotal, result.MaxWind, result.MaxGust, nil
 }
 
-func (s *Store) GetTodayStatsExtended(stationID string, localDate time.Time) (*TodayStatsResult, error) {
-	dayStart := time.Date(localDate.Year(), localDate.Month(), localDate.Day(), 0, 0, 0, 0, s.loc)
+// GetTodayStatsExtended summarises a station's observations from local
+// midnight on the day containing asOf up to asOf itself. Live callers pass
+// time.Now(); passing a past instant reproduces what the stats were then.
+func (s *Store) GetTodayStatsExtended(stationID string, asOf time.Time) (*TodayStatsResult, error) {
+	local := asOf.In(s.loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc)
 	startUTC := dayStart.UTC()
-	endUTC := time.Now().UTC()
+	endUTC := asOf.UTC()
 
 	result := &TodayStatsResult{}
 
 	err := s.db.QueryRow(`
-		SELECT MIN(temp), MAX(temp), MAX(precip_total), MAX(wind_speed), MAX(wind_gust)
+		SELECT MIN(temp), MAX(temp), MAX(precip_total), MAX(wind_speed), MAX(wind_gust),
+		       MIN(humidity), MAX(humidity), MIN(dewpoint)
 		FROM observations
 		WHERE station_id = ? AND observed_at >= ? AND observed_at <= ?
-	`, stationID, startUTC, endUTC).Scan(&result.MinTemp, &result.MaxTemp, &result.RainTotal, &result.MaxWind, &result.MaxGust)
+	`, stationID, startUTC, endUTC).Scan(&result.MinTemp, &result.MaxTemp, &result.RainTotal, &result.MaxWind, &result.MaxGust,
+		&result.MinHumidity, &result.MaxHumidity, &result.MinDewpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -755,6 +1306,43 @@ func (s *Store) GetTempChangeRate(stationID string) (sql.NullFloat64, error) {
 	return result, nil
 }
 
+// GetPressureTrend returns the station's pressure change over roughly the last
+// three hours (hPa), scaled to a three-hour rate. It is invalid if there
+// isn't at least an hour of readings.
+func (s *Store) GetPressureTrend(stationID string) (sql.NullFloat64, error) {
+	var result sql.NullFloat64
+	threeHoursAgo := time.Now().UTC().Add(-3 * time.Hour)
+
+	var oldestPressure, newestPressure sql.NullFloat64
+	var oldestTime, newestTime time.Time
+
+	err := s.db.QueryRow(`
+		SELECT pressure, observed_at FROM observations
+		WHERE station_id = ? AND observed_at >= ? AND pressure IS NOT NULL
+		ORDER BY observed_at ASC LIMIT 1
+	`, stationID, threeHoursAgo).Scan(&oldestPressure, &oldestTime)
+	if err != nil || !oldestPressure.Valid {
+		return result, nil
+	}
+
+	err = s.db.QueryRow(`
+		SELECT pressure, observed_at FROM observations
+		WHERE station_id = ? AND pressure IS NOT NULL
+		ORDER BY observed_at DESC LIMIT 1
+	`, stationID).Scan(&newestPressure, &newestTime)
+	if err != nil || !newestPressure.Valid {
+		return result, nil
+	}
+
+	hoursDiff := newestTime.Sub(oldestTime).Hours()
+	if hoursDiff < 1 {
+		return result, nil
+	}
+
+	change := (newestPressure.Float64 - oldestPressure.Float64) * 3 / hoursDiff
+	return sql.NullFloat64{Float64: change, Valid: true}, nil
+}
+
 func (s *Store) GetLatestForecasts() (map[string][]models.Forecast, error) {
 	today := time.Now().UTC().Format("2006-01-02")
 	// Get the most recent forecast with valid temp data for each source/date combination
@@ -811,7 +1399,9 @@ func (s *Store) GetVerificationStats() (map[string]models.VerificationStats, err
 			AVG(v.bias_wind) as avg_wind_bias,
 			AVG(ABS(v.bias_wind)) as mae_wind,
 			AVG(v.bias_precip) as avg_precip_bias,
-			AVG(ABS(v.bias_precip)) as mae_precip
+			AVG(ABS(v.bias_precip)) as mae_precip,
+			AVG(v.bias_humidity) as avg_humidity_bias,
+			AVG(ABS(v.bias_humidity)) as mae_humidity
 		FROM forecast_verification v
 		JOIN forecasts f ON v.forecast_id = f.id
 		WHERE v.bias_temp_max IS NOT NULL
@@ -828,7 +1418,7 @@ func (s *Store) GetVerificationStats() (map[string]models.VerificationStats, err
 		var stats models.VerificationStats
 		if err := rows.Scan(&source, &stats.Count, &stats.AvgMaxBias, &stats.AvgMinBias,
 			&stats.MAEMax, &stats.MAEMin, &stats.AvgWindBias, &stats.MAEWind,
-			&stats.AvgPrecipBias, &stats.MAEPrecip); err != nil {
+			&stats.AvgPrecipBias, &stats.MAEPrecip, &stats.AvgHumidityBias, &stats.MAEHumidity); err != nil {
 			return nil, err
 		}
 		result[source] = stats
@@ -839,6 +1429,16 @@ func (s *Store) GetVerificationStats() (map[string]models.VerificationStats, err
 // GetDay1VerificationStats returns stats for day-1 (next-day) forecasts only, grouped by source.
 // This is the most meaningful comparison between forecast sources.
 func (s *Store) GetDay1VerificationStats() (map[string]models.VerificationStats, error) {
+	return s.day1VerificationStats("")
+}
+
+// GetDay1VerificationStatsBetween is GetDay1VerificationStats limited to
+// forecasts valid from start to end inclusive.
+func (s *Store) GetDay1VerificationStatsBetween(start, end time.Time) (map[string]models.VerificationStats, error) {
+	return s.day1VerificationStats("AND SUBSTR(v.valid_date, 1, 10) BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+func (s *Store) day1VerificationStats(filter string, args ...any) (map[string]models.VerificationStats, error) {
 	rows, err := s.db.Query(`
 		SELECT 
 			f.source,
@@ -850,12 +1450,14 @@ func (s *Store) GetDay1VerificationStats() (map[string]models.VerificationStats,
 			AVG(v.bias_wind) as avg_wind_bias,
 			AVG(ABS(v.bias_wind)) as mae_wind,
 			AVG(v.bias_precip) as avg_precip_bias,
-			AVG(ABS(v.bias_precip)) as mae_precip
+			AVG(ABS(v.bias_precip)) as mae_precip,
+			AVG(v.bias_humidity) as avg_humidity_bias,
+			AVG(ABS(v.bias_humidity)) as mae_humidity
 		FROM forecast_verification v
 		JOIN forecasts f ON v.forecast_id = f.id
-		WHERE v.bias_temp_max IS NOT NULL AND f.day_of_forecast = 1
+		WHERE v.bias_temp_max IS NOT NULL AND f.day_of_forecast = 1 `+filter+`
 		GROUP BY f.source
-	`)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -867,7 +1469,7 @@ func (s *Store) GetDay1VerificationStats() (map[string]models.VerificationStats,
 		var stats models.VerificationStats
 		if err := rows.Scan(&source, &stats.Count, &stats.AvgMaxBias, &stats.AvgMinBias,
 			&stats.MAEMax, &stats.MAEMin, &stats.AvgWindBias, &stats.MAEWind,
-			&stats.AvgPrecipBias, &stats.MAEPrecip); err != nil {
+			&stats.AvgPrecipBias, &stats.MAEPrecip, &stats.AvgHumidityBias, &stats.MAEHumidity); err != nil {
 			return nil, err
 		}
 		result[source] = stats
@@ -971,9 +1573,57 @@ func (s *Store) GetBestLeadVerificationHistory(limit int) ([]VerificationHistory
 	return results, rows.Err()
 }
 
+// VerificationPair is one verified forecast max or min alongside what was observed.
+type VerificationPair struct {
+	Source        string
+	DayOfForecast int
+	ValidDate     string // YYYY-MM-DD
+	Forecast      float64
+	Actual        float64
+}
+
+// GetVerificationPairs returns every verified (forecast, actual) pair for
+// target ("tmax" or "tmin"), oldest first. An empty source includes all
+// sources.
+func (s *Store) GetVerificationPairs(source, target string) ([]VerificationPair, error) {
+	var forecastCol, actualCol string
+	switch target {
+	case "tmax":
+		forecastCol, actualCol = "v.forecast_temp_max", "v.actual_temp_max"
+	case "tmin":
+		forecastCol, actualCol = "v.forecast_temp_min", "v.actual_temp_min"
+	default:
+		return nil, fmt.Errorf("unknown verification target %q", target)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT f.source, f.day_of_forecast, SUBSTR(v.valid_date, 1, 10), `+forecastCol+`, `+actualCol+`
+		FROM forecast_verification v
+		JOIN forecasts f ON v.forecast_id = f.id
+		WHERE `+forecastCol+` IS NOT NULL AND `+actualCol+` IS NOT NULL
+		  AND (? = '' OR f.source = ?)
+		ORDER BY v.valid_date, f.source, f.day_of_forecast
+	`, source, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []VerificationPair
+	for rows.Next() {
+		var p VerificationPair
+		if err := rows.Scan(&p.Source, &p.DayOfForecast, &p.ValidDate, &p.Forecast, &p.Actual); err != nil {
+			return nil, err
+		}
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
 type BiasRow struct {
 	Source        string
 	DayOfForecast int
+	Regime        string
 	AvgBiasMax    float64
 	AvgBiasMin    float64
 	MAEMax        float64
@@ -1017,6 +1667,104 @@ func (s *Store) GetBiasStatsFromVerification(windowDays int) ([]BiasRow, error)
 	return results, rows.Err()
 }
 
+// GetBiasStatsByRegime returns bias statistics grouped by source, lead time and the
+// regime classified for each verified date on the primary station. Days without a
+// regime are excluded; they're covered by the all-regime stats.
+func (s *Store) GetBiasStatsByRegime(windowDays int) ([]BiasRow, error) {
+	cutoff := time.Now().AddDate(0, 0, -windowDays).Format("2006-01-02")
+	rows, err := s.db.Query(`
+		SELECT 
+			f.source,
+			f.day_of_forecast,
+			CASE 
+				WHEN ds.regime_heatwave = 1 THEN 'heatwave'
+				WHEN ds.regime_cold_snap = 1 THEN 'cold_snap'
+				WHEN ds.regime_inversion = 1 THEN 'inversion'
+				WHEN ds.regime_clear_calm = 1 THEN 'clear_calm'
+			END as regime_name,
+			COALESCE(AVG(v.bias_temp_max), 0) as avg_bias_max,
+			COALESCE(AVG(v.bias_temp_min), 0) as avg_bias_min,
+			COALESCE(AVG(ABS(v.bias_temp_max)), 0) as mae_max,
+			COALESCE(AVG(ABS(v.bias_temp_min)), 0) as mae_min,
+			COUNT(v.bias_temp_max) as count_max,
+			COUNT(v.bias_temp_min) as count_min
+		FROM forecast_verification v
+		JOIN forecasts f ON v.forecast_id = f.id
+		JOIN daily_summaries ds ON SUBSTR(v.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
+		JOIN stations st ON ds.station_id = st.station_id AND st.is_primary = 1
+		WHERE SUBSTR(v.valid_date, 1, 10) >= ?
+		  AND (v.bias_temp_max IS NOT NULL OR v.bias_temp_min IS NOT NULL)
+		GROUP BY f.source, f.day_of_forecast, regime_name
+		HAVING regime_name IS NOT NULL
+	`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []BiasRow
+	for rows.Next() {
+		var r BiasRow
+		if err := rows.Scan(&r.Source, &r.DayOfForecast, &r.Regime, &r.AvgBiasMax, &r.AvgBiasMin,
+			&r.MAEMax, &r.MAEMin, &r.CountMax, &r.CountMin); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// rainDayThreshold is the smallest daily total (mm) counted as a rain day,
+// matching the BOM's definition behind its chance of rain.
+const rainDayThreshold = 0.2
+
+// PrecipCalibrationBucket is one decile of forecast rain chance in a
+// reliability diagram. Forecast is the mean forecast chance and Observed the
+// fraction of those days that had rain, both from 0 to 1.
+type PrecipCalibrationBucket struct {
+	Bucket   int // 0 for 0-9%, 1 for 10-19% ... 9 for 90-100%
+	Forecast float64
+	Observed float64
+	N        int
+}
+
+// GetPrecipCalibration groups a source's verified forecasts from the last
+// windowDays by rain chance decile and returns how often each decile actually
+// rained, at any lead time. Empty deciles are omitted, as are chances outside
+// 0-100%.
+func (s *Store) GetPrecipCalibration(source string, windowDays int) ([]PrecipCalibrationBucket, error) {
+	cutoff := time.Now().AddDate(0, 0, -windowDays).Format("2006-01-02")
+	rows, err := s.db.Query(`
+		SELECT
+			MIN(f.precip_chance / 10, 9) as bucket,
+			AVG(f.precip_chance) / 100.0,
+			AVG(CASE WHEN v.actual_precip >= ? THEN 1.0 ELSE 0.0 END),
+			COUNT(*)
+		FROM forecast_verification v
+		JOIN forecasts f ON v.forecast_id = f.id
+		WHERE f.source = ?
+		  AND f.precip_chance BETWEEN 0 AND 100
+		  AND v.actual_precip IS NOT NULL
+		  AND SUBSTR(v.valid_date, 1, 10) >= ?
+		GROUP BY bucket
+		ORDER BY bucket
+	`, rainDayThreshold, source, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []PrecipCalibrationBucket
+	for rows.Next() {
+		var b PrecipCalibrationBucket
+		if err := rows.Scan(&b.Bucket, &b.Forecast, &b.Observed, &b.N); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
 type CorrectionStats struct {
 	Source        string
 	Target        string
@@ -1062,18 +1810,21 @@ func (s *Store) GetCorrectionStats(source, target string, dayOfForecast int) (*C
 	return &stats, nil
 }
 
-func (s *Store) GetAllCorrectionStats() (map[string]map[string]map[int]*CorrectionStats, error) {
+// CorrectionStatsMap indexes correction stats by source, target, day of forecast
+// and regime. The all-conditions bucket is keyed by regime "all".
+type CorrectionStatsMap map[string]map[string]map[int]map[string]*CorrectionStats
+
+func (s *Store) GetAllCorrectionStats() (CorrectionStatsMap, error) {
 	rows, err := s.db.Query(`
 		SELECT source, target, day_of_forecast, regime, window_days, sample_size, mean_bias, mae, updated_at
 		FROM forecast_correction_stats
-		WHERE regime = 'all'
 	`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[string]map[string]map[int]*CorrectionStats)
+	result := make(CorrectionStatsMap)
 	for rows.Next() {
 		var stats CorrectionStats
 		if err := rows.Scan(&stats.Source, &stats.Target, &stats.DayOfForecast, &stats.Regime,
@@ -1082,13 +1833,16 @@ func (s *Store) GetAllCorrectionStats() (map[string]map[string]map[int]*Correcti
 		}
 
 		if result[stats.Source] == nil {
-			result[stats.Source] = make(map[string]map[int]*CorrectionStats)
+			result[stats.Source] = make(map[string]map[int]map[string]*CorrectionStats)
 		}
 		if result[stats.Source][stats.Target] == nil {
-			result[stats.Source][stats.Target] = make(map[int]*CorrectionStats)
+			result[stats.Source][stats.Target] = make(map[int]map[string]*CorrectionStats)
+		}
+		if result[stats.Source][stats.Target][stats.DayOfForecast] == nil {
+			result[stats.Source][stats.Target][stats.DayOfForecast] = make(map[string]*CorrectionStats)
 		}
 		s := stats
-		result[stats.Source][stats.Target][stats.DayOfForecast] = &s
+		result[stats.Source][stats.Target][stats.DayOfForecast][stats.Regime] = &s
 	}
 	return result, rows.Err()
 }
@@ -1097,7 +1851,7 @@ func (s *Store) GetRecentDailySummaries(stationID string, days int) ([]models.Da
 	rows, err := s.db.Query(`
 		SELECT date, station_id, temp_max, temp_max_time, temp_min, temp_min_time, temp_avg, 
 		       humidity_avg, pressure_avg, precip_total, wind_max_gust, inversion_detected, inversion_strength,
-		       regime_heatwave, regime_inversion, regime_clear_calm
+		       regime_heatwave, regime_inversion, regime_clear_calm, regime_cold_snap
 		FROM daily_summaries
 		WHERE station_id = ?
 		ORDER BY date DESC
@@ -1114,7 +1868,7 @@ func (s *Store) GetRecentDailySummaries(stationID string, days int) ([]models.Da
 		if err := rows.Scan(&ds.Date, &ds.StationID, &ds.TempMax, &ds.TempMaxTime, &ds.TempMin, &ds.TempMinTime,
 			&ds.TempAvg, &ds.HumidityAvg, &ds.PressureAvg, &ds.PrecipTotal, &ds.WindMaxGust,
 			&ds.InversionDetected, &ds.InversionStrength,
-			&ds.RegimeHeatwave, &ds.RegimeInversion, &ds.RegimeClearCalm); err != nil {
+			&ds.RegimeHeatwave, &ds.RegimeInversion, &ds.RegimeClearCalm, &ds.RegimeColdSnap); err != nil {
 			return nil, err
 		}
 		summaries = append(summaries, ds)
@@ -1214,7 +1968,7 @@ func (s *Store) GetCorrectionStatsForRegime(source, target string, dayOfForecast
 // VerificationWithRegime extends VerificationHistoryRow with regime data.
 type VerificationWithRegime struct {
 	VerificationHistoryRow
-	Regime string // "heatwave", "inversion", "clear_calm", or ""
+	Regime string // "heatwave", "cold_snap", "inversion", "clear_calm", or ""
 }
 
 // GetBestLeadVerificationWithRegime returns verification history joined with daily_summaries
@@ -1224,7 +1978,7 @@ func (s *Store) GetBestLeadVerificationWithRegime(limit int) ([]VerificationWith
 		SELECT v.id, v.forecast_id, v.valid_date, v.forecast_temp_max, v.forecast_temp_min,
 		       v.actual_temp_max, v.actual_temp_min, v.bias_temp_max, v.bias_temp_min, v.created_at,
 		       f.source, f.day_of_forecast,
-		       ds.regime_heatwave, ds.regime_inversion, ds.regime_clear_calm
+		       ds.regime_heatwave, ds.regime_inversion, ds.regime_clear_calm, ds.regime_cold_snap
 		FROM forecast_verification v
 		JOIN forecasts f ON v.forecast_id = f.id
 		LEFT JOIN daily_summaries ds ON SUBSTR(v.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
@@ -1243,16 +1997,18 @@ func (s *Store) GetBestLeadVerificationWithRegime(limit int) ([]VerificationWith
 	var results []VerificationWithRegime
 	for rows.Next() {
 		var r VerificationWithRegime
-		var heatwave, inversion, clearCalm sql.NullBool
+		var heatwave, inversion, clearCalm, coldSnap sql.NullBool
 		if err := rows.Scan(&r.ID, &r.ForecastID, &r.ValidDate, &r.ForecastTempMax, &r.ForecastTempMin,
 			&r.ActualTempMax, &r.ActualTempMin, &r.BiasTempMax, &r.BiasTempMin, &r.CreatedAt,
 			&r.Source, &r.DayOfForecast,
-			&heatwave, &inversion, &clearCalm); err != nil {
+			&heatwave, &inversion, &clearCalm, &coldSnap); err != nil {
 			return nil, err
 		}
-		// Priority: heatwave > inversion > clear_calm
+		// Priority: heatwave > cold_snap > inversion > clear_calm
 		if heatwave.Valid && heatwave.Bool {
 			r.Regime = "heatwave"
+		} else if coldSnap.Valid && coldSnap.Bool {
+			r.Regime = "cold_snap"
 		} else if inversion.Valid && inversion.Bool {
 			r.Regime = "inversion"
 		} else if clearCalm.Valid && clearCalm.Bool {
@@ -1263,6 +2019,51 @@ func (s *Store) GetBestLeadVerificationWithRegime(limit int) ([]VerificationWith
 	return results, rows.Err()
 }
 
+// GetWorstForecasts returns the verifications with the largest absolute max
+// temp error, worst first, at any lead time. An empty source covers all
+// sources. Regime is "" on normal days or when no summary exists.
+func (s *Store) GetWorstForecasts(source string, limit int) ([]VerificationWithRegime, error) {
+	rows, err := s.db.Query(`
+		SELECT v.id, v.forecast_id, v.valid_date, v.forecast_temp_max, v.forecast_temp_min,
+		       v.actual_temp_max, v.actual_temp_min, v.bias_temp_max, v.bias_temp_min, v.created_at,
+		       f.source, f.day_of_forecast,
+		       CASE
+		           WHEN ds.regime IS NOT NULL AND ds.regime != 'all' THEN ds.regime
+		           WHEN ds.regime IS NOT NULL THEN ''
+		           WHEN ds.regime_heatwave = 1 THEN 'heatwave'
+		           WHEN ds.regime_cold_snap = 1 THEN 'cold_snap'
+		           WHEN ds.regime_inversion = 1 THEN 'inversion'
+		           WHEN ds.regime_clear_calm = 1 THEN 'clear_calm'
+		           ELSE ''
+		       END as regime_name
+		FROM forecast_verification v
+		JOIN forecasts f ON v.forecast_id = f.id
+		LEFT JOIN daily_summaries ds ON SUBSTR(v.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
+		LEFT JOIN stations st ON ds.station_id = st.station_id AND st.is_primary = 1
+		WHERE v.bias_temp_max IS NOT NULL
+		  AND (? = '' OR f.source = ?)
+		  AND (ds.station_id IS NULL OR st.station_id IS NOT NULL)
+		ORDER BY ABS(v.bias_temp_max) DESC, v.valid_date DESC
+		LIMIT ?
+	`, source, source, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []VerificationWithRegime
+	for rows.Next() {
+		var r VerificationWithRegime
+		if err := rows.Scan(&r.ID, &r.ForecastID, &r.ValidDate, &r.ForecastTempMax, &r.ForecastTempMin,
+			&r.ActualTempMax, &r.ActualTempMin, &r.BiasTempMax, &r.BiasTempMin, &r.CreatedAt,
+			&r.Source, &r.DayOfForecast, &r.Regime); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // RegimeStats holds MAE statistics for a specific regime, broken down by source.
 type RegimeStats struct {
 	Regime    string
@@ -1272,23 +2073,33 @@ type RegimeStats struct {
 	BOMMAEMin float64
 	WUDays    int
 	BOMDays   int
+	// WUMinDays and BOMMinDays count the days that also have a verified min.
+	WUMinDays  int
+	BOMMinDays int
 }
 
 // GetRegimeVerificationStats returns MAE grouped by regime and source (best lead: WU D+1, BOM D+2).
+// The stored regime is used where present; older summaries fall back to their flags.
+// WUDays and BOMDays count days with a verified max; a min MAE is only set
+// when its MinDays count is non-zero.
 func (s *Store) GetRegimeVerificationStats(windowDays int) ([]RegimeStats, error) {
 	cutoff := time.Now().AddDate(0, 0, -windowDays).Format("2006-01-02")
 	rows, err := s.db.Query(`
 		SELECT 
 			CASE 
+				WHEN ds.regime = 'all' THEN 'normal'
+				WHEN ds.regime IS NOT NULL THEN ds.regime
 				WHEN ds.regime_heatwave = 1 THEN 'heatwave'
+				WHEN ds.regime_cold_snap = 1 THEN 'cold_snap'
 				WHEN ds.regime_inversion = 1 THEN 'inversion'
 				WHEN ds.regime_clear_calm = 1 THEN 'clear_calm'
 				ELSE 'normal'
-			END as regime,
+			END as regime_name,
 			f.source,
-			COALESCE(AVG(ABS(v.bias_temp_max)), 0) as mae_max,
-			COALESCE(AVG(ABS(v.bias_temp_min)), 0) as mae_min,
-			COUNT(*) as count
+			AVG(ABS(v.bias_temp_max)) as mae_max,
+			AVG(ABS(v.bias_temp_min)) as mae_min,
+			COUNT(*) as count,
+			COUNT(v.bias_temp_min) as min_count
 		FROM forecast_verification v
 		JOIN forecasts f ON v.forecast_id = f.id
 		LEFT JOIN daily_summaries ds ON SUBSTR(v.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
@@ -1297,13 +2108,14 @@ func (s *Store) GetRegimeVerificationStats(windowDays int) ([]RegimeStats, error
 		  AND v.bias_temp_max IS NOT NULL
 		  AND ((f.source = 'wu' AND f.day_of_forecast = 1) OR (f.source = 'bom' AND f.day_of_forecast = 2))
 		  AND (ds.station_id IS NULL OR st.station_id IS NOT NULL)
-		GROUP BY regime, f.source
+		GROUP BY regime_name, f.source
 		ORDER BY 
-			CASE regime
+			CASE regime_name
 				WHEN 'heatwave' THEN 1
-				WHEN 'inversion' THEN 2
-				WHEN 'clear_calm' THEN 3
-				ELSE 4
+				WHEN 'cold_snap' THEN 2
+				WHEN 'inversion' THEN 3
+				WHEN 'clear_calm' THEN 4
+				ELSE 5
 			END,
 			f.source
 	`, cutoff)
@@ -1317,9 +2129,10 @@ func (s *Store) GetRegimeVerificationStats(windowDays int) ([]RegimeStats, error
 	regimeOrder := []string{}
 	for rows.Next() {
 		var regime, source string
-		var maeMax, maeMin float64
-		var count int
-		if err := rows.Scan(&regime, &source, &maeMax, &maeMin, &count); err != nil {
+		var maeMax float64
+		var maeMin sql.NullFloat64 // NULL when no day has a verified min
+		var count, minCount int
+		if err := rows.Scan(&regime, &source, &maeMax, &maeMin, &count, &minCount); err != nil {
 			return nil, err
 		}
 		if _, ok := regimeMap[regime]; !ok {
@@ -1329,12 +2142,12 @@ func (s *Store) GetRegimeVerificationStats(windowDays int) ([]RegimeStats, error
 		rs := regimeMap[regime]
 		if source == "wu" {
 			rs.WUMAEMax = maeMax
-			rs.WUMAEMin = maeMin
-			rs.WUDays = count
+			rs.WUMAEMin = maeMin.Float64
+			rs.WUDays, rs.WUMinDays = count, minCount
 		} else if source == "bom" {
 			rs.BOMMAEMax = maeMax
-			rs.BOMMAEMin = maeMin
-			rs.BOMDays = count
+			rs.BOMMAEMin = maeMin.Float64
+			rs.BOMDays, rs.BOMMinDays = count, minCount
 		}
 	}
 
@@ -1345,17 +2158,63 @@ func (s *Store) GetRegimeVerificationStats(windowDays int) ([]RegimeStats, error
 	return results, rows.Err()
 }
 
+// BestSourceInsufficientData is returned by GetBestSourceByRegime when a regime
+// has too few verified days from either source, or the two are tied.
+const BestSourceInsufficientData = "insufficient data"
+
+// Thresholds for GetBestSourceByRegime: each source needs minBestSourceDays
+// verified days, and must beat the other by more than bestSourceTieMargin °C.
+const (
+	minBestSourceDays   = 3
+	bestSourceTieMargin = 0.1
+)
+
+// GetBestSourceByRegime returns, for each regime seen in the window, the
+// source ("wu" or "bom") with the lower combined max/min MAE at its best lead
+// time, or BestSourceInsufficientData.
+func (s *Store) GetBestSourceByRegime(days int) (map[string]string, error) {
+	stats, err := s.GetRegimeVerificationStats(days)
+	if err != nil {
+		return nil, err
+	}
+	return BestSourceByRegime(stats), nil
+}
+
+// BestSourceByRegime is GetBestSourceByRegime over stats already fetched with
+// GetRegimeVerificationStats. A source's days only count when both its max and
+// min were verified, so a missing min MAE isn't mistaken for a perfect one.
+func BestSourceByRegime(stats []RegimeStats) map[string]string {
+	result := make(map[string]string, len(stats))
+	for _, rs := range stats {
+		if rs.WUMinDays < minBestSourceDays || rs.BOMMinDays < minBestSourceDays {
+			result[rs.Regime] = BestSourceInsufficientData
+			continue
+		}
+		wuMAE := (rs.WUMAEMax + rs.WUMAEMin) / 2
+		bomMAE := (rs.BOMMAEMax + rs.BOMMAEMin) / 2
+		switch {
+		case bomMAE < wuMAE-bestSourceTieMargin:
+			result[rs.Regime] = "bom"
+		case wuMAE < bomMAE-bestSourceTieMargin:
+			result[rs.Regime] = "wu"
+		default:
+			result[rs.Regime] = BestSourceInsufficientData
+		}
+	}
+	return result
+}
+
 // GetTodayRegime returns the regime for today (or most recent day with regime data).
 func (s *Store) GetTodayRegime(stationID string, today time.Time) (string, error) {
 	dateStr := today.Format("2006-01-02")
 	row := s.db.QueryRow(`
-		SELECT regime_heatwave, regime_inversion, regime_clear_calm
+		SELECT regime_heatwave, regime_inversion, regime_clear_calm, regime_cold_snap
 		FROM daily_summaries
 		WHERE station_id = ? AND SUBSTR(date, 1, 10) = ?
 	`, stationID, dateStr)
 
-	var heatwave, inversion, clearCalm sql.NullBool
-	err := row.Scan(&heatwave, &inversion, &clearCalm)
+	var heatwave, inversion, clearCalm, coldSnap sql.NullBool
+	err := row.Scan(&heatwave, &inversion, &clearCalm, &coldSnap)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -1363,10 +2222,13 @@ func (s *Store) GetTodayRegime(stationID string, today time.Time) (string, error
 		return "", err
 	}
 
-	// Priority: heatwave > inversion > clear_calm
+	// Priority: heatwave > cold_snap > inversion > clear_calm
 	if heatwave.Valid && heatwave.Bool {
 		return "heatwave", nil
 	}
+	if coldSnap.Valid && coldSnap.Bool {
+		return "cold_snap", nil
+	}
 	if inversion.Valid && inversion.Bool {
 		return "inversion", nil
 	}
@@ -1389,8 +2251,9 @@ func (s *Store) UpsertDisplayedForecast(df models.DisplayedForecast) error {
 			bias_day_used_max, bias_day_used_min,
 			bias_samples_max, bias_samples_min,
 			bias_fallback_max, bias_fallback_min,
+			nowcast_max,
 			source_max, source_min
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(valid_date, day_of_forecast, wu_forecast_id, bom_forecast_id) DO NOTHING
 	`, df.DisplayedAt, df.ValidDate.Format("2006-01-02"), df.DayOfForecast,
 		df.WUForecastID, df.BOMForecastID,
@@ -1400,10 +2263,56 @@ func (s *Store) UpsertDisplayedForecast(df models.DisplayedForecast) error {
 		df.BiasDayUsedMax, df.BiasDayUsedMin,
 		df.BiasSamplesMax, df.BiasSamplesMin,
 		df.BiasFallbackMax, df.BiasFallbackMin,
+		df.NowcastMax,
 		df.SourceMax, df.SourceMin)
 	return err
 }
 
+// GetLatestDisplayedForecast returns the most recently displayed forecast for a valid date,
+// or nil if none was logged.
+func (s *Store) GetLatestDisplayedForecast(validDate time.Time) (*models.DisplayedForecast, error) {
+	row := s.db.QueryRow(`
+		SELECT id, displayed_at, valid_date, day_of_forecast,
+			wu_forecast_id, bom_forecast_id,
+			raw_temp_max, raw_temp_min,
+			corrected_temp_max, corrected_temp_min,
+			bias_applied_max, bias_applied_min,
+			bias_day_used_max, bias_day_used_min,
+			bias_samples_max, bias_samples_min,
+			bias_fallback_max, bias_fallback_min,
+			nowcast_max,
+			source_max, source_min
+		FROM displayed_forecasts
+		WHERE SUBSTR(valid_date, 1, 10) = ?
+		ORDER BY displayed_at DESC
+		LIMIT 1
+	`, validDate.Format("2006-01-02"))
+
+	var df models.DisplayedForecast
+	var displayedAt, validDateStr string
+	err := row.Scan(&df.ID, &displayedAt, &validDateStr, &df.DayOfForecast,
+		&df.WUForecastID, &df.BOMForecastID,
+		&df.RawTempMax, &df.RawTempMin,
+		&df.CorrectedTempMax, &df.CorrectedTempMin,
+		&df.BiasAppliedMax, &df.BiasAppliedMin,
+		&df.BiasDayUsedMax, &df.BiasDayUsedMin,
+		&df.BiasSamplesMax, &df.BiasSamplesMin,
+		&df.BiasFallbackMax, &df.BiasFallbackMin,
+		&df.NowcastMax,
+		&df.SourceMax, &df.SourceMin)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(validDateStr) >= 10 {
+		df.ValidDate, _ = time.Parse("2006-01-02", validDateStr[:10])
+	}
+	df.DisplayedAt, _ = time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", displayedAt)
+	return &df, nil
+}
+
 // CorrectedAccuracyStats holds accuracy stats for corrected forecasts.
 type CorrectedAccuracyStats struct {
 	Count      int
@@ -1451,6 +2360,63 @@ func (s *Store) GetCorrectedAccuracyStats(stationID string, days int) (*Correcte
 	return &stats, nil
 }
 
+// CorrectedSourceAccuracy is displayed forecast accuracy for the days a
+// source ("bom", "wu" or "blend") was shown, so blended forecasts can be
+// compared with picking one source.
+type CorrectedSourceAccuracy struct {
+	Source  string
+	MaxDays int
+	MAEMax  sql.NullFloat64
+	MinDays int
+	MAEMin  sql.NullFloat64
+}
+
+// GetCorrectedAccuracyBySource splits GetCorrectedAccuracyStats by the source
+// each displayed max and min came from, ordered by source.
+func (s *Store) GetCorrectedAccuracyBySource(stationID string, days int) ([]CorrectedSourceAccuracy, error) {
+	rows, err := s.db.Query(`
+		WITH latest AS (
+			SELECT df.*,
+				ROW_NUMBER() OVER (
+					PARTITION BY SUBSTR(df.valid_date, 1, 10)
+					ORDER BY df.displayed_at DESC
+				) AS rn
+			FROM displayed_forecasts df
+			WHERE df.valid_date >= DATE('now', '-' || ? || ' days')
+		),
+		scored AS (
+			SELECT latest.source_max, latest.source_min,
+				ABS(latest.corrected_temp_max - ds.temp_max) AS max_err,
+				ABS(latest.corrected_temp_min - ds.temp_min) AS min_err
+			FROM latest
+			JOIN daily_summaries ds ON SUBSTR(latest.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
+			WHERE latest.rn = 1 AND ds.station_id = ?
+		)
+		SELECT source, COUNT(max_err), AVG(max_err), COUNT(min_err), AVG(min_err)
+		FROM (
+			SELECT source_max AS source, max_err, NULL AS min_err FROM scored WHERE source_max IS NOT NULL AND max_err IS NOT NULL
+			UNION ALL
+			SELECT source_min, NULL, min_err FROM scored WHERE source_min IS NOT NULL AND min_err IS NOT NULL
+		)
+		GROUP BY source
+		ORDER BY source
+	`, days, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []CorrectedSourceAccuracy
+	for rows.Next() {
+		var a CorrectedSourceAccuracy
+		if err := rows.Scan(&a.Source, &a.MaxDays, &a.MAEMax, &a.MinDays, &a.MAEMin); err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
 // CorrectedVerificationRow is a single row of corrected forecast verification.
 type CorrectedVerificationRow struct {
 	ValidDate        time.Time
@@ -1508,6 +2474,69 @@ func (s *Store) GetCorrectedVerificationHistory(stationID string, limit int) ([]
 	return results, rows.Err()
 }
 
+// CorrectedVsRawDay compares one day's raw and bias-corrected displayed
+// forecast against what was observed. Each MAE averages the max and min
+// absolute errors.
+type CorrectedVsRawDay struct {
+	Date         time.Time
+	RawMAE       float64
+	CorrectedMAE float64
+}
+
+// GetCorrectedVsRawSeries returns the raw and corrected forecast error for each
+// of the last days days, oldest first, so the accuracy page can show whether
+// bias correction is helping. Like GetCorrectedAccuracyStats it uses the last
+// forecast displayed for each date, and skips days missing either forecast or
+// an actual.
+func (s *Store) GetCorrectedVsRawSeries(stationID string, days int) ([]CorrectedVsRawDay, error) {
+	rows, err := s.db.Query(`
+		WITH latest AS (
+			SELECT df.*,
+				ROW_NUMBER() OVER (
+					PARTITION BY SUBSTR(df.valid_date, 1, 10)
+					ORDER BY df.displayed_at DESC
+				) AS rn
+			FROM displayed_forecasts df
+			WHERE df.valid_date >= DATE('now', '-' || ? || ' days')
+		)
+		SELECT
+			SUBSTR(latest.valid_date, 1, 10),
+			(ABS(latest.raw_temp_max - ds.temp_max) + ABS(latest.raw_temp_min - ds.temp_min)) / 2.0,
+			(ABS(latest.corrected_temp_max - ds.temp_max) + ABS(latest.corrected_temp_min - ds.temp_min)) / 2.0
+		FROM latest
+		JOIN daily_summaries ds ON SUBSTR(latest.valid_date, 1, 10) = SUBSTR(ds.date, 1, 10)
+		WHERE latest.rn = 1
+			AND ds.station_id = ?
+			AND latest.raw_temp_max IS NOT NULL
+			AND latest.raw_temp_min IS NOT NULL
+			AND latest.corrected_temp_max IS NOT NULL
+			AND latest.corrected_temp_min IS NOT NULL
+			AND ds.temp_max IS NOT NULL
+			AND ds.temp_min IS NOT NULL
+		ORDER BY latest.valid_date ASC
+	`, days, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []CorrectedVsRawDay
+	for rows.Next() {
+		var d CorrectedVsRawDay
+		var dateStr string
+		if err := rows.Scan(&dateStr, &d.RawMAE, &d.CorrectedMAE); err != nil {
+			return nil, err
+		}
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse valid date %q: %w", dateStr, err)
+		}
+		d.Date = date
+		results = append(results, d)
+	}
+	return results, rows.Err()
+}
+
 // DataHealthStats contains data quality metrics for the /data page.
 type DataHealthStats struct {
 	SchemaVersion     int
@@ -1548,6 +2577,48 @@ func (s *Store) GetDataHealthStats() (*DataHealthStats, error) {
 	return stats, nil
 }
 
+// StationCleanCount splits one station's observations into clean (ML-ready)
+// and QC-flagged, using the same tests as DataHealthStats.
+type StationCleanCount struct {
+	StationID  string
+	Total      int64
+	Clean      int64
+	Flagged    int64
+	FlaggedPct float64
+}
+
+// GetCleanCountsByStation returns each station's clean and flagged
+// observation counts, the stations flagging most often first.
+func (s *Store) GetCleanCountsByStation() ([]StationCleanCount, error) {
+	rows, err := s.db.Query(`
+		SELECT station_id, COUNT(*),
+			SUM(CASE WHEN qc_status IN (0, 1)
+				AND (quality_flags IS NULL OR quality_flags = '' OR quality_flags = '[]')
+				AND obs_type IN ('instant', 'hourly_aggregate') THEN 1 ELSE 0 END),
+			SUM(CASE WHEN quality_flags IS NOT NULL AND quality_flags != '' AND quality_flags != '[]' THEN 1 ELSE 0 END)
+		FROM observations
+		GROUP BY station_id
+		ORDER BY 1.0 * SUM(CASE WHEN quality_flags IS NOT NULL AND quality_flags != '' AND quality_flags != '[]' THEN 1 ELSE 0 END) / COUNT(*) DESC, station_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []StationCleanCount
+	for rows.Next() {
+		var r StationCleanCount
+		if err := rows.Scan(&r.StationID, &r.Total, &r.Clean, &r.Flagged); err != nil {
+			return nil, err
+		}
+		if r.Total > 0 {
+			r.FlaggedPct = 100 * float64(r.Flagged) / float64(r.Total)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
 // ObsTypeCount represents observation type distribution.
 type ObsTypeCount struct {
 	Type    string
@@ -2,6 +2,8 @@ package store
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -102,6 +104,149 @@ func TestUpsertStation_Update(t *testing.T) {
 	}
 }
 
+func TestUpsertStation_StaleThreshold(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "DEFAULT", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertStation(models.Station{StationID: "SLOW", Active: true, StaleThresholdMinutes: 180}); err != nil {
+		t.Fatal(err)
+	}
+
+	stations, err := store.GetActiveStations()
+	if err != nil {
+		t.Fatalf("GetActiveStations: %v", err)
+	}
+	got := make(map[string]int)
+	for _, st := range stations {
+		got[st.StationID] = st.StaleThresholdMinutes
+	}
+	if got["DEFAULT"] != models.DefaultStaleThresholdMinutes {
+		t.Errorf("DEFAULT threshold = %d, want %d", got["DEFAULT"], models.DefaultStaleThresholdMinutes)
+	}
+	if got["SLOW"] != 180 {
+		t.Errorf("SLOW threshold = %d, want 180", got["SLOW"])
+	}
+}
+
+func TestSetPrimaryStation(t *testing.T) {
+	store := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "OLD", IsPrimary: true, Active: true},
+		{StationID: "NEW", Active: true},
+	} {
+		if err := store.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	if err := store.SetPrimaryStation("NEW"); err != nil {
+		t.Fatalf("SetPrimaryStation: %v", err)
+	}
+
+	primary, err := store.GetPrimaryStation()
+	if err != nil {
+		t.Fatalf("GetPrimaryStation: %v", err)
+	}
+	if primary == nil || primary.StationID != "NEW" {
+		t.Fatalf("primary = %+v, want NEW", primary)
+	}
+	old, err := store.GetStation("OLD")
+	if err != nil {
+		t.Fatalf("GetStation: %v", err)
+	}
+	if old.IsPrimary {
+		t.Error("OLD is still primary")
+	}
+
+	// An unknown station rolls back rather than leaving no primary
+	if err := store.SetPrimaryStation("MISSING"); err == nil {
+		t.Error("expected error for unknown station")
+	}
+	primary, err = store.GetPrimaryStation()
+	if err != nil {
+		t.Fatalf("GetPrimaryStation: %v", err)
+	}
+	if primary == nil || primary.StationID != "NEW" {
+		t.Errorf("primary after failed swap = %+v, want NEW", primary)
+	}
+}
+
+func TestSeedStations(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Last run's config: OLD was primary and RETIRED has since been removed
+	for _, st := range []models.Station{
+		{StationID: "OLD", IsPrimary: true, Active: true},
+		{StationID: "NEW", Active: true},
+		{StationID: "RETIRED", Active: true},
+	} {
+		if err := store.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	if err := store.SeedStations([]models.Station{
+		{StationID: "OLD", Active: true},
+		{StationID: "NEW", IsPrimary: true, Active: true},
+	}); err != nil {
+		t.Fatalf("SeedStations: %v", err)
+	}
+
+	active, err := store.GetActiveStations()
+	if err != nil {
+		t.Fatalf("GetActiveStations: %v", err)
+	}
+	var primaries []string
+	for _, st := range active {
+		if st.StationID == "RETIRED" {
+			t.Error("RETIRED is still active after being removed from the config")
+		}
+		if st.IsPrimary {
+			primaries = append(primaries, st.StationID)
+		}
+	}
+	if len(active) != 2 {
+		t.Errorf("got %d active stations, want 2", len(active))
+	}
+	if len(primaries) != 1 || primaries[0] != "NEW" {
+		t.Errorf("primaries = %v, want [NEW]", primaries)
+	}
+}
+
+func TestUpdateStationFlags(t *testing.T) {
+	store := setupTestStore(t)
+
+	for _, st := range []models.Station{
+		{StationID: "OLD", IsPrimary: true, Active: true},
+		{StationID: "NEW", Active: false},
+	} {
+		if err := store.UpsertStation(st); err != nil {
+			t.Fatalf("UpsertStation: %v", err)
+		}
+	}
+
+	if err := store.UpdateStationFlags("NEW", true, true); err != nil {
+		t.Fatalf("UpdateStationFlags: %v", err)
+	}
+	primary, err := store.GetPrimaryStation()
+	if err != nil {
+		t.Fatalf("GetPrimaryStation: %v", err)
+	}
+	if primary == nil || primary.StationID != "NEW" || !primary.Active {
+		t.Errorf("primary = %+v, want active NEW", primary)
+	}
+	if old, _ := store.GetStation("OLD"); old == nil || old.IsPrimary {
+		t.Errorf("OLD = %+v, want no longer primary", old)
+	}
+
+	if err := store.UpdateStationFlags("MISSING", true, false); err == nil {
+		t.Error("expected error for unknown station")
+	}
+}
+
 func TestGetActiveStations_FilterInactive(t *testing.T) {
 	store := setupTestStore(t)
 
@@ -146,7 +291,7 @@ func TestInsertAndGetObservation(t *testing.T) {
 		t.Fatalf("InsertObservation: %v", err)
 	}
 
-	latest, err := store.GetLatestObservation("TEST001")
+	latest, err := store.GetLatestObservation("TEST001", false)
 	if err != nil {
 		t.Fatalf("GetLatestObservation: %v", err)
 	}
@@ -189,7 +334,7 @@ func TestInsertObservation_NoDuplicate(t *testing.T) {
 		t.Fatalf("InsertObservation second: %v", err)
 	}
 
-	latest, err := store.GetLatestObservation("TEST001")
+	latest, err := store.GetLatestObservation("TEST001", false)
 	if err != nil {
 		t.Fatalf("GetLatestObservation: %v", err)
 	}
@@ -298,6 +443,47 @@ func TestGetCleanObservations(t *testing.T) {
 	}
 }
 
+func TestGetCleanObservationsMulti(t *testing.T) {
+	store := setupTestStore(t)
+
+	baseTime := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, obs := range []models.Observation{
+		{StationID: "STA1", ObservedAt: baseTime, Temp: sql.NullFloat64{Float64: 20, Valid: true}, ObsType: models.ObsTypeInstant},
+		{StationID: "STA1", ObservedAt: baseTime.Add(5 * time.Minute), Temp: sql.NullFloat64{Float64: 85, Valid: true}, ObsType: models.ObsTypeInstant,
+			QualityFlags: sql.NullString{String: `["temp_out_of_range"]`, Valid: true}},
+		{StationID: "STA1", ObservedAt: baseTime.Add(10 * time.Minute), Temp: sql.NullFloat64{Float64: 21, Valid: true}, ObsType: models.ObsTypeInstant},
+		{StationID: "STA2", ObservedAt: baseTime, Temp: sql.NullFloat64{Float64: 15, Valid: true}, ObsType: models.ObsTypeInstant},
+		{StationID: "STA3", ObservedAt: baseTime, Temp: sql.NullFloat64{Float64: 10, Valid: true}, ObsType: models.ObsTypeInstant},
+	} {
+		if err := store.InsertObservation(obs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.GetCleanObservationsMulti([]string{"STA1", "STA2"}, baseTime.Add(-time.Hour), baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetCleanObservationsMulti: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d stations, want 2", len(got))
+	}
+	if len(got["STA1"]) != 2 {
+		t.Fatalf("len(STA1) = %d, want 2 (flagged outlier excluded)", len(got["STA1"]))
+	}
+	for _, obs := range got["STA1"] {
+		if obs.Temp.Float64 == 85 {
+			t.Error("flagged outlier returned")
+		}
+	}
+	if !got["STA1"][0].ObservedAt.Before(got["STA1"][1].ObservedAt) {
+		t.Error("expected observations in time order")
+	}
+	if len(got["STA2"]) != 1 {
+		t.Errorf("len(STA2) = %d, want 1", len(got["STA2"]))
+	}
+}
+
 func TestInsertAndGetForecast(t *testing.T) {
 	store := setupTestStore(t)
 
@@ -437,6 +623,46 @@ func TestMigrationVersion(t *testing.T) {
 	}
 }
 
+func TestGetAppliedMigrationDetails(t *testing.T) {
+	store := setupTestStore(t)
+
+	applied, err := store.GetAppliedMigrationDetails()
+	if err != nil {
+		t.Fatalf("GetAppliedMigrationDetails: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("got %d applied migrations, want %d", len(applied), len(migrations))
+	}
+	for i, m := range applied {
+		if m.Version != migrations[i].Version || m.Description != migrations[i].Description {
+			t.Errorf("applied[%d] = %d %q, want %d %q", i, m.Version, m.Description, migrations[i].Version, migrations[i].Description)
+		}
+		if m.AppliedAt.IsZero() || time.Since(m.AppliedAt) > time.Minute {
+			t.Errorf("applied[%d].AppliedAt = %v, want just now", i, m.AppliedAt)
+		}
+	}
+
+	pending, err := store.PendingMigrations()
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending = %v, want none", pending)
+	}
+
+	last := migrations[len(migrations)-1].Version
+	if _, err := store.db.Exec("DELETE FROM schema_migrations WHERE version = ?", last); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = store.PendingMigrations()
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+	if len(pending) != 1 || pending[0] != last {
+		t.Errorf("pending = %v, want [%d]", pending, last)
+	}
+}
+
 func TestGetLatestObservation_NoData(t *testing.T) {
 	store := setupTestStore(t)
 
@@ -444,7 +670,7 @@ func TestGetLatestObservation_NoData(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	latest, err := store.GetLatestObservation("EMPTY")
+	latest, err := store.GetLatestObservation("EMPTY", false)
 	if err != nil {
 		t.Fatalf("GetLatestObservation: %v", err)
 	}
@@ -498,7 +724,7 @@ func TestGetLatestObservation_ReturnsLatest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	latest, err := store.GetLatestObservation("TEST001")
+	latest, err := store.GetLatestObservation("TEST001", false)
 	if err != nil {
 		t.Fatalf("GetLatestObservation: %v", err)
 	}
@@ -510,6 +736,154 @@ func TestGetLatestObservation_ReturnsLatest(t *testing.T) {
 	}
 }
 
+func TestGetLatestCleanObservation_SkipsFlagged(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "TEST001", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	for _, obs := range []models.Observation{
+		{StationID: "TEST001", ObservedAt: baseTime, Temp: sql.NullFloat64{Float64: 21, Valid: true}},
+		// A QC-failed spike, then a flagged one on top
+		{StationID: "TEST001", ObservedAt: baseTime.Add(5 * time.Minute), Temp: sql.NullFloat64{Float64: 58, Valid: true}, QCStatus: -1},
+		{
+			StationID:    "TEST001",
+			ObservedAt:   baseTime.Add(10 * time.Minute),
+			Temp:         sql.NullFloat64{Float64: 60, Valid: true},
+			QualityFlags: sql.NullString{String: `["temp_out_of_range"]`, Valid: true},
+		},
+	} {
+		if err := store.InsertObservation(obs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clean, err := store.GetLatestCleanObservation("TEST001")
+	if err != nil {
+		t.Fatalf("GetLatestCleanObservation: %v", err)
+	}
+	if clean == nil || clean.Temp.Float64 != 21 {
+		t.Errorf("GetLatestCleanObservation = %+v, want the 21°C reading", clean)
+	}
+
+	// The raw latest is still the flagged row, for staleness checks
+	latest, err := store.GetLatestObservation("TEST001", true)
+	if err != nil {
+		t.Fatalf("GetLatestObservation: %v", err)
+	}
+	if latest == nil || latest.Temp.Float64 != 60 {
+		t.Errorf("GetLatestObservation = %+v, want the flagged 60°C reading", latest)
+	}
+
+	none, err := store.GetLatestCleanObservation("MISSING")
+	if err != nil {
+		t.Fatalf("GetLatestCleanObservation: %v", err)
+	}
+	if none != nil {
+		t.Errorf("GetLatestCleanObservation(MISSING) = %+v, want nil", none)
+	}
+}
+
+func TestGetFieldCompleteness(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "NOBARO", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Four readings without a barometer; solar only on the daytime half
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 4; i++ {
+		obs := models.Observation{
+			StationID:   "NOBARO",
+			ObservedAt:  now.Add(-time.Duration(i) * time.Hour),
+			Temp:        sql.NullFloat64{Float64: 15, Valid: true},
+			Humidity:    sql.NullInt64{Int64: 70, Valid: true},
+			WindSpeed:   sql.NullFloat64{Float64: 5, Valid: true},
+			PrecipTotal: sql.NullFloat64{Float64: 0, Valid: true},
+		}
+		if i < 2 {
+			obs.SolarRadiation = sql.NullFloat64{Float64: 400, Valid: true}
+		}
+		if err := store.InsertObservation(obs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Outside the window, with pressure, so it mustn't count
+	if err := store.InsertObservation(models.Observation{
+		StationID:  "NOBARO",
+		ObservedAt: now.Add(-48 * time.Hour),
+		Pressure:   sql.NullFloat64{Float64: 1013, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetFieldCompleteness("NOBARO", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetFieldCompleteness: %v", err)
+	}
+	want := map[string]float64{"temp": 1, "humidity": 1, "pressure": 0, "wind": 1, "solar": 0.5, "precip": 1}
+	if len(got) != len(want) {
+		t.Errorf("GetFieldCompleteness = %v, want %v", got, want)
+	}
+	for field, w := range want {
+		if got[field] != w {
+			t.Errorf("%s = %v, want %v", field, got[field], w)
+		}
+	}
+
+	empty, err := store.GetFieldCompleteness("MISSING", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetFieldCompleteness: %v", err)
+	}
+	if empty["temp"] != 0 || len(empty) != len(want) {
+		t.Errorf("GetFieldCompleteness(MISSING) = %v, want every field at 0", empty)
+	}
+}
+
+func TestGetLatestObservation_ExcludeFuture(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "TEST001", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, obs := range []models.Observation{
+		{StationID: "TEST001", ObservedAt: now.Add(-5 * time.Minute), Temp: sql.NullFloat64{Float64: 18, Valid: true}},
+		// Slightly ahead but within tolerance, so ingest left it unflagged
+		{StationID: "TEST001", ObservedAt: now.Add(2 * time.Minute), Temp: sql.NullFloat64{Float64: 19, Valid: true}},
+		{
+			StationID:    "TEST001",
+			ObservedAt:   now.Add(6 * time.Hour),
+			Temp:         sql.NullFloat64{Float64: 30, Valid: true},
+			QualityFlags: sql.NullString{String: `["future_timestamp"]`, Valid: true},
+		},
+	} {
+		if err := store.InsertObservation(obs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := store.GetLatestObservation("TEST001", true)
+	if err != nil {
+		t.Fatalf("GetLatestObservation: %v", err)
+	}
+	if latest == nil || latest.Temp.Float64 != 19 {
+		t.Errorf("latest excluding future = %+v, want the slightly-ahead 19°C reading", latest)
+	}
+
+	latest, err = store.GetLatestObservation("TEST001", false)
+	if err != nil {
+		t.Fatalf("GetLatestObservation: %v", err)
+	}
+	if latest == nil || latest.Temp.Float64 != 30 {
+		t.Errorf("latest including future = %+v, want the flagged 30°C reading", latest)
+	}
+}
+
 func TestGetObservations_InclusiveDateRange(t *testing.T) {
 	store := setupTestStore(t)
 
@@ -601,3 +975,1129 @@ func TestIngestHealth_Aggregation(t *testing.T) {
 		t.Error("Expected health summary for wu/pws/observations/current")
 	}
 }
+
+func TestGetBiasStatsByRegime(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "PRIMARY", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	for i := 1; i <= 4; i++ {
+		validDate := time.Date(now.Year(), now.Month(), now.Day()-i, 0, 0, 0, 0, time.UTC)
+		heatwave := i <= 2
+		if err := store.UpsertDailySummary(models.DailySummary{
+			Date:           validDate,
+			StationID:      "PRIMARY",
+			TempMax:        sql.NullFloat64{Float64: 30, Valid: true},
+			RegimeHeatwave: sql.NullBool{Bool: heatwave, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+		if err := store.InsertForecast(models.Forecast{
+			Source:        "wu",
+			FetchedAt:     validDate.Add(-24 * time.Hour),
+			ValidDate:     validDate,
+			DayOfForecast: 1,
+			TempMax:       sql.NullFloat64{Float64: 30, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertForecast: %v", err)
+		}
+		bias := 1.0
+		if heatwave {
+			bias = 3.0
+		}
+		if err := store.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:  int64(i),
+			ValidDate:   validDate,
+			BiasTempMax: sql.NullFloat64{Float64: bias, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertForecastVerification: %v", err)
+		}
+	}
+
+	rows, err := store.GetBiasStatsByRegime(30)
+	if err != nil {
+		t.Fatalf("GetBiasStatsByRegime: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("len(rows) = %d, want 1 (only the heatwave days)", len(rows))
+	}
+	if rows[0].Regime != "heatwave" {
+		t.Errorf("Regime = %q, want heatwave", rows[0].Regime)
+	}
+	if rows[0].CountMax != 2 {
+		t.Errorf("CountMax = %d, want 2", rows[0].CountMax)
+	}
+	if rows[0].AvgBiasMax != 3.0 {
+		t.Errorf("AvgBiasMax = %v, want 3.0", rows[0].AvgBiasMax)
+	}
+}
+
+func TestDailySummary_StoredRegime(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "PRIMARY", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	validDate := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	// The stored regime takes precedence over the individual flags
+	if err := store.UpsertDailySummary(models.DailySummary{
+		Date:            validDate,
+		StationID:       "PRIMARY",
+		TempMax:         sql.NullFloat64{Float64: 18, Valid: true},
+		RegimeInversion: sql.NullBool{Bool: false, Valid: true},
+		Regime:          sql.NullString{String: "inversion", Valid: true},
+		Condition:       sql.NullString{String: "fog", Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertDailySummary: %v", err)
+	}
+
+	ds, err := store.GetDailySummary("PRIMARY", validDate)
+	if err != nil {
+		t.Fatalf("GetDailySummary: %v", err)
+	}
+	if ds == nil {
+		t.Fatal("expected summary")
+	}
+	if ds.Regime.String != "inversion" || ds.Condition.String != "fog" {
+		t.Errorf("Regime/Condition = %q/%q, want inversion/fog", ds.Regime.String, ds.Condition.String)
+	}
+
+	if err := store.InsertForecast(models.Forecast{
+		Source:        "wu",
+		FetchedAt:     validDate.Add(-24 * time.Hour),
+		ValidDate:     validDate,
+		DayOfForecast: 1,
+		TempMax:       sql.NullFloat64{Float64: 20, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertForecast: %v", err)
+	}
+	if err := store.UpsertForecastVerification(models.ForecastVerification{
+		ForecastID:  1,
+		ValidDate:   validDate,
+		BiasTempMax: sql.NullFloat64{Float64: 2, Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertForecastVerification: %v", err)
+	}
+
+	stats, err := store.GetRegimeVerificationStats(30)
+	if err != nil {
+		t.Fatalf("GetRegimeVerificationStats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Regime != "inversion" {
+		t.Fatalf("stats = %+v, want a single inversion row", stats)
+	}
+	if stats[0].WUDays != 1 || stats[0].WUMAEMax != 2 {
+		t.Errorf("WU stats = %d days, MAE %.1f, want 1 day, MAE 2.0", stats[0].WUDays, stats[0].WUMAEMax)
+	}
+}
+
+func TestBestSourceByRegime_MissingMinIsNotPerfect(t *testing.T) {
+	// WU has no verified mins, so a zero min MAE would make it the clear winner
+	stats := []RegimeStats{{
+		Regime:   "heatwave",
+		WUMAEMax: 1.5, WUDays: 5,
+		BOMMAEMax: 1.5, BOMMAEMin: 1.5, BOMDays: 5, BOMMinDays: 5,
+	}}
+	if got := BestSourceByRegime(stats)["heatwave"]; got != BestSourceInsufficientData {
+		t.Errorf("best source = %q, want %q", got, BestSourceInsufficientData)
+	}
+}
+
+func TestGetActualsForDate_DayPartHumidity(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Humid overnight readings either side of the day part don't count
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	for hour, humidity := range map[int]int64{3: 95, 10: 50, 15: 40, 22: 90} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:  "TEST001",
+			ObservedAt: time.Date(2025, 1, 15, hour, 0, 0, 0, store.loc).UTC(),
+			Temp:       sql.NullFloat64{Float64: 20, Valid: true},
+			Humidity:   sql.NullInt64{Int64: humidity, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	actuals, err := store.GetActualsForDate("TEST001", date)
+	if err != nil {
+		t.Fatalf("GetActualsForDate: %v", err)
+	}
+	if !actuals.HumidityAvg.Valid || actuals.HumidityAvg.Float64 != 45 {
+		t.Errorf("HumidityAvg = %+v, want the 7am-7pm mean of 45", actuals.HumidityAvg)
+	}
+}
+
+func TestUpsertForecastVerification(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().In(store.loc)
+	validDate := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, store.loc)
+	if err := store.InsertForecast(models.Forecast{
+		Source:        "wu",
+		FetchedAt:     validDate.Add(-24 * time.Hour),
+		ValidDate:     validDate,
+		DayOfForecast: 1,
+		TempMax:       sql.NullFloat64{Float64: 22, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertForecast: %v", err)
+	}
+
+	// A first pass, then a re-run at a different time of day after the
+	// actual max was corrected from 20°C to 21°C
+	for _, run := range []struct {
+		at     time.Time
+		actual float64
+	}{
+		{validDate.Add(6 * time.Hour), 20},
+		{validDate.Add(20 * time.Hour), 21},
+	} {
+		if err := store.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:      1,
+			ValidDate:       run.at,
+			ForecastTempMax: sql.NullFloat64{Float64: 22, Valid: true},
+			ActualTempMax:   sql.NullFloat64{Float64: run.actual, Valid: true},
+			BiasTempMax:     sql.NullFloat64{Float64: 22 - run.actual, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertForecastVerification: %v", err)
+		}
+	}
+
+	var count int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM forecast_verification`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d verification rows, want 1", count)
+	}
+
+	history, err := store.GetVerificationHistory("wu", 10)
+	if err != nil {
+		t.Fatalf("GetVerificationHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("got %d history rows, want 1", len(history))
+	}
+	if history[0].ActualTempMax.Float64 != 21 || history[0].BiasTempMax.Float64 != 1 {
+		t.Errorf("actual/bias = %.1f/%.1f, want 21.0/1.0", history[0].ActualTempMax.Float64, history[0].BiasTempMax.Float64)
+	}
+}
+
+func TestForecastVerification_Humidity(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC()
+	for i, bias := range []float64{6, -2} {
+		validDate := time.Date(now.Year(), now.Month(), now.Day()-i-1, 0, 0, 0, 0, time.UTC)
+		if err := store.InsertForecast(models.Forecast{
+			Source:        "wu",
+			FetchedAt:     validDate.Add(-24 * time.Hour),
+			ValidDate:     validDate,
+			DayOfForecast: 1,
+			TempMax:       sql.NullFloat64{Float64: 20, Valid: true},
+			Humidity:      sql.NullInt64{Int64: 60, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertForecast: %v", err)
+		}
+		if err := store.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:       int64(i + 1),
+			ValidDate:        validDate,
+			BiasTempMax:      sql.NullFloat64{Float64: 1, Valid: true},
+			ForecastHumidity: sql.NullFloat64{Float64: 60, Valid: true},
+			ActualHumidity:   sql.NullFloat64{Float64: 60 - bias, Valid: true},
+			BiasHumidity:     sql.NullFloat64{Float64: bias, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertForecastVerification: %v", err)
+		}
+	}
+
+	var forecastHumidity, actualHumidity, biasHumidity sql.NullFloat64
+	if err := store.db.QueryRow(`SELECT forecast_humidity, actual_humidity, bias_humidity FROM forecast_verification WHERE forecast_id = 1`).
+		Scan(&forecastHumidity, &actualHumidity, &biasHumidity); err != nil {
+		t.Fatalf("query verification: %v", err)
+	}
+	if forecastHumidity.Float64 != 60 || actualHumidity.Float64 != 54 || biasHumidity.Float64 != 6 {
+		t.Errorf("humidity forecast/actual/bias = %v/%v/%v, want 60/54/6",
+			forecastHumidity.Float64, actualHumidity.Float64, biasHumidity.Float64)
+	}
+
+	for name, get := range map[string]func() (map[string]models.VerificationStats, error){
+		"GetVerificationStats":     store.GetVerificationStats,
+		"GetDay1VerificationStats": store.GetDay1VerificationStats,
+	} {
+		stats, err := get()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		wu := stats["wu"]
+		if !wu.AvgHumidityBias.Valid || wu.AvgHumidityBias.Float64 != 2 {
+			t.Errorf("%s: AvgHumidityBias = %v, want 2", name, wu.AvgHumidityBias)
+		}
+		if !wu.MAEHumidity.Valid || wu.MAEHumidity.Float64 != 4 {
+			t.Errorf("%s: MAEHumidity = %v, want 4", name, wu.MAEHumidity)
+		}
+	}
+}
+
+func TestGetWorstForecasts(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "PRIMARY", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	seed := []struct {
+		source string
+		bias   float64
+		regime string
+	}{
+		{"wu", 1.5, ""},
+		{"wu", -6, "heatwave"},
+		{"bom", 4, "inversion"},
+		{"wu", 3, ""},
+		{"bom", -0.5, ""},
+	}
+	for i, sd := range seed {
+		validDate := time.Date(now.Year(), now.Month(), now.Day()-i-1, 0, 0, 0, 0, time.UTC)
+		if sd.regime != "" {
+			if err := store.UpsertDailySummary(models.DailySummary{
+				Date:      validDate,
+				StationID: "PRIMARY",
+				Regime:    sql.NullString{String: sd.regime, Valid: true},
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.InsertForecast(models.Forecast{
+			Source:        sd.source,
+			FetchedAt:     validDate.AddDate(0, 0, -1),
+			ValidDate:     validDate,
+			DayOfForecast: 1,
+			TempMax:       sql.NullFloat64{Float64: 25 + sd.bias, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:  int64(i + 1),
+			ValidDate:   validDate,
+			BiasTempMax: sql.NullFloat64{Float64: sd.bias, Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		source      string
+		limit       int
+		wantBiases  []float64
+		wantRegimes []string
+	}{
+		{"", 3, []float64{-6, 4, 3}, []string{"heatwave", "inversion", ""}},
+		{"wu", 10, []float64{-6, 3, 1.5}, []string{"heatwave", "", ""}},
+		{"bom", 1, []float64{4}, []string{"inversion"}},
+	}
+	for _, tt := range tests {
+		worst, err := store.GetWorstForecasts(tt.source, tt.limit)
+		if err != nil {
+			t.Fatalf("GetWorstForecasts(%q): %v", tt.source, err)
+		}
+		if len(worst) != len(tt.wantBiases) {
+			t.Fatalf("GetWorstForecasts(%q) returned %d rows, want %d", tt.source, len(worst), len(tt.wantBiases))
+		}
+		for i, w := range worst {
+			if w.BiasTempMax.Float64 != tt.wantBiases[i] {
+				t.Errorf("GetWorstForecasts(%q)[%d] bias = %v, want %v", tt.source, i, w.BiasTempMax.Float64, tt.wantBiases[i])
+			}
+			if w.Regime != tt.wantRegimes[i] {
+				t.Errorf("GetWorstForecasts(%q)[%d] regime = %q, want %q", tt.source, i, w.Regime, tt.wantRegimes[i])
+			}
+			if tt.source != "" && w.Source != tt.source {
+				t.Errorf("GetWorstForecasts(%q)[%d] source = %q", tt.source, i, w.Source)
+			}
+		}
+	}
+}
+
+func TestGetBestSourceByRegime(t *testing.T) {
+	store := setupTestStore(t)
+
+	if err := store.UpsertStation(models.Station{StationID: "PRIMARY", IsPrimary: true, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	days := []struct {
+		regime          string
+		wuBias, bomBias float64
+	}{
+		{"heatwave", 3, 1},
+		{"heatwave", -2.5, 0.5},
+		{"heatwave", 3.5, -1},
+		{"inversion", 0.5, 2},
+		{"inversion", -1, 3},
+		{"inversion", 0, -2.5},
+		{"clear_calm", 1, 3},
+	}
+
+	now := time.Now().UTC()
+	var forecastID int64
+	for i, d := range days {
+		validDate := time.Date(now.Year(), now.Month(), now.Day()-i-1, 0, 0, 0, 0, time.UTC)
+		if err := store.UpsertDailySummary(models.DailySummary{
+			Date:      validDate,
+			StationID: "PRIMARY",
+			TempMax:   sql.NullFloat64{Float64: 25, Valid: true},
+			Regime:    sql.NullString{String: d.regime, Valid: true},
+		}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+
+		for _, fc := range []struct {
+			source string
+			lead   int
+			bias   float64
+		}{{"wu", 1, d.wuBias}, {"bom", 2, d.bomBias}} {
+			if err := store.InsertForecast(models.Forecast{
+				Source:        fc.source,
+				FetchedAt:     validDate.AddDate(0, 0, -fc.lead),
+				ValidDate:     validDate,
+				DayOfForecast: fc.lead,
+				TempMax:       sql.NullFloat64{Float64: 25 + fc.bias, Valid: true},
+			}); err != nil {
+				t.Fatalf("InsertForecast: %v", err)
+			}
+			forecastID++
+			if err := store.UpsertForecastVerification(models.ForecastVerification{
+				ForecastID:  forecastID,
+				ValidDate:   validDate,
+				BiasTempMax: sql.NullFloat64{Float64: fc.bias, Valid: true},
+				BiasTempMin: sql.NullFloat64{Float64: fc.bias, Valid: true},
+			}); err != nil {
+				t.Fatalf("UpsertForecastVerification: %v", err)
+			}
+		}
+	}
+
+	best, err := store.GetBestSourceByRegime(30)
+	if err != nil {
+		t.Fatalf("GetBestSourceByRegime: %v", err)
+	}
+
+	want := map[string]string{
+		"heatwave":   "bom",
+		"inversion":  "wu",
+		"clear_calm": BestSourceInsufficientData,
+	}
+	for regime, source := range want {
+		if best[regime] != source {
+			t.Errorf("best[%q] = %q, want %q", regime, best[regime], source)
+		}
+	}
+}
+
+func TestMigrate_AccuracyIndexesOnExistingDB(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Simulate a database created before the index migration, with existing data.
+	if _, err := store.db.Exec(`
+		DROP INDEX idx_verification_valid_date;
+		DROP INDEX idx_verification_forecast_id;
+		DROP INDEX idx_displayed_forecasts_valid_date;
+		DELETE FROM schema_migrations WHERE version = 27;
+	`); err != nil {
+		t.Fatalf("rollback migration: %v", err)
+	}
+	if err := store.UpsertForecastVerification(models.ForecastVerification{
+		ForecastID:  1,
+		ValidDate:   time.Now().UTC(),
+		BiasTempMax: sql.NullFloat64{Float64: 1, Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertForecastVerification: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	version, err := store.MigrationVersion()
+	if err != nil {
+		t.Fatalf("MigrationVersion: %v", err)
+	}
+	if version < 27 {
+		t.Errorf("MigrationVersion = %d, want >= 27", version)
+	}
+
+	for _, name := range []string{"idx_verification_valid_date", "idx_verification_forecast_id", "idx_displayed_forecasts_valid_date"} {
+		var count int
+		if err := store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?`, name).Scan(&count); err != nil {
+			t.Fatalf("query index %s: %v", name, err)
+		}
+		if count != 1 {
+			t.Errorf("index %s not found", name)
+		}
+	}
+}
+
+func TestGetTodayStats_UsesStoreLocation(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().In(store.loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, store.loc)
+
+	// Just before local midnight belongs to yesterday even though it may be
+	// the same UTC date; local midnight itself starts today.
+	for _, obs := range []struct {
+		at   time.Time
+		temp float64
+	}{
+		{dayStart.Add(-30 * time.Minute), 40},
+		{dayStart, 12},
+	} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: obs.at.UTC(),
+			Temp:       sql.NullFloat64{Float64: obs.temp, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	minTemp, maxTemp, _, _, _, err := store.GetTodayStats("TEST1", now)
+	if err != nil {
+		t.Fatalf("GetTodayStats: %v", err)
+	}
+	if !maxTemp.Valid || maxTemp.Float64 != 12 {
+		t.Errorf("max = %v, want 12 (yesterday's 40 excluded)", maxTemp)
+	}
+	if !minTemp.Valid || minTemp.Float64 != 12 {
+		t.Errorf("min = %v, want 12", minTemp)
+	}
+}
+
+func TestGetTodayStatsExtended_AsOf(t *testing.T) {
+	store := setupTestStore(t)
+
+	// 2pm on a past winter day, with readings from the night before, that
+	// morning, and that evening after asOf
+	asOf := time.Date(2024, 6, 15, 14, 0, 0, 0, store.loc)
+	for _, obs := range []struct {
+		at   time.Time
+		temp float64
+		gust float64
+	}{
+		{time.Date(2024, 6, 14, 23, 30, 0, 0, store.loc), -4, 50},
+		{time.Date(2024, 6, 15, 6, 0, 0, 0, store.loc), -1, 10},
+		{time.Date(2024, 6, 15, 13, 0, 0, 0, store.loc), 11, 25},
+		{asOf, 12, 15},
+		{time.Date(2024, 6, 15, 15, 0, 0, 0, store.loc), 14, 60},
+	} {
+		if err := store.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: obs.at.UTC(),
+			Temp:       sql.NullFloat64{Float64: obs.temp, Valid: true},
+			WindGust:   sql.NullFloat64{Float64: obs.gust, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	stats, err := store.GetTodayStatsExtended("TEST1", asOf)
+	if err != nil {
+		t.Fatalf("GetTodayStatsExtended: %v", err)
+	}
+	if !stats.MinTemp.Valid || stats.MinTemp.Float64 != -1 {
+		t.Errorf("MinTemp = %v, want -1", stats.MinTemp)
+	}
+	if !stats.MaxTemp.Valid || stats.MaxTemp.Float64 != 12 {
+		t.Errorf("MaxTemp = %v, want 12 (the 3pm reading is after asOf)", stats.MaxTemp)
+	}
+	if !stats.MaxGust.Valid || stats.MaxGust.Float64 != 25 {
+		t.Errorf("MaxGust = %v, want 25", stats.MaxGust)
+	}
+	if !stats.MaxTempTime.Valid || !stats.MaxTempTime.Time.Equal(asOf) {
+		t.Errorf("MaxTempTime = %v, want %v", stats.MaxTempTime, asOf)
+	}
+
+	// The same instant expressed in UTC is still the 15th in Melbourne
+	utcStats, err := store.GetTodayStatsExtended("TEST1", asOf.UTC())
+	if err != nil {
+		t.Fatalf("GetTodayStatsExtended: %v", err)
+	}
+	if utcStats.MinTemp != stats.MinTemp || utcStats.MaxTemp != stats.MaxTemp {
+		t.Errorf("UTC asOf gave %v/%v, want %v/%v", utcStats.MinTemp, utcStats.MaxTemp, stats.MinTemp, stats.MaxTemp)
+	}
+}
+
+func TestGetTodayStatsExtended_HumidityAndDewpoint(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().In(store.loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, store.loc)
+
+	// Yesterday's reading is outside the window and must not set the extremes
+	if err := store.InsertObservation(models.Observation{
+		StationID:  "TEST1",
+		ObservedAt: dayStart.Add(-time.Hour).UTC(),
+		Humidity:   sql.NullInt64{Int64: 20, Valid: true},
+		Dewpoint:   sql.NullFloat64{Float64: -5, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+
+	readings := []struct {
+		humidity int64
+		dewpoint float64
+	}{
+		{95, 14}, {80, 12.5}, {45, 9}, {62, 11},
+	}
+	for i, r := range readings {
+		at := dayStart.Add(time.Duration(i) * time.Second)
+		if err := store.InsertObservation(models.Observation{
+			StationID:  "TEST1",
+			ObservedAt: at.UTC(),
+			Humidity:   sql.NullInt64{Int64: r.humidity, Valid: true},
+			Dewpoint:   sql.NullFloat64{Float64: r.dewpoint, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	stats, err := store.GetTodayStatsExtended("TEST1", now)
+	if err != nil {
+		t.Fatalf("GetTodayStatsExtended: %v", err)
+	}
+	if !stats.MinHumidity.Valid || stats.MinHumidity.Int64 != 45 {
+		t.Errorf("MinHumidity = %v, want 45", stats.MinHumidity)
+	}
+	if !stats.MaxHumidity.Valid || stats.MaxHumidity.Int64 != 95 {
+		t.Errorf("MaxHumidity = %v, want 95", stats.MaxHumidity)
+	}
+	if !stats.MinDewpoint.Valid || stats.MinDewpoint.Float64 != 9 {
+		t.Errorf("MinDewpoint = %v, want 9", stats.MinDewpoint)
+	}
+}
+
+func TestGetObservationBuckets(t *testing.T) {
+	store := setupTestStore(t)
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	// 0.1mm falls every minute, counting from a reading just before start
+	if err := store.InsertObservation(models.Observation{
+		StationID:   "TEST001",
+		ObservedAt:  start.Add(-time.Minute),
+		PrecipTotal: sql.NullFloat64{Float64: 2.0, Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	for i := 0; i < 60; i++ {
+		if err := store.InsertObservation(models.Observation{
+			StationID:   "TEST001",
+			ObservedAt:  start.Add(time.Duration(i) * time.Minute),
+			Temp:        sql.NullFloat64{Float64: float64(i), Valid: true},
+			WindGust:    sql.NullFloat64{Float64: float64(i % 15), Valid: true},
+			PrecipTotal: sql.NullFloat64{Float64: 2.0 + float64(i+1)*0.1, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	buckets, err := store.GetObservationBuckets("TEST001", start, start.Add(time.Hour), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GetObservationBuckets: %v", err)
+	}
+	if len(buckets) != 4 {
+		t.Fatalf("len(buckets) = %d, want 4", len(buckets))
+	}
+
+	for i, b := range buckets {
+		wantStart := start.Add(time.Duration(i) * 15 * time.Minute)
+		if !b.Start.Equal(wantStart) {
+			t.Errorf("bucket %d Start = %v, want %v", i, b.Start, wantStart)
+		}
+		if b.Samples != 15 {
+			t.Errorf("bucket %d Samples = %d, want 15", i, b.Samples)
+		}
+		// Minutes 15i..15i+14 average to 15i+7
+		if wantAvg := float64(15*i + 7); b.TempAvg.Float64 != wantAvg {
+			t.Errorf("bucket %d TempAvg = %v, want %v", i, b.TempAvg.Float64, wantAvg)
+		}
+		if b.WindGustMax.Float64 != 14 {
+			t.Errorf("bucket %d WindGustMax = %v, want 14", i, b.WindGustMax.Float64)
+		}
+		if math.Abs(b.Precip.Float64-1.5) > 1e-9 {
+			t.Errorf("bucket %d Precip = %v, want 1.5", i, b.Precip.Float64)
+		}
+	}
+}
+
+func TestGetObservationBuckets_PrecipDailyReset(t *testing.T) {
+	store := setupTestStore(t)
+
+	// 5-minute readings across the daily reset: 0.5mm before it, then the
+	// total restarts at 0.2mm and climbs to 0.6mm
+	start := time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)
+	totals := []float64{4.0, 4.2, 4.5, 0.2, 0.4, 0.6}
+	for i, total := range totals {
+		if err := store.InsertObservation(models.Observation{
+			StationID:   "TEST001",
+			ObservedAt:  start.Add(time.Duration(i) * 5 * time.Minute),
+			PrecipTotal: sql.NullFloat64{Float64: total, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	buckets, err := store.GetObservationBuckets("TEST001", start, start.Add(30*time.Minute), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GetObservationBuckets: %v", err)
+	}
+	want := []float64{0.5, 0.6}
+	if len(buckets) != len(want) {
+		t.Fatalf("len(buckets) = %d, want %d", len(buckets), len(want))
+	}
+	for i, b := range buckets {
+		if !b.Precip.Valid || math.Abs(b.Precip.Float64-want[i]) > 1e-9 {
+			t.Errorf("bucket %d Precip = %+v, want %v", i, b.Precip, want[i])
+		}
+	}
+}
+
+func TestGetObservationBuckets_PrecipPredecessor(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Noon local, after an overnight outage. Yesterday's last reading and a
+	// QC-flagged one this morning can't be the predecessor, so only the rise
+	// between the two clean readings in the window counts.
+	start := time.Date(2025, 1, 15, 1, 0, 0, 0, time.UTC)
+	readings := []struct {
+		at      time.Time
+		total   float64
+		flagged bool
+	}{
+		{start.Add(-14 * time.Hour), 1.0, false}, // 10pm yesterday
+		{start.Add(-time.Hour), 9.0, true},
+		{start.Add(5 * time.Minute), 3.0, false},
+		{start.Add(10 * time.Minute), 3.4, false},
+	}
+	for _, r := range readings {
+		obs := models.Observation{
+			StationID:   "TEST001",
+			ObservedAt:  r.at,
+			PrecipTotal: sql.NullFloat64{Float64: r.total, Valid: true},
+		}
+		if r.flagged {
+			obs.QCStatus = 2
+		}
+		if err := store.InsertObservation(obs); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	buckets, err := store.GetObservationBuckets("TEST001", start, start.Add(15*time.Minute), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GetObservationBuckets: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("len(buckets) = %d, want 1", len(buckets))
+	}
+	if b := buckets[0]; !b.Precip.Valid || math.Abs(b.Precip.Float64-0.4) > 1e-9 {
+		t.Errorf("Precip = %+v, want 0.4", b.Precip)
+	}
+}
+
+func TestGetDiurnalRange(t *testing.T) {
+	store := setupTestStore(t)
+
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	temp := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	summaries := []models.DailySummary{
+		{Date: day(1), StationID: "PRIMARY", TempMax: temp(28.5), TempMin: temp(8.0)},
+		{Date: day(2), StationID: "PRIMARY", TempMax: temp(22.0), TempMin: temp(12.5)},
+		{Date: day(3), StationID: "PRIMARY", TempMax: temp(25.0)}, // no min
+		{Date: day(4), StationID: "PRIMARY", TempMin: temp(6.0)},  // no max
+		{Date: day(5), StationID: "PRIMARY", TempMax: temp(31.0), TempMin: temp(4.0)},
+		{Date: day(6), StationID: "PRIMARY", TempMax: temp(20.0), TempMin: temp(10.0)}, // after end
+		{Date: day(2), StationID: "OTHER", TempMax: temp(40.0), TempMin: temp(0.0)},
+	}
+	for _, ds := range summaries {
+		if err := store.UpsertDailySummary(ds); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+
+	ranges, err := store.GetDiurnalRange("PRIMARY", day(1), day(5))
+	if err != nil {
+		t.Fatalf("GetDiurnalRange: %v", err)
+	}
+
+	want := []struct {
+		day   int
+		value float64
+	}{{1, 20.5}, {2, 9.5}, {5, 27.0}}
+	if len(ranges) != len(want) {
+		t.Fatalf("len(ranges) = %d, want %d: %+v", len(ranges), len(want), ranges)
+	}
+	for i, w := range want {
+		if got := ranges[i].Date.Format("2006-01-02"); got != day(w.day).Format("2006-01-02") {
+			t.Errorf("ranges[%d].Date = %s, want %s", i, got, day(w.day).Format("2006-01-02"))
+		}
+		if ranges[i].Range != w.value {
+			t.Errorf("ranges[%d].Range = %v, want %v", i, ranges[i].Range, w.value)
+		}
+	}
+}
+
+func TestGetPrecipCalibration(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC()
+	rain := func(mm float64) sql.NullFloat64 { return sql.NullFloat64{Float64: mm, Valid: true} }
+	seeds := []struct {
+		source  string
+		daysAgo int
+		chance  int64
+		actual  sql.NullFloat64
+	}{
+		// 10-19%: one of four rained.
+		{"wu", 1, 10, rain(0)},
+		{"wu", 2, 15, rain(0)},
+		{"wu", 3, 10, rain(0)},
+		{"wu", 4, 15, rain(1.0)},
+		// 60-69%: three of five rained; 0.1mm is below a rain day.
+		{"wu", 5, 60, rain(3)},
+		{"wu", 6, 60, rain(0.2)},
+		{"wu", 7, 60, rain(0.1)},
+		{"wu", 8, 60, rain(0)},
+		{"wu", 9, 60, rain(5)},
+		// 90-100%: both rained, and 100% shares the top decile.
+		{"wu", 10, 90, rain(12)},
+		{"wu", 11, 100, rain(4)},
+		// Excluded: another source, no observed rain, outside the window,
+		// and chances that aren't percentages.
+		{"bom", 1, 60, rain(0)},
+		{"wu", 12, 30, sql.NullFloat64{}},
+		{"wu", 200, 10, rain(8)},
+		{"wu", 13, -20, rain(0)},
+		{"wu", 14, 150, rain(0)},
+	}
+	for i, sd := range seeds {
+		validDate := time.Date(now.Year(), now.Month(), now.Day()-sd.daysAgo, 0, 0, 0, 0, time.UTC)
+		if err := store.InsertForecast(models.Forecast{
+			Source:        sd.source,
+			FetchedAt:     validDate.Add(-24 * time.Hour),
+			ValidDate:     validDate,
+			DayOfForecast: 1,
+			PrecipChance:  sql.NullInt64{Int64: sd.chance, Valid: true},
+		}); err != nil {
+			t.Fatalf("InsertForecast: %v", err)
+		}
+		if err := store.UpsertForecastVerification(models.ForecastVerification{
+			ForecastID:   int64(i + 1),
+			ValidDate:    validDate,
+			ActualPrecip: sd.actual,
+		}); err != nil {
+			t.Fatalf("UpsertForecastVerification: %v", err)
+		}
+	}
+
+	buckets, err := store.GetPrecipCalibration("wu", 30)
+	if err != nil {
+		t.Fatalf("GetPrecipCalibration: %v", err)
+	}
+	want := []PrecipCalibrationBucket{
+		{Bucket: 1, Forecast: 0.125, Observed: 0.25, N: 4},
+		{Bucket: 6, Forecast: 0.6, Observed: 0.6, N: 5},
+		{Bucket: 9, Forecast: 0.95, Observed: 1, N: 2},
+	}
+	if len(buckets) != len(want) {
+		t.Fatalf("buckets = %+v, want %+v", buckets, want)
+	}
+	for i, w := range want {
+		b := buckets[i]
+		if b.Bucket != w.Bucket || b.N != w.N ||
+			math.Abs(b.Forecast-w.Forecast) > 1e-9 || math.Abs(b.Observed-w.Observed) > 1e-9 {
+			t.Errorf("buckets[%d] = %+v, want %+v", i, b, w)
+		}
+	}
+}
+
+func TestGetIngestLatencyStats(t *testing.T) {
+	store := setupTestStore(t)
+
+	insert := func(source, endpoint string, startedAt time.Time, took time.Duration, finished bool) {
+		t.Helper()
+		var finishedAt sql.NullTime
+		if finished {
+			finishedAt = sql.NullTime{Time: startedAt.Add(took), Valid: true}
+		}
+		if _, err := store.db.Exec(`
+			INSERT INTO ingest_runs (started_at, finished_at, source, endpoint, success)
+			VALUES (?, ?, ?, ?, TRUE)
+		`, startedAt, finishedAt, source, endpoint); err != nil {
+			t.Fatalf("insert ingest run: %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	// 1s to 20s: p50 is the 10th run, p95 the 19th
+	for i := 1; i <= 20; i++ {
+		insert("wu", "pws/observations/current", now.Add(-time.Duration(i)*time.Minute), time.Duration(i)*time.Second, true)
+	}
+	insert("bom", "forecast/fwo", now.Add(-time.Hour), 2500*time.Millisecond, true)
+	// Unfinished and out-of-window runs are left out
+	insert("bom", "forecast/fwo", now.Add(-30*time.Minute), 0, false)
+	insert("bom", "forecast/fwo", now.AddDate(0, 0, -10), time.Minute, true)
+
+	stats, err := store.GetIngestLatencyStats(7)
+	if err != nil {
+		t.Fatalf("GetIngestLatencyStats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(stats), stats)
+	}
+
+	wu := stats["wu/pws/observations/current"]
+	if wu.Runs != 20 || wu.P50 != 10*time.Second || wu.P95 != 19*time.Second || wu.Max != 20*time.Second {
+		t.Errorf("wu = %+v, want 20 runs, p50 10s, p95 19s, max 20s", wu)
+	}
+	bom := stats["bom/forecast/fwo"]
+	if bom.Runs != 1 || bom.P50 != 2500*time.Millisecond || bom.P95 != 2500*time.Millisecond || bom.Max != 2500*time.Millisecond {
+		t.Errorf("bom = %+v, want 1 run of 2.5s", bom)
+	}
+}
+
+func TestCleanupOldRawPayloads(t *testing.T) {
+	store := setupTestStore(t)
+
+	for i, age := range []int{0, 10, 40} {
+		id, err := store.StoreRawPayload(nil, "wu", "pws/current", nil, nil, []byte(fmt.Sprintf(`{"age": %d}`, age)))
+		if err != nil {
+			t.Fatalf("StoreRawPayload %d: %v", i, err)
+		}
+		if _, err := store.db.Exec(`UPDATE raw_payloads SET fetched_at = ? WHERE id = ?`, time.Now().UTC().AddDate(0, 0, -age), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := store.CleanupOldRawPayloads(30)
+	if err != nil {
+		t.Fatalf("CleanupOldRawPayloads: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+
+	stats, err := store.GetRawPayloadStats()
+	if err != nil {
+		t.Fatalf("GetRawPayloadStats: %v", err)
+	}
+	if stats.TotalCount != 2 {
+		t.Errorf("%d payloads remain, want 2", stats.TotalCount)
+	}
+	if age := time.Since(stats.OldestFetchedAt); age < 9*24*time.Hour || age > 11*24*time.Hour {
+		t.Errorf("oldest remaining payload is %v old, want the 10-day-old one", age)
+	}
+}
+
+func TestGetCorrectedVsRawSeries(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC()
+	day1 := time.Date(now.Year(), now.Month(), now.Day()-2, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	nf := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	for _, ds := range []models.DailySummary{
+		{Date: day1, StationID: "PRIMARY", TempMax: nf(20), TempMin: nf(5)},
+		{Date: day2, StationID: "PRIMARY", TempMax: nf(25), TempMin: nf(10)},
+	} {
+		if err := store.UpsertDailySummary(ds); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+
+	for _, df := range []models.DisplayedForecast{
+		// Superseded by the later display for the same day
+		{DisplayedAt: day1.Add(-48 * time.Hour), ValidDate: day1, DayOfForecast: 2,
+			RawTempMax: nf(30), RawTempMin: nf(15), CorrectedTempMax: nf(30), CorrectedTempMin: nf(15)},
+		// Raw misses by 4 and 2, corrected by 1 and 1
+		{DisplayedAt: day1.Add(-24 * time.Hour), ValidDate: day1, DayOfForecast: 1,
+			RawTempMax: nf(24), RawTempMin: nf(3), CorrectedTempMax: nf(21), CorrectedTempMin: nf(6)},
+		// Raw misses by 1 and 1, corrected by 2 and 3
+		{DisplayedAt: day2.Add(-24 * time.Hour), ValidDate: day2, DayOfForecast: 1,
+			RawTempMax: nf(24), RawTempMin: nf(11), CorrectedTempMax: nf(27), CorrectedTempMin: nf(7)},
+	} {
+		if err := store.UpsertDisplayedForecast(df); err != nil {
+			t.Fatalf("UpsertDisplayedForecast: %v", err)
+		}
+	}
+
+	series, err := store.GetCorrectedVsRawSeries("PRIMARY", 7)
+	if err != nil {
+		t.Fatalf("GetCorrectedVsRawSeries: %v", err)
+	}
+	if len(series) != 2 {
+		t.Fatalf("got %d days, want 2", len(series))
+	}
+
+	want := []struct {
+		date                 time.Time
+		rawMAE, correctedMAE float64
+	}{
+		{day1, 3, 1},
+		{day2, 1, 2.5},
+	}
+	for i, w := range want {
+		got := series[i]
+		if got.Date.Format("2006-01-02") != w.date.Format("2006-01-02") {
+			t.Errorf("day %d date = %s, want %s", i, got.Date.Format("2006-01-02"), w.date.Format("2006-01-02"))
+		}
+		if math.Abs(got.RawMAE-w.rawMAE) > 0.001 {
+			t.Errorf("day %d RawMAE = %.2f, want %.2f", i, got.RawMAE, w.rawMAE)
+		}
+		if math.Abs(got.CorrectedMAE-w.correctedMAE) > 0.001 {
+			t.Errorf("day %d CorrectedMAE = %.2f, want %.2f", i, got.CorrectedMAE, w.correctedMAE)
+		}
+	}
+}
+
+func TestGetCorrectedAccuracyBySource(t *testing.T) {
+	store := setupTestStore(t)
+
+	now := time.Now().UTC()
+	day1 := time.Date(now.Year(), now.Month(), now.Day()-3, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day2.AddDate(0, 0, 1)
+
+	nf := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	ns := func(v string) sql.NullString { return sql.NullString{String: v, Valid: true} }
+	for _, day := range []time.Time{day1, day2, day3} {
+		if err := store.UpsertDailySummary(models.DailySummary{Date: day, StationID: "PRIMARY", TempMax: nf(20), TempMin: nf(5)}); err != nil {
+			t.Fatalf("UpsertDailySummary: %v", err)
+		}
+	}
+
+	for _, df := range []models.DisplayedForecast{
+		// Blend misses the max by 1 and 3, WU the min by 2 both days
+		{DisplayedAt: day1.Add(-24 * time.Hour), ValidDate: day1, DayOfForecast: 1,
+			CorrectedTempMax: nf(21), CorrectedTempMin: nf(7), SourceMax: ns("blend"), SourceMin: ns("wu")},
+		{DisplayedAt: day2.Add(-24 * time.Hour), ValidDate: day2, DayOfForecast: 1,
+			CorrectedTempMax: nf(17), CorrectedTempMin: nf(3), SourceMax: ns("blend"), SourceMin: ns("wu")},
+		// BOM misses the max by 4; blend the min by 1
+		{DisplayedAt: day3.Add(-24 * time.Hour), ValidDate: day3, DayOfForecast: 1,
+			CorrectedTempMax: nf(24), CorrectedTempMin: nf(6), SourceMax: ns("bom"), SourceMin: ns("blend")},
+	} {
+		if err := store.UpsertDisplayedForecast(df); err != nil {
+			t.Fatalf("UpsertDisplayedForecast: %v", err)
+		}
+	}
+
+	got, err := store.GetCorrectedAccuracyBySource("PRIMARY", 7)
+	if err != nil {
+		t.Fatalf("GetCorrectedAccuracyBySource: %v", err)
+	}
+	want := []struct {
+		source           string
+		maxDays, minDays int
+		maeMax, maeMin   float64
+	}{
+		{"blend", 2, 1, 2, 1},
+		{"bom", 1, 0, 4, 0},
+		{"wu", 0, 2, 0, 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sources, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Source != w.source || g.MaxDays != w.maxDays || g.MinDays != w.minDays {
+			t.Errorf("row %d = %s max %d days min %d days, want %s %d %d", i, g.Source, g.MaxDays, g.MinDays, w.source, w.maxDays, w.minDays)
+		}
+		if g.MAEMax.Float64 != w.maeMax || g.MAEMin.Float64 != w.maeMin {
+			t.Errorf("%s MAE = %.1f/%.1f, want %.1f/%.1f", g.Source, g.MAEMax.Float64, g.MAEMin.Float64, w.maeMax, w.maeMin)
+		}
+	}
+}
+
+func TestGetCleanCountsByStation(t *testing.T) {
+	store := setupTestStore(t)
+	baseTime := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// GOOD flags one reading in ten, FLAKY four in ten
+	for station, flagged := range map[string]int{"GOOD": 1, "FLAKY": 4} {
+		for i := range 10 {
+			obs := models.Observation{
+				StationID:  station,
+				ObservedAt: baseTime.Add(time.Duration(i) * 5 * time.Minute),
+				Temp:       sql.NullFloat64{Float64: 20, Valid: true},
+				ObsType:    models.ObsTypeInstant,
+			}
+			if i < flagged {
+				obs.QualityFlags = sql.NullString{String: `["temp_out_of_range"]`, Valid: true}
+			}
+			if err := store.InsertObservation(obs); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	counts, err := store.GetCleanCountsByStation()
+	if err != nil {
+		t.Fatalf("GetCleanCountsByStation: %v", err)
+	}
+	want := []StationCleanCount{
+		{StationID: "FLAKY", Total: 10, Clean: 6, Flagged: 4, FlaggedPct: 40},
+		{StationID: "GOOD", Total: 10, Clean: 9, Flagged: 1, FlaggedPct: 10},
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("got %d stations, want %d: %+v", len(counts), len(want), counts)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("counts[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+}
+
+func TestUpsertForecastVerification_MatchesMigratedRow(t *testing.T) {
+	store := setupTestStore(t)
+
+	// Simulate verifications stored before the unique index, with a bound
+	// time.Time's text and a duplicate for the same forecast and day.
+	if _, err := store.db.Exec(`
+		DROP INDEX idx_forecast_verification_unique;
+		DELETE FROM schema_migrations WHERE version IN (33, 36);
+		INSERT INTO forecast_verification (forecast_id, valid_date, actual_temp_max)
+		VALUES (1, '2026-01-10 00:00:00 +0000 UTC', 20), (1, '2026-01-10 00:00:00 +0000 UTC', 21);
+	`); err != nil {
+		t.Fatalf("rollback migration: %v", err)
+	}
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if err := store.UpsertForecastVerification(models.ForecastVerification{
+		ForecastID:    1,
+		ValidDate:     time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		ActualTempMax: sql.NullFloat64{Float64: 22, Valid: true},
+	}); err != nil {
+		t.Fatalf("UpsertForecastVerification: %v", err)
+	}
+
+	var count int
+	var actual float64
+	if err := store.db.QueryRow(`SELECT COUNT(*), MAX(actual_temp_max) FROM forecast_verification WHERE forecast_id = 1`).Scan(&count, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d verification rows, want 1", count)
+	}
+	if actual != 22 {
+		t.Errorf("actual_temp_max = %v, want re-verified 22", actual)
+	}
+}